@@ -0,0 +1,61 @@
+//go:build js && wasm
+
+// Command ankiprep-wasm exposes the conversion pipeline to JavaScript, for the
+// static page in web/. It registers one global function:
+//
+//	ankiprepProcess(csv, options) -> {output, records, duplicates} or {error}
+//
+// where options is an object with the boolean fields french, smartQuotes,
+// autoLang, skipDuplicates, keepHeader, noHeader and tabSeparated.
+package main
+
+import (
+	"syscall/js"
+
+	"ankiprep/pkg/ankiprep"
+)
+
+func main() {
+	js.Global().Set("ankiprepProcess", js.FuncOf(process))
+
+	// Keep the Go runtime alive for later calls from the page
+	select {}
+}
+
+// process is the JavaScript binding of ankiprep.ProcessCSVString
+func process(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return map[string]interface{}{"error": "ankiprepProcess needs the CSV content as a string"}
+	}
+
+	var opts []ankiprep.Option
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		flags := []struct {
+			name   string
+			option ankiprep.Option
+		}{
+			{"french", ankiprep.WithFrenchTypography()},
+			{"smartQuotes", ankiprep.WithSmartQuotes()},
+			{"autoLang", ankiprep.WithAutoLanguage()},
+			{"skipDuplicates", ankiprep.WithDedupe(ankiprep.DedupeExact)},
+			{"keepHeader", ankiprep.WithKeepHeader()},
+			{"noHeader", ankiprep.WithNoHeader()},
+			{"tabSeparated", ankiprep.WithTabSeparated()},
+		}
+		for _, flag := range flags {
+			if value := args[1].Get(flag.name); value.Type() == js.TypeBoolean && value.Bool() {
+				opts = append(opts, flag.option)
+			}
+		}
+	}
+
+	output, result, err := ankiprep.ProcessCSVString(args[0].String(), opts...)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	return map[string]interface{}{
+		"output":     output,
+		"records":    result.OutputRecords,
+		"duplicates": result.DuplicatesRemoved,
+	}
+}
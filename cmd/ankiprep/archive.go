@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"ankiprep/internal/models"
+)
+
+// archiveInputs maps the name of each input file read from a .zip argument,
+// such as bundle.zip:chapter1.csv, to the archive it came from
+var archiveInputs = map[string]string{}
+
+// expandArchives replaces the .zip archives among inputPaths with the CSV and
+// TSV files they contain, extracted through fileService. It returns the paths
+// to parse and the name to show for each extracted file. Archives that cannot
+// be read are handled by the --on-error policy like other input files.
+func expandArchives(inputPaths []string) ([]string, map[string]string, error) {
+	var expanded []string
+	names := make(map[string]string)
+	for _, path := range inputPaths {
+		if !models.IsZip(path) {
+			expanded = append(expanded, path)
+			continue
+		}
+
+		files, err := fileService.ExtractZip(path, isSupportedFile)
+		if err == nil && len(files) == 0 {
+			err = fmt.Errorf("no .csv or .tsv files in the archive")
+		}
+		if err != nil {
+			err = fmt.Errorf("cannot read %s: %v", path, err)
+			if onError == onErrorFail {
+				return nil, nil, err
+			}
+			warn(models.SkippedInputIssue(err))
+			failedInputs = append(failedInputs, err.Error())
+			continue
+		}
+
+		if deterministic {
+			sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+		}
+		progress.Printf("Extracted %d file(s) from %s", len(files), path)
+		for _, file := range files {
+			name := path + ":" + file.Name
+			names[file.Path] = name
+			archiveInputs[name] = path
+			expanded = append(expanded, file.Path)
+		}
+	}
+	return expanded, names, nil
+}
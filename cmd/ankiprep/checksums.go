@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"ankiprep/internal/models"
+)
+
+// verifyChecksums checks every input file against the --verify-checksums
+// manifest before anything is parsed, recording the digests in the report.
+// A .zip input is verified as a whole.
+func verifyChecksums(inputPaths []string) error {
+	manifest, err := models.LoadChecksumManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("--verify-checksums: %v", err)
+	}
+
+	for _, path := range inputPaths {
+		digest, err := manifest.Verify(path)
+		if err != nil {
+			return err
+		}
+		progress.Printf("Checksum OK: %s", path)
+		if runReport != nil {
+			runReport.AddChecksum(path, digest)
+		}
+	}
+	return nil
+}
+
+// recordOutputChecksum adds the SHA-256 of the output file written by sink to
+// the report, so the report shows which output the verified inputs produced
+func recordOutputChecksum(sink models.OutputSink, report *models.ProcessingReport) error {
+	file, ok := sink.(*models.FileSink)
+	if !ok {
+		return nil
+	}
+
+	digest, err := models.FileSHA256(file.Path)
+	if err != nil {
+		return err
+	}
+	progress.Printf("Output SHA-256: %s", digest)
+	report.AddChecksum(file.Path, digest)
+	return nil
+}
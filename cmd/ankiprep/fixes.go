@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"ankiprep/internal/models"
+)
+
+// applyFixes is set by --apply-fixes
+var applyFixes bool
+
+// maxFixPasses bounds how often a file is checked again after its fixes are
+// applied; every pass fixes at least one quote, so it only stops runaway
+// files with a quote problem on almost every line
+const maxFixPasses = 100
+
+// readFixed reads the CSV/TSV file of inputFile and applies the safe fixes of
+// the issues found in its text, printing each change and recording it in the
+// report. The first issue without a safe fix is printed and ends the fixing.
+// The file itself is never changed.
+func readFixed(inputFile *models.InputFile) ([]byte, error) {
+	data, err := readInput(inputFile.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	for pass := 0; pass < maxFixPasses; pass++ {
+		var fixed []*models.Issue
+		var unsafe *models.Issue
+		for _, issue := range models.CheckCSVText(inputFile.Path, data, inputFile.Separator) {
+			if issue.Fix.Safe {
+				fixed = append(fixed, issue)
+			} else if unsafe == nil {
+				unsafe = issue
+			}
+		}
+
+		if len(fixed) > 0 {
+			fixes := make([]*models.Fix, len(fixed))
+			for i, issue := range fixed {
+				fixes[i] = issue.Fix
+			}
+			if data, err = models.ApplyFixes(data, fixes); err != nil {
+				return nil, err
+			}
+			for _, issue := range fixed {
+				fmt.Fprintf(os.Stderr, "Fixed %s: %s\n", issue.Location(), issue.Fix.Description)
+				if runReport != nil {
+					runReport.AddIssue(issue)
+				}
+			}
+		}
+		if unsafe != nil {
+			warn(unsafe)
+			break
+		}
+		if len(fixed) == 0 {
+			break
+		}
+	}
+	return data, nil
+}
+
+// explainParseError prints the issues with a fix found in the text of a file
+// that failed to parse, so the error comes with what to change
+func explainParseError(inputFile *models.InputFile) {
+	data, err := readInput(inputFile.Path)
+	if err != nil {
+		return
+	}
+	for _, issue := range models.CheckCSVText(inputFile.Path, data, inputFile.Separator) {
+		warn(issue)
+	}
+}
+
+// readInput reads a whole input file, decompressing it if needed
+func readInput(path string) ([]byte, error) {
+	file, err := models.OpenInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ankiprep/internal/fixtures"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// gen-fixture flags
+	fixtureOutput  string
+	fixtureRows    int
+	fixtureColumns int
+	fixtureKinds   []string
+	fixtureTSV     bool
+)
+
+// genFixtureCmd writes synthetic edge-case input files
+var genFixtureCmd = &cobra.Command{
+	Use:    "gen-fixture",
+	Short:  "Generate an edge-case CSV/TSV fixture",
+	Hidden: true,
+	Long: `Generate a deterministic CSV/TSV file covering edge cases ankiprep must
+handle: embedded newlines, quotes, cloze deletions, unicode, French
+punctuation and a UTF-8 BOM. Ragged rows, which ankiprep rejects, are only
+included when asked for with --kinds. Useful for checking Anki import
+settings and for reproducing parsing problems.
+
+Available kinds: ` + strings.Join(fixtures.Kinds(), ", ") + `
+
+Examples:
+  ankiprep gen-fixture -o edge.csv
+  ankiprep gen-fixture --rows 10000 --kinds basic -o big.csv
+  ankiprep gen-fixture --kinds newlines,quotes --tsv
+  ankiprep gen-fixture --kinds basic,ragged -o ragged.csv`,
+	Args: cobra.NoArgs,
+	Run:  runGenFixture,
+}
+
+func init() {
+	defaults := fixtures.DefaultOptions()
+	genFixtureCmd.Flags().StringVarP(&fixtureOutput, "output", "o", "", "Write the fixture to this path instead of stdout")
+	genFixtureCmd.Flags().IntVar(&fixtureRows, "rows", defaults.Rows, "Number of data rows")
+	genFixtureCmd.Flags().IntVar(&fixtureColumns, "columns", defaults.Columns, "Number of columns")
+	genFixtureCmd.Flags().StringSliceVar(&fixtureKinds, "kinds", defaults.Kinds, "Edge cases to include (comma-separated)")
+	genFixtureCmd.Flags().BoolVar(&fixtureTSV, "tsv", false, "Write tab-separated values")
+	rootCmd.AddCommand(genFixtureCmd)
+}
+
+// runGenFixture executes the gen-fixture subcommand
+func runGenFixture(cmd *cobra.Command, args []string) {
+	opts := fixtures.Options{
+		Rows:      fixtureRows,
+		Columns:   fixtureColumns,
+		Separator: ',',
+		Kinds:     fixtureKinds,
+	}
+	if fixtureTSV {
+		opts.Separator = '\t'
+	}
+
+	var err error
+	if fixtureOutput == "" {
+		err = fixtures.Generate(os.Stdout, opts)
+	} else {
+		err = fixtures.WriteFile(fixtureOutput, opts)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Inspect flags
+	showValues          bool
+	maxDistinct         int
+	similarColumn       string
+	similarityThreshold float64
+	similarityMethod    string
+	swapColumns         []string
+)
+
+// inspectCmd reports on input files without writing any output
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [files...]",
+	Short: "Report on input files without converting them",
+	Long: `Inspect parses the input files and prints their structure: separator,
+columns and record counts, followed by the merged column list.
+
+With --values, low-cardinality columns (such as Tags or Part of Speech) are
+listed with the count of each distinct value, and values that only differ by
+case or whitespace ("noun" vs "Noun" vs "noun ") are flagged so they can be
+fixed before import.
+
+With --similar, notes whose key column values are alike but not identical
+(such as "to run" and "run (to)") are grouped into clusters for manual merging.
+The jaccard method compares word sets, ignoring order and punctuation; the
+levenshtein method compares characters and catches typos.
+
+With --swap-check, two columns are compared row by row against the language
+each column usually holds in its file (e.g. French fronts, English backs), and
+rows that look flipped by a spreadsheet paste error are listed.
+
+With --show-invisibles, column names and values are printed with visible
+placeholders for narrow and regular no-break spaces, zero-width characters and
+byte order marks ([NNBSP], [NBSP], [ZWSP], [BOM], ...).
+
+Examples:
+  ankiprep inspect vocab.csv
+  ankiprep inspect *.csv --values
+  ankiprep inspect vocab.csv --values --max-distinct 50
+  ankiprep inspect *.csv --similar English --threshold 0.8
+  ankiprep inspect vocab.csv --swap-check Front,Back
+  ankiprep inspect vocab.csv --values --show-invisibles`,
+	Args: requireInputs,
+	Run:  runInspect,
+}
+
+func init() {
+	inspectCmd.Flags().BoolVar(&showValues, "values", false, "List distinct values of low-cardinality columns and flag near-matches")
+	inspectCmd.Flags().IntVar(&maxDistinct, "max-distinct", 20, "Skip columns with more distinct values than this in the --values report")
+	inspectCmd.Flags().StringVar(&similarColumn, "similar", "", "Group notes with near-duplicate values in this key column")
+	inspectCmd.Flags().Float64Var(&similarityThreshold, "threshold", 0.8, "Minimum similarity (0.0-1.0) for --similar clusters")
+	inspectCmd.Flags().StringVar(&similarityMethod, "method", "jaccard", "Similarity measure for --similar: jaccard or levenshtein")
+	inspectCmd.Flags().StringSliceVar(&swapColumns, "swap-check", nil, "Flag rows where these two columns look swapped (e.g. Front,Back)")
+	inspectCmd.Flags().BoolVar(&showInvisibles, "show-invisibles", false, "Mark NNBSP, NBSP, zero-width spaces and BOMs with visible placeholders")
+	rootCmd.AddCommand(inspectCmd)
+}
+
+// runInspect executes the inspect subcommand
+func runInspect(cmd *cobra.Command, args []string) {
+	_, inputFiles, mergedHeaders, err := loadInputs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, inputFile := range inputFiles {
+		fmt.Printf("File %s: %d records (%s)\n", inputFile.Path, len(inputFile.Records), getFileType(inputFile))
+		fmt.Printf("  Columns: %s\n", joinHeaders(inputFile.Headers))
+	}
+	fmt.Printf("Merged columns (%d): %s\n", len(mergedHeaders), joinHeaders(mergedHeaders))
+
+	entries, _ := models.BuildEntries(inputFiles, mergedHeaders, keepHeader)
+
+	if showValues {
+		showValueReport(collectColumnValues(entries, mergedHeaders))
+	}
+
+	if similarColumn != "" {
+		if err := showSimilarityReport(entries, mergedHeaders); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if len(swapColumns) > 0 {
+		if err := showSwapReport(entries, mergedHeaders); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// joinHeaders lists column names for display
+func joinHeaders(headers []string) string {
+	shown := make([]string, len(headers))
+	for i, header := range headers {
+		shown[i] = showHeader(header)
+	}
+	return strings.Join(shown, ", ")
+}
+
+// collectColumnValues tallies the values of every column; tags columns are
+// counted per tag rather than per cell
+func collectColumnValues(entries []*models.DataEntry, headers []string) []*models.ColumnValues {
+	var columns []*models.ColumnValues
+	for _, header := range headers {
+		values := models.NewColumnValues(header)
+		for _, entry := range entries {
+			value := entry.GetValue(header)
+			if models.IsTagsColumn(header) {
+				for _, tag := range strings.Fields(value) {
+					values.Add(tag)
+				}
+			} else {
+				values.Add(value)
+			}
+		}
+		columns = append(columns, values)
+	}
+	return columns
+}
+
+func showValueReport(columns []*models.ColumnValues) {
+	fmt.Printf("\nColumn values:\n")
+	for _, column := range columns {
+		if column.DistinctCount() == 0 {
+			fmt.Printf("\n%s: empty\n", showHeader(column.Column))
+			continue
+		}
+		if column.DistinctCount() > maxDistinct {
+			fmt.Printf("\n%s: %d distinct values (skipped, above --max-distinct)\n",
+				showHeader(column.Column), column.DistinctCount())
+			continue
+		}
+
+		fmt.Printf("\n%s: %d distinct values\n", showHeader(column.Column), column.DistinctCount())
+		for _, value := range column.SortedValues() {
+			fmt.Printf("  %-30s %d\n", quoteValue(value.Value), value.Count)
+		}
+		for _, group := range column.NearMatches() {
+			quoted := make([]string, len(group))
+			for i, value := range group {
+				quoted[i] = quoteValue(value)
+			}
+			fmt.Printf("  Warning: near-matching values %s\n", strings.Join(quoted, ", "))
+		}
+	}
+}
+
+// showSimilarityReport prints clusters of near-duplicate notes
+func showSimilarityReport(entries []*models.DataEntry, headers []string) error {
+	if !containsString(headers, similarColumn) {
+		return fmt.Errorf("column %q not found (available: %s)", similarColumn, strings.Join(headers, ", "))
+	}
+	if similarityThreshold <= 0 || similarityThreshold > 1 {
+		return fmt.Errorf("--threshold must be between 0 and 1, got %g", similarityThreshold)
+	}
+
+	similarity, err := models.GetSimilarityFunc(similarityMethod)
+	if err != nil {
+		return err
+	}
+
+	clusters := models.ClusterSimilar(entries, similarColumn, similarityThreshold, similarity)
+
+	fmt.Printf("\nSimilar %s values (%s >= %.2f): %d cluster(s)\n",
+		similarColumn, strings.ToLower(similarityMethod), similarityThreshold, len(clusters))
+	for i, cluster := range clusters {
+		fmt.Printf("\nCluster %d:\n", i+1)
+		for _, entry := range cluster {
+			fmt.Printf("  %s:%d: %s\n", entry.Source, entry.LineNumber, quoteValue(entry.GetValue(similarColumn)))
+		}
+	}
+
+	return nil
+}
+
+// showSwapReport prints rows whose two checked columns look swapped
+func showSwapReport(entries []*models.DataEntry, headers []string) error {
+	if len(swapColumns) != 2 {
+		return fmt.Errorf("--swap-check needs exactly two columns, got %d", len(swapColumns))
+	}
+	for _, column := range swapColumns {
+		if !containsString(headers, column) {
+			return fmt.Errorf("column %q not found (available: %s)", column, strings.Join(headers, ", "))
+		}
+	}
+
+	a, b := swapColumns[0], swapColumns[1]
+	suspects := models.DetectSwappedColumns(entries, a, b)
+
+	fmt.Printf("\nPossibly swapped %s/%s rows: %d\n", a, b, len(suspects))
+	for _, entry := range suspects {
+		fmt.Printf("  %s:%d: %s=%s %s=%s\n", entry.Source, entry.LineNumber,
+			a, quoteValue(entry.GetValue(a)), b, quoteValue(entry.GetValue(b)))
+	}
+
+	return nil
+}
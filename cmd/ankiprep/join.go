@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"ankiprep/internal/models"
+)
+
+// maxUnmatchedShown limits how many unmatched join keys are listed
+const maxUnmatchedShown = 10
+
+// loadJoin reads the --join lookup file, or returns nil without --join
+func loadJoin(headers []string) (*models.JoinService, error) {
+	if joinSpec == "" {
+		return nil, nil
+	}
+
+	path, key, err := models.ParseJoinSpec(joinSpec)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(headers, key) {
+		return nil, fmt.Errorf("join column %q not found in the input (available: %s)", key, strings.Join(headers, ", "))
+	}
+
+	// Lookup files always have a header row naming the columns to add
+	lookup := models.NewInputFile(path)
+	lookup.DetectSeparator()
+	parser := models.NewCSVParser()
+	parser.LazyQuotes = !strictQuotes
+	parser.Header = models.HeaderPresent
+	err = parser.ParseFile(lookup, func(record []string, line int) error {
+		lookup.AddRecord(record, line)
+		return nil
+	})
+	if err != nil {
+		return nil, models.ParseFailure(path, err)
+	}
+
+	join, err := models.NewJoinService(lookup, key)
+	if err != nil {
+		return nil, err
+	}
+	progress.Printf("Joining %s on %s: adding %s", path, key, strings.Join(join.Columns, ", "))
+	return join, nil
+}
+
+// showJoin prints how many notes found their key in the lookup file and the
+// first keys that did not
+func showJoin(join *models.JoinService) {
+	fmt.Fprintf(statusOut(), "Joined %s on %s: %d matched, %d unmatched key(s)\n",
+		join.Lookup, join.Key, join.Matched, len(join.Unmatched))
+	for i, key := range join.Unmatched {
+		if i == maxUnmatchedShown {
+			fmt.Fprintf(statusOut(), "  ... and %d more\n", len(join.Unmatched)-maxUnmatchedShown)
+			break
+		}
+		fmt.Fprintf(statusOut(), "  %s\n", quoteValue(key))
+	}
+}
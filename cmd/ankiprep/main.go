@@ -1,16 +1,25 @@
 package main
 
 import (
-	"encoding/csv"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"ankiprep/internal/ankiconnect"
 	"ankiprep/internal/models"
+	"ankiprep/internal/notify"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/text/collate"
 )
 
 var (
@@ -21,8 +30,120 @@ var (
 	smartQuotes    bool
 	skipDuplicates bool
 	keepHeader     bool
+	configPath     string
+	spellDicts     []string
+	spellColumns   []string
+	autoLang       bool
+	cjkSpacing     bool
+	reportPath     string
+	strictQuotes   bool
+	noHeader       bool
+	assumeHeader   bool
+	outputColumns  []string
+	requiredCols   []string
+	uniqueCols     []string
+	outputSep      string
+	dedupeStrategy string
+	dedupeColumns  []string
+	keepTemp       bool
+	recordStats    bool
+	statsPath      string
+	outputFormat   string
+	pushNotes      bool
+	pushDeck       string
+	pushNoteType   string
+	ankiConnectURL string
+	deterministic  bool
+	sortBy         string
+	collateLang    string
+	mergeTags      bool
+	mergeFields    []string
+	maxFieldBytes  int
+	onOversize     string
+	dataURIMode    string
+	mediaDir       string
+	noteTypesPath  string
+	quizletFiles   []string
+	memriseFiles   []string
+	quizletTermSep string
+	quizletRowSep  string
+	joinSpec       string
+	frequencyList  string
+	frequencyCol   string
+	replaceMapPath string
+	regexRules     []string
+	mergeSimilar   bool
+	plainHeader    bool
+	notifyWebhook  string
+	notifyEmail    []string
+	smtpServer     string
+	smtpFrom       string
+	retryAttempts  int
+	retryBackoff   time.Duration
+	onError        string
+	schemaPath     string
+	cellTimeout    time.Duration
+	preflight      bool
+	useMmap        bool
+	dirMarks       []string
+	symbolRules    []string
+	compressOutput bool
+	verifyOutput   bool
+	manifestPath   string
+	splitSpecs     []string
+	splitOverflow  string
+	joinColSpecs   []string
+	explodeSpecs   []string
+	deckFromCol    string
+	deckPrefix     string
+	profileName    string
+	headerAliases  bool
+	noTransform    bool
+	showDupes      bool
+	dedupeHash     string
+	verifyDupes    bool
+	assertStable   bool
+	heartbeatEvery time.Duration
+	cacheDir       string
+	emitLanguages  bool
+	writeBatch     int
+	networkFS      string
 )
 
+// progress receives the --verbose progress lines and per-stage counts;
+// runProcess replaces it with one writing to statusOut when --verbose is set
+var progress = models.NewProgressReporter(nil)
+
+// heartbeat prints "still working" lines during long stages; runProcess sets
+// it from --heartbeat, and while nil stages run silently
+var heartbeat *models.Heartbeat
+
+// typographyCache reuses the typography of unchanged input files; runProcess
+// opens it for --cache-dir, and while nil every cell is formatted
+var typographyCache *models.TypographyCache
+
+// fileService tracks temporary output files so they can be cleaned up
+var fileService = models.NewFileService()
+
+// exitValidation is the exit code used when input files do not meet the
+// --require contract; all other errors exit with 1
+const exitValidation = 3
+
+// exitInputErrors is the exit code of an --on-error abort-at-end run in which
+// some input files could not be read
+const exitInputErrors = 2
+
+// --on-error policies for input files that cannot be read
+const (
+	onErrorFail  = "fail"         // Stop the run at the first bad file
+	onErrorSkip  = "skip"         // Leave bad files out with a warning
+	onErrorAtEnd = "abort-at-end" // Leave bad files out, then exit with exitInputErrors
+)
+
+// failedInputs lists the input files left out under --on-error skip or
+// abort-at-end, with the reason
+var failedInputs []string
+
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "ankiprep [files...]",
@@ -41,141 +162,1023 @@ Examples:
   ankiprep input.csv
   ankiprep *.csv -o flashcards.csv
   ankiprep file1.csv file2.tsv -f -q
-  ankiprep data.csv -s -v`,
-	Version: "1.0.0",
-	Args:    cobra.MinimumNArgs(1),
+  ankiprep data.csv -s -v
+  ankiprep data.csv --config deck.json`,
+	Version: version,
+	Args:    requireInputs,
 	Run:     runProcess,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if noHeader && assumeHeader {
+			return fmt.Errorf("--no-header and --assume-header cannot be used together")
+		}
+		return applyProfile(cmd)
+	},
 }
 
 func init() {
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
-	rootCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Specify output file path")
-	rootCmd.Flags().BoolVarP(&frenchMode, "french", "f", false, "Add thin spaces before French punctuation (:;!?)")
-	rootCmd.Flags().BoolVarP(&smartQuotes, "smart-quotes", "q", false, "Convert straight quotes to curly quotes")
-	rootCmd.Flags().BoolVarP(&skipDuplicates, "skip-duplicates", "s", false, "Remove entries with identical content")
+	rootCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Specify output file path (- for stdout)")
 	rootCmd.Flags().BoolVarP(&keepHeader, "keep-header", "k", false, "Preserve the first row of CSV files")
+	rootCmd.Flags().StringVar(&outputSep, "output-separator", "comma", "Output field separator: comma or tab")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "", "Output format: csv or tsv for Anki, or mochi, remnote or quizlet (default: from --output-separator)")
+	rootCmd.Flags().BoolVar(&compressOutput, "compress", false, "Write gzip-compressed output; the default output name gets a .gz suffix")
+	rootCmd.Flags().BoolVar(&verifyOutput, "verify", false, "Read the written import file back as Anki would and check its record and column counts and #directives, failing if they differ from what was written")
+	rootCmd.Flags().BoolVar(&emitLanguages, "emit-languages", false, "Declare the column languages (from --config or a re-processed import file) in a #languages line of the Anki import file, which Anki ignores and later runs read back")
+	rootCmd.Flags().BoolVar(&plainHeader, "plain-header", false, "Write a plain CSV/TSV with a header row instead of an Anki import file (no #metadata lines)")
+	rootCmd.Flags().BoolVar(&pushNotes, "push", false, "Add the notes to the running Anki through AnkiConnect instead of writing a file")
+	rootCmd.Flags().StringVar(&pushDeck, "deck", "Default", "Deck that --push adds notes to")
+	rootCmd.Flags().StringVar(&pushNoteType, "note-type", "", "Note type the notes are for; with --push or --note-types its fields are checked against the output columns (--push default: Basic)")
+	rootCmd.Flags().StringVar(&noteTypesPath, "note-types", "", "JSON file of note type fields for --note-type (default: ask AnkiConnect)")
+	rootCmd.Flags().StringVar(&ankiConnectURL, "ankiconnect-url", ankiconnect.DefaultURL, "AnkiConnect address used by --push")
+	rootCmd.Flags().IntVar(&writeBatch, "write-batch-bytes", models.DefaultBatchSize, "Collect this many bytes of output before each write, so network filesystems see few large writes; -v reports the throughput of every batch")
+	rootCmd.Flags().StringVar(&networkFS, "network-fs", networkFSAuto, "Whether the output is on a network filesystem (SMB, NFS): auto (detect), on or off; on uses larger writes, retries with longer waits and reads the output back to verify it")
+	rootCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Keep the typography of each input file in this directory, so re-runs only format the files whose content or typography options changed")
+	rootCmd.Flags().BoolVar(&keepTemp, "keep-temp", false, "Keep the temporary output file of a failed or cancelled run for debugging")
+	rootCmd.Flags().BoolVar(&recordStats, "record-stats", false, "Append this run's metrics to the local stats file (see 'ankiprep stats')")
+	rootCmd.Flags().BoolVar(&deterministic, "deterministic", false, "Produce byte-identical output for identical data: sort input files by name and leave timings out of the report")
+	rootCmd.Flags().StringVar(&sortBy, "sort-by", "", "Sort output rows by this column (byte order, stable); default is input order")
+	rootCmd.Flags().StringVar(&collateLang, "collate", "", "Sort --sort-by values by the alphabet of this language (e.g. fr, so \"école\" sorts next to \"ecole\") instead of byte order")
+	rootCmd.Flags().StringVar(&frequencyList, "order-by-frequency", "", "Order rows by the rank of their key word in this wordlist (most frequent first); unknown words go last")
+	rootCmd.Flags().StringVar(&frequencyCol, "frequency-column", "", "Key column for --order-by-frequency (default: first output column)")
+	rootCmd.Flags().StringVar(&joinSpec, "join", "", "Add the columns of a lookup file to rows with the same key: \"lookup.csv on Word\"")
+	rootCmd.Flags().BoolVar(&preflight, "preflight", false, "Before processing, estimate the duplicate rate and mostly empty columns from a sample and ask whether to continue")
+	rootCmd.Flags().StringVar(&onError, "on-error", onErrorFail, "What to do with an input file that cannot be read: fail (stop at once), skip (warn and go on) or abort-at-end (go on, then exit with code 2)")
+	rootCmd.Flags().StringVar(&reportPath, "report", "", "Write a JSON processing report (counts, warnings, column statistics) to this path")
+	rootCmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "POST the JSON report to this URL when the run completes or fails")
+	rootCmd.Flags().StringSliceVar(&notifyEmail, "notify-email", nil, "Mail the JSON report to these addresses when the run completes or fails (comma-separated)")
+	rootCmd.Flags().StringVar(&smtpServer, "smtp-server", "localhost:25", "SMTP server (host:port) for --notify-email; credentials come from $"+smtpUsernameEnv+" and $"+smtpPasswordEnv)
+	rootCmd.Flags().StringVar(&smtpFrom, "smtp-from", "ankiprep@localhost", "Sender address of --notify-email mail")
+	rootCmd.Flags().IntVar(&retryAttempts, "retries", models.DefaultRetryAttempts, "Attempts for each AnkiConnect or webhook request before giving up on a flaky connection")
+	rootCmd.Flags().DurationVar(&retryBackoff, "retry-backoff", models.DefaultRetryBackoff, "Wait before retrying a failed request; doubles after each failure (with some jitter)")
+	addProcessingFlags(rootCmd.Flags())
+
+	// Parsing flags shared with subcommands that read input files
+	rootCmd.PersistentFlags().BoolVar(&noHeader, "no-header", false, "Input files have no header row; name columns Column1..N")
+	rootCmd.PersistentFlags().BoolVar(&assumeHeader, "assume-header", false, "Input files have a header row; skip the first-row checks")
+	rootCmd.PersistentFlags().StringVar(&statsPath, "stats-file", "", "Local stats file used by --record-stats and 'ankiprep stats' (default: user config directory)")
+	rootCmd.PersistentFlags().StringSliceVar(&quizletFiles, "quizlet", nil, "Also read this Quizlet export (term and definition per row) as Front,Back (repeatable)")
+	rootCmd.PersistentFlags().StringSliceVar(&memriseFiles, "memrise", nil, "Also read this Memrise CSV export, naming its first two columns Front,Back (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&quizletTermSep, "quizlet-term-sep", "tab", "Separator between term and definition in --quizlet files: tab, comma, semicolon or any text")
+	rootCmd.PersistentFlags().StringVar(&quizletRowSep, "quizlet-row-sep", "newline", "Separator between rows in --quizlet files: newline, semicolon or any text")
+	rootCmd.PersistentFlags().BoolVar(&headerAliases, "header-aliases", false, "Rename column names in other languages (Recto/Verso, Frente/Verso, 表/裏) Front/Back/Tags, so files in different languages merge; other flags then refer to the new names")
+	rootCmd.PersistentFlags().BoolVar(&mergeSimilar, "merge-similar-headers", false, "Merge columns whose names differ only by case or surrounding spaces (\"Back\" and \"back \")")
+	rootCmd.PersistentFlags().StringVar(&manifestPath, "verify-checksums", "", "Check every input file against this sha256sum manifest before reading it, and record input and output hashes in the report")
+	rootCmd.PersistentFlags().BoolVar(&useMmap, "mmap", false, "Read input files through a memory map instead of read calls where supported; the parser still copies the data, so this saves system calls, not memory")
+	rootCmd.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "", "Export OpenTelemetry traces (a span per stage and input file, or per request with serve) to this OTLP/HTTP collector, e.g. http://localhost:4318")
+	rootCmd.PersistentFlags().BoolVar(&applyFixes, "apply-fixes", false, "Apply safe fixes to mistakes in input files (a stray separator at the end of the header, an unclosed quote) as they are read, printing each change; files are not modified")
+	rootCmd.PersistentFlags().BoolVar(&strictQuotes, "strict-quotes", false, "Report malformed quoting (with line and column) instead of accepting it leniently")
+}
+
+// addProcessingFlags registers the flags that control how entries are
+// transformed, for every command that runs the pipeline
+func addProcessingFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&frenchMode, "french", "f", false, "Add thin spaces before French punctuation (:;!?)")
+	flags.BoolVarP(&smartQuotes, "smart-quotes", "q", false, "Convert straight quotes to curly quotes")
+	flags.BoolVarP(&skipDuplicates, "skip-duplicates", "s", false, "Remove entries with identical content")
+	flags.StringVar(&dedupeStrategy, "dedupe-strategy", models.DedupeExact, "How --skip-duplicates compares entries: exact, normalized, key-columns, fuzzy or merge-fields")
+	flags.BoolVar(&mergeTags, "merge-tags", false, "With --skip-duplicates, add the tags of removed duplicates to the entry that is kept")
+	flags.StringSliceVar(&mergeFields, "merge-fields", nil, "With --skip-duplicates, add the <br>-separated items (e.g. example sentences) of these columns in removed duplicates to the entry that is kept; --dedupe-strategy merge-fields ignores them when comparing entries (comma-separated)")
+	flags.StringVar(&dedupeHash, "dedupe-hash", models.HashMD5, "Hash --skip-duplicates finds duplicates by: md5, fnv, xxhash (fastest) or sha256")
+	flags.BoolVar(&verifyDupes, "verify-duplicates", false, "Compare entries with the same hash in full before removing one, ruling out hash collisions")
+	flags.BoolVar(&showDupes, "show-duplicates", false, "With --skip-duplicates, print each kept entry's file and line with those of the duplicates removed")
+	flags.BoolVar(&assertStable, "assert-stable", false, "With --skip-duplicates, check that the first occurrence of each entry was kept, in input order, and fail otherwise")
+	flags.StringSliceVar(&dedupeColumns, "dedupe-columns", nil, "Columns compared by --dedupe-strategy key-columns (comma-separated)")
+	flags.BoolVar(&autoLang, "auto-lang", false, "With --french, apply French spacing per cell only when the text looks French")
+	flags.BoolVar(&cjkSpacing, "cjk-spacing", false, "Space Chinese, Japanese and Korean text by CJK rules: no spaces around fullwidth punctuation (French spacing always leaves CJK text alone)")
+	flags.StringSliceVar(&dirMarks, "direction-marks", nil, "Columns whose cells mixing right-to-left (Hebrew, Arabic) and left-to-right text get direction marks so punctuation renders on the right side (comma-separated)")
+	flags.DurationVar(&cellTimeout, "cell-timeout", models.DefaultCellTimeout, "Leave a cell unformatted, with a warning, when its typography takes longer than this (0: no limit)")
+	flags.DurationVar(&heartbeatEvery, "heartbeat", models.DefaultHeartbeat, "While duplicate removal or typography runs longer than this, print how far it got at this interval (0: never)")
+	flags.BoolVar(&noTransform, "no-transform", false, "Leave cell contents exactly as read: only merge files, remove duplicates and write the Anki metadata; flags that change cells are rejected")
+	flags.StringVar(&profileName, "profile", "", "Use the settings of a built-in profile such as french-vocab (see 'ankiprep profiles'); flags given override it")
+	flags.StringVar(&configPath, "config", "", "Load pipeline settings (e.g. field templates) from a JSON file")
+	flags.StringVar(&schemaPath, "schema", "", "YAML deck schema declaring the expected columns, their types, required values, per-column typography and the note type")
+	flags.StringVar(&replaceMapPath, "replace-map", "", "CSV file of exact cell substitutions with the columns Column,From,To (e.g. n. to noun)")
+	flags.StringArrayVar(&regexRules, "regex", nil, "Find and replace in a column, sed style: 'Back:s/\\s+$//' (* for every column; repeatable, applied in order)")
+	flags.StringArrayVar(&symbolRules, "normalize-symbols", nil, "Normalize emoji variation selectors and lookalike symbols in a column: 'Front:ascii' (fullwidth ：！？ to ASCII), 'Hanzi:fullwidth' (ASCII :!? to fullwidth) or 'Notes:emoji' (* for every column; repeatable)")
+	flags.StringSliceVar(&spellDicts, "spell-dict", nil, "Wordlist or hunspell .dic file used to flag likely typos (repeatable)")
+	flags.StringSliceVar(&spellColumns, "spell-columns", nil, "Columns to spell-check against --spell-dict (comma-separated)")
+	flags.StringVar(&dataURIMode, "data-uris", models.DataURIKeep, "What to do with base64 data: URIs (inline images) in fields: keep, strip or extract")
+	flags.StringVar(&mediaDir, "media-dir", "", "Directory --data-uris extract saves images to (e.g. Anki's collection.media)")
+	flags.IntVar(&maxFieldBytes, "max-field-bytes", 0, "Limit every field to this many bytes (0: no limit)")
+	flags.StringVar(&onOversize, "on-oversize", models.OversizeTruncate, "What to do with fields over --max-field-bytes: truncate, skip (drop the row) or error")
+	flags.StringSliceVar(&requiredCols, "require", nil, "Fail if any input file lacks these columns (comma-separated)")
+	flags.StringSliceVar(&uniqueCols, "unique", nil, "Fail, listing the file and line of each row, if a value of these columns is in more than one row after processing (comma-separated)")
+	flags.StringSliceVar(&outputColumns, "columns", nil, "Output only these columns, in this order (comma-separated)")
+	flags.StringArrayVar(&splitSpecs, "split-column", nil, "Split a column on a delimiter into several columns: 'Examples|;|Example1,Example2,Example3' (repeatable)")
+	flags.StringVar(&splitOverflow, "split-overflow", models.SplitOverflowJoin, "What to do with --split-column values beyond the last column: join (keep them in it), drop (with a warning) or error")
+	flags.StringArrayVar(&joinColSpecs, "join-columns", nil, "Combine columns into one with a separator, dropping them: 'Example1+Example2+Example3=Examples:<br>' (repeatable)")
+	flags.StringArrayVar(&explodeSpecs, "explode", nil, "Turn a row into one row per delimited value of a column, copying the other fields: 'Synonyms|;' (repeatable)")
+	flags.StringVar(&deckFromCol, "deck-from-column", "", "Add a Deck column (and #deck column directive) naming each note's deck after this column, so Anki files notes into subdecks")
+	flags.StringVar(&deckPrefix, "deck-prefix", "", "Parent decks put before --deck-from-column values, e.g. 'French::Course::'")
 }
 
 // runProcess executes the main processing logic - simplified version
 func runProcess(cmd *cobra.Command, args []string) {
 	startTime := time.Now()
+	report := models.NewProcessingReport()
+	runReport = report
+	if verbose {
+		progress = models.NewProgressReporter(statusOut())
+	}
+	heartbeat = models.NewHeartbeat(statusOut(), heartbeatEvery)
+	fileService.KeepTemp = keepTemp
+	ctx, stop := cancelOnSignal()
+	defer stop()
+
+	if err := startTracing(); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	runSpan = tracer.Start("ankiprep", nil)
+	if err := checkOutputFlags(cmd); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	if err := checkCollation(); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	if err := checkProcessingFlags(); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
 
-	// Validate and collect input files
-	inputPaths, err := collectInputFiles(args)
+	config, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	if err := loadSchema(); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	if err := checkNoTransform(cmd, config); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
 	}
 
-	if verbose {
-		fmt.Printf("Processing %d input file(s)...\n", len(inputPaths))
+	inputPaths, inputFiles, mergedHeaders, err := loadInputs(args)
+	if err != nil {
+		exitIfCancelled(ctx, startTime)
+		exitRun(1, fmt.Sprintf("Error: %v", err))
 	}
+	exitIfCancelled(ctx, startTime)
 
-	// Parse input files
-	var inputFiles []*models.InputFile
+	for _, failure := range failedInputs {
+		report.AddErrorString(failure)
+	}
+
+	checkRequiredColumns(inputFiles)
+	declareLanguages(config, inputFiles)
+
+	if cacheDir != "" {
+		if typographyCache, err = openTypographyCache(inputFiles, config); err != nil {
+			exitRun(1, fmt.Sprintf("Error: %v", err))
+		}
+	}
+
+	mergeStart := time.Now()
+	join, err := loadJoin(mergedHeaders)
+	if err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	if join != nil {
+		mergedHeaders = join.Headers(mergedHeaders)
+	}
+
+	// Entries are built with the input columns, then split into the others
+	inputHeaders := mergedHeaders
+	if mergedHeaders, err = reshapeHeaders(mergedHeaders); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+
+	outputHeaders, err := selectColumns(mergedHeaders)
+	if err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+
+	checkHeaderNames(outputHeaders)
+	checkNoteType(ctx, outputHeaders)
+	exitIfCancelled(ctx, startTime)
+
+	// Process all records
+	allEntries, totalRecords := models.BuildEntries(inputFiles, inputHeaders, keepHeader)
+
+	progress.Printf("Processing records: %d total entries", totalRecords)
+
+	if preflight {
+		runPreflight(allEntries, mergedHeaders, config)
+	}
+
+	// Join before any transformation, so looked-up values are processed too
+	if join != nil {
+		join.Join(allEntries)
+	}
+	if allEntries, err = reshapeEntries(allEntries, report); err != nil {
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	// Exploded rows count as records, so removed duplicates stay positive
+	totalRecords = len(models.DataEntries(allEntries))
+	progress.Add("merging", totalRecords, time.Since(mergeStart))
+	traceStage("merging", mergeStart, totalRecords)
+	exitIfCancelled(ctx, startTime)
+
+	allEntries, err = transformEntries(ctx, allEntries, mergedHeaders, config, report)
+	if err != nil {
+		exitIfCancelled(ctx, startTime)
+		exitRun(1, fmt.Sprintf("Error: %v", err))
+	}
+	exitIfCancelled(ctx, startTime)
+	if len(uniqueCols) > 0 {
+		checkUnique(allEntries, mergedHeaders, report)
+	}
+
+	if sortBy != "" {
+		if !containsString(mergedHeaders, sortBy) {
+			exitRun(1, fmt.Sprintf("Error: --sort-by column %q not found (available: %s)", sortBy, strings.Join(mergedHeaders, ", ")))
+		}
+		sortEntries(allEntries)
+	}
+
+	unranked := 0
+	if frequencyList != "" {
+		if unranked, err = orderByFrequency(allEntries, mergedHeaders, outputHeaders); err != nil {
+			exitRun(1, fmt.Sprintf("Error: %v", err))
+		}
+	}
+
+	// Write output
+	sink := outputSink(inputPaths, config.Languages)
+	writeStart := time.Now()
+	if err := sink.Write(ctx, allEntries, outputHeaders); err != nil {
+		exitIfCancelled(ctx, startTime)
+		cleanupTempFiles()
+		exitRun(1, fmt.Sprintf("Error writing output: %v", err))
+	}
+	progress.Add("writing", len(allEntries), time.Since(writeStart))
+	traceStage("writing", writeStart, len(allEntries))
+	if verifyOutput {
+		verifyStart := time.Now()
+		if err := verifyWritten(sink, allEntries, outputHeaders); err != nil {
+			exitRun(1, fmt.Sprintf("Error: %v", err))
+		}
+		progress.Add("verifying", len(allEntries), time.Since(verifyStart))
+		traceStage("verifying", verifyStart, len(allEntries))
+	}
+	if manifestPath != "" {
+		if err := recordOutputChecksum(sink, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot compute the output checksum: %v\n", err)
+		}
+	}
+	if pusher, ok := sink.(*ankiconnect.Sink); ok {
+		fmt.Fprintf(statusOut(), "Added %d of %d notes to deck %q\n", pusher.Added, len(models.DataEntries(allEntries)), pushDeck)
+	}
+
+	// Success message
+	processingTime := time.Since(startTime)
 	for _, path := range inputPaths {
-		inputFile, err := parseFile(path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", path, err)
-			os.Exit(1)
+		report.AddInputFile(path)
+	}
+	// Rows skipped for their size are not duplicates, and a --keep-header
+	// row is not a record
+	outputRecords := len(models.DataEntries(allEntries))
+	report.SetCounts(totalRecords, totalRecords-report.SkippedRecords-outputRecords, outputRecords)
+	if !deterministic {
+		report.SetProcessingTime(processingTime)
+		report.SetStages(progress.Stages())
+	}
+	report.CollectColumnStats(outputHeaders, models.DataEntries(allEntries))
+	report.Cards = models.CountCards(allEntries, outputHeaders)
+	if join != nil {
+		report.UnmatchedKeys = append(report.UnmatchedKeys, join.Unmatched...)
+	}
+	showIssues(report)
+
+	if reportPath != "" {
+		if err := writeReport(reportPath, report); err != nil {
+			cleanupTempFiles()
+			exitRun(1, fmt.Sprintf("Error writing report: %v", err))
 		}
-		inputFiles = append(inputFiles, inputFile)
+	}
 
-		if verbose {
-			fmt.Printf("File %s: %d records (%d bytes) (%s)\n",
-				path, len(inputFile.Records)+1, getFileSize(path), getFileType(path))
+	if recordStats {
+		if err := recordRunStats(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot record run statistics: %v\n", err)
 		}
 	}
 
-	// Merge headers
-	mergedHeaders := mergeHeaders(inputFiles)
+	fmt.Fprintf(statusOut(), "Done. Processed %d unique entries in %.2f seconds\n",
+		outputRecords, processingTime.Seconds())
+	showDuplicateSources(report)
+	showCardCount(report.Cards)
+	if join != nil {
+		showJoin(join)
+	}
+	if unranked > 0 {
+		fmt.Fprintf(statusOut(), "Frequency order: %d row(s) with words not in %s placed last\n", unranked, frequencyList)
+	}
+
 	if verbose {
-		fmt.Printf("Merging headers: found %d unique columns\n", len(mergedHeaders))
+		showSummary(inputPaths, totalRecords, outputRecords, processingTime)
+		showColumnStats(report)
 	}
 
-	// Process all records
-	var allEntries []*models.DataEntry
-	totalRecords := 0
+	if onError == onErrorAtEnd && len(failedInputs) > 0 {
+		exitRun(exitInputErrors, fmt.Sprintf("Error: %d of %d input file(s) could not be read", len(failedInputs), len(failedInputs)+len(inputPaths)))
+	}
 
+	runSpan.SetAttribute("input.files", len(inputPaths))
+	runSpan.SetAttribute("output.records", outputRecords)
+	endTracing("")
+	sendNotifications(&notify.Event{Status: notify.StatusCompleted, Output: notifyOutput(inputPaths)})
+}
+
+// Helper functions - simplified implementations
+
+// loadConfig reads the --config file, or returns an empty configuration
+func loadConfig() (*models.Config, error) {
+	if configPath == "" {
+		return models.NewConfig(), nil
+	}
+	return models.LoadConfig(configPath)
+}
+
+// declareLanguages adds the column languages declared by re-processed Anki
+// import files to those of the config file, which take precedence
+func declareLanguages(config *models.Config, inputFiles []*models.InputFile) {
 	for _, inputFile := range inputFiles {
-		// Add header if keepHeader is true and this is the first file
-		if keepHeader && len(allEntries) == 0 {
-			headerEntry := models.NewDataEntry(make(map[string]string), inputFile.Path, 0)
-			for i, header := range inputFile.Headers {
-				if i < len(mergedHeaders) {
-					headerEntry.Values[mergedHeaders[i]] = header
-				}
+		for column, language := range inputFile.Languages {
+			if _, ok := config.Languages[column]; !ok {
+				config.Languages[column] = language
 			}
-			allEntries = append(allEntries, headerEntry)
 		}
+	}
+	if len(config.Languages) > 0 {
+		progress.Printf("Column languages: %s", models.LanguageSummary(config.Languages))
+	}
+}
+
+// loadInputs collects, parses and merges the input files named by args and
+// by --quizlet and --memrise
+func loadInputs(args []string) ([]string, []*models.InputFile, []string, error) {
+	var inputPaths []string
+	if len(args) > 0 {
+		var err error
+		if inputPaths, err = collectInputFiles(args); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	csvCount := len(inputPaths)
+	inputPaths = append(inputPaths, quizletFiles...)
+	inputPaths = append(inputPaths, memriseFiles...)
+
+	switch onError {
+	case onErrorFail, onErrorSkip, onErrorAtEnd:
+	default:
+		return nil, nil, nil, fmt.Errorf("invalid --on-error %q: must be fail, skip or abort-at-end", onError)
+	}
 
-		// Process data records
-		for lineNum, record := range inputFile.Records {
-			entry := models.NewDataEntry(make(map[string]string), inputFile.Path, lineNum+2)
-			for i, value := range record {
-				if i < len(inputFile.Headers) && i < len(mergedHeaders) {
-					entry.Values[mergedHeaders[i]] = value
+	if manifestPath != "" {
+		if err := verifyChecksums(inputPaths); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// Files extracted from .zip inputs are only needed until they are parsed
+	defer fileService.RemoveExtracted()
+	csvPaths, names, err := expandArchives(inputPaths[:csvCount])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	inputPaths = append(csvPaths, inputPaths[csvCount:]...)
+	csvCount = len(csvPaths)
+
+	progress.Printf("Processing %d input file(s)...", len(inputPaths))
+
+	var parsedPaths []string
+	var inputFiles []*models.InputFile
+	for i, path := range inputPaths {
+		name := path
+		if extracted, ok := names[path]; ok {
+			name = extracted
+		}
+
+		var inputFile *models.InputFile
+		var err error
+		start := time.Now()
+		switch {
+		case i < csvCount:
+			inputFile, err = parseFile(path)
+		case i < csvCount+len(quizletFiles):
+			inputFile, err = parseQuizletFile(path)
+		default:
+			inputFile, err = parseMemriseFile(path)
+		}
+		if err != nil {
+			// Parse errors show the line they are on
+			snippet := ""
+			var parseErr *models.ParseError
+			if errors.As(err, &parseErr) {
+				parseErr.File = name
+				snippet = parseErr.Snippet()
+			}
+			err = models.ParseFailure(name, err)
+			if onError == onErrorFail {
+				if snippet != "" {
+					err = fmt.Errorf("%v\n%s", err, snippet)
 				}
+				return nil, nil, nil, err
+			}
+			warn(models.SkippedInputIssue(err))
+			if snippet != "" {
+				fmt.Fprintln(os.Stderr, snippet)
 			}
-			allEntries = append(allEntries, entry)
-			totalRecords++
+			failedInputs = append(failedInputs, err.Error())
+			continue
 		}
+		inputFile.Path = name
+		parsedPaths = append(parsedPaths, name)
+		inputFiles = append(inputFiles, inputFile)
+		progress.Add("parsing", len(inputFile.Records), time.Since(start))
+		traceFile(name, start, len(inputFile.Records))
+
+		progress.Printf("File %s: %d records (%d bytes) (%s)", name, len(inputFile.Records)+1, getFileSize(path), getFileType(inputFile))
 	}
 
-	if verbose {
-		fmt.Printf("Processing records: %d total entries\n", totalRecords)
+	if len(inputFiles) == 0 {
+		return nil, nil, nil, fmt.Errorf("none of the %d input file(s) could be read", len(inputPaths))
+	}
+
+	mergeStart := time.Now()
+	if headerAliases {
+		for _, header := range models.ApplyHeaderAliases(inputFiles) {
+			name, _ := models.HeaderAlias(header)
+			progress.Printf("Renaming column %q to %s", header, name)
+		}
+	}
+	if deckSchema != nil {
+		deckSchema.RenameColumns(inputFiles)
+	}
+	checkSimilarHeaders(inputFiles)
+
+	mergedHeaders := models.MergeHeaders(inputFiles)
+	progress.Add("merging", 0, time.Since(mergeStart))
+	traceStage("merging headers", mergeStart, 0)
+	progress.Printf("Merging headers: found %d unique columns", len(mergedHeaders))
+
+	return parsedPaths, inputFiles, mergedHeaders, nil
+}
+
+// checkSimilarHeaders warns about column names that differ only by case or
+// surrounding spaces, which would otherwise become separate sparse columns,
+// or merges them with --merge-similar-headers
+func checkSimilarHeaders(inputFiles []*models.InputFile) {
+	similar := models.SimilarHeaders(inputFiles)
+	if len(similar) == 0 {
+		return
+	}
+
+	if mergeSimilar {
+		models.CoalesceHeaders(inputFiles)
+		for _, group := range similar {
+			progress.Printf("Merging similar columns %s into %q", quoteHeaders(group), group[0])
+		}
+		return
 	}
 
+	for _, group := range similar {
+		warn(&models.Issue{
+			Severity:   models.SeverityWarning,
+			Code:       models.IssueSimilarHeaders,
+			Message:    fmt.Sprintf("columns %s differ only by case or spacing", quoteHeaders(group)),
+			Suggestion: "use --merge-similar-headers to merge them",
+		})
+	}
+}
+
+// quoteHeaders lists quoted column names, so differences in spacing show
+func quoteHeaders(headers []string) string {
+	quoted := make([]string, len(headers))
+	for i, header := range headers {
+		quoted[i] = fmt.Sprintf("%q", header)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// checkRequiredColumns exits with exitValidation, naming each file and the
+// columns it lacks, when an input file does not have every --require column
+func checkRequiredColumns(inputFiles []*models.InputFile) {
+	var problems []string
+	for _, inputFile := range inputFiles {
+		if missing := inputFile.MissingColumns(requiredCols); len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("Error: %s is missing required column(s): %s", inputFile.Path, strings.Join(missing, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		exitRun(exitValidation, strings.Join(problems, "\n"))
+	}
+}
+
+// checkUnique exits with exitValidation, listing where each value is, when
+// a --unique column has the same value in more than one row. Unlike
+// --skip-duplicates nothing is dropped: such rows differ in other columns
+// and need to be merged or told apart by hand.
+func checkUnique(entries []*models.DataEntry, headers []string, report *models.ProcessingReport) {
+	for _, column := range uniqueCols {
+		if !containsString(headers, column) {
+			exitRun(1, fmt.Sprintf("Error: column %q not found for --unique (available: %s)", column, strings.Join(headers, ", ")))
+		}
+	}
+
+	collisions := models.FindCollisions(entries, uniqueCols)
+	if len(collisions) == 0 {
+		progress.Printf("Checking unique columns: %s", strings.Join(uniqueCols, ", "))
+		return
+	}
+
+	problems := []string{fmt.Sprintf("Error: %d value(s) of unique column(s) %s are in more than one row:", len(collisions), strings.Join(uniqueCols, ", "))}
+	for _, collision := range collisions {
+		locations := make([]string, len(collision.Locations))
+		for i, location := range collision.Locations {
+			locations[i] = location.String()
+		}
+		problems = append(problems, fmt.Sprintf("  %s %q: %s", collision.Column, collision.Value, strings.Join(locations, ", ")))
+
+		first := collision.Locations[0]
+		for _, location := range collision.Locations[1:] {
+			issue := models.NewIssue(models.IssueNotUnique, location.Source, location.Line,
+				fmt.Sprintf("%s %q is also in %s", collision.Column, collision.Value, first))
+			issue.Severity = models.SeverityError
+			report.AddIssue(issue)
+		}
+	}
+	exitRun(exitValidation, strings.Join(problems, "\n"))
+}
+
+// selectColumns returns the --columns selection in the requested order, or
+// all merged headers when no selection was given
+func selectColumns(headers []string) ([]string, error) {
+	columns := outputColumns
+	if len(columns) == 0 && deckSchema != nil {
+		columns = schemaColumns(headers)
+	}
+	if len(columns) == 0 {
+		return headers, nil
+	}
+
+	var selected []string
+	for _, column := range columns {
+		if !containsString(headers, column) {
+			return nil, fmt.Errorf("unknown column %q in --columns (available: %s)", column, strings.Join(headers, ", "))
+		}
+		if containsString(selected, column) {
+			return nil, fmt.Errorf("column %q listed twice in --columns", column)
+		}
+		selected = append(selected, column)
+	}
+
+	progress.Printf("Selecting %d of %d columns: %s", len(selected), len(headers), strings.Join(selected, ", "))
+	return selected, nil
+}
+
+// checkProcessingFlags checks the values of the processing flags before any
+// input is read, so a mistyped flag does not wait for a large file to parse
+func checkProcessingFlags() error {
+	switch onOversize {
+	case models.OversizeTruncate, models.OversizeSkip, models.OversizeError:
+	default:
+		return fmt.Errorf("invalid --on-oversize %q: must be truncate, skip or error", onOversize)
+	}
+	if maxFieldBytes < 0 {
+		return fmt.Errorf("--max-field-bytes must not be negative")
+	}
+
+	switch dataURIMode {
+	case models.DataURIKeep, models.DataURIStrip:
+	case models.DataURIExtract:
+		if mediaDir == "" {
+			return fmt.Errorf("--data-uris extract requires --media-dir (e.g. your Anki profile's collection.media folder)")
+		}
+	default:
+		return fmt.Errorf("invalid --data-uris %q: must be keep, strip or extract", dataURIMode)
+	}
+
+	if mergeTags && !skipDuplicates {
+		return fmt.Errorf("--merge-tags requires --skip-duplicates")
+	}
+	if showDupes && !skipDuplicates {
+		return fmt.Errorf("--show-duplicates requires --skip-duplicates")
+	}
+	if assertStable && !skipDuplicates {
+		return fmt.Errorf("--assert-stable requires --skip-duplicates")
+	}
+	if len(mergeFields) > 0 && !skipDuplicates {
+		return fmt.Errorf("--merge-fields requires --skip-duplicates")
+	}
+	return nil
+}
+
+// transformEntries runs the processing stages (spell-check, duplicate removal,
+// typography, templates) over the entries and returns the surviving entries
+func transformEntries(ctx context.Context, entries []*models.DataEntry, headers []string, config *models.Config, report *models.ProcessingReport) ([]*models.DataEntry, error) {
+	// Flag likely typos before any text is transformed
+	if len(spellDicts) > 0 {
+		if err := spellCheck(entries, headers, report); err != nil {
+			return nil, err
+		}
+	}
+
+	// Clean-ups before deduplication are timed as normalizing
+	normalizeStart := time.Now()
+
+	// Inline images go first, so later steps compare and measure real text
+	if err := handleDataURIs(entries, headers, report); err != nil {
+		return nil, err
+	}
+
+	// Substitute whole values before they are compared or transformed
+	if replaceMapPath != "" {
+		replaceMap, err := models.LoadReplaceMap(replaceMapPath)
+		if err != nil {
+			return nil, err
+		}
+		report.Replacements = replaceMap.Apply(entries)
+		progress.Printf("Applying %d substitution(s): %d cell(s) replaced", replaceMap.Size(), report.Replacements)
+	}
+
+	// Config rules run first, then --regex rules in command line order
+	rules := config.RegexRules()
+	for i, rule := range regexRules {
+		parsed, err := models.ParseRegexRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("--regex #%d %q: %v", i+1, rule, err)
+		}
+		rules = append(rules, parsed)
+	}
+	for _, rule := range rules {
+		if rule.Column != models.AllColumns && !containsString(headers, rule.Column) {
+			return nil, fmt.Errorf("regex column %q not found (available: %s)", rule.Column, strings.Join(headers, ", "))
+		}
+	}
+	if len(rules) > 0 {
+		changed := models.ApplyRegexRules(entries, rules)
+		progress.Printf("Applying %d regex rule(s): %d cell(s) changed", len(rules), changed)
+	}
+
+	// Normalize emoji and lookalike symbols before entries are compared
+	// and typography looks for punctuation
+	symbols := config.SymbolRules()
+	for i, rule := range symbolRules {
+		parsed, err := models.ParseSymbolRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("--normalize-symbols #%d %q: %v", i+1, rule, err)
+		}
+		symbols = append(symbols, parsed)
+	}
+	for _, rule := range symbols {
+		if rule.Column != models.AllColumns && !containsString(headers, rule.Column) {
+			return nil, fmt.Errorf("symbol column %q not found (available: %s)", rule.Column, strings.Join(headers, ", "))
+		}
+	}
+	if len(symbols) > 0 {
+		changed := models.ApplySymbolRules(entries, symbols)
+		progress.Printf("Normalizing symbols in %d column rule(s): %d cell(s) changed", len(symbols), changed)
+	}
+
+	// Clean up item lists before comparing entries, so notes that only
+	// differ by a repeated synonym are found as duplicates
+	if lists := config.ItemLists(); len(lists) > 0 {
+		changed := models.DedupeItems(entries, lists)
+		progress.Printf("Removing repeated items: %d cell(s) changed", changed)
+	}
+
+	// Validate values once they are cleaned up, before rows are dropped
+	if deckSchema != nil {
+		checkSchema(entries, report)
+	}
+	progress.Add("normalizing", len(models.DataEntries(entries)), time.Since(normalizeStart))
+	traceStage("normalizing", normalizeStart, len(models.DataEntries(entries)))
+
 	// Remove duplicates if requested
 	if skipDuplicates {
-		originalCount := len(allEntries)
-		allEntries = removeDuplicates(allEntries)
-		if verbose && originalCount > len(allEntries) {
-			fmt.Printf("Removing duplicates: %d duplicates found\n", originalCount-len(allEntries))
-		} else if verbose {
-			fmt.Printf("Removing duplicates: no duplicates found\n")
+		mergeLists := mergeFieldLists(config)
+		hasher, err := newHasher(mergeLists)
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range dedupeColumns {
+			if !containsString(headers, column) {
+				return nil, fmt.Errorf("column %q not found for --dedupe-columns (available: %s)", column, strings.Join(headers, ", "))
+			}
+		}
+		for _, list := range mergeLists {
+			if !containsString(headers, list.Column) {
+				return nil, fmt.Errorf("column %q not found for --merge-fields (available: %s)", list.Column, strings.Join(headers, ", "))
+			}
+		}
+
+		originalCount := len(entries)
+		start := time.Now()
+		detector, err := models.NewDuplicateDetectorWithHash(hasher, dedupeHash, verifyDupes)
+		if err != nil {
+			return nil, err
+		}
+		detector.MergeTags = mergeTags
+		detector.MergeFields = mergeLists
+		var snapshot *models.DedupeSnapshot
+		if assertStable {
+			snapshot = models.SnapshotDedupe(entries, hasher)
+		}
+		var unique []*models.DataEntry
+		heartbeat.Each("duplicate removal", entries, func(chunk []*models.DataEntry) error {
+			unique = append(unique, detector.RemoveDuplicates(chunk)...)
+			return nil
+		})
+		entries = unique
+		progress.Add("deduplication", originalCount, time.Since(start))
+		traceStage("deduplication", start, originalCount)
+		if snapshot != nil {
+			if err := snapshot.Verify(entries); err != nil {
+				return nil, fmt.Errorf("--assert-stable: %v", err)
+			}
+			progress.Printf("Checking duplicate removal: first occurrences kept in input order")
+		}
+		report.DuplicateSources = append(report.DuplicateSources, detector.Sources()...)
+		report.Duplicates = append(report.Duplicates, detector.Groups()...)
+		if detector.Collisions() > 0 {
+			progress.Printf("Verifying duplicates: %d hash collision(s) kept as distinct entries", detector.Collisions())
+		}
+		if showDupes {
+			showDuplicates(detector.Groups())
+		}
+		if originalCount > len(entries) {
+			progress.Printf("Removing duplicates: %d duplicates found", originalCount-len(entries))
+		} else {
+			progress.Printf("Removing duplicates: no duplicates found")
 		}
 	}
 
 	// Apply typography formatting
-	if frenchMode || smartQuotes {
-		if verbose {
-			fmt.Printf("Applying typography formatting")
-			if frenchMode && smartQuotes {
-				fmt.Printf(" (French typography and smart quotes)")
-			} else if frenchMode {
-				fmt.Printf(" (French typography)")
-			} else {
-				fmt.Printf(" (smart quotes)")
+	typographyRules := columnTypography(config)
+	if frenchMode || smartQuotes || cjkSpacing || len(typographyRules) > 0 {
+		var modes []string
+		if frenchMode {
+			modes = append(modes, "French typography")
+		}
+		if smartQuotes {
+			modes = append(modes, "smart quotes")
+		}
+		if cjkSpacing {
+			modes = append(modes, "CJK spacing")
+		}
+		mode := "schema columns only"
+		if len(modes) > 0 {
+			mode = strings.Join(modes, " and ")
+		}
+		detection := ""
+		if frenchMode && autoLang {
+			detection = " with per-cell language detection"
+		}
+		progress.Printf("Applying typography formatting (%s)%s...", mode, detection)
+		start := time.Now()
+		pending := typographyCache.Apply(entries)
+		if typographyCache != nil {
+			progress.Printf("Reusing cached typography: %d of %d entries", len(entries)-len(pending), len(entries))
+		}
+		var slow []*models.SlowField
+		err := heartbeat.Each("typography processing", pending, func(chunk []*models.DataEntry) error {
+			chunkSlow, err := models.ApplyTypographyLimit(ctx, chunk, typographyRules, frenchMode, smartQuotes, cjkSpacing, autoLang, cellTimeout)
+			slow = append(slow, chunkSlow...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		typographyCache.Store(pending, slow)
+		if err := typographyCache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot update the cache: %v\n", err)
+		}
+		progress.Add("typography", len(entries), time.Since(start))
+		traceStage("typography", start, len(entries))
+		for _, field := range slow {
+			report.AddIssue(models.NewIssue(models.IssueSlowCell, field.Entry.Source, field.Entry.LineNumber,
+				fmt.Sprintf("column %s (%d bytes) took over %s to format; left unchanged", field.Column, field.Size, cellTimeout)))
+		}
+	}
+
+	// Mark the direction of mixed Hebrew/Arabic and Latin text, after
+	// typography so the marks follow the final punctuation
+	if len(dirMarks) > 0 {
+		for _, column := range dirMarks {
+			if !containsString(headers, column) {
+				return nil, fmt.Errorf("column %q not found for --direction-marks (available: %s)", column, strings.Join(headers, ", "))
 			}
-			fmt.Printf("...\n")
 		}
-		applyTypography(allEntries, frenchMode, smartQuotes)
+		changed := models.ApplyDirectionMarks(entries, dirMarks)
+		progress.Printf("Adding direction marks: %d mixed-direction field(s) in %s", changed, strings.Join(dirMarks, ", "))
 	}
 
-	// Write output
-	outputFile := determineOutputPath(inputPaths)
-	if verbose {
-		fmt.Printf("Writing output to %s\n", outputFile)
+	// Wrap configured columns in HTML templates (after typography so
+	// quotes inside the markup are left alone)
+	if templates := config.FieldTemplates(); len(templates) > 0 {
+		progress.Printf("Applying field templates to %d column(s)", len(templates))
+		models.ApplyTemplates(entries, templates)
 	}
 
-	err = writeCSV(outputFile, mergedHeaders, allEntries)
+	// Enforce the field size limit last, on the text that will be imported
+	if maxFieldBytes > 0 {
+		var err error
+		if entries, err = limitFieldSizes(entries, headers, report); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// mergeFieldLists returns the columns whose items are merged from removed
+// duplicates: those of the config, with their delimiters, then the
+// --merge-fields columns not in it, whose items are separated by <br>
+func mergeFieldLists(config *models.Config) []*models.ItemList {
+	lists := config.MergeLists()
+	for _, column := range mergeFields {
+		if _, ok := config.MergeFields[column]; !ok {
+			lists = append(lists, models.NewItemList(column, models.DefaultMergeDelimiter))
+		}
+	}
+	return lists
+}
+
+// newHasher returns the hasher of --dedupe-strategy, which compares the
+// --dedupe-columns for key-columns and every column but the merged ones for
+// merge-fields
+func newHasher(mergeLists []*models.ItemList) (models.Hasher, error) {
+	if dedupeStrategy != models.DedupeMergeFields {
+		return models.NewHasher(dedupeStrategy, dedupeColumns)
+	}
+	var columns []string
+	for _, list := range mergeLists {
+		columns = append(columns, list.Column)
+	}
+	return models.NewHasher(dedupeStrategy, columns)
+}
+
+// columnTypography returns the typography of the columns that do not follow
+// the flags alone: those declared in a language, unless the schema gives
+// them a typography of their own
+func columnTypography(config *models.Config) map[string]models.TypographyRule {
+	rules := models.LanguageTypography(config.Languages, frenchMode, smartQuotes, cjkSpacing)
+	if deckSchema != nil {
+		maps.Copy(rules, deckSchema.TypographyRules())
+	}
+	return rules
+}
+
+// openTypographyCache opens the --cache-dir cache for inputFiles, keyed by
+// the build and every option that changes how a cell is formatted
+func openTypographyCache(inputFiles []*models.InputFile, config *models.Config) (*models.TypographyCache, error) {
+	info := buildInfo()
+	return models.NewTypographyCache(cacheDir, inputFiles, info.Version, info.Commit,
+		fmt.Sprint(frenchMode, smartQuotes, cjkSpacing, autoLang), fmt.Sprint(columnTypography(config)))
+}
+
+// sortCollator is the collation of the --collate language, nil for byte order
+var sortCollator *collate.Collator
+
+// checkCollation checks --collate before any input is read and loads its
+// collation
+func checkCollation() error {
+	if collateLang == "" {
+		return nil
+	}
+	if sortBy == "" {
+		return fmt.Errorf("--collate requires --sort-by")
+	}
+	collator, err := models.NewCollator(collateLang)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("--collate: %v", err)
 	}
+	sortCollator = collator
+	return nil
+}
 
-	// Success message
-	processingTime := time.Since(startTime)
-	fmt.Printf("Done. Processed %d unique entries in %.2f seconds\n",
-		len(allEntries), processingTime.Seconds())
+// sortEntries sorts the entries by the --sort-by column, in byte order or
+// with the --collate language's collation
+func sortEntries(entries []*models.DataEntry) {
+	if sortCollator == nil {
+		models.SortEntries(entries, sortBy)
+		return
+	}
+	progress.Printf("Sorting by %s in %s collation order", sortBy, collateLang)
+	models.SortEntriesCollated(entries, sortBy, sortCollator)
+}
 
-	if verbose {
-		showSummary(inputPaths, totalRecords, len(allEntries), processingTime)
+// orderByFrequency sorts the entries by the --order-by-frequency rank of
+// their --frequency-column value and returns how many were not ranked
+func orderByFrequency(entries []*models.DataEntry, headers, outputHeaders []string) (int, error) {
+	if sortBy != "" {
+		return 0, fmt.Errorf("--order-by-frequency and --sort-by cannot be used together")
+	}
+
+	column := frequencyCol
+	if column == "" && len(outputHeaders) > 0 {
+		column = outputHeaders[0]
+	}
+	if !containsString(headers, column) {
+		return 0, fmt.Errorf("--frequency-column %q not found (available: %s)", column, strings.Join(headers, ", "))
 	}
+
+	list, err := models.LoadFrequencyList(frequencyList)
+	if err != nil {
+		return 0, err
+	}
+	progress.Printf("Ordering by frequency of %s (%d ranked words)", column, list.Size())
+
+	return models.SortByFrequency(entries, column, list), nil
 }
 
-// Helper functions - simplified implementations
+// handleDataURIs keeps, strips or extracts base64 data URIs according to
+// --data-uris, adding an issue for each field that had any
+func handleDataURIs(entries []*models.DataEntry, headers []string, report *models.ProcessingReport) error {
+	store := models.NewMediaStore(mediaDir)
+	for _, entry := range entries {
+		if entry.LineNumber == 0 {
+			continue
+		}
+		for _, header := range headers {
+			value := entry.GetValue(header)
+			stats := models.FindDataURIs(value)
+			if stats.Count == 0 {
+				continue
+			}
+
+			switch dataURIMode {
+			case models.DataURIKeep:
+				issue := models.NewIssue(models.IssueDataURI, entry.Source, entry.LineNumber,
+					fmt.Sprintf("column %s embeds %d data URI image(s) (%d bytes)", header, stats.Count, stats.Bytes))
+				issue.Suggestion = "use --data-uris strip or extract"
+				report.AddIssue(issue)
+			case models.DataURIStrip:
+				entry.SetValue(header, models.StripDataURIs(value))
+				issue := models.NewIssue(models.IssueDataURI, entry.Source, entry.LineNumber,
+					fmt.Sprintf("column %s: removed %d data URI image(s) (%d bytes)", header, stats.Count, stats.Bytes))
+				issue.Severity = models.SeverityInfo
+				report.AddIssue(issue)
+			case models.DataURIExtract:
+				extracted, err := store.ExtractDataURIs(value)
+				if err != nil {
+					return err
+				}
+				entry.SetValue(header, extracted)
+				issue := models.NewIssue(models.IssueDataURI, entry.Source, entry.LineNumber,
+					fmt.Sprintf("column %s: extracted %d data URI image(s) to %s", header, stats.Count, mediaDir))
+				issue.Severity = models.SeverityInfo
+				report.AddIssue(issue)
+			}
+		}
+	}
+
+	if store.Saved > 0 {
+		progress.Printf("Saved %d media file(s) to %s", store.Saved, mediaDir)
+	}
+	return nil
+}
+
+// limitFieldSizes truncates, drops or rejects entries with fields over
+// --max-field-bytes, adding an issue for each field
+func limitFieldSizes(entries []*models.DataEntry, headers []string, report *models.ProcessingReport) ([]*models.DataEntry, error) {
+	oversize := models.FindOversizeFields(entries, headers, maxFieldBytes)
+	if len(oversize) == 0 {
+		return entries, nil
+	}
+
+	switch onOversize {
+	case models.OversizeTruncate:
+		for _, field := range oversize {
+			field.Entry.SetValue(field.Column, models.TruncateUTF8(field.Entry.GetValue(field.Column), maxFieldBytes))
+			report.AddIssue(models.NewIssue(models.IssueFieldTruncated, field.Entry.Source, field.Entry.LineNumber,
+				fmt.Sprintf("column %s is %d bytes, truncated to %d", field.Column, field.Size, maxFieldBytes)))
+		}
+		return entries, nil
+
+	case models.OversizeSkip:
+		skipped := make(map[*models.DataEntry]bool)
+		for _, field := range oversize {
+			skipped[field.Entry] = true
+			report.AddIssue(models.NewIssue(models.IssueFieldTooLarge, field.Entry.Source, field.Entry.LineNumber,
+				fmt.Sprintf("column %s is %d bytes, over --max-field-bytes %d; row skipped", field.Column, field.Size, maxFieldBytes)))
+		}
+		var kept []*models.DataEntry
+		for _, entry := range entries {
+			if !skipped[entry] {
+				kept = append(kept, entry)
+			}
+		}
+		report.SkippedRecords += len(skipped)
+		return kept, nil
+
+	case models.OversizeError:
+		for _, field := range oversize {
+			fmt.Fprintf(os.Stderr, "  %s\n", field)
+		}
+		return nil, fmt.Errorf("%d field(s) exceed --max-field-bytes %d", len(oversize), maxFieldBytes)
+	}
+
+	return entries, nil
+}
 
 func collectInputFiles(args []string) ([]string, error) {
 	var inputPaths []string
@@ -192,7 +1195,7 @@ func collectInputFiles(args []string) ([]string, error) {
 			inputPaths = append(inputPaths, arg)
 		} else {
 			for _, match := range matches {
-				if isSupportedFile(match) {
+				if isSupportedFile(match) || models.IsZip(match) {
 					inputPaths = append(inputPaths, match)
 				}
 			}
@@ -203,155 +1206,225 @@ func collectInputFiles(args []string) ([]string, error) {
 		return nil, fmt.Errorf("no valid input files found")
 	}
 
+	// Argument order decides the merged column order; sorting makes it
+	// independent of how the files were listed
+	if deterministic {
+		sort.Strings(inputPaths)
+	}
+
 	return inputPaths, nil
 }
 
 func parseFile(filePath string) (*models.InputFile, error) {
+	if models.HasAnkiHeader(filePath) {
+		return parseAnkiExport(filePath)
+	}
+
 	inputFile := models.NewInputFile(filePath)
 	inputFile.DetectSeparator()
 
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+	parser := models.NewCSVParser()
+	parser.LazyQuotes = !strictQuotes
+	parser.Header = headerMode()
+	parser.Mmap = useMmap
+	addRecord := func(record []string, line int) error {
+		inputFile.AddRecord(record, line)
+		return nil
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.Comma = inputFile.Separator
-	reader.LazyQuotes = true
-	reader.TrimLeadingSpace = false
-
-	records, err := reader.ReadAll()
+	var err error
+	if applyFixes {
+		var data []byte
+		if data, err = readFixed(inputFile); err != nil {
+			return nil, err
+		}
+		err = parser.Parse(bytes.NewReader(data), inputFile, addRecord)
+	} else if err = parser.ParseFile(inputFile, addRecord); err != nil {
+		explainParseError(inputFile)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if len(records) < 1 {
-		return nil, fmt.Errorf("file contains no data")
+	if parser.Header == models.HeaderAuto && inputFile.LooksHeaderless() && !confirmHeader(inputFile) {
+		inputFile.UseGeneratedHeaders()
 	}
 
-	inputFile.Headers = records[0]
+	return inputFile, nil
+}
 
-	// Strip UTF-8 BOM from first header field if present
-	if len(inputFile.Headers) > 0 && len(inputFile.Headers[0]) > 0 {
-		if runes := []rune(inputFile.Headers[0]); len(runes) > 0 && runes[0] == '\uFEFF' {
-			inputFile.Headers[0] = string(runes[1:])
-		}
+// parseAnkiExport reads a file that starts with Anki #directives, such as an
+// Anki "Notes in Plain Text" export or a previous ankiprep output
+func parseAnkiExport(filePath string) (*models.InputFile, error) {
+	file, err := models.OpenInput(filePath)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	if len(records) > 1 {
-		inputFile.Records = records[1:]
+	inputFile := models.NewInputFile(filePath)
+	header, err := models.ParseAnkiExport(file, inputFile, !strictQuotes)
+	if err != nil {
+		return nil, err
+	}
+	if conflict := models.SeparatorConflict(filePath, header); conflict != "" {
+		warn(models.NewIssue(models.IssueSeparator, filePath, 0, conflict))
 	}
-
 	return inputFile, nil
 }
 
-func mergeHeaders(inputFiles []*models.InputFile) []string {
-	seen := make(map[string]bool)
-	var merged []string
+// parseQuizletFile reads a Quizlet export with the --quizlet-*-sep separators
+func parseQuizletFile(filePath string) (*models.InputFile, error) {
+	termSep, err := models.ParseSeparatorName(quizletTermSep)
+	if err != nil {
+		return nil, fmt.Errorf("--quizlet-term-sep: %v", err)
+	}
+	rowSep, err := models.ParseSeparatorName(quizletRowSep)
+	if err != nil {
+		return nil, fmt.Errorf("--quizlet-row-sep: %v", err)
+	}
 
-	for _, inputFile := range inputFiles {
-		for _, header := range inputFile.Headers {
-			if header != "" && !seen[header] {
-				seen[header] = true
-				merged = append(merged, header)
-			}
-		}
+	file, err := models.OpenInput(filePath)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	return merged
+	inputFile := models.NewInputFile(filePath)
+	if err := models.ParseQuizlet(file, inputFile, termSep, rowSep); err != nil {
+		return nil, err
+	}
+	return inputFile, nil
 }
 
-func removeDuplicates(entries []*models.DataEntry) []*models.DataEntry {
-	seen := make(map[string]bool)
-	var unique []*models.DataEntry
+// parseMemriseFile reads a Memrise CSV export, which always has a header row
+func parseMemriseFile(filePath string) (*models.InputFile, error) {
+	inputFile := models.NewInputFile(filePath)
+	inputFile.DetectSeparator()
 
-	for _, entry := range entries {
-		key := entry.GetHash()
-		if !seen[key] {
-			seen[key] = true
-			unique = append(unique, entry)
-		}
+	parser := models.NewCSVParser()
+	parser.LazyQuotes = !strictQuotes
+	parser.Header = models.HeaderPresent
+	parser.Mmap = useMmap
+	err := parser.ParseFile(inputFile, func(record []string, line int) error {
+		inputFile.AddRecord(record, line)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return unique
+	if err := models.NormalizeMemrise(inputFile); err != nil {
+		return nil, err
+	}
+	return inputFile, nil
 }
 
-// isEnglishColumn determines if a column header indicates English content
-// that should not have French typography rules applied
-func isEnglishColumn(header string) bool {
-	header = strings.ToLower(strings.TrimSpace(header))
-	englishPatterns := []string{"english", "eng", "pronunciation", "phonetic"}
+// requireInputs accepts the command line when it names at least one input
+// file, as an argument or through --quizlet or --memrise
+func requireInputs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && len(quizletFiles) == 0 && len(memriseFiles) == 0 {
+		return fmt.Errorf("requires at least 1 arg(s), only received 0")
+	}
+	return nil
+}
 
-	for _, pattern := range englishPatterns {
-		if strings.Contains(header, pattern) {
-			return true
-		}
+// headerMode translates the --no-header and --assume-header flags
+func headerMode() models.HeaderMode {
+	switch {
+	case noHeader:
+		return models.HeaderAbsent
+	case assumeHeader:
+		return models.HeaderPresent
+	default:
+		return models.HeaderAuto
 	}
-	return false
 }
 
-func applyTypography(entries []*models.DataEntry, french, quotes bool) {
-	for _, entry := range entries {
-		for key, value := range entry.Values {
-			// Determine which typography rules to apply based on column header
-			isEnglish := isEnglishColumn(key)
+// confirmHeader asks whether a suspicious first row is really a header. When
+// stdin is not a terminal the row is kept as the header and a warning is printed.
+func confirmHeader(inputFile *models.InputFile) bool {
+	reason := inputFile.HeaderSuspicion()
+	if !isInteractive() {
+		issue := models.NewIssue(models.IssueHeaderSuspect, inputFile.Path, 0,
+			fmt.Sprintf("first row probably isn't a header (%s): %s", reason, strings.Join(inputFile.Headers, ",")))
+		issue.Suggestion = "use --no-header or --assume-header"
+		warn(issue)
+		return true
+	}
 
-			// Always apply smart quotes if enabled
-			applySmartQuotes := quotes
+	fmt.Fprintf(os.Stderr, "First row of %s probably isn't a header (%s): %s\n", inputFile.Path, reason, strings.Join(inputFile.Headers, ","))
+	fmt.Fprintf(os.Stderr, "Use it as the header row? [y/N] ")
 
-			// Only apply French typography to non-English fields
-			applyFrench := french && !isEnglish
+	var answer string
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
 
-			// Create processor with appropriate settings
-			processor := models.NewTypographyProcessor(applyFrench, applySmartQuotes)
-			entry.Values[key] = processor.ProcessText(value)
-		}
+// isInteractive reports whether stdin is a terminal (and not the null device,
+// which is also a character device)
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
 	}
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
 }
 
-func writeCSV(outputPath string, headers []string, entries []*models.DataEntry) error {
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return err
+// spellCheck reports words of the designated columns missing from the
+// dictionaries as warnings; cell content is never modified
+func spellCheck(entries []*models.DataEntry, headers []string, report *models.ProcessingReport) error {
+	if len(spellColumns) == 0 {
+		return fmt.Errorf("--spell-dict requires --spell-columns")
 	}
-	defer file.Close()
 
-	// Write Anki metadata headers directly (not as CSV)
-	ankiHeaders := []string{
-		"#separator:comma",
-		"#html:true",
-		"#columns:" + strings.Join(headers, ","),
+	for _, column := range spellColumns {
+		if !containsString(headers, column) {
+			return fmt.Errorf("spell-check column %q not found (available: %s)", column, strings.Join(headers, ", "))
+		}
 	}
 
-	for _, header := range ankiHeaders {
-		if _, err := file.WriteString(header + "\n"); err != nil {
+	dictionary := models.NewDictionary()
+	for _, path := range spellDicts {
+		if err := dictionary.LoadWordList(path); err != nil {
 			return err
 		}
 	}
 
-	// Now write data using CSV writer
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	progress.Printf("Spell-checking %d column(s) against %d known words", len(spellColumns), dictionary.Size())
 
-	// Write data
 	for _, entry := range entries {
-		record := make([]string, len(headers))
-		for i, header := range headers {
-			record[i] = entry.Values[header]
+		if entry.LineNumber == 0 {
+			continue
 		}
-		if err := writer.Write(record); err != nil {
-			return err
+		for _, column := range spellColumns {
+			for _, word := range dictionary.UnknownWords(entry.GetValue(column)) {
+				report.AddIssue(models.NewIssue(models.IssuePossibleTypo, entry.Source, entry.LineNumber,
+					fmt.Sprintf("possible typo %q in column %s", word, column)))
+			}
 		}
 	}
 
 	return nil
 }
 
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
 // Utility functions
 func isSupportedFile(filePath string) bool {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	return ext == ".csv" || ext == ".tsv"
+	ext := strings.ToLower(filepath.Ext(models.TrimCompression(filePath)))
+	return ext == ".csv" || ext == ".tsv" || ext == ".txt"
 }
 
 func getFileSize(filePath string) int64 {
@@ -361,40 +1434,143 @@ func getFileSize(filePath string) int64 {
 	return 0
 }
 
-func getFileType(filePath string) string {
-	if strings.HasSuffix(strings.ToLower(filePath), ".tsv") {
-		return "tab-separated"
+func getFileType(inputFile *models.InputFile) string {
+	return inputFile.GetSeparatorString() + "-separated"
+}
+
+// showDuplicateSources prints where removed duplicates came from, one line per
+// pair of files
+func showDuplicateSources(report *models.ProcessingReport) {
+	if len(report.DuplicateSources) == 0 {
+		return
+	}
+	fmt.Fprintf(statusOut(), "Duplicates removed:\n")
+	for _, sources := range report.DuplicateSources {
+		noun := "duplicates"
+		if sources.Count == 1 {
+			noun = "duplicate"
+		}
+		if sources.CrossFile() {
+			fmt.Fprintf(statusOut(), "  %d %s between %s and %s\n", sources.Count, noun, sources.Original, sources.Duplicate)
+		} else {
+			fmt.Fprintf(statusOut(), "  %d %s within %s\n", sources.Count, noun, sources.Original)
+		}
 	}
-	return "comma-separated"
 }
 
-func determineOutputPath(inputPaths []string) string {
-	if outputPath != "" {
-		return outputPath
+// showDuplicates prints every kept entry with the duplicates removed in its
+// favour, for --show-duplicates
+func showDuplicates(groups []*models.DuplicateGroup) {
+	for _, group := range groups {
+		fmt.Fprintf(statusOut(), "Duplicate: kept %s, removed %s\n", group.Kept, group.RemovedRanges())
 	}
+}
 
-	if len(inputPaths) == 1 {
-		base := strings.TrimSuffix(inputPaths[0], filepath.Ext(inputPaths[0]))
-		return base + "_processed.csv"
+// showCardCount prints how many cards the cloze notes will create, so the
+// size of an import is known before it is done
+func showCardCount(cards *models.CardCount) {
+	if cards.ClozeNotes == 0 {
+		return
+	}
+	fmt.Fprintf(statusOut(), "Cards: %d from %d cloze note(s) (up to %d per note)", cards.ClozeCards, cards.ClozeNotes, cards.MaxPerNote)
+	if cards.OtherNotes > 0 {
+		fmt.Fprintf(statusOut(), ", plus %d note(s) without clozes", cards.OtherNotes)
 	}
+	fmt.Fprintln(statusOut())
+}
 
-	return "merged_output.csv"
+// showIssues prints the issues found in rows of the input to stderr; issues
+// about whole files and columns were printed by warn, and applied fixes by
+// readFixed, as they were found
+func showIssues(report *models.ProcessingReport) {
+	var rows []*models.Issue
+	for _, issue := range report.Issues {
+		if issue.Line > 0 && (issue.Fix == nil || !issue.Fix.Applied) {
+			rows = append(rows, issue)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Issues (%d):\n", len(rows))
+	for _, issue := range rows {
+		fmt.Fprintf(os.Stderr, "  %s[%s] %s\n", issue.Severity, issue.Code, issue)
+	}
+}
+
+// warn prints an issue about a whole file or column and records it in the
+// report of the run
+func warn(issue *models.Issue) {
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", issue)
+	if runReport != nil {
+		runReport.AddIssue(issue)
+	}
+}
+
+// writeReport saves the processing report as indented JSON
+func writeReport(path string, report *models.ProcessingReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := fileService.CreateTemp(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return fileService.Commit(file.Name(), path)
+}
+
+// sparseColumnThreshold is the fill rate (percent) below which a column is flagged as mostly empty
+const sparseColumnThreshold = 10.0
+
+// showColumnStats prints per-column statistics, flagging mostly empty columns
+// that usually point to a broken merge
+func showColumnStats(report *models.ProcessingReport) {
+	if len(report.Columns) == 0 {
+		return
+	}
+	fmt.Fprintf(statusOut(), "\nColumn statistics:\n")
+	fmt.Fprintf(statusOut(), "  %-20s %10s %8s %8s %10s\n", "Column", "Filled", "Max len", "Avg len", "Distinct")
+	for _, column := range report.Columns {
+		fmt.Fprintf(statusOut(), "  %-20s %9.1f%% %8d %8.1f %10d", column.Column, column.FillRate(),
+			column.MaxLength, column.AverageLength, column.DistinctCount)
+		if column.Total > 0 && column.FillRate() < sparseColumnThreshold {
+			fmt.Fprintf(statusOut(), "  (mostly empty)")
+		}
+		fmt.Fprintf(statusOut(), "\n")
+	}
 }
 
 func showSummary(inputFiles []string, totalInput, totalOutput int, duration time.Duration) {
-	fmt.Printf("\nProcessing Summary:\n")
-	fmt.Printf("Input files: %d\n", len(inputFiles))
+	progress.Printf("\nProcessing Summary:")
+	progress.Printf("Input files: %d", len(inputFiles))
 	for i, file := range inputFiles {
-		fmt.Printf("  %d. %s\n", i+1, file)
+		progress.Printf("  %d. %s", i+1, file)
+	}
+	progress.Printf("Total input records: %d", totalInput)
+	progress.Printf("Output records: %d", totalOutput)
+	progress.Printf("Processing time: %.2f seconds", duration.Seconds())
+	for _, stage := range progress.Stages() {
+		share := 0.0
+		if duration > 0 {
+			share = float64(stage.Duration) / float64(duration) * 100
+		}
+		progress.Printf("  %s: %d record(s) in %.2f seconds (%.0f%%)", stage.Name, stage.Items, stage.Duration.Seconds(), share)
 	}
-	fmt.Printf("Total input records: %d\n", totalInput)
-	fmt.Printf("Output records: %d\n", totalOutput)
-	fmt.Printf("Processing time: %.2f seconds\n", duration.Seconds())
 	if duration.Seconds() > 0 && totalOutput > 0 {
 		rate := float64(totalOutput) / duration.Seconds()
-		fmt.Printf("Processing rate: %.0f records/second\n", rate)
+		progress.Printf("Processing rate: %.0f records/second", rate)
 	}
-	fmt.Printf("Processing completed successfully\n")
+	progress.Printf("Processing completed successfully")
 }
 
 func Execute() {
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// cellFlags are the processing flags that change the contents of cells
+var cellFlags = []string{
+	"french", "smart-quotes", "auto-lang", "merge-tags", "replace-map", "regex",
+	"max-field-bytes", "split-column", "join-columns", "explode", "direction-marks",
+	"normalize-symbols", "cjk-spacing", "merge-fields",
+}
+
+// checkNoTransform rejects, with --no-transform, every flag, --config rule
+// and --schema setting that would change the contents of a cell, so cells
+// are written as they were read
+func checkNoTransform(cmd *cobra.Command, config *models.Config) error {
+	if !noTransform {
+		return nil
+	}
+
+	for _, name := range cellFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--no-transform leaves cells as they are and cannot be combined with --%s", name)
+		}
+	}
+	if dataURIMode != models.DataURIKeep {
+		return fmt.Errorf("--no-transform leaves cells as they are and cannot be combined with --data-uris %s", dataURIMode)
+	}
+
+	// Tab-separated and non-Anki output rewrite line breaks and tabs
+	if outputSep == models.SeparatorTab {
+		return fmt.Errorf("--no-transform writes comma-separated output, since tab-separated output turns line breaks into <br>")
+	}
+	if _, anki := models.FormatSeparator(outputFormat); outputFormat != "" && !anki {
+		return fmt.Errorf("--no-transform cannot be combined with --format %s", outputFormat)
+	}
+
+	if len(config.RegexRules()) > 0 || len(config.ItemLists()) > 0 || len(config.FieldTemplates()) > 0 || len(config.SymbolRules()) > 0 || len(config.MergeLists()) > 0 {
+		return fmt.Errorf("--no-transform cannot be combined with the regex rules, item lists, merged fields, field templates or symbol rules of --config %s", configPath)
+	}
+	if deckSchema != nil && len(deckSchema.TypographyRules()) > 0 {
+		return fmt.Errorf("--no-transform cannot be combined with the typography of the deck schema")
+	}
+
+	progress.Printf("Leaving cell contents unchanged (--no-transform)")
+	return nil
+}
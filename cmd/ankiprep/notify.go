@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"ankiprep/internal/models"
+	"ankiprep/internal/notify"
+)
+
+// SMTP credentials are read from the environment rather than flags, so they
+// do not show up in process lists or shell history
+const (
+	smtpUsernameEnv = "ANKIPREP_SMTP_USERNAME"
+	smtpPasswordEnv = "ANKIPREP_SMTP_PASSWORD"
+)
+
+// runReport is the report of the conversion in progress; the --notify-*
+// targets receive it when the run ends, even if it fails part way
+var runReport *models.ProcessingReport
+
+// notifiers returns the targets of --notify-webhook and --notify-email
+func notifiers() []notify.Notifier {
+	var targets []notify.Notifier
+	if notifyWebhook != "" {
+		webhook := notify.NewWebhook(notifyWebhook)
+		webhook.Retry = retryPolicy()
+		targets = append(targets, webhook)
+	}
+	if len(notifyEmail) > 0 {
+		targets = append(targets, &notify.Email{
+			Server:   smtpServer,
+			From:     smtpFrom,
+			To:       notifyEmail,
+			Username: os.Getenv(smtpUsernameEnv),
+			Password: os.Getenv(smtpPasswordEnv),
+		})
+	}
+	return targets
+}
+
+// sendNotifications delivers event to every target; a target that cannot be
+// reached is only a warning, since the run itself is over
+func sendNotifications(event *notify.Event) {
+	if runReport == nil {
+		return
+	}
+	event.Report = runReport
+	for _, target := range notifiers() {
+		if err := target.Notify(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot send notification: %v\n", err)
+		}
+	}
+}
+
+// notifyOutput describes where a completed run put the notes
+func notifyOutput(inputPaths []string) string {
+	switch {
+	case pushNotes:
+		return fmt.Sprintf("AnkiConnect deck %q", pushDeck)
+	case outputPath == stdoutPath:
+		return "standard output"
+	}
+	return determineOutputPath(inputPaths)
+}
+
+// exitRun ends a failed or, with exitCancelled, cancelled run: it prints
+// message, sends it to the --notify-* targets with the report so far,
+// exports the run's traces and exits with code
+func exitRun(code int, message string) {
+	fmt.Fprintln(os.Stderr, message)
+	if runReport != nil {
+		runReport.AddErrorString(message)
+	}
+	endTracing(message)
+	status := notify.StatusFailed
+	if code == exitCancelled {
+		status = notify.StatusCancelled
+	}
+	sendNotifications(&notify.Event{Status: status, Error: message})
+	os.Exit(code)
+}
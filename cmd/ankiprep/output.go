@@ -0,0 +1,299 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ankiprep/internal/ankiconnect"
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// stdoutPath is the -o value that writes the import file to standard output
+const stdoutPath = "-"
+
+// Values of --network-fs
+const (
+	networkFSAuto = "auto"
+	networkFSOn   = "on"
+	networkFSOff  = "off"
+)
+
+// checkOutputFlags validates the flags that choose where output goes
+func checkOutputFlags(cmd *cobra.Command) error {
+	if outputSep != models.SeparatorComma && outputSep != models.SeparatorTab {
+		return fmt.Errorf("invalid --output-separator %q: must be comma or tab", outputSep)
+	}
+	if _, ok := models.NewOutputFormat(outputFormat, outputSep); !ok {
+		return fmt.Errorf("invalid --format %q: must be csv, tsv, mochi, remnote or quizlet", outputFormat)
+	}
+	if outputFormat != "" && cmd.Flags().Changed("output-separator") {
+		if separator, ok := models.FormatSeparator(outputFormat); !ok || separator != outputSep {
+			return fmt.Errorf("--format %s and --output-separator %s disagree", outputFormat, outputSep)
+		}
+	}
+	if separator, ok := models.FormatSeparator(outputFormat); ok {
+		outputSep = separator
+	}
+	if plainHeader {
+		if _, anki := models.FormatSeparator(outputFormat); outputFormat != "" && !anki {
+			return fmt.Errorf("--plain-header writes CSV or TSV and cannot be combined with --format %s", outputFormat)
+		}
+		if pushNotes {
+			return fmt.Errorf("--plain-header writes a file and cannot be combined with --push")
+		}
+	}
+	if verifyOutput {
+		conflict := ""
+		if _, anki := models.FormatSeparator(outputFormat); outputFormat != "" && !anki {
+			conflict = "--format " + outputFormat
+		} else if pushNotes {
+			conflict = "--push"
+		} else if plainHeader {
+			conflict = "--plain-header"
+		} else if outputPath == stdoutPath {
+			conflict = "-o -"
+		}
+		if conflict != "" {
+			return fmt.Errorf("--verify reads back an Anki import file and cannot be combined with %s", conflict)
+		}
+	}
+	if emitLanguages {
+		if _, anki := models.FormatSeparator(outputFormat); outputFormat != "" && !anki {
+			return fmt.Errorf("--emit-languages writes an Anki directive and cannot be combined with --format %s", outputFormat)
+		} else if pushNotes || plainHeader {
+			return fmt.Errorf("--emit-languages writes an Anki directive and cannot be combined with --push or --plain-header")
+		}
+	}
+	if compressOutput && pushNotes {
+		return fmt.Errorf("--compress writes a file and cannot be combined with --push")
+	}
+	if pushNotes {
+		if outputPath != "" || outputFormat != "" {
+			return fmt.Errorf("--push adds notes to Anki and cannot be combined with -o or --format")
+		}
+		if pushDeck == "" {
+			return fmt.Errorf("--push needs a --deck")
+		}
+	}
+	switch networkFS {
+	case networkFSAuto, networkFSOn, networkFSOff:
+	default:
+		return fmt.Errorf("invalid --network-fs %q: must be auto, on or off", networkFS)
+	}
+	if writeBatch < 1 {
+		return fmt.Errorf("--write-batch-bytes must be at least 1, got %d", writeBatch)
+	}
+	if writeBatch > models.MaxBatchSize {
+		return fmt.Errorf("--write-batch-bytes must be at most %d (64 MiB), got %d", models.MaxBatchSize, writeBatch)
+	}
+	if retryAttempts < 1 {
+		return fmt.Errorf("--retries must be at least 1, got %d", retryAttempts)
+	}
+	if retryBackoff < 0 {
+		return fmt.Errorf("--retry-backoff cannot be negative, got %s", retryBackoff)
+	}
+	return nil
+}
+
+// retryPolicy returns the --retries and --retry-backoff settings used for
+// AnkiConnect and --notify-webhook requests
+func retryPolicy() *models.RetryPolicy {
+	return models.NewRetryPolicy(retryAttempts, retryBackoff)
+}
+
+// ankiClient returns a client for AnkiConnect at --ankiconnect-url
+func ankiClient() *ankiconnect.Client {
+	client := ankiconnect.NewClient(ankiConnectURL)
+	client.Retry = retryPolicy()
+	return client
+}
+
+// checkHeaderNames warns about output columns whose names are changed for the
+// #columns line of the Anki import file
+func checkHeaderNames(headers []string) {
+	if _, anki := models.FormatSeparator(outputFormat); pushNotes || plainHeader || (outputFormat != "" && !anki) {
+		return
+	}
+	for _, header := range headers {
+		if sanitized := models.SanitizeHeader(header); sanitized != header {
+			warn(&models.Issue{
+				Severity: models.SeverityWarning,
+				Code:     models.IssueHeaderRenamed,
+				Message:  fmt.Sprintf("column %q is written as %q in #columns (no line breaks or leading #)", header, sanitized),
+			})
+		}
+	}
+}
+
+// defaultNoteType is the note type --push uses without --note-type
+const defaultNoteType = "Basic"
+
+// noteType returns --note-type, or the note type of the schema without it
+func noteType() string {
+	if pushNoteType != "" {
+		return pushNoteType
+	}
+	return schemaNoteType
+}
+
+// checkNoteType warns about output columns that do not line up with the fields
+// of the note type, which Anki would silently drop or leave empty. The fields
+// come from --note-types, or from AnkiConnect with --push; runs writing a
+// file without --note-types are not checked, so they never wait for Anki.
+func checkNoteType(ctx context.Context, headers []string) {
+	name := noteType()
+	if name == "" || (noteTypesPath == "" && !pushNotes) {
+		return
+	}
+
+	var fields []string
+	if noteTypesPath != "" {
+		noteTypes, err := models.LoadNoteTypes(noteTypesPath)
+		if err != nil {
+			exitRun(1, fmt.Sprintf("Error: %v", err))
+		}
+		var ok bool
+		if fields, ok = noteTypes[name]; !ok {
+			exitRun(1, fmt.Sprintf("Error: note type %q not found in %s", name, noteTypesPath))
+		}
+	} else {
+		var err error
+		fields, err = ankiClient().ModelFieldNames(ctx, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot check the fields of note type %q: %v\n", name, err)
+			return
+		}
+	}
+
+	progress.Printf("Note type %q fields: %s", name, strings.Join(fields, ", "))
+	for _, problem := range models.CheckNoteTypeFields(name, headers, fields) {
+		warn(&models.Issue{Severity: models.SeverityWarning, Code: models.IssueNoteTypeMismatch, Message: problem})
+	}
+}
+
+// outputSink returns the sink selected by -o, --format and --push
+func outputSink(inputPaths []string, languages map[string]string) models.OutputSink {
+	if pushNotes {
+		model := noteType()
+		if model == "" {
+			model = defaultNoteType
+		}
+		progress.Printf("Adding %s notes to deck %q through AnkiConnect at %s", model, pushDeck, ankiConnectURL)
+		return &ankiconnect.Sink{
+			Client: ankiClient(),
+			Deck:   pushDeck,
+			Model:  model,
+		}
+	}
+
+	format, _ := models.NewOutputFormat(outputFormat, outputSep)
+	if plainHeader {
+		format = models.PlainFormat(outputSep)
+	} else if emitLanguages {
+		format = models.AnkiLanguagesFormat(outputSep, languages)
+	}
+	if compressOutput {
+		format = models.GzipFormat(format)
+	}
+	if outputPath == stdoutPath {
+		return &models.StreamSink{Writer: os.Stdout, Format: format, BatchSize: writeBatch, Progress: progress}
+	}
+
+	outputFile := determineOutputPath(inputPaths)
+	progress.Printf("Writing output to %s", outputFile)
+
+	// Leftovers of crashed runs would otherwise pile up next to the output
+	if removed, err := fileService.RemoveStaleTempFiles(filepath.Dir(outputFile), models.StaleTempAge); err == nil && removed > 0 {
+		progress.Printf("Removed %d stale temporary file(s)", removed)
+	}
+
+	batchSize := writeBatch
+	if onNetworkFS(outputFile) {
+		fileService.UseNetworkSettings()
+		if batchSize == models.DefaultBatchSize {
+			batchSize = models.NetworkBatchSize
+		}
+		progress.Printf("Writing to a network filesystem: %d-byte batches, up to %d attempts, output verified after writing",
+			batchSize, fileService.Retry.MaxAttempts)
+	}
+
+	return &models.FileSink{Path: outputFile, Format: format, Files: fileService, BatchSize: batchSize, Progress: progress}
+}
+
+// verifyWritten reads back the import file sink wrote for --verify and
+// returns an error listing every way it differs from entries and headers
+func verifyWritten(sink models.OutputSink, entries []*models.DataEntry, headers []string) error {
+	file, ok := sink.(*models.FileSink)
+	if !ok {
+		return nil
+	}
+
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if compressOutput {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("cannot read back %s: %v", file.Path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	problems := models.VerifyAnkiOutput(r, file.Path, headers, outputSep, len(entries))
+	if len(problems) > 0 {
+		return fmt.Errorf("%s does not read back as written:\n  %s", file.Path, strings.Join(problems, "\n  "))
+	}
+	progress.Printf("Verified %s: %d record(s) of %d column(s) read back as written", file.Path, len(entries), len(headers))
+	return nil
+}
+
+// onNetworkFS reports whether the output file goes to a network filesystem,
+// as --network-fs says or, with auto, as detected from its directory
+func onNetworkFS(outputFile string) bool {
+	switch networkFS {
+	case networkFSOn:
+		return true
+	case networkFSOff:
+		return false
+	}
+	return models.IsNetworkPath(filepath.Dir(outputFile))
+}
+
+// statusOut is where summary and log lines go. It is always stderr, so stdout
+// only carries results and ankiprep -o - can be piped into other tools.
+func statusOut() io.Writer {
+	return os.Stderr
+}
+
+func determineOutputPath(inputPaths []string) string {
+	if outputPath != "" {
+		return outputPath
+	}
+
+	ext := models.FormatExtension(outputFormat, outputSep)
+	if compressOutput {
+		ext += models.GzipExt
+	}
+
+	if len(inputPaths) == 1 {
+		input := models.TrimCompression(inputPaths[0])
+		if archive, ok := archiveInputs[inputPaths[0]]; ok {
+			input = archive
+		}
+		base := strings.TrimSuffix(input, filepath.Ext(input))
+		return base + "_processed" + ext
+	}
+
+	return "merged_output" + ext
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"ankiprep/internal/models"
+)
+
+// preflightEmptyRate is the share of empty cells above which a column is
+// listed as mostly empty in the pre-flight summary
+const preflightEmptyRate = 0.5
+
+// runPreflight prints the --preflight estimate for the merged entries and,
+// when stdin is a terminal, asks whether to go on with the run
+func runPreflight(entries []*models.DataEntry, headers []string, config *models.Config) {
+	hasher, err := newHasher(mergeFieldLists(config))
+	if err != nil {
+		// The strategy error is reported by deduplication itself
+		hasher = models.ExactHasher{}
+	}
+
+	preflight := models.EstimatePreflight(entries, headers, hasher, models.PreflightSampleSize)
+	out := statusOut()
+
+	if preflight.Sampled == preflight.Entries {
+		fmt.Fprintf(out, "Pre-flight check (all %d entries):\n", preflight.Entries)
+		fmt.Fprintf(out, "  Duplicates: %d (%.0f%%)\n", preflight.Duplicates, 100*preflight.DuplicateRate())
+	} else {
+		fmt.Fprintf(out, "Pre-flight check (sample of %d of %d entries):\n", preflight.Sampled, preflight.Entries)
+		fmt.Fprintf(out, "  Duplicates: about %d (%.0f%%)\n", preflight.Duplicates, 100*preflight.DuplicateRate())
+	}
+
+	var sparse []string
+	for _, header := range headers {
+		if rate := preflight.EmptyRates[header]; rate > preflightEmptyRate {
+			sparse = append(sparse, fmt.Sprintf("%s (%.0f%% empty)", header, 100*rate))
+		}
+	}
+	if len(sparse) > 0 {
+		fmt.Fprintf(out, "  Mostly empty columns: %s\n", strings.Join(sparse, ", "))
+	} else {
+		fmt.Fprintf(out, "  Mostly empty columns: none\n")
+	}
+
+	if !isInteractive() {
+		return
+	}
+	fmt.Fprintf(out, "Continue? [Y/n] ")
+
+	var answer string
+	fmt.Scanln(&answer)
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "n" || answer == "no" {
+		exitRun(exitCancelled, "Cancelled after the pre-flight check")
+	}
+}
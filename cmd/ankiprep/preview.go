@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Preview flags
+	previewRows    int
+	showInvisibles bool
+	previewDiff    bool
+)
+
+// previewCmd runs the pipeline on the first rows and prints the result
+var previewCmd = &cobra.Command{
+	Use:   "preview [files...]",
+	Short: "Print the first processed rows without writing output",
+	Long: `Preview runs the full processing pipeline on the first N rows of the input
+and prints each resulting field, so settings can be evaluated without opening
+the output file. Narrow no-break spaces are shown as [NNBSP] and line breaks
+(<br> tags and embedded newlines) are marked with ↵. With --show-invisibles,
+no-break spaces, zero-width characters and byte order marks are marked too.
+
+With --diff, each changed field is printed as a unified diff of the original
+against the processed text, with the narrow no-break spaces inserted and the
+quotes converted counted below it, so the effect of rule changes can be
+reviewed column by column.
+
+Examples:
+  ankiprep preview vocab.csv
+  ankiprep preview vocab.csv -n 10 -f -q
+  ankiprep preview vocab.csv -f --show-invisibles
+  ankiprep preview vocab.csv -n 20 -f -q --diff`,
+	Args: requireInputs,
+	Run:  runPreview,
+}
+
+func init() {
+	previewCmd.Flags().IntVarP(&previewRows, "rows", "n", 5, "Number of rows to preview")
+	previewCmd.Flags().BoolVar(&showInvisibles, "show-invisibles", false, "Mark NNBSP, NBSP, zero-width spaces and BOMs with visible placeholders")
+	previewCmd.Flags().BoolVar(&previewDiff, "diff", false, "Print a unified diff of original against processed text for each changed field")
+	addProcessingFlags(previewCmd.Flags())
+	rootCmd.AddCommand(previewCmd)
+}
+
+// runPreview executes the preview subcommand
+func runPreview(cmd *cobra.Command, args []string) {
+	if err := checkProcessingFlags(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := loadSchema(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := checkNoTransform(cmd, config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	_, inputFiles, mergedHeaders, err := loadInputs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	checkRequiredColumns(inputFiles)
+	declareLanguages(config, inputFiles)
+
+	inputHeaders := mergedHeaders
+	if mergedHeaders, err = reshapeHeaders(mergedHeaders); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputHeaders, err := selectColumns(mergedHeaders)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, _ := models.BuildEntries(inputFiles, inputHeaders, keepHeader)
+	if previewRows >= 0 && len(entries) > previewRows {
+		entries = entries[:previewRows]
+	}
+	report := models.NewProcessingReport()
+	if entries, err = reshapeEntries(entries, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Processing changes the entries in place, so --diff keeps the originals
+	originals := make(map[*models.DataEntry]map[string]string, len(entries))
+	if previewDiff {
+		for _, entry := range entries {
+			originals[entry] = maps.Clone(entry.Values)
+		}
+	}
+
+	heartbeat = models.NewHeartbeat(os.Stderr, heartbeatEvery)
+	entries, err = transformEntries(context.Background(), entries, mergedHeaders, config, report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	showIssues(report)
+
+	if previewDiff {
+		printDiffs(entries, originals, outputHeaders)
+		return
+	}
+
+	width := 0
+	for _, header := range outputHeaders {
+		if len([]rune(showHeader(header))) > width {
+			width = len([]rune(showHeader(header)))
+		}
+	}
+
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Row %d (%s:%d)\n", i+1, entry.Source, entry.LineNumber)
+		for _, header := range outputHeaders {
+			value := renderVisible(entry.GetValue(header))
+			indent := "\n  " + strings.Repeat(" ", width) + "  "
+			fmt.Printf("  %-*s  %s\n", width, showHeader(header), strings.ReplaceAll(value, "\n", indent))
+		}
+	}
+}
+
+// printDiffs prints, for each entry with changed fields, a unified diff of
+// every changed field against its original value
+func printDiffs(entries []*models.DataEntry, originals map[*models.DataEntry]map[string]string, headers []string) {
+	changedRows := 0
+	for i, entry := range entries {
+		original, ok := originals[entry]
+		if !ok {
+			continue
+		}
+
+		printed := false
+		for _, header := range headers {
+			before, after := original[header], entry.GetValue(header)
+			if before == after {
+				continue
+			}
+			if !printed {
+				if changedRows > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("Row %d (%s:%d)\n", i+1, entry.Source, entry.LineNumber)
+				changedRows++
+				printed = true
+			}
+
+			column := showHeader(header)
+			fmt.Print(models.UnifiedDiff(column+" (original)", column+" (processed)", renderVisible(before)+"\n", renderVisible(after)+"\n"))
+
+			var counts []string
+			changes := models.CountCellChanges(before, after)
+			if changes.NNBSPs > 0 {
+				counts = append(counts, fmt.Sprintf("%d NNBSP(s) inserted", changes.NNBSPs))
+			}
+			if changes.Quotes > 0 {
+				counts = append(counts, fmt.Sprintf("%d quote(s) converted", changes.Quotes))
+			}
+			if len(counts) > 0 {
+				fmt.Printf("  (%s)\n", strings.Join(counts, ", "))
+			}
+		}
+	}
+
+	if changedRows > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("%d of %d row(s) changed\n", changedRows, len(entries))
+}
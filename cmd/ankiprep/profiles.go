@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// profile is the --profile of the run, or nil without --profile
+var profile *models.Profile
+
+// profilesCmd lists the built-in processing profiles
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the built-in processing profiles",
+	Long: `Profiles lists the processing profiles built into ankiprep, with the flags and
+deck schema each one uses. Pick one with --profile; flags given on the
+command line override the profile's, and --schema replaces its schema.
+
+Examples:
+  ankiprep profiles
+  ankiprep vocab.csv --profile french-vocab
+  ankiprep grammar.csv --profile cloze-grammar --dedupe-strategy exact`,
+	Args: cobra.NoArgs,
+	Run:  runProfiles,
+}
+
+func init() {
+	rootCmd.AddCommand(profilesCmd)
+}
+
+// runProfiles executes the profiles subcommand
+func runProfiles(cmd *cobra.Command, args []string) {
+	profiles, err := models.BuiltinProfiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for i, profile := range profiles {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s\n  %s\n", profile.Name, profile.Description)
+		for _, name := range profile.FlagNames() {
+			fmt.Printf("  --%s %s\n", name, profile.Flags[name])
+		}
+		if schema := profile.Schema; schema != nil {
+			if schema.NoteType != "" {
+				fmt.Printf("  Note type: %s\n", schema.NoteType)
+			}
+			fmt.Printf("  Columns: %s\n", strings.Join(schema.ColumnNames(), ", "))
+		}
+	}
+}
+
+// applyProfile sets the flags of the --profile that are not given on the
+// command line, so explicit flags always win
+func applyProfile(cmd *cobra.Command) error {
+	if profileName == "" {
+		return nil
+	}
+
+	var err error
+	if profile, err = models.LookupProfile(profileName); err != nil {
+		return err
+	}
+	for _, name := range profile.FlagNames() {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("profile %s sets --%s, which %s does not have", profile.Name, name, cmd.Name())
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, profile.Flags[name]); err != nil {
+			return fmt.Errorf("profile %s: %v", profile.Name, err)
+		}
+	}
+	return nil
+}
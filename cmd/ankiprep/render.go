@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// lineBreakPattern matches HTML line break tags
+var lineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+// invisibleReplacer swaps characters that look like plain spaces (or nothing at
+// all) in a terminal for the placeholders printed by --show-invisibles
+var invisibleReplacer = strings.NewReplacer(
+	"\u202F", "[NNBSP]",
+	"\u00A0", "[NBSP]",
+	"\u200B", "[ZWSP]",
+	"\u200C", "[ZWNJ]",
+	"\u200D", "[ZWJ]",
+	"\u2060", "[WJ]",
+	"\uFEFF", "[BOM]",
+)
+
+// markInvisibles replaces invisible and look-alike space characters with
+// visible placeholders
+func markInvisibles(text string) string {
+	return invisibleReplacer.Replace(text)
+}
+
+// renderVisible marks narrow no-break spaces (every invisible character with
+// --show-invisibles) and line breaks so they can be seen in a terminal; every
+// line break is followed by a real newline
+func renderVisible(text string) string {
+	if showInvisibles {
+		text = markInvisibles(text)
+	} else {
+		text = strings.ReplaceAll(text, "\u202F", "[NNBSP]")
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\n", "↵\n")
+	text = lineBreakPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		return tag + "↵\n"
+	})
+	return text
+}
+
+// quoteValue quotes a value for single-line reports, marking invisible
+// characters first when --show-invisibles is set
+func quoteValue(value string) string {
+	if showInvisibles {
+		value = markInvisibles(value)
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// showHeader renders a column name, marking invisible characters when
+// --show-invisibles is set
+func showHeader(header string) string {
+	if showInvisibles {
+		return markInvisibles(header)
+	}
+	return header
+}
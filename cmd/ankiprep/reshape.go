@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"ankiprep/internal/models"
+)
+
+var (
+	// columnSplits are the parsed --split-column specifications of the run
+	columnSplits []*models.ColumnSplit
+
+	// columnJoins are the parsed --join-columns specifications of the run
+	columnJoins []*models.ColumnJoin
+
+	// rowExplodes are the parsed --explode specifications of the run
+	rowExplodes []*models.RowExplode
+
+	// deckRoute fills the Deck column with --deck-from-column, or is nil
+	deckRoute *models.DeckRoute
+)
+
+// reshapeHeaders parses the --split-column, --join-columns and --explode
+// specifications against headers and returns the headers with every split
+// column replaced by its targets, then the columns of every join replaced by
+// its target, and with the Deck column of --deck-from-column added. Splits
+// come first, so split columns can be joined with others, and rows are
+// exploded last, on any of the resulting columns.
+func reshapeHeaders(headers []string) ([]string, error) {
+	switch splitOverflow {
+	case models.SplitOverflowJoin, models.SplitOverflowDrop, models.SplitOverflowError:
+	default:
+		return nil, fmt.Errorf("invalid --split-overflow %q: must be join, drop or error", splitOverflow)
+	}
+
+	columnSplits = nil
+	for _, spec := range splitSpecs {
+		split, err := models.ParseColumnSplit(spec)
+		if err != nil {
+			return nil, err
+		}
+		if !containsString(headers, split.Column) {
+			return nil, fmt.Errorf("split column %q not found (available: %s)", split.Column, strings.Join(headers, ", "))
+		}
+		for _, target := range split.Targets {
+			if target != split.Column && containsString(headers, target) {
+				return nil, fmt.Errorf("cannot split %q into %q: the column already exists", split.Column, target)
+			}
+		}
+
+		headers = split.Headers(headers)
+		columnSplits = append(columnSplits, split)
+		progress.Printf("Splitting %s on %q into %s", split.Column, split.Delimiter, strings.Join(split.Targets, ", "))
+	}
+
+	columnJoins = nil
+	for _, spec := range joinColSpecs {
+		join, err := models.ParseColumnJoin(spec)
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range join.Sources {
+			if !containsString(headers, source) {
+				return nil, fmt.Errorf("column %q of --join-columns not found (available: %s)", source, strings.Join(headers, ", "))
+			}
+		}
+		if !containsString(join.Sources, join.Target) && containsString(headers, join.Target) {
+			return nil, fmt.Errorf("cannot join columns into %q: the column already exists", join.Target)
+		}
+
+		headers = join.Headers(headers)
+		columnJoins = append(columnJoins, join)
+		progress.Printf("Joining %s into %s with %q", strings.Join(join.Sources, ", "), join.Target, join.Separator)
+	}
+
+	rowExplodes = nil
+	for _, spec := range explodeSpecs {
+		explode, err := models.ParseRowExplode(spec)
+		if err != nil {
+			return nil, err
+		}
+		if !containsString(headers, explode.Column) {
+			return nil, fmt.Errorf("explode column %q not found (available: %s)", explode.Column, strings.Join(headers, ", "))
+		}
+		rowExplodes = append(rowExplodes, explode)
+	}
+
+	deckRoute = nil
+	if deckFromCol != "" {
+		if !containsString(headers, deckFromCol) {
+			return nil, fmt.Errorf("--deck-from-column %q not found (available: %s)", deckFromCol, strings.Join(headers, ", "))
+		}
+		if containsString(headers, models.DeckColumn) {
+			return nil, fmt.Errorf("--deck-from-column: the input already has a %s column", models.DeckColumn)
+		}
+		if len(outputColumns) > 0 && !containsString(outputColumns, models.DeckColumn) {
+			warn(&models.Issue{
+				Severity: models.SeverityWarning,
+				Code:     models.IssueFlagIgnored,
+				Message:  fmt.Sprintf("--columns leaves out the %s column, so --deck-from-column has no effect", models.DeckColumn),
+			})
+		}
+
+		deckRoute = &models.DeckRoute{Column: deckFromCol, Prefix: deckPrefix}
+		headers = append(headers, models.DeckColumn)
+		progress.Printf("Routing notes to decks %s<%s>", deckPrefix, deckFromCol)
+	} else if deckPrefix != "" {
+		return nil, fmt.Errorf("--deck-prefix requires --deck-from-column")
+	}
+	return headers, nil
+}
+
+// reshapeEntries splits the --split-column cells of entries, handling values
+// beyond the target columns as --split-overflow says, combines the
+// --join-columns columns and returns the entries with one row per --explode
+// value, with their --deck-from-column deck. Rows are exploded before
+// deduplication, so repeated values across rows can be removed with
+// --skip-duplicates.
+func reshapeEntries(entries []*models.DataEntry, report *models.ProcessingReport) ([]*models.DataEntry, error) {
+	join := splitOverflow == models.SplitOverflowJoin
+	for _, split := range columnSplits {
+		err := split.Apply(entries, join, func(entry *models.DataEntry, overflow []string) error {
+			excess := fmt.Sprintf("column %q has %d values for %d columns", split.Column, len(split.Targets)+len(overflow), len(split.Targets))
+			if splitOverflow == models.SplitOverflowError {
+				return fmt.Errorf("%s:%d: %s (use --split-overflow join or drop)", entry.Source, entry.LineNumber, excess)
+			}
+			report.AddIssue(models.NewIssue(models.IssueSplitOverflow, entry.Source, entry.LineNumber, excess+"; dropped "+quoteHeaders(overflow)))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, join := range columnJoins {
+		join.Apply(entries)
+	}
+
+	for _, explode := range rowExplodes {
+		before := len(models.DataEntries(entries))
+		entries = explode.Apply(entries)
+		progress.Printf("Exploding %s on %q: %d entries became %d", explode.Column, explode.Delimiter, before, len(models.DataEntries(entries)))
+	}
+
+	if deckRoute != nil {
+		deckRoute.Apply(entries)
+	}
+	return entries, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"ankiprep/internal/models"
+)
+
+// deckSchema is the --schema file of the run, or the schema of its
+// --profile, or nil without either
+var deckSchema *models.DeckSchema
+
+// schemaNoteType is the note type of the schema, used when --note-type is
+// not given
+var schemaNoteType string
+
+// loadSchema reads the --schema file, or takes the schema of the --profile
+// without one. Its columns are required in every input file unless optional,
+// and its note type and column list are used when --note-type and --columns
+// are not given.
+func loadSchema() error {
+	source := schemaPath
+	var schema *models.DeckSchema
+	if schemaPath != "" {
+		var err error
+		if schema, err = models.LoadDeckSchema(schemaPath); err != nil {
+			return err
+		}
+	} else if profile != nil && profile.Schema != nil {
+		source = "of profile " + profile.Name
+		schema = profile.Schema
+	} else {
+		return nil
+	}
+	deckSchema = schema
+
+	requiredCols = append(requiredCols, schema.ExpectedColumns()...)
+	schemaNoteType = schema.NoteType
+	progress.Printf("Using schema %s: %d column(s)", source, len(schema.Columns))
+	return nil
+}
+
+// schemaColumns returns the schema columns found in headers, in schema order,
+// so optional columns no input file has are left out
+func schemaColumns(headers []string) []string {
+	var columns []string
+	for _, name := range deckSchema.ColumnNames() {
+		if containsString(headers, name) {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// checkSchema adds an issue for every value that breaks the schema
+func checkSchema(entries []*models.DataEntry, report *models.ProcessingReport) {
+	for _, entry := range models.DataEntries(entries) {
+		for _, issue := range deckSchema.CheckEntry(entry) {
+			report.AddIssue(issue)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"ankiprep/internal/update"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// self-update flags
+	updateCheckOnly bool
+)
+
+// selfUpdateCmd replaces the running executable with the latest release
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update ankiprep to the latest release",
+	Long: `Self-update checks the latest GitHub release of ankiprep, downloads the
+binary for this platform, compares its SHA-256 checksum with the release's
+checksums.txt to catch corrupted or truncated downloads, and replaces the
+current executable in one step. The checksums come from the same release as
+the binary, so they do not prove who published it.
+
+Installs managed by Homebrew or Scoop are left alone; update those with
+"brew upgrade ankiprep" or "scoop update ankiprep".
+
+Examples:
+  ankiprep self-update --check
+  ankiprep self-update`,
+	Args: cobra.NoArgs,
+	Run:  runSelfUpdate,
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&updateCheckOnly, "check", false, "Only report whether a newer version is available")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+// runSelfUpdate executes the self-update subcommand
+func runSelfUpdate(cmd *cobra.Command, args []string) {
+	if err := selfUpdate(); err != nil {
+		cleanupTempFiles()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func selfUpdate() error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate the ankiprep executable: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	switch update.PackageManager(exePath) {
+	case "Homebrew":
+		return fmt.Errorf("ankiprep was installed with Homebrew; run \"brew upgrade ankiprep\" instead")
+	case "Scoop":
+		return fmt.Errorf("ankiprep was installed with Scoop; run \"scoop update ankiprep\" instead")
+	}
+
+	client := update.NewClient()
+	release, err := client.LatestRelease()
+	if err != nil {
+		return err
+	}
+
+	current := version
+	if !update.IsNewer(release.TagName, current) {
+		fmt.Printf("ankiprep %s is up to date\n", current)
+		return nil
+	}
+	fmt.Printf("New version available: %s (current: %s)\n", release.TagName, current)
+	if updateCheckOnly {
+		return nil
+	}
+
+	name := update.AssetName(runtime.GOOS, runtime.GOARCH)
+	asset, ok := release.Asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	checksums, ok := release.Asset(update.ChecksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s; refusing to install an unchecked download", release.TagName, update.ChecksumsAsset)
+	}
+
+	sums, err := client.Download(checksums.URL)
+	if err != nil {
+		return fmt.Errorf("cannot download checksums: %v", err)
+	}
+	expected, ok := update.ParseChecksums(sums)[name]
+	if !ok {
+		return fmt.Errorf("%s does not list %s; refusing to install an unchecked download", update.ChecksumsAsset, name)
+	}
+
+	fmt.Fprintf(os.Stderr, "Downloading %s...\n", asset.Name)
+	binary, err := client.Download(asset.URL)
+	if err != nil {
+		return fmt.Errorf("cannot download %s: %v", asset.Name, err)
+	}
+	if err := update.VerifyChecksum(binary, expected); err != nil {
+		return fmt.Errorf("%s: %v", asset.Name, err)
+	}
+
+	if err := replaceExecutable(exePath, binary); err != nil {
+		return fmt.Errorf("cannot replace %s: %v", exePath, err)
+	}
+
+	fmt.Printf("Updated ankiprep to %s\n", release.TagName)
+	return nil
+}
+
+// replaceExecutable writes binary next to exePath and moves it into place
+func replaceExecutable(exePath string, binary []byte) error {
+	file, err := fileService.CreateTemp(exePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(binary); err != nil {
+		return err
+	}
+	if err := file.Chmod(0755); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	// Windows cannot overwrite a running executable, but can rename it
+	if runtime.GOOS != "windows" {
+		return fileService.Commit(file.Name(), exePath)
+	}
+	oldPath := exePath + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(exePath, oldPath); err != nil {
+		return err
+	}
+	if err := fileService.Commit(file.Name(), exePath); err != nil {
+		// Put the running executable back rather than leave none
+		if restoreErr := os.Rename(oldPath, exePath); restoreErr != nil {
+			return fmt.Errorf("%v (and cannot restore %s: %v)", err, oldPath, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
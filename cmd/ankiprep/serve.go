@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"ankiprep/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// serve flags
+	listenAddr string
+)
+
+// serveCmd exposes the pipeline as an HTTP API
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the conversion pipeline over HTTP",
+	Long: `Serve starts an HTTP server so the pipeline can be used from a web page or
+another program without installing ankiprep:
+
+  POST /convert      multipart form with one or more "files" (.csv or .tsv)
+                     and optional fields french, smart_quotes, auto_lang,
+                     skip_duplicates, dedupe_strategy, dedupe_columns,
+                     keep_header, no_header, columns and format (csv or tsv);
+                     replies with the Anki import file as a download
+  POST /typography   JSON {"text": "...", "french": true, "smart_quotes": true};
+                     replies with {"text": "..."}
+  GET  /health       replies "ok"
+
+Uploads are limited to 32 MB per request and deleted once converted. The
+server has no authentication; listen on localhost or put it behind a proxy.
+With --otel-endpoint every request is exported as a trace.
+
+Examples:
+  ankiprep serve
+  ankiprep serve --listen :8080
+  curl -F files=@vocab.csv -F french=true localhost:8080/convert -o vocab.txt`,
+	Args: cobra.NoArgs,
+	Run:  runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&listenAddr, "listen", "localhost:8080", "Address to listen on (host:port)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// runServe executes the serve subcommand
+func runServe(cmd *cobra.Command, args []string) {
+	if err := startTracing(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	handler := tracer.Handler(server.NewHandler(), func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: cannot export traces: %v\n", err)
+	})
+	httpServer := &http.Server{
+		Addr:              listenAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", listenAddr)
+	if err := httpServer.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// exitCancelled is the exit code after SIGINT or SIGTERM (128 + SIGINT, as shells report it)
+const exitCancelled = 130
+
+// cleanupTempFiles removes the temporary files of a failed or cancelled run,
+// or lists them when --keep-temp is set. Files extracted from .zip inputs
+// are always removed.
+func cleanupTempFiles() {
+	fileService.RemoveExtracted()
+	if fileService.KeepTemp {
+		for _, path := range fileService.TempFiles() {
+			fmt.Fprintf(os.Stderr, "Keeping temporary file %s (--keep-temp)\n", path)
+		}
+		return
+	}
+	fileService.CleanupTempFiles()
+}
+
+// signalError is the cause of a run context cancelled by a signal
+type signalError struct {
+	signal os.Signal
+}
+
+func (e *signalError) Error() string { return "cancelled by " + e.signal.String() }
+
+// cancelOnSignal returns a context that SIGINT or SIGTERM cancels, with a
+// signalError as its cause, and the function that releases it. After the
+// first signal the default handling is restored, so a second one stops a
+// run that does not wind down.
+func cancelOnSignal() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			cancel(&signalError{signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
+// exitIfCancelled ends a run whose context a signal cancelled: temporary
+// files are removed, the notifications sent and the run exits with
+// exitCancelled. Outputs are only renamed into place once complete, so a
+// cancelled run never leaves a partial output file behind.
+func exitIfCancelled(ctx context.Context, startTime time.Time) {
+	var cancelled *signalError
+	if !errors.As(context.Cause(ctx), &cancelled) {
+		return
+	}
+	fmt.Fprintln(os.Stderr)
+	cleanupTempFiles()
+	exitRun(exitCancelled, fmt.Sprintf("Cancelled by %s after %.2f seconds", cancelled.signal, time.Since(startTime).Seconds()))
+}
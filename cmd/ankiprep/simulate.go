@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Simulate flags
+	importFields []string
+)
+
+// simulateCmd reports what Anki's importer would make of import files
+var simulateCmd = &cobra.Command{
+	Use:   "simulate [files...]",
+	Short: "Show the notes Anki would import from import files",
+	Long: `Simulate reads import files (such as ankiprep output) by the rules of Anki's
+CSV importer and prints what Anki would see: the separator and HTML setting,
+from the #separator and #html directives or guessed as Anki guesses them, the
+columns and the note fields they fill, and the number of rows and notes.
+
+Rows Anki would not turn into a note of their own are listed with their line:
+rows whose first field is empty, lines starting with # (read as comments),
+and rows with the GUID or first field of an earlier row, which update or keep
+that note instead. Fields past the column count, and columns that fill no
+field of the note type given with --fields, are reported as dropped.
+
+Examples:
+  ankiprep simulate vocab_processed.csv
+  ankiprep simulate vocab_processed.csv --fields Front,Back,Example`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runSimulate,
+}
+
+func init() {
+	simulateCmd.Flags().StringSliceVar(&importFields, "fields", nil, "Fields of the note type the notes are imported into, in order (default: one field per column)")
+	rootCmd.AddCommand(simulateCmd)
+}
+
+// runSimulate executes the simulate subcommand
+func runSimulate(cmd *cobra.Command, args []string) {
+	for _, path := range args {
+		result, err := simulateImport(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		showImport(path, result)
+	}
+}
+
+// simulateImport runs the import simulation on the file at path
+func simulateImport(path string) (*models.AnkiImport, error) {
+	file, err := models.OpenInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return models.SimulateAnkiImport(file, path, importFields)
+}
+
+// showImport prints the result of an import simulation
+func showImport(path string, result *models.AnkiImport) {
+	separator := (&models.InputFile{Separator: result.Separator}).GetSeparatorString()
+	if result.SeparatorGuessed {
+		separator += " (guessed from the first line: no #separator)"
+	}
+	html := "no"
+	if result.HTML {
+		html = "yes"
+	}
+	if result.HTMLGuessed {
+		html += " (guessed from the fields: no #html)"
+	}
+
+	fmt.Printf("File %s:\n", path)
+	fmt.Printf("  Separator: %s\n", separator)
+	fmt.Printf("  HTML: %s\n", html)
+	fmt.Printf("  Columns (%d): %s\n", len(result.Columns), joinHeaders(result.Columns))
+	for column := 1; column <= len(result.Columns); column++ {
+		if metadata, ok := result.Metadata[column]; ok {
+			fmt.Printf("  %s: column %d\n", metadata, column)
+		}
+	}
+
+	fields := make([]string, len(result.Fields))
+	for i, field := range result.Fields {
+		fields[i] = showHeader(field) + " (empty)"
+		if column := result.FieldColumns[i]; column > 0 {
+			fields[i] = fmt.Sprintf("%s (column %d)", showHeader(field), column)
+		}
+	}
+	fmt.Printf("  Fields (%d): %s\n", len(fields), strings.Join(fields, ", "))
+	fmt.Printf("  Rows: %d\n", result.Rows)
+	fmt.Printf("  Notes: %d\n", result.Notes)
+
+	if len(result.Issues) > 0 {
+		fmt.Printf("  Issues (%d):\n", len(result.Issues))
+		for _, issue := range result.Issues {
+			fmt.Printf("    %s[%s] %s\n", issue.Severity, issue.Code, issue)
+		}
+	}
+}
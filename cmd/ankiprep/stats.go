@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// stats flags
+	statsLast int
+)
+
+// slowRunFactor flags runs this many times slower than the median throughput;
+// runs under slowRunMinRows are skipped since startup time dominates them
+const (
+	slowRunFactor  = 10.0
+	slowRunMinRows = 1000
+)
+
+// statsCmd shows the runs recorded with --record-stats
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show throughput of past runs recorded with --record-stats",
+	Long: `Stats lists the runs recorded in the local stats file: when they ran, how
+many rows they processed, how long they took and how many duplicates were
+removed. Runs more than 10x slower than the median throughput are flagged,
+which usually points to an input format that processes badly.
+
+Nothing is recorded unless a run is started with --record-stats, and the file
+never leaves this computer.
+
+Examples:
+  ankiprep vocab.csv --record-stats
+  ankiprep stats
+  ankiprep stats --last 50`,
+	Args: cobra.NoArgs,
+	Run:  runStats,
+}
+
+func init() {
+	statsCmd.Flags().IntVar(&statsLast, "last", 20, "Number of most recent runs to list")
+	rootCmd.AddCommand(statsCmd)
+}
+
+// statsFile returns the --stats-file path, or the default location
+func statsFile() (string, error) {
+	if statsPath != "" {
+		return statsPath, nil
+	}
+	return models.DefaultStatsPath()
+}
+
+// recordRunStats appends a finished run to the stats file
+func recordRunStats(report *models.ProcessingReport) error {
+	path, err := statsFile()
+	if err != nil {
+		return err
+	}
+	return models.AppendRunStats(path, models.NewRunStats(report, time.Now()))
+}
+
+// runStats executes the stats subcommand
+func runStats(cmd *cobra.Command, args []string) {
+	path, err := statsFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	runs, err := models.LoadRunStats(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No runs recorded in %s; add --record-stats to record them\n", path)
+		return
+	}
+
+	median := models.MedianRowsPerSecond(runs)
+	shown := runs
+	if statsLast > 0 && len(shown) > statsLast {
+		shown = shown[len(shown)-statsLast:]
+	}
+
+	fmt.Printf("%-19s  %5s  %8s  %9s  %10s  %6s\n", "Run", "Files", "Rows", "Time", "Rows/s", "Dupes")
+	for _, run := range shown {
+		fmt.Printf("%-19s  %5d  %8d  %8.2fs  %10.0f  %5.1f%%\n",
+			run.Time.Local().Format("2006-01-02 15:04:05"), len(run.InputFiles), run.InputRecords,
+			run.Duration.Seconds(), run.RowsPerSecond(), run.DedupeRate())
+	}
+
+	fmt.Printf("\n%d run(s) recorded, median throughput %.0f rows/s\n", len(runs), median)
+	for _, run := range shown {
+		if rate := run.RowsPerSecond(); run.InputRecords >= slowRunMinRows && rate*slowRunFactor < median {
+			files := make([]string, len(run.InputFiles))
+			for i, file := range run.InputFiles {
+				files[i] = filepath.Base(file)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: run at %s processed %.0f rows/s, over %.0fx slower than the median (%s)\n",
+				run.Time.Local().Format("2006-01-02 15:04:05"), rate, slowRunFactor, strings.Join(files, ", "))
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// test-golden flags
+	goldenUpdate bool
+)
+
+// Files next to a golden fixture, named after the whole fixture name so
+// vocab.csv and vocab.tsv have their own: the expected output and extra
+// arguments
+const (
+	goldenExt     = ".golden"
+	goldenArgsExt = ".args"
+)
+
+// testGoldenCmd checks fixtures against their stored expected output
+var testGoldenCmd = &cobra.Command{
+	Use:   "test-golden dir [-- flags...]",
+	Short: "Check fixtures against stored expected output",
+	Long: `Test-golden processes every .csv and .tsv fixture in a directory and compares
+the output with the expected output stored next to it, printing a unified
+diff for each fixture that changed. Run it after upgrading ankiprep to check
+that your own decks, configs and rules still produce the same notes.
+
+For a fixture vocab.csv the directory holds:
+
+  vocab.csv.golden   the expected output (create or refresh it with --update)
+  vocab.csv.args     optional flags for this fixture only, split on spaces
+                     and line breaks (e.g. "--config deck.json" on one line)
+
+Flags after -- are passed to every fixture, with paths relative to the
+current directory. Every fixture runs with --deterministic, so output does
+not depend on the machine. The exit code is 1 when any fixture differs.
+
+Examples:
+  ankiprep test-golden decks/ --update -- --config deck.json -f
+  ankiprep test-golden decks/ -- --config deck.json -f`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runTestGolden,
+}
+
+func init() {
+	testGoldenCmd.Flags().BoolVar(&goldenUpdate, "update", false, "Write the current output as the expected output instead of comparing")
+	rootCmd.AddCommand(testGoldenCmd)
+}
+
+// runTestGolden executes the test-golden subcommand
+func runTestGolden(cmd *cobra.Command, args []string) {
+	if dash := cmd.ArgsLenAtDash(); dash > 1 || (dash == -1 && len(args) > 1) {
+		fmt.Fprintf(os.Stderr, "Error: test-golden takes one directory; put ankiprep flags after --\n")
+		os.Exit(1)
+	}
+	dir, sharedArgs := args[0], args[1:]
+
+	fixtures, err := goldenFixtures(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(fixtures) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no .csv or .tsv fixtures in %s\n", dir)
+		os.Exit(1)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, fixture := range fixtures {
+		goldenPath := fixture + goldenExt
+
+		output, err := runGoldenFixture(self, fixture, sharedArgs)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", fixture, err)
+			failed++
+			continue
+		}
+
+		if goldenUpdate {
+			if err := os.WriteFile(goldenPath, []byte(output), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("updated %s\n", goldenPath)
+			continue
+		}
+
+		expected, err := os.ReadFile(goldenPath)
+		if err != nil {
+			fmt.Printf("FAIL %s: no expected output %s; create it with --update\n", fixture, goldenPath)
+			failed++
+			continue
+		}
+		if diff := models.UnifiedDiff(goldenPath, "output of "+fixture, string(expected), output); diff != "" {
+			fmt.Printf("FAIL %s\n%s", fixture, diff)
+			failed++
+			continue
+		}
+		fmt.Printf("ok   %s\n", fixture)
+	}
+
+	if goldenUpdate {
+		return
+	}
+	fmt.Printf("\n%d of %d fixture(s) passed\n", len(fixtures)-failed, len(fixtures))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// goldenFixtures returns the .csv and .tsv files of dir, sorted by name
+func goldenFixtures(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".csv" || ext == ".tsv") {
+			fixtures = append(fixtures, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(fixtures)
+	return fixtures, nil
+}
+
+// runGoldenFixture runs ankiprep on one fixture and returns its output
+func runGoldenFixture(self, fixture string, sharedArgs []string) (string, error) {
+	args := []string{fixture, "--deterministic", "-o", "-"}
+	args = append(args, sharedArgs...)
+
+	if data, err := os.ReadFile(fixture + goldenArgsExt); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				args = append(args, strings.Fields(line)...)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	run := exec.Command(self, args...)
+	run.Stdout = &stdout
+	run.Stderr = &stderr
+	if err := run.Run(); err != nil {
+		return "", fmt.Errorf("%v\n%s", err, stderr.String())
+	}
+	return stdout.String(), nil
+}
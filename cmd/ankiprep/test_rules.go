@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// testRulesCmd runs typography rule test cases written in YAML
+var testRulesCmd = &cobra.Command{
+	Use:   "test-rules file.yaml...",
+	Short: "Run typography rule test cases from YAML files",
+	Long: `Test-rules formats the input of every case in the given YAML files and
+compares it with the expected text, so rules can be checked without writing Go
+tests. Each file holds a list of cases:
+
+  - name: space before a colon
+    flags: [french]
+    input: "Bonjour : monde"
+    expected: "Bonjour\u202F: monde"
+
+  - name: English columns keep plain spaces
+    column: English
+    flags: [french]
+    input: "Hello : world"
+    expected: "Hello : world"
+
+flags may hold french, smart-quotes and auto-lang, like the command line
+flags of the same names. column names the column the text is in (default:
+none), for the rules that depend on it. Write invisible characters with
+\u escapes in double-quoted strings; failures print them as [NNBSP], [NBSP]
+and so on. The exit code is 1 when any case fails.
+
+Examples:
+  ankiprep test-rules rules_test.yaml
+  ankiprep test-rules locales/*.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runTestRules,
+}
+
+func init() {
+	rootCmd.AddCommand(testRulesCmd)
+}
+
+// runTestRules executes the test-rules subcommand
+func runTestRules(cmd *cobra.Command, args []string) {
+	total, failed := 0, 0
+	for _, path := range args {
+		tests, err := models.LoadRuleTests(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, test := range tests {
+			total++
+			got, ok := test.Run()
+			if ok {
+				fmt.Printf("ok   %s\n", test.Name)
+				continue
+			}
+			failed++
+			fmt.Printf("FAIL %s (%s:%d)\n", test.Name, path, test.Line)
+			fmt.Printf("  input:    %q\n", markInvisibles(test.Input))
+			fmt.Printf("  expected: %q\n", markInvisibles(*test.Expected))
+			fmt.Printf("  got:      %q\n", markInvisibles(got))
+		}
+	}
+
+	fmt.Printf("\n%d of %d case(s) passed\n", total-failed, total)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
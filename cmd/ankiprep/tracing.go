@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"ankiprep/internal/tracing"
+)
+
+var (
+	// otelEndpoint is the OTLP/HTTP collector spans are exported to
+	otelEndpoint string
+
+	// tracer exports the spans of the run, or is nil without --otel-endpoint
+	tracer *tracing.Tracer
+
+	// runSpan is the root span of the run; stages are its children
+	runSpan *tracing.Span
+)
+
+// startTracing creates the tracer of --otel-endpoint, if any
+func startTracing() error {
+	if otelEndpoint == "" {
+		return nil
+	}
+	var err error
+	if tracer, err = tracing.New(otelEndpoint, "ankiprep"); err != nil {
+		return err
+	}
+	tracer.Retry = retryPolicy()
+	return nil
+}
+
+// traceStage records a stage of the run that began at start and ended now
+func traceStage(name string, start time.Time, records int) {
+	span := tracer.StartAt(name, runSpan, start)
+	span.SetAttribute("records", records)
+	span.End()
+}
+
+// traceFile records the parsing of one input file
+func traceFile(path string, start time.Time, records int) {
+	span := tracer.StartAt("parse file", runSpan, start)
+	span.SetAttribute("file.path", path)
+	span.SetAttribute("records", records)
+	span.End()
+}
+
+// endTracing ends the run span, failed with message unless it is empty, and
+// exports the spans of the run
+func endTracing(message string) {
+	if message != "" {
+		runSpan.Fail(message)
+	}
+	runSpan.End()
+	if err := tracer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot export traces: %v\n", err)
+	}
+}
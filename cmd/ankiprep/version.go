@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set at build time with
+//
+//	go build -ldflags "-X main.version=1.1.0 -X main.commit=$(git rev-parse --short HEAD) \
+//	  -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/ankiprep
+//
+// commit and buildDate fall back to the VCS information Go embeds in binaries
+// built from a git checkout.
+var (
+	version   = "1.0.0"
+	commit    = ""
+	buildDate = ""
+	features  = "french-typography,smart-quotes,dedupe,field-templates,spell-check,tsv-output,self-update"
+)
+
+var (
+	// version flags
+	versionJSON bool
+)
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit"`
+	BuildDate string   `json:"build_date"`
+	GoVersion string   `json:"go_version"`
+	Platform  string   `json:"platform"`
+	Features  []string `json:"features"`
+}
+
+// versionCmd prints version and build metadata
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Long: `Print the version, commit, build date, Go version and enabled features of
+this ankiprep binary. Use --json for a machine-readable form that wrappers can
+check for compatibility.`,
+	Args: cobra.NoArgs,
+	Run:  runVersion,
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print build information as JSON")
+	rootCmd.AddCommand(versionCmd)
+}
+
+// runVersion executes the version subcommand
+func runVersion(cmd *cobra.Command, args []string) {
+	info := buildInfo()
+
+	if versionJSON {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("ankiprep %s\n", info.Version)
+	fmt.Printf("  Commit:     %s\n", valueOrUnknown(info.Commit))
+	fmt.Printf("  Built:      %s\n", valueOrUnknown(info.BuildDate))
+	fmt.Printf("  Go version: %s (%s)\n", info.GoVersion, info.Platform)
+	fmt.Printf("  Features:   %s\n", strings.Join(info.Features, ", "))
+}
+
+// buildInfo collects the metadata of the running binary
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  []string{},
+	}
+
+	if embedded, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range embedded.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+
+	for _, feature := range strings.Split(features, ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			info.Features = append(info.Features, feature)
+		}
+	}
+	return info
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ankiprep/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaExt is the suffix of the schema file the wizard suggests
+const schemaExt = ".schema.yaml"
+
+// wizardCmd builds a deck schema for an input file by asking questions
+var wizardCmd = &cobra.Command{
+	Use:   "wizard input.csv",
+	Short: "Build a deck schema step by step, then convert the file",
+	Long: `Wizard shows the columns of an input file with a sample value each and asks
+which of them hold the Front and Back fields, the tags and the deck, and which
+typography rules each field needs. The answers are saved as a --schema file,
+so the same conversion can be repeated later with:
+
+  ankiprep input.csv --schema input.schema.yaml
+
+and the file is then converted with that schema. Press Enter to accept the
+default shown in brackets. Columns can be chosen by number or by name.
+
+Examples:
+  ankiprep wizard vocab.csv
+  ankiprep wizard vocab.tsv --no-header`,
+	Args: cobra.ExactArgs(1),
+	Run:  runWizard,
+}
+
+func init() {
+	rootCmd.AddCommand(wizardCmd)
+}
+
+// wizard asks the questions of the wizard subcommand on in
+type wizard struct {
+	in *bufio.Reader
+}
+
+// runWizard executes the wizard subcommand
+func runWizard(cmd *cobra.Command, args []string) {
+	_, inputFiles, headers, err := loadInputs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Columns of %s:\n", args[0])
+	for i, header := range headers {
+		fmt.Printf("  %d. %s%s\n", i+1, showHeader(header), sampleValue(inputFiles, header))
+	}
+	fmt.Println()
+
+	w := &wizard{in: bufio.NewReader(os.Stdin)}
+	schema, err := w.buildSchema(headers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	base := strings.TrimSuffix(models.TrimCompression(args[0]), filepath.Ext(models.TrimCompression(args[0])))
+	path, err := w.ask("Save the schema as", base+schemaExt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := schema.Save(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	runArgs := schemaRunArgs(args[0], path)
+	fmt.Printf("Wrote %s\n", path)
+	fmt.Printf("Convert again later with: ankiprep %s\n", strings.Join(runArgs, " "))
+
+	if convert, err := w.confirm("Convert "+args[0]+" now?", true); err != nil || !convert {
+		return
+	}
+	if err := runAnkiprep(runArgs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// buildSchema asks for the column of each Anki field and its typography
+func (w *wizard) buildSchema(headers []string) (*models.DeckSchema, error) {
+	schema := &models.DeckSchema{}
+	used := make(map[string]bool)
+
+	fields := []struct {
+		name     string
+		fallback int // Default column, or -1 for none
+	}{
+		{"Front", 0},
+		{"Back", 1},
+		{models.TagsColumn, -1},
+		{models.DeckColumn, -1},
+	}
+	for _, field := range fields {
+		if field.fallback >= len(headers) {
+			field.fallback = -1
+		}
+		header, err := w.askColumn(field.name+" column", headers, field.fallback, used)
+		if err != nil {
+			return nil, err
+		}
+		if header == "" {
+			continue
+		}
+		used[header] = true
+
+		column := &models.SchemaColumn{Name: field.name, Required: field.name == "Front"}
+		if header != field.name {
+			column.Source = header
+		}
+		schema.Columns = append(schema.Columns, column)
+	}
+
+	var others []string
+	for _, header := range headers {
+		if !used[header] {
+			others = append(others, header)
+		}
+	}
+	if len(others) > 0 {
+		keep, err := w.confirm(fmt.Sprintf("Keep the other columns (%s) as extra fields?", joinHeaders(others)), false)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			for _, header := range others {
+				schema.Columns = append(schema.Columns, &models.SchemaColumn{Name: header})
+			}
+		}
+	}
+
+	for _, column := range schema.Columns {
+		if models.IsAnkiMetadataColumn(column.Name) || models.IsTagsColumn(column.Name) {
+			continue
+		}
+		typography, err := w.askChoice("Typography for "+column.Name, models.TypographyNone,
+			models.TypographyNone, models.TypographyFrench, models.TypographyQuotes, models.TypographyBoth)
+		if err != nil {
+			return nil, err
+		}
+		if typography != models.TypographyNone {
+			column.Typography = typography
+		}
+	}
+
+	noteType, err := w.ask("Note type to check the fields against (Enter to skip)", "")
+	if err != nil {
+		return nil, err
+	}
+	schema.NoteType = noteType
+	return schema, nil
+}
+
+// ask prints question and returns the answer, or fallback for an empty one
+func (w *wizard) ask(question, fallback string) (string, error) {
+	if fallback != "" {
+		fmt.Printf("%s [%s]: ", question, fallback)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+
+	answer, err := w.in.ReadString('\n')
+	if err == io.EOF && answer == "" {
+		fmt.Println()
+		return fallback, nil
+	} else if err != nil && err != io.EOF {
+		return "", err
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return fallback, nil
+	}
+	return answer, nil
+}
+
+// confirm asks a yes or no question
+func (w *wizard) confirm(question string, fallback bool) (bool, error) {
+	hint := "y/N"
+	if fallback {
+		hint = "Y/n"
+	}
+	answer, err := w.ask(question+" ["+hint+"]", "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	return fallback, nil
+}
+
+// askChoice asks until the answer is one of choices
+func (w *wizard) askChoice(question, fallback string, choices ...string) (string, error) {
+	question += " (" + strings.Join(choices, ", ") + ")"
+	for {
+		answer, err := w.ask(question, fallback)
+		if err != nil {
+			return "", err
+		}
+		if containsString(choices, answer) {
+			return answer, nil
+		}
+		fmt.Printf("  %q is not one of %s\n", answer, strings.Join(choices, ", "))
+		if err := w.atEOF(); err != nil {
+			return "", err
+		}
+	}
+}
+
+// askColumn asks for a column by number or name until the answer is a column
+// not used yet. An empty answer picks the column at fallback, or none when
+// fallback is -1.
+func (w *wizard) askColumn(question string, headers []string, fallback int, used map[string]bool) (string, error) {
+	defaultAnswer := ""
+	if fallback >= 0 && !used[headers[fallback]] {
+		defaultAnswer = strconv.Itoa(fallback + 1)
+	} else {
+		question += " (Enter for none)"
+	}
+
+	for {
+		answer, err := w.ask(question, defaultAnswer)
+		if err != nil || answer == "" {
+			return "", err
+		}
+
+		header := answer
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(headers) {
+			header = headers[n-1]
+		}
+		switch {
+		case !containsString(headers, header):
+			fmt.Printf("  no column %q; enter a number from 1 to %d\n", answer, len(headers))
+		case used[header]:
+			fmt.Printf("  column %q is already used\n", header)
+		default:
+			return header, nil
+		}
+		if err := w.atEOF(); err != nil {
+			return "", err
+		}
+	}
+}
+
+// atEOF fails once there are no more answers to read, so a question asked
+// again does not loop forever on a closed input
+func (w *wizard) atEOF() error {
+	if _, err := w.in.Peek(1); err != nil {
+		return fmt.Errorf("no valid answer given")
+	}
+	return nil
+}
+
+// sampleValue returns the first non-empty value of the column, formatted for
+// the column list
+func sampleValue(inputFiles []*models.InputFile, header string) string {
+	for _, inputFile := range inputFiles {
+		index := slices.Index(inputFile.Headers, header)
+		if index < 0 {
+			continue
+		}
+		for _, record := range inputFile.Records {
+			if index < len(record) && strings.TrimSpace(record[index]) != "" {
+				value := []rune(record[index])
+				if len(value) > 40 {
+					value = append(value[:40], '…')
+				}
+				return fmt.Sprintf("  e.g. %q", string(value))
+			}
+		}
+	}
+	return ""
+}
+
+// schemaRunArgs returns the arguments converting input with the schema at
+// path, keeping the header flags the wizard was run with
+func schemaRunArgs(input, path string) []string {
+	args := []string{input, "--schema", path}
+	if noHeader {
+		args = append(args, "--no-header")
+	}
+	if assumeHeader {
+		args = append(args, "--assume-header")
+	}
+	return args
+}
+
+// runAnkiprep runs ankiprep again with args, on the wizard's terminal
+func runAnkiprep(args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	run := exec.Command(self, args...)
+	run.Stdin = os.Stdin
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	return run.Run()
+}
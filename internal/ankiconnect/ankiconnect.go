@@ -0,0 +1,199 @@
+// Package ankiconnect adds notes to a running Anki through the AnkiConnect
+// add-on, as an alternative to writing an import file.
+package ankiconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+// DefaultURL is where AnkiConnect listens unless configured otherwise
+const DefaultURL = "http://localhost:8765"
+
+// apiVersion is the AnkiConnect API version requests are written for
+const apiVersion = 6
+
+// Note is a note in the shape AnkiConnect's addNotes action expects
+type Note struct {
+	DeckName  string            `json:"deckName"`
+	ModelName string            `json:"modelName"`
+	Fields    map[string]string `json:"fields"`
+	Tags      []string          `json:"tags"`
+}
+
+// Client sends actions to AnkiConnect
+type Client struct {
+	URL   string
+	HTTP  *http.Client
+	Retry *models.RetryPolicy // Retries requests that fail on the way; nil tries once
+}
+
+// NewClient creates a new Client for AnkiConnect at url
+func NewClient(url string) *Client {
+	return &Client{
+		URL:   url,
+		HTTP:  &http.Client{Timeout: 60 * time.Second},
+		Retry: models.DefaultRetryPolicy(),
+	}
+}
+
+// request is the envelope of every AnkiConnect action
+type request struct {
+	Action  string      `json:"action"`
+	Version int         `json:"version"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// response is the envelope of every AnkiConnect reply
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *string         `json:"error"`
+}
+
+// invoke runs an action and decodes its result into result. Requests that do
+// not get through are retried by c.Retry; an error reply from AnkiConnect, or
+// ctx being done, is final. Retrying addNotes is safe, since AnkiConnect
+// skips duplicate notes.
+func (c *Client) invoke(ctx context.Context, action string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(request{Action: action, Version: apiVersion, Params: params})
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	err = c.Retry.Do(func() error {
+		data, err = c.post(ctx, action, body)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var reply response
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return fmt.Errorf("cannot read AnkiConnect reply: %v", err)
+	}
+	if reply.Error != nil {
+		return fmt.Errorf("AnkiConnect %s: %s", action, *reply.Error)
+	}
+	if result != nil {
+		return json.Unmarshal(reply.Result, result)
+	}
+	return nil
+}
+
+// post sends one request and returns the reply body; client errors other
+// than 429 Too Many Requests, and ctx being done, are Permanent
+func (c *Client) post(ctx context.Context, action string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, models.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, models.Permanent(ctx.Err())
+		}
+		return nil, fmt.Errorf("cannot reach AnkiConnect at %s (is Anki running with the add-on installed?): %v", c.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("AnkiConnect %s: %s", action, resp.Status)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, models.Permanent(err)
+		}
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// AddNotes adds the notes and returns how many were added; AnkiConnect skips
+// notes it cannot add, such as duplicates of existing notes
+func (c *Client) AddNotes(ctx context.Context, notes []*Note) (int, error) {
+	var ids []*int64
+	if err := c.invoke(ctx, "addNotes", map[string]interface{}{"notes": notes}, &ids); err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, id := range ids {
+		if id != nil {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// ModelFieldNames returns the field names of a note type, in order
+func (c *Client) ModelFieldNames(ctx context.Context, model string) ([]string, error) {
+	var fields []string
+	if err := c.invoke(ctx, "modelFieldNames", map[string]string{"modelName": model}, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Sink is an OutputSink that adds every entry as a note in Deck using the
+// note type Model; columns are matched to note fields by name, a Tags column
+// becomes the note's tags and a Deck column overrides Deck
+type Sink struct {
+	Client *Client
+	Deck   string
+	Model  string
+	Added  int // Notes added by the last Write
+}
+
+// Write adds the entries to Anki, skipping a preserved header row
+func (s *Sink) Write(ctx context.Context, entries []*models.DataEntry, headers []string) error {
+	var notes []*Note
+	for _, entry := range models.DataEntries(entries) {
+		notes = append(notes, NewNote(entry, headers, s.Deck, s.Model))
+	}
+	if len(notes) == 0 {
+		s.Added = 0
+		return nil
+	}
+
+	added, err := s.Client.AddNotes(ctx, notes)
+	if err != nil {
+		return err
+	}
+	s.Added = added
+	return nil
+}
+
+// NewNote converts an entry into a note of deck and model, or of the deck in
+// its Deck column when it has one
+func NewNote(entry *models.DataEntry, headers []string, deck, model string) *Note {
+	note := &Note{
+		DeckName:  deck,
+		ModelName: model,
+		Fields:    make(map[string]string),
+		Tags:      []string{},
+	}
+	for _, header := range headers {
+		if models.IsTagsColumn(header) {
+			note.Tags = append(note.Tags, strings.Fields(entry.GetValue(header))...)
+			continue
+		}
+		if header == models.DeckColumn {
+			if value := strings.TrimSpace(entry.GetValue(header)); value != "" {
+				note.DeckName = value
+			}
+			continue
+		}
+		note.Fields[header] = entry.GetValue(header)
+	}
+	return note
+}
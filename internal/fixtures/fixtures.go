@@ -0,0 +1,187 @@
+// Package fixtures generates CSV/TSV files covering the edge cases ankiprep must
+// handle. It is shared by the test suite and the hidden gen-fixture command, so
+// users can check their Anki import settings against the same data.
+package fixtures
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Edge case kinds that can be combined in a fixture
+const (
+	KindBasic    = "basic"    // Plain ASCII values
+	KindNewlines = "newlines" // Values with embedded newlines
+	KindQuotes   = "quotes"   // Straight double quotes and apostrophes
+	KindCloze    = "cloze"    // Cloze deletions with hints
+	KindUnicode  = "unicode"  // Accents, symbols and CJK text
+	KindFrench   = "french"   // French punctuation and guillemets
+	KindBOM      = "bom"      // UTF-8 byte order mark before the header
+	KindRagged   = "ragged"   // Rows with missing or extra fields
+)
+
+// valueKinds produce cell content; the others change the file layout
+var valueKinds = map[string]func(row, col int) string{
+	KindBasic: func(row, col int) string {
+		return fmt.Sprintf("value_%d_%d", row, col)
+	},
+	KindNewlines: func(row, col int) string {
+		return fmt.Sprintf("line one %d\nline two %d", row, col)
+	},
+	KindQuotes: func(row, col int) string {
+		return fmt.Sprintf(`He said "it's %d" to 'them' %d`, row, col)
+	},
+	KindCloze: func(row, col int) string {
+		return fmt.Sprintf("{{c1::Paris::capitale}} : ville %d {{c2::%d}}", row, col)
+	},
+	KindUnicode: func(row, col int) string {
+		return fmt.Sprintf("école – ½ – 猫 – ñ %d/%d", row, col)
+	},
+	KindFrench: func(row, col int) string {
+		return fmt.Sprintf("Bonjour ! Ça va ? « oui » %d; %d", row, col)
+	},
+}
+
+// layoutKinds change the file structure rather than cell content
+var layoutKinds = map[string]bool{
+	KindBOM:    true,
+	KindRagged: true,
+}
+
+// Options configures a generated fixture
+type Options struct {
+	Rows      int      // Number of data rows
+	Columns   int      // Number of columns
+	Separator rune     // Field separator (comma or tab)
+	Kinds     []string // Edge cases to include; empty means KindBasic
+}
+
+// DefaultOptions returns options for a small comma-separated fixture with
+// every edge case ankiprep processes; KindRagged is left out, since the
+// parser rejects ragged rows, and must be asked for
+func DefaultOptions() Options {
+	var kinds []string
+	for _, kind := range Kinds() {
+		if kind != KindRagged {
+			kinds = append(kinds, kind)
+		}
+	}
+	return Options{
+		Rows:      20,
+		Columns:   3,
+		Separator: ',',
+		Kinds:     kinds,
+	}
+}
+
+// Kinds returns the names of all supported edge case kinds, sorted
+func Kinds() []string {
+	var kinds []string
+	for kind := range valueKinds {
+		kinds = append(kinds, kind)
+	}
+	for kind := range layoutKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Validate checks if the options describe a fixture that can be generated
+func (o Options) Validate() error {
+	if o.Rows < 0 {
+		return fmt.Errorf("rows cannot be negative")
+	}
+	if o.Columns < 1 {
+		return fmt.Errorf("fixture needs at least one column")
+	}
+	if o.Separator != ',' && o.Separator != '\t' {
+		return fmt.Errorf("invalid separator: must be comma or tab")
+	}
+	for _, kind := range o.Kinds {
+		if _, ok := valueKinds[kind]; !ok && !layoutKinds[kind] {
+			return fmt.Errorf("unknown fixture kind %q (available: %s)", kind, strings.Join(Kinds(), ", "))
+		}
+	}
+	return nil
+}
+
+// Generate writes a fixture to w. Output is deterministic and every row is
+// unique, so the number of output notes equals the number of rows.
+func Generate(w io.Writer, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	var generators []func(row, col int) string
+	bom, ragged := false, false
+	for _, kind := range opts.Kinds {
+		if generator, ok := valueKinds[kind]; ok {
+			generators = append(generators, generator)
+		}
+		bom = bom || kind == KindBOM
+		ragged = ragged || kind == KindRagged
+	}
+	if len(generators) == 0 {
+		generators = append(generators, valueKinds[KindBasic])
+	}
+
+	if bom {
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = opts.Separator
+
+	headers := make([]string, opts.Columns)
+	for i := range headers {
+		headers[i] = fmt.Sprintf("col%d", i+1)
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for row := 0; row < opts.Rows; row++ {
+		// Cycle edge cases across cells so each kind appears in every column
+		record := make([]string, opts.Columns)
+		for col := range record {
+			record[col] = generators[(row+col)%len(generators)](row, col)
+		}
+
+		if ragged {
+			switch {
+			case row%7 == 3 && len(record) > 1:
+				record = record[:len(record)-1]
+			case row%11 == 5:
+				record = append(record, fmt.Sprintf("extra_%d", row))
+			}
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteFile generates a fixture into the file at path
+func WriteFile(path string, opts Options) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := Generate(file, opts); err != nil {
+		return err
+	}
+	return file.Close()
+}
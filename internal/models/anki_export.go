@@ -0,0 +1,247 @@
+package models
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Column names given to the metadata columns of Anki plain-text exports
+const (
+	GUIDColumn     = "GUID"
+	NoteTypeColumn = "Note Type"
+	DeckColumn     = "Deck"
+	TagsColumn     = "Tags"
+)
+
+// ankiSeparators maps the separator names Anki writes in #separator: lines
+var ankiSeparators = map[string]rune{
+	"tab":       '\t',
+	"comma":     ',',
+	"semicolon": ';',
+	"space":     ' ',
+	"pipe":      '|',
+	"colon":     ':',
+}
+
+// AnkiHeader holds the #directives at the top of an Anki plain-text export
+// ("Notes in Plain Text") or an Anki import file
+type AnkiHeader struct {
+	Separator rune              // Field separator; 0 if not declared
+	HTML      bool              // Fields contain HTML
+	HTMLSet   bool              // An #html: directive is present
+	Columns   []string          // Column names from #columns:, if any
+	Metadata  map[int]string    // 1-based column number to metadata column name
+	Languages map[string]string // Column name to language, from #languages:
+	Lines     int               // Number of directive lines
+}
+
+// IsAnkiMetadataColumn determines if a column holds note metadata (GUID, note
+// type or deck) rather than field content
+func IsAnkiMetadataColumn(header string) bool {
+	return header == GUIDColumn || header == NoteTypeColumn || header == DeckColumn
+}
+
+// HasAnkiHeader reports whether the file at path starts with Anki #directives
+func HasAnkiHeader(path string) bool {
+	file, err := OpenInput(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	line, _ := bufio.NewReader(file).ReadString('\n')
+	line = strings.TrimPrefix(line, "\uFEFF")
+	for _, directive := range []string{"#separator:", "#html:", "#columns:", "#tags column:", "#notetype column:", "#deck column:", "#guid column:"} {
+		if strings.HasPrefix(line, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadAnkiHeader consumes the leading #directive lines of r
+func ReadAnkiHeader(r *bufio.Reader) (*AnkiHeader, error) {
+	header := &AnkiHeader{Metadata: make(map[int]string)}
+	metadataNames := map[string]string{
+		"guid":     GUIDColumn,
+		"notetype": NoteTypeColumn,
+		"deck":     DeckColumn,
+		"tags":     TagsColumn,
+	}
+
+	// Skip a byte order mark before the first directive
+	if peek, _ := r.Peek(3); string(peek) == "\uFEFF" {
+		r.Discard(3)
+	}
+
+	for {
+		peek, err := r.Peek(1)
+		if err != nil || peek[0] != '#' {
+			return header, nil
+		}
+
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		header.Lines++
+		line = strings.TrimRight(line, "\r\n")
+
+		name, value, _ := strings.Cut(strings.TrimPrefix(line, "#"), ":")
+		switch {
+		case name == "separator":
+			separator, ok := ankiSeparators[strings.ToLower(value)]
+			if !ok {
+				runes := []rune(value)
+				if len(runes) != 1 {
+					return nil, fmt.Errorf("line %d: unknown separator %q", header.Lines, value)
+				}
+				separator = runes[0]
+			}
+			header.Separator = separator
+		case name == "html":
+			header.HTML = value == "true"
+			header.HTMLSet = true
+		case name == "columns":
+			columns, err := parseColumns(value, header.separatorOr('\t'))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid #columns: %v", header.Lines, err)
+			}
+			header.Columns = columns
+		case name == "languages":
+			languages, err := parseLanguages(value, header.separatorOr('\t'))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid #languages: %v", header.Lines, err)
+			}
+			header.Languages = languages
+		case strings.HasSuffix(name, " column"):
+			column, err := strconv.Atoi(value)
+			if err != nil || column < 1 {
+				return nil, fmt.Errorf("line %d: invalid column number %q", header.Lines, value)
+			}
+			if metadata, ok := metadataNames[strings.TrimSuffix(name, " column")]; ok {
+				header.Metadata[column] = metadata
+			}
+		}
+		// Other directives (#deck:, #notetype:, #tags:) apply to the whole
+		// file and are not needed to read it
+
+		if err == io.EOF {
+			return header, nil
+		}
+	}
+}
+
+// parseColumns reads the names of a #columns: line, which Anki parses as a
+// record of the file, quotes included. Files written by older versions of
+// ankiprep separated the names by commas whatever the separator.
+func parseColumns(value string, separator rune) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if !strings.ContainsRune(value, separator) && strings.Contains(value, ",") {
+		separator = ','
+	}
+
+	reader := csv.NewReader(strings.NewReader(value))
+	reader.Comma = separator
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	return reader.Read()
+}
+
+// separatorOr returns the declared separator, or fallback if there is none
+func (h *AnkiHeader) separatorOr(fallback rune) rune {
+	if h.Separator != 0 {
+		return h.Separator
+	}
+	return fallback
+}
+
+// ColumnNames names count columns: #columns: names where given, metadata
+// names for #guid/#notetype/#deck/#tags columns, and Column1..N otherwise
+func (h *AnkiHeader) ColumnNames(count int) []string {
+	names := GenerateHeaders(count)
+	for i := range names {
+		if i < len(h.Columns) && h.Columns[i] != "" {
+			names[i] = h.Columns[i]
+		}
+		if metadata, ok := h.Metadata[i+1]; ok {
+			names[i] = metadata
+		}
+	}
+	return names
+}
+
+// extensionSeparator returns the separator implied by a .csv or .tsv path,
+// compressed or not
+func extensionSeparator(path string) (rune, bool) {
+	switch strings.ToLower(filepath.Ext(TrimCompression(path))) {
+	case ".csv":
+		return ',', true
+	case ".tsv":
+		return '\t', true
+	}
+	return 0, false
+}
+
+// SeparatorConflict describes how the #separator: directive of header
+// contradicts the extension of path, or returns "" when they agree. The
+// directive is what Anki itself uses, so it wins.
+func SeparatorConflict(path string, header *AnkiHeader) string {
+	implied, ok := extensionSeparator(path)
+	if !ok || header.Separator == 0 || header.Separator == implied {
+		return ""
+	}
+
+	declared := (&InputFile{Separator: header.Separator}).GetSeparatorString()
+	return fmt.Sprintf("#separator:%s contradicts the %s extension; reading as %s-separated",
+		declared, filepath.Ext(TrimCompression(path)), declared)
+}
+
+// ParseAnkiExport reads an Anki plain-text export into inputFile. Exports
+// have no header row: column names come from the directives, and data
+// records start after them. The #separator: directive decides the separator
+// even when it contradicts the extension (see SeparatorConflict); without
+// one, .csv and .tsv files go by their extension and anything else is
+// tab-separated, as in Anki's own exports.
+func ParseAnkiExport(r io.Reader, inputFile *InputFile, lazyQuotes bool) (*AnkiHeader, error) {
+	reader := bufio.NewReader(r)
+	header, err := ReadAnkiHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	fallback, ok := extensionSeparator(inputFile.Path)
+	if !ok {
+		fallback = '\t'
+	}
+	inputFile.Separator = header.separatorOr(fallback)
+	parser := NewCSVParser()
+	parser.LazyQuotes = lazyQuotes
+	parser.Header = HeaderAbsent
+	parser.LineOffset = header.Lines
+	err = parser.Parse(reader, inputFile, func(record []string, line int) error {
+		inputFile.AddRecord(record, line)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	width := len(inputFile.Headers)
+	for _, record := range inputFile.Records {
+		if len(record) > width {
+			width = len(record)
+		}
+	}
+	inputFile.Headers = header.ColumnNames(width)
+	inputFile.HasHeader = false
+	inputFile.Languages = header.Languages
+	return header, nil
+}
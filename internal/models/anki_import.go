@@ -0,0 +1,258 @@
+package models
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ankiGuessedSeparators are the separators Anki tries, in order, on the first
+// line of a file without a #separator directive; a line with none of them
+// is split on spaces
+var ankiGuessedSeparators = []rune{'\t', '|', ';', ':', ',', ' '}
+
+// ankiHTMLPattern matches the tags and entities that make Anki read a file
+// without an #html directive as HTML
+var ankiHTMLPattern = regexp.MustCompile(`</?[a-zA-Z][^>]*>|&#?\w+;`)
+
+// ankiTagPattern matches the HTML tags left out when Anki checks whether the
+// first field of a note is empty
+var ankiTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// AnkiImport is what Anki's CSV importer makes of an import file
+type AnkiImport struct {
+	Separator        rune           // Field separator
+	SeparatorGuessed bool           // Separator guessed from the first line, as no #separator declares it
+	HTML             bool           // Fields are read as HTML
+	HTMLGuessed      bool           // HTML guessed from the fields, as no #html declares it
+	Columns          []string       // Column names, from #columns or Column1..N
+	Metadata         map[int]string // 1-based column number to metadata column name
+	Fields           []string       // Names of the note fields
+	FieldColumns     []int          // 1-based column of each note field (0: none, left empty)
+	Rows             int            // Records read, leaving out comments and blank lines
+	Notes            int            // Notes added
+	Issues           []*Issue       // Rows skipped or merged and data left out
+}
+
+// SimulateAnkiImport reads an import file by the rules of Anki's CSV
+// importer and reports the notes it would add:
+//
+//   - Leading #key:value lines are directives. #separator decides the
+//     separator; without it Anki takes the first of tab, |, ;, :, comma
+//     and space found in the first line, whatever the file extension.
+//   - #html decides whether fields are HTML; without it they are when any
+//     field contains a tag or entity.
+//   - Later lines starting with # are comments and are skipped.
+//   - Metadata columns (#guid, #notetype, #deck and #tags column) are not
+//     fields; the other columns fill the note fields in order. fields names
+//     the fields of the note type; without it every other column is a field.
+//     Fields in a row past the column count (from #columns, or the first
+//     row) are dropped.
+//   - A row whose first field is empty is skipped. A row with the GUID of an
+//     earlier row, or with no GUID column and the first field of an earlier
+//     row, updates or keeps that note instead of adding one, as the
+//     importer's "Existing notes" option (update or preserve) says.
+func SimulateAnkiImport(r io.Reader, path string, fields []string) (*AnkiImport, error) {
+	reader := bufio.NewReader(r)
+	header, err := ReadAnkiHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+
+	result := &AnkiImport{Separator: header.Separator, HTML: header.HTML, Metadata: header.Metadata}
+	if result.Separator == 0 {
+		result.Separator, result.SeparatorGuessed = guessAnkiSeparator(text), true
+	}
+
+	records, lines, comments, err := readAnkiRecords(text, result.Separator)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range comments {
+		result.addIssue(NewIssue(IssueImportSkipped, path, header.Lines+line, "line starts with # and is read as a comment"))
+	}
+
+	if !header.HTMLSet {
+		result.HTML, result.HTMLGuessed = containsHTML(records), true
+	}
+
+	width := len(header.Columns)
+	if width == 0 && len(records) > 0 {
+		width = len(records[0])
+	}
+	result.Columns = header.ColumnNames(width)
+	result.mapFields(path, fields)
+
+	notes := make(map[string]int)
+	for i, record := range records {
+		line := header.Lines + lines[i]
+		result.Rows++
+
+		if len(record) > width {
+			result.addIssue(NewIssue(IssueImportDropped, path, line,
+				fmt.Sprintf("%d field(s) past the %d column(s) are dropped", len(record)-width, width)))
+		}
+		first := result.fieldValue(record, 0)
+		if result.HTML {
+			first = ankiTagPattern.ReplaceAllString(first, "")
+		}
+		if strings.TrimSpace(first) == "" {
+			result.addIssue(NewIssue(IssueImportSkipped, path, line, "first field is empty; Anki skips the row"))
+			continue
+		}
+
+		key, what := "field:"+first, "first field"
+		if guid := result.metadataValue(record, GUIDColumn); guid != "" {
+			key, what = "guid:"+guid, "GUID"
+		} else if noteType := result.metadataValue(record, NoteTypeColumn); noteType != "" {
+			key = noteType + "\x00" + key
+		}
+		if earlier, ok := notes[key]; ok {
+			result.addIssue(NewIssue(IssueImportMerged, path, line,
+				fmt.Sprintf("same %s as line %d; Anki updates or keeps that note instead of adding one", what, earlier)))
+			continue
+		}
+		notes[key] = line
+		result.Notes++
+	}
+
+	sort.SliceStable(result.Issues, func(i, j int) bool { return result.Issues[i].Line < result.Issues[j].Line })
+	return result, nil
+}
+
+// guessAnkiSeparator returns the separator Anki guesses from the first line
+// of text
+func guessAnkiSeparator(text string) rune {
+	line, _, _ := strings.Cut(text, "\n")
+	for _, separator := range ankiGuessedSeparators {
+		if strings.ContainsRune(line, separator) {
+			return separator
+		}
+	}
+	return ' '
+}
+
+// readAnkiRecords splits text into records, returning the line each starts
+// on and the lines skipped as comments. Quotes are read leniently and rows
+// may have any number of fields, as in Anki.
+func readAnkiRecords(text string, separator rune) ([][]string, []int, []int, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = separator
+	reader.Comment = '#'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	var records [][]string
+	var starts, comments []int
+	sourceLines := strings.Split(text, "\n")
+	skipped := func(from, to int) {
+		for line := from; line < to && line <= len(sourceLines); line++ {
+			if strings.HasPrefix(sourceLines[line-1], "#") {
+				comments = append(comments, line)
+			}
+		}
+	}
+
+	next := 1 // First line not yet part of a record
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		start, _ := reader.FieldPos(0)
+		last, _ := reader.FieldPos(len(record) - 1)
+		skipped(next, start)
+		next = last + strings.Count(record[len(record)-1], "\n") + 1
+
+		records = append(records, record)
+		starts = append(starts, start)
+	}
+	skipped(next, len(sourceLines)+1)
+	return records, starts, comments, nil
+}
+
+// containsHTML reports whether a field of records contains a tag or entity
+func containsHTML(records [][]string) bool {
+	for _, record := range records {
+		for _, field := range record {
+			if ankiHTMLPattern.MatchString(field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapFields sets the note fields and the columns filling them, recording
+// columns that fill no field and fields no column fills
+func (a *AnkiImport) mapFields(path string, fields []string) {
+	var columns []int
+	for i := range a.Columns {
+		if _, ok := a.Metadata[i+1]; !ok {
+			columns = append(columns, i+1)
+		}
+	}
+
+	if fields == nil {
+		for _, column := range columns {
+			a.Fields = append(a.Fields, a.Columns[column-1])
+		}
+		a.FieldColumns = columns
+		return
+	}
+
+	a.Fields = fields
+	a.FieldColumns = make([]int, len(fields))
+	copy(a.FieldColumns, columns)
+	if len(columns) > len(fields) {
+		var names []string
+		for _, column := range columns[len(fields):] {
+			names = append(names, a.Columns[column-1])
+		}
+		a.addIssue(&Issue{Severity: SeverityWarning, Code: IssueImportDropped, File: path,
+			Message: fmt.Sprintf("column(s) %s fill no field of the note type and are not imported", strings.Join(names, ", "))})
+	} else if len(columns) < len(fields) {
+		a.addIssue(&Issue{Severity: SeverityWarning, Code: IssueImportDropped, File: path,
+			Message: fmt.Sprintf("field(s) %s have no column and stay empty", strings.Join(fields[len(columns):], ", "))})
+	}
+}
+
+// fieldValue returns the value record gives the note field at index
+func (a *AnkiImport) fieldValue(record []string, index int) string {
+	if index >= len(a.FieldColumns) {
+		return ""
+	}
+	column := a.FieldColumns[index]
+	if column == 0 || column > len(record) {
+		return ""
+	}
+	return record[column-1]
+}
+
+// metadataValue returns the value of record in the metadata column name, or
+// "" when there is none
+func (a *AnkiImport) metadataValue(record []string, name string) string {
+	for column, metadata := range a.Metadata {
+		if metadata == name && column <= len(record) {
+			return record[column-1]
+		}
+	}
+	return ""
+}
+
+// addIssue records an issue of the import
+func (a *AnkiImport) addIssue(issue *Issue) {
+	a.Issues = append(a.Issues, issue)
+}
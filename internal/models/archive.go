@@ -0,0 +1,114 @@
+package models
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ZipExt is the extension of archive inputs whose files are merged
+const ZipExt = ".zip"
+
+// MaxZipExtract is the default bound on the bytes ExtractZip writes for one
+// archive, so a small archive that expands to fill the disk (a zip bomb) is
+// rejected
+const MaxZipExtract = 4 << 30
+
+// IsZip reports whether path is a .zip archive input
+func IsZip(p string) bool {
+	return strings.EqualFold(filepath.Ext(p), ZipExt)
+}
+
+// ExtractedFile is a file extracted from an archive to temporary storage
+type ExtractedFile struct {
+	Name string // Name of the entry in the archive, such as chapter1/words.csv
+	Path string // Where it was extracted to
+}
+
+// ExtractZip extracts the entries of the archive for which match returns true
+// to a temporary directory, in archive order. Directories and the metadata
+// macOS adds to archives (__MACOSX/, ._ and other dot files) are skipped.
+// Entry names use / whichever system made the archive; a name leaving the
+// archive (../x, ..\x or an absolute path) or entries over MaxExtract bytes
+// in all fail the extraction. The files stay until RemoveExtracted.
+func (s *FileService) ExtractZip(archive string, match func(name string) bool) ([]ExtractedFile, error) {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	dir, err := os.MkdirTemp("", "ankiprep-zip-*")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.tempDirs = append(s.tempDirs, dir)
+	s.mu.Unlock()
+
+	var extracted []ExtractedFile
+	limit := s.MaxExtract
+	if limit <= 0 {
+		limit = MaxZipExtract
+	}
+	remaining := limit
+	for _, entry := range reader.File {
+		name := strings.ReplaceAll(entry.Name, `\`, "/")
+		base := path.Base(name)
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") || !match(name) {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return nil, fmt.Errorf("cannot extract %s: the name leaves the archive", entry.Name)
+		}
+
+		// Entries may share a name in different folders, and their folders
+		// are not trusted, so each is extracted flat under a numbered name
+		target := filepath.Join(dir, fmt.Sprintf("%d-%s", len(extracted)+1, base))
+		written, err := extractEntry(entry, target, remaining, limit)
+		if err != nil {
+			return nil, fmt.Errorf("cannot extract %s: %v", name, err)
+		}
+		remaining -= written
+		extracted = append(extracted, ExtractedFile{Name: name, Path: target})
+	}
+	return extracted, nil
+}
+
+// extractEntry writes the content of entry to target and returns its size,
+// failing once it is over the remaining bytes of the archive's limit
+func extractEntry(entry *zip.File, target string, remaining, limit int64) (int64, error) {
+	source, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	file, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(file, io.LimitReader(source, remaining+1))
+	if err == nil && written > remaining {
+		err = fmt.Errorf("archive expands to over %d bytes", limit)
+	}
+	if err != nil {
+		file.Close()
+		return written, err
+	}
+	return written, file.Close()
+}
+
+// RemoveExtracted deletes the files extracted by ExtractZip
+func (s *FileService) RemoveExtracted() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, dir := range s.tempDirs {
+		os.RemoveAll(dir)
+	}
+	s.tempDirs = nil
+}
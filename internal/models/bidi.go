@@ -0,0 +1,181 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Direction marks, invisible characters that take a direction so the
+// punctuation next to them does too
+const (
+	LeftToRightMark = '\u200E'
+	RightToLeftMark = '\u200F'
+)
+
+// rtlScripts are the scripts written right to left
+var rtlScripts = []*unicode.RangeTable{unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko}
+
+// bidiMarkupPattern matches what direction is not read from: HTML tags,
+// entities and the {{c1:: }} :: syntax of cloze deletions
+var bidiMarkupPattern = regexp.MustCompile(`^(?:<[^>]*>|&#?\w+;|\{\{c\d+::|\}\}|::)`)
+
+// IsRTL reports whether r is a letter of a right-to-left script or a
+// right-to-left mark
+func IsRTL(r rune) bool {
+	return r == RightToLeftMark || unicode.In(r, rtlScripts...)
+}
+
+// isLTR reports whether r is a letter of a left-to-right script or a
+// left-to-right mark
+func isLTR(r rune) bool {
+	return r == LeftToRightMark || (unicode.IsLetter(r) && !IsRTL(r))
+}
+
+// strongRun is a stretch of text between its first and last character of
+// one direction, and any neutral characters between them
+type strongRun struct {
+	start, end int
+	rtl        bool
+}
+
+// strongRuns splits text into runs of one direction, skipping markup
+func strongRuns(text string) []strongRun {
+	var runs []strongRun
+	for i := 0; i < len(text); {
+		if markup := bidiMarkupPattern.FindString(text[i:]); markup != "" {
+			i += len(markup)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if rtl := IsRTL(r); rtl || isLTR(r) {
+			if n := len(runs); n > 0 && runs[n-1].rtl == rtl {
+				runs[n-1].end = i + size
+			} else {
+				runs = append(runs, strongRun{start: i, end: i + size, rtl: rtl})
+			}
+		}
+		i += size
+	}
+	return runs
+}
+
+// rtlSpans returns the [start, end) spans of text French typography leaves
+// alone: each right-to-left run with the quotes and brackets opening it and
+// everything up to the next left-to-right letter, so no spacing is inserted
+// before the punctuation of a Hebrew or Arabic phrase or inside the
+// guillemets around it
+func rtlSpans(text string) [][2]int {
+	runs := strongRuns(text)
+	var spans [][2]int
+	for i, run := range runs {
+		if !run.rtl {
+			continue
+		}
+		// Take in the opening quotes and brackets just before the run
+		start, end := openingStart(text, run.start), len(text)
+		if i+1 < len(runs) {
+			end = runs[i+1].start
+		}
+		if n := len(spans); n > 0 && start < spans[n-1][1] {
+			start = spans[n-1][1]
+		}
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans
+}
+
+// HasMixedDirection reports whether text has both right-to-left and
+// left-to-right letters, leaving out markup
+func HasMixedDirection(text string) bool {
+	return len(strongRuns(text)) > 1
+}
+
+// AddDirectionMarks puts a right-to-left mark after each right-to-left run
+// of a mixed-direction text and a left-to-right mark after each
+// left-to-right run, following the punctuation and digits directly after the
+// run (and the spaces French typography puts before punctuation), so they stay with the words they belong to whatever the direction of
+// the field. Text of one direction is returned unchanged, and marks already
+// in place are not added twice.
+func AddDirectionMarks(text string) string {
+	runs := strongRuns(text)
+	if len(runs) < 2 {
+		return text
+	}
+
+	var b strings.Builder
+	pos := 0
+	for _, run := range runs {
+		end := run.end
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if unicode.Is(unicode.Zs, r) {
+				// French typography puts a space before ? ! : ; and », which
+				// belong to the run as much as the punctuation itself
+				if space := spaceBeforePunct(text[end:]); space > 0 {
+					end += space
+					continue
+				}
+				break
+			}
+			if !unicode.IsPunct(r) && !unicode.IsSymbol(r) && !unicode.IsDigit(r) {
+				break
+			}
+			if bidiMarkupPattern.MatchString(text[end:]) {
+				break
+			}
+			end += size
+		}
+
+		mark := LeftToRightMark
+		if run.rtl {
+			mark = RightToLeftMark
+		}
+		b.WriteString(text[pos:end])
+		if last, _ := utf8.DecodeLastRuneInString(text[:end]); last != mark {
+			b.WriteRune(mark)
+		}
+		pos = end
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+// spaceBeforePunct returns the length of the spaces text starts with when
+// punctuation or a symbol follows them, and 0 otherwise. Opening brackets and
+// quotes belong to the text after them, so they do not count.
+func spaceBeforePunct(text string) int {
+	end := 0
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !unicode.Is(unicode.Zs, r) {
+			break
+		}
+		end += size
+	}
+	r, _ := utf8.DecodeRuneInString(text[end:])
+	if end == len(text) || !(unicode.IsPunct(r) || unicode.IsSymbol(r)) || unicode.In(r, unicode.Ps, unicode.Pi) || bidiMarkupPattern.MatchString(text[end:]) {
+		return 0
+	}
+	return end
+}
+
+// ApplyDirectionMarks adds direction marks to the mixed-direction values of
+// columns and returns how many values changed
+func ApplyDirectionMarks(entries []*DataEntry, columns []string) int {
+	changed := 0
+	for _, entry := range DataEntries(entries) {
+		for _, column := range columns {
+			value, ok := entry.Values[column]
+			if !ok {
+				continue
+			}
+			if marked := AddDirectionMarks(value); marked != value {
+				entry.Values[column] = marked
+				changed++
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,75 @@
+package models
+
+import "sort"
+
+// CardCount estimates how many cards an import creates. Anki makes one card
+// per distinct cloze number in a cloze note, whichever fields the clozes are
+// in; notes without clozes are counted separately since their card count
+// depends on the note type (one each for Basic).
+type CardCount struct {
+	ClozeNotes int `json:"cloze_notes"` // Notes with at least one cloze deletion
+	ClozeCards int `json:"cloze_cards"` // Cards the cloze notes generate
+	MaxPerNote int `json:"max_per_note"`
+	OtherNotes int `json:"other_notes"` // Notes without cloze deletions
+}
+
+// ClozeNumbers returns the distinct cloze numbers used in text, ascending.
+// Nested clozes count, as they do in Anki.
+func ClozeNumbers(text string) []int {
+	seen := make(map[int]bool)
+	for _, number := range clozeOpenings(text) {
+		seen[number] = true
+	}
+
+	numbers := make([]int, 0, len(seen))
+	for number := range seen {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// clozeOpenings returns the number of every {{cN:: opening in text, including
+// nested and unclosed ones; unlike ParseClozeBlocks it does not validate blocks
+func clozeOpenings(text string) []int {
+	var numbers []int
+	for _, match := range clozeStartPattern.FindAllStringSubmatch(text, -1) {
+		number := 0
+		for _, digit := range match[1] {
+			number = number*10 + int(digit-'0')
+		}
+		if number > 0 {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// CountCards counts the cards the entries create with the given columns; a
+// preserved header row is not a note
+func CountCards(entries []*DataEntry, headers []string) *CardCount {
+	count := &CardCount{}
+	for _, entry := range entries {
+		if entry.LineNumber == 0 {
+			continue
+		}
+
+		numbers := make(map[int]bool)
+		for _, header := range headers {
+			for _, number := range clozeOpenings(entry.GetValue(header)) {
+				numbers[number] = true
+			}
+		}
+
+		if len(numbers) == 0 {
+			count.OtherNotes++
+			continue
+		}
+		count.ClozeNotes++
+		count.ClozeCards += len(numbers)
+		if len(numbers) > count.MaxPerNote {
+			count.MaxPerNote = len(numbers)
+		}
+	}
+	return count
+}
@@ -0,0 +1,20 @@
+package models
+
+import "strings"
+
+// CellChanges counts the typography processing applied to one cell
+type CellChanges struct {
+	NNBSPs int // Narrow no-break spaces inserted
+	Quotes int // Straight quotes converted to curly quotes or guillemets
+}
+
+// CountCellChanges compares a cell before and after processing
+func CountCellChanges(before, after string) CellChanges {
+	straight := func(text string) int {
+		return strings.Count(text, `"`) + strings.Count(text, "'")
+	}
+	return CellChanges{
+		NNBSPs: max(strings.Count(after, "\u202F")-strings.Count(before, "\u202F"), 0),
+		Quotes: max(straight(before)-straight(after), 0),
+	}
+}
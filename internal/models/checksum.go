@@ -0,0 +1,91 @@
+package models
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumManifest holds the expected SHA-256 of files, by absolute path
+type ChecksumManifest map[string]string
+
+// LoadChecksumManifest reads a manifest in the format of sha256sum: one
+// "<hex digest>  <file>" line per file, with a '*' before the name for
+// binary mode. Relative names are resolved against the manifest's
+// directory; blank lines and # comments are ignored.
+func LoadChecksumManifest(path string) (ChecksumManifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	manifest := make(ChecksumManifest)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		digest, name, ok := strings.Cut(text, " ")
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		if _, err := hex.DecodeString(digest); !ok || err != nil || len(digest) != sha256.Size*2 || name == "" {
+			return nil, fmt.Errorf("%s:%d: expected \"<sha256>  <file>\", got %q", path, line, text)
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(path), name)
+		}
+		if name, err = filepath.Abs(name); err != nil {
+			return nil, err
+		}
+		manifest[name] = strings.ToLower(digest)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Verify checks the SHA-256 of the file at path against the manifest and
+// returns its digest. Files missing from the manifest fail too, so every
+// input of a run is accounted for.
+func (m ChecksumManifest) Verify(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	expected, ok := m[abs]
+	if !ok {
+		return "", fmt.Errorf("%s is not listed in the checksum manifest", path)
+	}
+
+	digest, err := FileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	if digest != expected {
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, expected, digest)
+	}
+	return digest, nil
+}
+
+// FileSHA256 returns the hex SHA-256 digest of the file at path
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// cjkScripts are the scripts of Chinese, Japanese and Korean text
+var cjkScripts = []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Bopomofo}
+
+// IsCJK reports whether r is a Chinese, Japanese or Korean character:
+// a letter of their scripts, CJK punctuation (、。「」) or a fullwidth form
+// (：！？)
+func IsCJK(r rune) bool {
+	return unicode.In(r, cjkScripts...) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJK symbols and punctuation
+		r == 0x30FC || // Katakana-hiragana prolonged sound mark
+		(r >= 0xFF00 && r <= 0xFFEF) // Halfwidth and fullwidth forms
+}
+
+// isCJKPunct reports whether r is CJK or fullwidth punctuation
+func isCJKPunct(r rune) bool {
+	return IsCJK(r) && unicode.IsPunct(r)
+}
+
+// cjkSpans returns the [start, end) spans of text French typography leaves
+// alone: each run of CJK characters, fullwidth punctuation included, with
+// the quotes and brackets opening it and everything up to the next other
+// letter, so no spacing is inserted in a Japanese answer or before a
+// fullwidth question mark
+func cjkSpans(text string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(text); {
+		if markup := bidiMarkupPattern.FindString(text[i:]); markup != "" {
+			i += len(markup)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !IsCJK(r) {
+			i += size
+			continue
+		}
+
+		start, end := openingStart(text, i), len(text)
+		for j := i + size; j < len(text); {
+			if markup := bidiMarkupPattern.FindString(text[j:]); markup != "" {
+				j += len(markup)
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if unicode.IsLetter(r) && !IsCJK(r) {
+				end = j
+				break
+			}
+			j += size
+		}
+		if n := len(spans); n > 0 && start < spans[n-1][1] {
+			start = spans[n-1][1]
+		}
+		spans = append(spans, [2]int{start, end})
+		i = end
+	}
+	return spans
+}
+
+// openingStart returns where the opening quotes and brackets just before
+// position start of text begin, or start when there are none
+func openingStart(text string, start int) int {
+	for j := start; j > 0; {
+		r, size := utf8.DecodeLastRuneInString(text[:j])
+		if !unicode.IsSpace(r) && !unicode.In(r, unicode.Ps, unicode.Pi) && r != '"' && r != '\'' {
+			break
+		}
+		j -= size
+		if !unicode.IsSpace(r) {
+			start = j
+		}
+	}
+	return start
+}
+
+// mergeSpans returns the spans of a and b in order, joining those that
+// overlap
+func mergeSpans(a, b [][2]int) [][2]int {
+	spans := append(append([][2]int(nil), a...), b...)
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var merged [][2]int
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span[0] <= merged[n-1][1] {
+			if span[1] > merged[n-1][1] {
+				merged[n-1][1] = span[1]
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// ApplyCJKSpacing spaces CJK text the way it is typeset: the spaces
+// (ordinary, no-break or thin) between a CJK or fullwidth punctuation mark
+// and the CJK character next to it are removed, as in 「 はい 」 or
+// はい ！. Spaces between CJK and Latin text, and ideographic spaces, are
+// left alone.
+func ApplyCJKSpacing(text string) string {
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isCJKSpace(r) {
+			i += size
+			continue
+		}
+		end := i
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !isCJKSpace(r) {
+				break
+			}
+			end += size
+		}
+
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if i > 0 && end < len(text) && IsCJK(before) && IsCJK(after) && (isCJKPunct(before) || isCJKPunct(after)) {
+			b.WriteString(text[pos:i])
+			pos = end
+		}
+		i = end
+	}
+	if pos == 0 {
+		return text
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+// isCJKSpace reports whether r is a space ApplyCJKSpacing may remove: an
+// ordinary, no-break, narrow no-break or thin space, but not a line break
+// or the ideographic space CJK text uses on purpose
+func isCJKSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\u00A0' || r == '\u202F' || r == '\u2009'
+}
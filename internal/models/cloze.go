@@ -57,91 +57,132 @@ func IsValidClozeDeletionPattern(text string) bool {
 	return clozeStartPattern.MatchString(text) && strings.HasSuffix(text, "}}")
 }
 
-// ParseClozeBlocks extracts all cloze deletion blocks from the given text using brace counting.
-// Returns blocks sorted by StartPos with no overlapping positions.
+// ParseClozeBlocks extracts the outermost cloze deletion blocks of text with
+// scanClozes, so deletions may span lines, hold MathJax braces and nest.
+// Returns blocks sorted by StartPos with no overlapping positions; unclosed
+// and invalid deletions are left out.
 func ParseClozeBlocks(text string) ([]ClozeDeletionBlock, error) {
 	var blocks []ClozeDeletionBlock
-
-	// Find all potential cloze starts
-	starts := clozeStartPattern.FindAllStringSubmatchIndex(text, -1)
-
-	for _, match := range starts {
-		startPos := match[0]
-		numberStart := match[2]
-		numberEnd := match[3]
-		contentStart := match[1] // This is the end of the full match "{{c1::"
-
-		// Parse the cloze number
-		numberStr := text[numberStart:numberEnd]
-		var number int
-		if n, err := fmt.Sscanf(numberStr, "%d", &number); err != nil || n != 1 || number <= 0 {
-			continue // Skip invalid numbers
-		}
-
-		// Find the matching }} by counting braces
-		braceCount := 2 // Start with 2 open braces from {{
-		endPos := -1
-
-		for i := contentStart; i < len(text)-1; i++ {
-			if text[i] == '{' && text[i+1] == '{' {
-				braceCount += 2
-				i++ // Skip the next {
-			} else if text[i] == '}' && text[i+1] == '}' {
-				braceCount -= 2
-				if braceCount == 0 {
-					endPos = i + 2 // Include both }}
-					break
-				}
-				i++ // Skip the next }
-			}
-		}
-
-		if endPos == -1 {
-			continue // No matching closing braces found
+	for _, span := range scanClozes(text) {
+		if !span.closed {
+			continue
 		}
 
-		// Extract the full text and content
-		fullText := text[startPos:endPos]
-		content := text[contentStart : endPos-2] // Exclude the final }}
-
-		// Parse content and hint
 		var hint *string
-		if strings.Contains(content, "::") {
-			parts := strings.SplitN(content, "::", 2)
-			if len(parts) == 2 {
-				content = parts[0]
-				hintValue := parts[1]
-				if strings.TrimSpace(hintValue) != "" {
-					hint = &hintValue
-				}
+		if span.hint >= 0 {
+			if hintValue := text[span.hint : span.end-len("}}")]; strings.TrimSpace(hintValue) != "" {
+				hint = &hintValue
 			}
 		}
 
 		block := ClozeDeletionBlock{
-			FullText: fullText,
-			Number:   number,
-			Content:  content,
+			FullText: text[span.start:span.end],
+			Number:   span.number,
+			Content:  text[span.content:span.answerEnd()],
 			Hint:     hint,
-			StartPos: startPos,
-			EndPos:   endPos,
+			StartPos: span.start,
+			EndPos:   span.end,
 		}
-
-		// Validate the block
 		if err := block.Validate(); err != nil {
-			// Log warning but continue processing
 			continue
 		}
-
 		blocks = append(blocks, block)
 	}
+	return blocks, nil
+}
+
+// clozeSpan locates a cloze deletion found by scanClozes
+type clozeSpan struct {
+	start, end int  // text[start:end] is the deletion, or its opening when unclosed
+	number     int  // N of {{cN::
+	content    int  // Start of the answer
+	hint       int  // Start of the hint after the answer's ::, or -1
+	closed     bool // Whether the deletion has its closing }}
+}
+
+// answerEnd returns where the answer of a closed deletion ends
+func (s clozeSpan) answerEnd() int {
+	if s.hint >= 0 {
+		return s.hint - len("::")
+	}
+	return s.end - len("}}")
+}
 
-	// Verify no overlaps (blocks are already sorted by StartPos due to search order)
-	for i := 1; i < len(blocks); i++ {
-		if blocks[i-1].EndPos > blocks[i].StartPos {
-			return nil, fmt.Errorf("overlapping cloze blocks detected at positions %d and %d",
-				blocks[i-1].StartPos, blocks[i].StartPos)
+// scanClozes finds the outermost cloze deletions of text in one pass. Unlike
+// a regular expression it follows nesting: braces inside a deletion (MathJax
+// such as \(x^{2}\), or another deletion) must be closed before its }} ends
+// it, and its hint starts at the first :: outside them. Deletions may span
+// line breaks. An unclosed deletion yields only its opening {{cN::, followed
+// by the deletions closed inside it.
+func scanClozes(text string) []clozeSpan {
+	type frame struct {
+		span     clozeSpan
+		depth    int         // Braces opened inside the deletion and not yet closed
+		children []clozeSpan // Deletions closed inside this one
+	}
+
+	var spans []clozeSpan
+	var stack []*frame
+	for i := 0; i < len(text); {
+		if number, n := clozeOpening(text[i:]); n > 0 {
+			stack = append(stack, &frame{span: clozeSpan{start: i, number: number, content: i + n, hint: -1}})
+			i += n
+			continue
+		}
+		if len(stack) == 0 {
+			i++
+			continue
+		}
+
+		top := stack[len(stack)-1]
+		switch {
+		case top.depth == 0 && strings.HasPrefix(text[i:], "}}"):
+			stack = stack[:len(stack)-1]
+			top.span.end, top.span.closed = i+2, true
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, top.span)
+			} else {
+				spans = append(spans, top.span)
+			}
+			i += 2
+			continue
+		case top.depth == 0 && top.span.hint < 0 && strings.HasPrefix(text[i:], "::"):
+			top.span.hint = i + 2
+			i += 2
+			continue
+		case text[i] == '{':
+			top.depth++
+		case text[i] == '}' && top.depth > 0:
+			top.depth--
 		}
+		i++
 	}
 
-	return blocks, nil
+	// Whatever is left never closed; outer frames hold only what was closed
+	// before the next one opened, so the result stays in order
+	for _, open := range stack {
+		open.span.end = open.span.content
+		spans = append(spans, open.span)
+		spans = append(spans, open.children...)
+	}
+	return spans
+}
+
+// clozeOpening returns the number and length of the {{cN:: that text starts
+// with, or a length of 0
+func clozeOpening(text string) (int, int) {
+	if !strings.HasPrefix(text, "{{c") {
+		return 0, 0
+	}
+	number, i := 0, len("{{c")
+	for ; i < len(text) && text[i] >= '0' && text[i] <= '9'; i++ {
+		if number < 1<<20 {
+			number = number*10 + int(text[i]-'0')
+		}
+	}
+	if i == len("{{c") || !strings.HasPrefix(text[i:], "::") {
+		return 0, 0
+	}
+	return number, i + len("::")
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// NewCollator returns the collation of a language such as fr, in which
+// letters sort by the language's alphabet and accents and case only decide
+// between otherwise equal values, so "école" sorts next to "ecole" rather
+// than after "z". The rules are built into ankiprep, so the order does not
+// depend on the system's locale.
+func NewCollator(tag string) (*collate.Collator, error) {
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid language %q: %v", tag, err)
+	}
+	if _, _, confidence := language.NewMatcher(collate.Supported()).Match(parsed); confidence == language.No {
+		return nil, fmt.Errorf("no collation for language %q", tag)
+	}
+	return collate.New(parsed), nil
+}
+
+// SortEntriesCollated is SortEntries, comparing values with collator
+func SortEntriesCollated(entries []*DataEntry, column string, collator *collate.Collator) {
+	sortEntries(entries, column, func(a, b string) bool {
+		return collator.CompareString(a, b) < 0
+	})
+}
+
+// sortEntries stably sorts the entries by the value of column with less,
+// keeping a preserved header row first
+func sortEntries(entries []*DataEntry, column string, less func(a, b string) bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].LineNumber == 0 || entries[j].LineNumber == 0 {
+			return entries[i].LineNumber == 0 && entries[j].LineNumber != 0
+		}
+		return less(entries[i].GetValue(column), entries[j].GetValue(column))
+	})
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColumnJoin describes columns combined into one, such as three example
+// sentences for a note type with a single Examples field
+type ColumnJoin struct {
+	Sources   []string // Columns combined, in order
+	Target    string   // Column receiving the combined value
+	Separator string   // Text between values, e.g. "<br>"
+}
+
+// ParseColumnJoin parses a join specification such as
+// "Example1+Example2+Example3=Examples:<br>": the source columns, the target
+// column and the separator, which may itself contain ':'
+func ParseColumnJoin(spec string) (*ColumnJoin, error) {
+	sources, rest, ok := strings.Cut(spec, "=")
+	target, separator, hasSeparator := strings.Cut(rest, ":")
+	if !ok || !hasSeparator {
+		return nil, fmt.Errorf("invalid column join %q: expected \"<column>+<column>...=<column>:<separator>\"", spec)
+	}
+
+	join := &ColumnJoin{
+		Target:    strings.TrimSpace(target),
+		Separator: separator,
+	}
+	for _, source := range strings.Split(sources, "+") {
+		join.Sources = append(join.Sources, strings.TrimSpace(source))
+	}
+
+	if err := join.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid column join %q: %v", spec, err)
+	}
+	return join, nil
+}
+
+// Validate checks that the join names its columns and a separator
+func (j *ColumnJoin) Validate() error {
+	if j.Target == "" {
+		return fmt.Errorf("target column name cannot be empty")
+	}
+	if j.Separator == "" {
+		return fmt.Errorf("separator cannot be empty")
+	}
+	if len(j.Sources) < 2 {
+		return fmt.Errorf("at least two columns are needed")
+	}
+
+	seen := make(map[string]bool)
+	for _, source := range j.Sources {
+		if source == "" {
+			return fmt.Errorf("column name cannot be empty")
+		}
+		if seen[source] {
+			return fmt.Errorf("column %q is listed twice", source)
+		}
+		seen[source] = true
+	}
+	return nil
+}
+
+// Headers returns headers with the source columns replaced by the target,
+// at the position of the first source
+func (j *ColumnJoin) Headers(headers []string) []string {
+	var joined []string
+	placed := false
+	for _, header := range headers {
+		if !containsHeader(j.Sources, header) {
+			joined = append(joined, header)
+		} else if !placed {
+			joined = append(joined, j.Target)
+			placed = true
+		}
+	}
+	return joined
+}
+
+// Join combines the non-empty source values with the separator; values are
+// trimmed of surrounding whitespace
+func (j *ColumnJoin) Join(values []string) string {
+	var parts []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, j.Separator)
+}
+
+// Apply combines the source columns of every entry into the target and
+// removes the sources. A preserved header row gets the target name.
+func (j *ColumnJoin) Apply(entries []*DataEntry) {
+	for _, entry := range entries {
+		values := make([]string, len(j.Sources))
+		for i, source := range j.Sources {
+			values[i] = entry.Values[source]
+			delete(entry.Values, source)
+		}
+
+		if entry.LineNumber == 0 {
+			entry.SetValue(j.Target, j.Target)
+		} else {
+			entry.SetValue(j.Target, j.Join(values))
+		}
+	}
+}
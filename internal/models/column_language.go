@@ -0,0 +1,106 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// columnLanguages are the languages a column can be declared in
+var columnLanguages = []string{
+	LanguageFrench, LanguageEnglish, LanguageJapanese, LanguageChinese, LanguageKorean,
+	LanguageRussian, LanguageGreek, LanguageArabic, LanguageHebrew,
+}
+
+// ValidateColumnLanguage returns an error unless language is a code a column
+// can be declared in
+func ValidateColumnLanguage(column, language string) error {
+	for _, code := range columnLanguages {
+		if language == code {
+			return nil
+		}
+	}
+	return fmt.Errorf("language %q of column %q: must be one of %s", language, column, strings.Join(columnLanguages, ", "))
+}
+
+// LanguageTypography returns the typography of columns declared in a
+// language, for the typography the flags enable: French spacing only in
+// French columns, CJK spacing only in Chinese, Japanese and Korean columns,
+// and smart quotes in every column. A declared language replaces the guess
+// made from the column name or, with --auto-lang, from each cell.
+func LanguageTypography(languages map[string]string, french, quotes, cjk bool) map[string]TypographyRule {
+	rules := make(map[string]TypographyRule)
+	if !french && !quotes && !cjk {
+		return rules
+	}
+	for column, language := range languages {
+		rules[column] = TypographyRule{
+			French:      french && language == LanguageFrench,
+			SmartQuotes: quotes,
+			CJKSpacing:  cjk && (language == LanguageJapanese || language == LanguageChinese || language == LanguageKorean),
+		}
+	}
+	return rules
+}
+
+// LanguagesDirective returns the #languages line declaring the language of
+// each of headers that has one, e.g. "#languages:Front=fr,Back=en", or ""
+// when none has. Anki ignores the line; ankiprep reads it back so a
+// re-processed import file keeps its column languages.
+func LanguagesDirective(headers []string, languages map[string]string, separator string) (string, error) {
+	var declared []string
+	for _, header := range headers {
+		if language, ok := languages[header]; ok {
+			declared = append(declared, SanitizeHeader(header)+"="+language)
+		}
+	}
+	if len(declared) == 0 {
+		return "", nil
+	}
+
+	var line strings.Builder
+	writer := csv.NewWriter(&line)
+	if separator == SeparatorTab {
+		writer.Comma = '\t'
+	}
+	if err := writer.Write(declared); err != nil {
+		return "", err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return "#languages:" + strings.TrimSuffix(line.String(), "\n"), nil
+}
+
+// parseLanguages reads the value of a #languages line; declarations of
+// unknown languages are left out
+func parseLanguages(value string, separator rune) (map[string]string, error) {
+	declared, err := parseColumns(value, separator)
+	if err != nil {
+		return nil, err
+	}
+
+	languages := make(map[string]string)
+	for _, declaration := range declared {
+		i := strings.LastIndex(declaration, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q is not column=language", declaration)
+		}
+		column, language := declaration[:i], declaration[i+1:]
+		if ValidateColumnLanguage(column, language) == nil {
+			languages[column] = language
+		}
+	}
+	return languages, nil
+}
+
+// LanguageSummary returns the languages as "Back=en, Front=fr", sorted by
+// column name
+func LanguageSummary(languages map[string]string) string {
+	parts := make([]string, 0, len(languages))
+	for _, column := range sortedKeys(languages) {
+		parts = append(parts, column+"="+languages[column])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// What to do with the items of a split cell beyond its target columns
+const (
+	SplitOverflowJoin  = "join"  // Keep them in the last column, with their delimiters
+	SplitOverflowDrop  = "drop"  // Drop them, with a warning
+	SplitOverflowError = "error" // Fail the run
+)
+
+// ColumnSplit describes a column whose cells pack several values that belong
+// in columns of their own, such as three example sentences in one cell
+type ColumnSplit struct {
+	Column    string   // Column holding the packed values
+	Delimiter string   // Text between values, e.g. ";"
+	Targets   []string // Columns receiving the values, in order
+}
+
+// ParseColumnSplit parses a split specification such as
+// "Examples|;|Example1,Example2,Example3": the column, the delimiter and the
+// comma-separated target columns. The delimiter may itself be "|".
+func ParseColumnSplit(spec string) (*ColumnSplit, error) {
+	first, last := strings.Index(spec, "|"), strings.LastIndex(spec, "|")
+	if first < 0 || last-first < 2 {
+		return nil, fmt.Errorf("invalid split %q: expected \"<column>|<delimiter>|<column>,<column>...\"", spec)
+	}
+
+	split := &ColumnSplit{
+		Column:    strings.TrimSpace(spec[:first]),
+		Delimiter: spec[first+1 : last],
+	}
+	for _, target := range strings.Split(spec[last+1:], ",") {
+		split.Targets = append(split.Targets, strings.TrimSpace(target))
+	}
+
+	if err := split.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid split %q: %v", spec, err)
+	}
+	return split, nil
+}
+
+// Validate checks that the split names its columns and a delimiter
+func (s *ColumnSplit) Validate() error {
+	if s.Column == "" {
+		return fmt.Errorf("column name cannot be empty")
+	}
+	if strings.TrimSpace(s.Delimiter) == "" {
+		return fmt.Errorf("delimiter cannot be empty or whitespace")
+	}
+
+	seen := make(map[string]bool)
+	for _, target := range s.Targets {
+		if target == "" {
+			return fmt.Errorf("target column name cannot be empty")
+		}
+		if seen[target] {
+			return fmt.Errorf("target column %q is listed twice", target)
+		}
+		seen[target] = true
+	}
+	return nil
+}
+
+// Headers returns headers with the split column replaced by its targets
+func (s *ColumnSplit) Headers(headers []string) []string {
+	var split []string
+	for _, header := range headers {
+		if header == s.Column {
+			split = append(split, s.Targets...)
+		} else {
+			split = append(split, header)
+		}
+	}
+	return split
+}
+
+// Split cuts value into one value per target column, trimmed of surrounding
+// whitespace. Missing values are empty. Values beyond the last target are
+// returned as overflow; with join they stay in the last value instead, as
+// written in the cell.
+func (s *ColumnSplit) Split(value string, join bool) (values, overflow []string) {
+	var items []string
+	if strings.TrimSpace(value) != "" {
+		if join {
+			items = strings.SplitN(value, s.Delimiter, len(s.Targets))
+		} else {
+			items = strings.Split(value, s.Delimiter)
+		}
+	}
+
+	values = make([]string, len(s.Targets))
+	for i, item := range items {
+		if i < len(values) {
+			values[i] = strings.TrimSpace(item)
+		} else {
+			overflow = append(overflow, strings.TrimSpace(item))
+		}
+	}
+	return values, overflow
+}
+
+// Apply splits the column of every entry into its targets and removes it.
+// A preserved header row gets the target names. onOverflow is called for
+// every entry with more values than targets, with the values left out; with
+// join nothing is left out, as the last target keeps the rest of the cell.
+func (s *ColumnSplit) Apply(entries []*DataEntry, join bool, onOverflow func(entry *DataEntry, overflow []string) error) error {
+	for _, entry := range entries {
+		value, ok := entry.Values[s.Column]
+		delete(entry.Values, s.Column)
+
+		if entry.LineNumber == 0 {
+			for _, target := range s.Targets {
+				entry.SetValue(target, target)
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		values, overflow := s.Split(value, join)
+		for i, target := range s.Targets {
+			entry.SetValue(target, values[i])
+		}
+		if len(overflow) > 0 {
+			if err := onOverflow(entry, overflow); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
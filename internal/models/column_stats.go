@@ -0,0 +1,54 @@
+package models
+
+import "unicode/utf8"
+
+// ColumnStats summarizes the values of a single output column
+type ColumnStats struct {
+	Column        string  `json:"column"`         // Column header name
+	Total         int     `json:"total"`          // Number of entries inspected
+	NonEmpty      int     `json:"non_empty"`      // Number of non-empty values
+	MaxLength     int     `json:"max_length"`     // Longest value in characters
+	AverageLength float64 `json:"average_length"` // Mean length of non-empty values in characters
+	DistinctCount int     `json:"distinct"`       // Number of distinct non-empty values
+
+	totalLength int
+	distinct    map[string]bool
+}
+
+// NewColumnStats creates a new ColumnStats instance
+func NewColumnStats(column string) *ColumnStats {
+	return &ColumnStats{
+		Column:   column,
+		distinct: make(map[string]bool),
+	}
+}
+
+// Add records one value of the column
+func (s *ColumnStats) Add(value string) {
+	s.Total++
+	if value == "" {
+		return
+	}
+
+	length := utf8.RuneCountInString(value)
+	s.NonEmpty++
+	s.totalLength += length
+	if length > s.MaxLength {
+		s.MaxLength = length
+	}
+
+	if !s.distinct[value] {
+		s.distinct[value] = true
+		s.DistinctCount++
+	}
+
+	s.AverageLength = float64(s.totalLength) / float64(s.NonEmpty)
+}
+
+// FillRate returns the percentage of entries with a non-empty value
+func (s *ColumnStats) FillRate() float64 {
+	if s.Total == 0 {
+		return 0.0
+	}
+	return float64(s.NonEmpty) / float64(s.Total) * 100.0
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValueCount pairs a distinct column value with its number of occurrences
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// ColumnValues tallies the distinct values found in a single column
+type ColumnValues struct {
+	Column string         // Column header name
+	Counts map[string]int // Distinct value to occurrence count
+}
+
+// NewColumnValues creates a new ColumnValues instance
+func NewColumnValues(column string) *ColumnValues {
+	return &ColumnValues{
+		Column: column,
+		Counts: make(map[string]int),
+	}
+}
+
+// Add records one occurrence of a value; empty cells are not counted
+func (c *ColumnValues) Add(value string) {
+	if value == "" {
+		return
+	}
+	c.Counts[value]++
+}
+
+// DistinctCount returns the number of distinct values seen
+func (c *ColumnValues) DistinctCount() int {
+	return len(c.Counts)
+}
+
+// SortedValues returns values ordered by descending count, then alphabetically
+func (c *ColumnValues) SortedValues() []ValueCount {
+	values := make([]ValueCount, 0, len(c.Counts))
+	for value, count := range c.Counts {
+		values = append(values, ValueCount{Value: value, Count: count})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+
+	return values
+}
+
+// NearMatches returns groups of distinct values that only differ by case or
+// whitespace (e.g. "noun", "Noun" and "noun "), each group sorted alphabetically
+func (c *ColumnValues) NearMatches() [][]string {
+	groups := make(map[string][]string)
+	for value := range c.Counts {
+		key := normalizeForComparison(value)
+		groups[key] = append(groups[key], value)
+	}
+
+	var matches [][]string
+	for _, group := range groups {
+		if len(group) > 1 {
+			sort.Strings(group)
+			matches = append(matches, group)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i][0] < matches[j][0]
+	})
+
+	return matches
+}
+
+// normalizeForComparison lowercases a value and collapses its whitespace
+func normalizeForComparison(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
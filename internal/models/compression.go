@@ -0,0 +1,88 @@
+package models
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Suffixes of compressed input files, which are decompressed while they are
+// read; the extension before the suffix still decides the separator
+const (
+	GzipExt = ".gz"
+	ZstdExt = ".zst"
+)
+
+// TrimCompression returns path without a .gz or .zst suffix, so that
+// vocab.tsv.zst is treated as a .tsv file
+func TrimCompression(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case GzipExt, ZstdExt:
+		return path[:len(path)-len(filepath.Ext(path))]
+	}
+	return path
+}
+
+// IsCompressed reports whether path ends in .gz or .zst
+func IsCompressed(path string) bool {
+	return TrimCompression(path) != path
+}
+
+// OpenInput opens the file at path for reading, decompressing .gz and .zst
+// files on the fly. Closing the reader closes the file.
+func OpenInput(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case GzipExt:
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &decompressedFile{Reader: reader, close: reader.Close, file: file}, nil
+	case ZstdExt:
+		// A single decoding goroutine keeps memory low; input is read in order
+		decoder, err := zstd.NewReader(file, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &decompressedFile{Reader: decoder, close: func() error { decoder.Close(); return nil }, file: file}, nil
+	}
+	return file, nil
+}
+
+// decompressedFile reads the decompressed content of file
+type decompressedFile struct {
+	io.Reader
+	close func() error
+	file  *os.File
+}
+
+// Close releases the decompressor and closes the file
+func (f *decompressedFile) Close() error {
+	err := f.close()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// GzipFormat wraps format so that its output is gzip-compressed
+func GzipFormat(format OutputFormat) OutputFormat {
+	return func(w io.Writer, entries []*DataEntry, headers []string) error {
+		writer := gzip.NewWriter(w)
+		if err := format(writer, entries, headers); err != nil {
+			return err
+		}
+		return writer.Close()
+	}
+}
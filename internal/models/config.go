@@ -0,0 +1,154 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Config holds pipeline settings loaded from a JSON configuration file
+type Config struct {
+	Templates   map[string]string `json:"templates"`    // Column name to HTML template wrapping its values
+	DedupeItems map[string]string `json:"dedupe_items"` // Column name to the delimiter between its items
+	MergeFields map[string]string `json:"merge_fields"` // Column name to the delimiter between the items merged from duplicates
+	Regex       []string          `json:"regex"`        // Find and replace rules (Column:s/pattern/replacement/flags), in order
+	Symbols     map[string]string `json:"symbols"`      // Column name (or *) to its symbol normalization: ascii, fullwidth or emoji
+	Languages   map[string]string `json:"languages"`    // Column name to the language of its text: fr, en, ja...
+}
+
+// NewConfig creates an empty Config instance
+func NewConfig() *Config {
+	return &Config{
+		Templates:   map[string]string{},
+		DedupeItems: map[string]string{},
+		MergeFields: map[string]string{},
+		Symbols:     map[string]string{},
+		Languages:   map[string]string{},
+	}
+}
+
+// LoadConfig reads and validates a JSON configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %v", err)
+	}
+
+	config := NewConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+
+	return config, nil
+}
+
+// Validate checks if the configuration meets all validation requirements
+func (c *Config) Validate() error {
+	for _, template := range c.FieldTemplates() {
+		if err := template.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, list := range c.ItemLists() {
+		if err := list.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, list := range c.MergeLists() {
+		if err := list.Validate(); err != nil {
+			return err
+		}
+	}
+	for i, rule := range c.Regex {
+		if _, err := ParseRegexRule(rule); err != nil {
+			return fmt.Errorf("regex rule %d %q: %v", i+1, rule, err)
+		}
+	}
+	for _, rule := range c.SymbolRules() {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, column := range sortedKeys(c.Languages) {
+		if err := ValidateColumnLanguage(column, c.Languages[column]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FieldTemplates returns the configured templates sorted by column name
+func (c *Config) FieldTemplates() []*FieldTemplate {
+	var templates []*FieldTemplate
+	for column, template := range c.Templates {
+		templates = append(templates, NewFieldTemplate(column, template))
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Column < templates[j].Column
+	})
+
+	return templates
+}
+
+// ItemLists returns the columns whose repeated items are removed, sorted by
+// column name
+func (c *Config) ItemLists() []*ItemList {
+	var lists []*ItemList
+	for column, delimiter := range c.DedupeItems {
+		lists = append(lists, NewItemList(column, delimiter))
+	}
+
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].Column < lists[j].Column
+	})
+
+	return lists
+}
+
+// MergeLists returns the columns whose items are merged from removed
+// duplicates, sorted by column name
+func (c *Config) MergeLists() []*ItemList {
+	var lists []*ItemList
+	for column, delimiter := range c.MergeFields {
+		lists = append(lists, NewItemList(column, delimiter))
+	}
+
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].Column < lists[j].Column
+	})
+
+	return lists
+}
+
+// RegexRules returns the find and replace rules in order; rules that do not
+// parse are left out, as Validate reports them
+func (c *Config) RegexRules() []*RegexRule {
+	var rules []*RegexRule
+	for _, rule := range c.Regex {
+		if parsed, err := ParseRegexRule(rule); err == nil {
+			rules = append(rules, parsed)
+		}
+	}
+	return rules
+}
+
+// SymbolRules returns the symbol normalizations sorted by column name, with
+// the one for every column (*) first
+func (c *Config) SymbolRules() []*SymbolRule {
+	var rules []*SymbolRule
+	for column, mode := range c.Symbols {
+		rules = append(rules, &SymbolRule{Column: column, Mode: mode})
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Column < rules[j].Column
+	})
+
+	return rules
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// csvRecord is a record found by scanCSV
+type csvRecord struct {
+	line      int        // Line the record starts on
+	lineStart int        // Offset of the start of that line
+	fields    int        // Number of fields
+	open      *openQuote // First quoted field that runs past the end of its line
+	atEOF     bool       // The record ends inside a quoted field at the end of the data
+}
+
+// openQuote is where a quoted field spanning lines begins
+type openQuote struct {
+	field     int // Index of the field in its record
+	line      int
+	column    int
+	lineEnd   int // Offset of the end of the line the quote is on
+	endColumn int // Column of that end
+}
+
+// csvLineEnd returns the offset of the end of the line containing pos, before
+// any \r\n
+func csvLineEnd(data []byte, pos int) int {
+	end := bytes.IndexByte(data[pos:], '\n')
+	if end < 0 {
+		return len(data)
+	}
+	end += pos
+	if end > pos && data[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+// scanCSV walks the records of data the way encoding/csv reads them with
+// LazyQuotes, calling visit for each until it returns false. Only the shape
+// of records is tracked, not their values.
+func scanCSV(data []byte, separator rune, visit func(record *csvRecord) bool) {
+	sep := []byte(string(separator))
+	line, lineStart := 1, 0
+	i := 0
+
+	// atLineEnd reports the length of the line break at pos, if any
+	atLineEnd := func(pos int) int {
+		if pos < len(data) && data[pos] == '\n' {
+			return 1
+		}
+		if pos+1 < len(data) && data[pos] == '\r' && data[pos+1] == '\n' {
+			return 2
+		}
+		return 0
+	}
+
+	for i < len(data) {
+		// encoding/csv skips empty lines between records
+		if n := atLineEnd(i); n > 0 {
+			i += n
+			line, lineStart = line+1, i
+			continue
+		}
+
+		record := &csvRecord{line: line, lineStart: lineStart}
+		for done := false; !done; {
+			record.fields++
+			if i < len(data) && data[i] == '"' {
+				end := csvLineEnd(data, i)
+				quote := &openQuote{field: record.fields - 1, line: line, column: i - lineStart + 1, lineEnd: end, endColumn: end - lineStart + 1}
+				i++
+				for {
+					if i >= len(data) {
+						record.atEOF = true
+						done = true
+						break
+					}
+					if data[i] == '"' {
+						switch {
+						case i+1 < len(data) && data[i+1] == '"':
+							i += 2
+							continue
+						case bytes.HasPrefix(data[i+1:], sep):
+							i += 1 + len(sep)
+						case i+1 >= len(data) || atLineEnd(i+1) > 0:
+							i++
+							done = true
+						default:
+							// A bare quote inside a quoted field is kept
+							i++
+							continue
+						}
+						break
+					}
+					if n := atLineEnd(i); n > 0 {
+						if record.open == nil {
+							record.open = quote
+						}
+						i += n
+						line, lineStart = line+1, i
+						continue
+					}
+					i++
+				}
+			} else {
+				for {
+					if i >= len(data) || atLineEnd(i) > 0 {
+						done = true
+						break
+					}
+					if bytes.HasPrefix(data[i:], sep) {
+						i += len(sep)
+						break
+					}
+					i++
+				}
+			}
+		}
+
+		// Step over the line break ending the record
+		if n := atLineEnd(i); n > 0 {
+			i += n
+			line, lineStart = line+1, i
+		}
+		if !visit(record) {
+			return
+		}
+	}
+}
+
+// CheckCSVText looks for mistakes in the text of a CSV/TSV file that have a
+// fix: a header ending in stray separators the rows do not have, and the
+// first quote that is not closed where it was meant to be, found as a quoted
+// field running past its line that reaches the end of the file or leaves its
+// record with the wrong number of fields. A bare quote in a field spanning
+// lines is not enough, as the field may be a valid multi-line value. Later
+// quotes are only checked once that one is fixed, since every record after it
+// is read out of step.
+func CheckCSVText(path string, data []byte, separator rune) []*Issue {
+	var issues []*Issue
+	var header *csvRecord
+	expected, records := 0, 0
+	scanCSV(data, separator, func(record *csvRecord) bool {
+		records++
+		if header == nil {
+			header, expected = record, record.fields
+			if record.open != nil {
+				issues = append(issues, unbalancedQuote(path, record, 0))
+				return false
+			}
+			return true
+		}
+
+		if records == 2 {
+			if issue := straySeparators(path, data, header, record.fields, separator); issue != nil {
+				issues = append(issues, issue)
+				expected = record.fields
+			}
+		}
+		if record.open != nil && (record.atEOF || record.fields != expected) {
+			issues = append(issues, unbalancedQuote(path, record, expected))
+			return false
+		}
+		return true
+	})
+	return issues
+}
+
+// straySeparators returns an issue when the header line ends in separators
+// that leave it exactly that many fields longer than the first data record,
+// which encoding/csv rejects as a wrong number of fields
+func straySeparators(path string, data []byte, header *csvRecord, fields int, separator rune) *Issue {
+	sep := string(separator)
+	end := csvLineEnd(data, header.lineStart)
+	line := string(data[header.lineStart:end])
+	stray := 0
+	for strings.HasSuffix(line, sep) {
+		line = strings.TrimSuffix(line, sep)
+		stray++
+	}
+	if stray == 0 || header.fields-stray != fields {
+		return nil
+	}
+
+	offset := end - stray*len(sep)
+	column := offset - header.lineStart + 1
+	return &Issue{
+		Severity:   SeverityError,
+		Code:       IssueStraySeparator,
+		File:       path,
+		Line:       header.line,
+		Column:     column,
+		Message:    fmt.Sprintf("header ends with %d stray %q the rows do not have", stray, sep),
+		Suggestion: "remove them, or rerun with --apply-fixes",
+		Fix: &Fix{
+			Description: fmt.Sprintf("removed %d stray %q at the end of the header", stray, sep),
+			Safe:        true,
+			Edits:       []TextEdit{{Line: header.line, Column: column, Offset: offset, Length: stray * len(sep)}},
+		},
+	}
+}
+
+// unbalancedQuote returns the issue of a record whose quoted field runs past
+// its line and so swallows the records after it. Closing the quote at the
+// end of its line is safe when the record fails to read as it is and closing
+// the quote leaves a record of the expected number of fields.
+func unbalancedQuote(path string, record *csvRecord, expected int) *Issue {
+	quote := record.open
+	fails := record.atEOF || record.fields != expected
+	safe := fails && expected > 0 && quote.field+1 == expected
+	suggestion := "close it, or double the quotes inside the field (\"\")"
+	if safe {
+		suggestion += ", or rerun with --apply-fixes"
+	}
+	return &Issue{
+		Severity:   SeverityError,
+		Code:       IssueUnbalancedQuote,
+		File:       path,
+		Line:       quote.line,
+		Column:     quote.column,
+		Message:    "quote is not closed on its line, so the lines after it are read as one field",
+		Suggestion: suggestion,
+		Fix: &Fix{
+			Description: fmt.Sprintf("closed the quote at the end of line %d", quote.line),
+			Safe:        safe,
+			Edits:       []TextEdit{{Line: quote.line, Column: quote.endColumn, Offset: quote.lineEnd, Text: `"`}},
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RecordHandler receives each data record with the line number where it starts
+type RecordHandler func(record []string, line int) error
+
+// HeaderMode controls how the first row of an input file is interpreted
+type HeaderMode int
+
+const (
+	HeaderAuto    HeaderMode = iota // First row is the header; callers may check HeaderSuspicion
+	HeaderPresent                   // First row is the header, no questions asked
+	HeaderAbsent                    // First row is data; columns are named Column1..N
+)
+
+// CSVParser reads CSV/TSV input files one record at a time
+type CSVParser struct {
+	LazyQuotes bool       // Accept bare and unescaped quotes instead of failing
+	Header     HeaderMode // How to interpret the first row
+	Mmap       bool       // ParseFile reads through a memory map where possible
+	LineOffset int        // Lines before the text read, added to every line number
+}
+
+// NewCSVParser creates a new CSVParser instance with lenient quoting
+func NewCSVParser() *CSVParser {
+	return &CSVParser{
+		LazyQuotes: true,
+		Header:     HeaderAuto,
+	}
+}
+
+// ParseFile streams the file at inputFile.Path using inputFile.Separator. The
+// first record becomes inputFile.Headers (with any UTF-8 BOM stripped) and every
+// following record is passed to onRecord with the line it starts on, so records
+// spanning several lines keep accurate line numbers. With HeaderAbsent the first
+// record is passed to onRecord too and generated names are used as headers.
+// Returning an error from onRecord stops parsing.
+//
+// With Mmap the file is read through a memory map instead of read calls;
+// empty files, pipes and platforms without memory maps are read normally.
+// The bytes are still copied into the CSV reader's buffers, so this saves
+// system calls, not copies or memory. The file must not be truncated while
+// it is parsed.
+//
+// Files ending in .gz or .zst are decompressed while they are read.
+func (p *CSVParser) ParseFile(inputFile *InputFile, onRecord RecordHandler) error {
+	if IsCompressed(inputFile.Path) {
+		reader, err := OpenInput(inputFile.Path)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		return p.Parse(reader, inputFile, onRecord)
+	}
+
+	file, err := os.Open(inputFile.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if p.Mmap {
+		if data, unmap, err := mapFile(file); err == nil {
+			// Records are copied out of the map, so it can go once parsed
+			defer unmap()
+			return p.Parse(bytes.NewReader(data), inputFile, onRecord)
+		}
+	}
+
+	return p.Parse(file, inputFile, onRecord)
+}
+
+// Parse streams records from r as described for ParseFile. Malformed
+// records fail with a ParseError naming the file, line and column.
+func (p *CSVParser) Parse(r io.Reader, inputFile *InputFile, onRecord RecordHandler) error {
+	recorder := newLineRecorder(r)
+	reader := csv.NewReader(recorder)
+	reader.Comma = inputFile.Separator
+	reader.LazyQuotes = p.LazyQuotes
+	reader.TrimLeadingSpace = false
+
+	headers, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("file contains no data")
+	}
+	if err != nil {
+		return recorder.locate(err, inputFile.Path, p.LineOffset)
+	}
+	headers = stripBOM(headers)
+
+	if p.Header == HeaderAbsent {
+		inputFile.Headers = GenerateHeaders(len(headers))
+		inputFile.HasHeader = false
+		if err := onRecord(headers, 1+p.LineOffset); err != nil {
+			return err
+		}
+	} else {
+		inputFile.Headers = headers
+		inputFile.HasHeader = true
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return recorder.locate(err, inputFile.Path, p.LineOffset)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err := onRecord(record, line+p.LineOffset); err != nil {
+			return err
+		}
+	}
+}
+
+// stripBOM removes a UTF-8 byte order mark from the first header field
+func stripBOM(headers []string) []string {
+	if len(headers) > 0 {
+		if runes := []rune(headers[0]); len(runes) > 0 && runes[0] == '\uFEFF' {
+			headers[0] = string(runes[1:])
+		}
+	}
+	return headers
+}
@@ -1,7 +1,6 @@
 package models
 
 import (
-	"crypto/md5"
 	"fmt"
 	"strings"
 )
@@ -54,8 +53,50 @@ func (e *DataEntry) SetValue(columnName, value string) {
 	e.Values[columnName] = value
 }
 
+// IsTagsColumn determines if a column holds space-separated Anki tags
+func IsTagsColumn(header string) bool {
+	header = strings.ToLower(strings.TrimSpace(header))
+	return header == "tags" || header == "tag"
+}
+
+// MergeTags adds the tags of other that are missing from the tags columns of
+// e, keeping e's tags first. Tags are separated by whitespace; hierarchical
+// tags such as French::Verbs are single tags. Tags columns are merged in
+// name order.
+func (e *DataEntry) MergeTags(other *DataEntry) {
+	for _, column := range sortedKeys(other.Values) {
+		if !IsTagsColumn(column) {
+			continue
+		}
+		value := other.Values[column]
+
+		tags := strings.Fields(e.GetValue(column))
+		seen := make(map[string]bool)
+		for _, tag := range tags {
+			seen[tag] = true
+		}
+		added := false
+		for _, tag := range strings.Fields(value) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+				added = true
+			}
+		}
+		if added {
+			e.SetValue(column, strings.Join(tags, " "))
+		}
+	}
+}
+
 // GetHash returns a hash of all field values for duplicate detection
 func (e *DataEntry) GetHash() string {
+	return md5Hex(e.hashContent())
+}
+
+// hashContent returns all field values in a consistent order, as hashed by
+// GetHash
+func (e *DataEntry) hashContent() string {
 	// Create a consistent string representation of all values
 	var keys []string
 	for key := range e.Values {
@@ -76,9 +117,7 @@ func (e *DataEntry) GetHash() string {
 		parts = append(parts, fmt.Sprintf("%s:%s", key, e.Values[key]))
 	}
 
-	content := strings.Join(parts, "|")
-	hash := md5.Sum([]byte(content))
-	return fmt.Sprintf("%x", hash)
+	return strings.Join(parts, "|")
 }
 
 // IsExactDuplicate checks if this entry is an exact duplicate of another
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Ways of handling data URIs in fields
+const (
+	DataURIKeep    = "keep"    // Leave them in place
+	DataURIStrip   = "strip"   // Remove them, with any <img> tag they are the source of
+	DataURIExtract = "extract" // Save them as media files and reference those instead
+)
+
+// dataURIPattern matches base64 data URIs such as data:image/png;base64,iVBOR...
+var dataURIPattern = regexp.MustCompile(`data:([\w.+-]+/[\w.+-]+)?(?:;[\w.+-]+=[\w.+-]+)*;base64,([A-Za-z0-9+/]+=*)`)
+
+// imgDataURIPattern matches <img> tags whose source is a data URI
+var imgDataURIPattern = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']?data:[^>]*>`)
+
+// mediaExtensions maps image media types to the extension of extracted files
+var mediaExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+	"image/bmp":     ".bmp",
+}
+
+// DataURIStats counts the data URIs found in one field
+type DataURIStats struct {
+	Count int // Number of data URIs
+	Bytes int // Total length of the URIs in the field
+}
+
+// FindDataURIs counts the data URIs in value
+func FindDataURIs(value string) DataURIStats {
+	var stats DataURIStats
+	for _, uri := range dataURIPattern.FindAllString(value, -1) {
+		stats.Count++
+		stats.Bytes += len(uri)
+	}
+	return stats
+}
+
+// StripDataURIs removes the data URIs from value, dropping <img> tags whose
+// source is one
+func StripDataURIs(value string) string {
+	value = imgDataURIPattern.ReplaceAllStringFunc(value, func(tag string) string {
+		if dataURIPattern.MatchString(tag) {
+			return ""
+		}
+		return tag
+	})
+	return dataURIPattern.ReplaceAllString(value, "")
+}
+
+// MediaStore saves media files extracted from fields into Dir, named after a
+// hash of their content so identical images are stored once
+type MediaStore struct {
+	Dir   string
+	Saved int // Number of files written
+}
+
+// NewMediaStore creates a new MediaStore writing to dir
+func NewMediaStore(dir string) *MediaStore {
+	return &MediaStore{Dir: dir}
+}
+
+// ExtractDataURIs saves every data URI in value to the store and replaces it
+// with the file name, which is how Anki fields reference media
+func (s *MediaStore) ExtractDataURIs(value string) (string, error) {
+	var saveErr error
+	result := dataURIPattern.ReplaceAllStringFunc(value, func(uri string) string {
+		if saveErr != nil {
+			return uri
+		}
+		match := dataURIPattern.FindStringSubmatch(uri)
+		data, err := base64.StdEncoding.DecodeString(match[2])
+		if err != nil {
+			// Not valid base64 after all; leave it for the user to inspect
+			return uri
+		}
+		name, err := s.save(match[1], data)
+		if err != nil {
+			saveErr = err
+			return uri
+		}
+		return name
+	})
+	return result, saveErr
+}
+
+// save writes data to the store unless a file with the same content exists
+func (s *MediaStore) save(mediaType string, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	ext, ok := mediaExtensions[strings.ToLower(mediaType)]
+	if !ok {
+		ext = ".bin"
+	}
+	name := "ankiprep-" + hex.EncodeToString(sum[:8]) + ext
+
+	path := filepath.Join(s.Dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return name, nil
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create media directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("cannot save media file: %v", err)
+	}
+	s.Saved++
+	return name, nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import "strings"
+
+// DeckRoute fills the Deck column from another column, such as a chapter
+// number, so Anki files every note into its subdeck on import
+type DeckRoute struct {
+	Column string // Column naming the subdeck
+	Prefix string // Parent decks, e.g. "French::Course::"
+}
+
+// DeckName returns the deck of a note whose column holds value. Notes with
+// an empty value go to the deck named by the prefix alone.
+func (r *DeckRoute) DeckName(value string) string {
+	if value = strings.TrimSpace(value); value == "" {
+		return strings.TrimSuffix(r.Prefix, "::")
+	}
+	return r.Prefix + value
+}
+
+// Apply sets the Deck column of every entry. A preserved header row gets the
+// column name.
+func (r *DeckRoute) Apply(entries []*DataEntry) {
+	for _, entry := range entries {
+		if entry.LineNumber == 0 {
+			entry.SetValue(DeckColumn, DeckColumn)
+			continue
+		}
+		entry.SetValue(DeckColumn, r.DeckName(entry.GetValue(r.Column)))
+	}
+}
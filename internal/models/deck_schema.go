@@ -0,0 +1,224 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Column types a deck schema can declare
+const (
+	ColumnText   = "text"   // Any value
+	ColumnNumber = "number" // A decimal number (1,5 and 1.5 are both accepted)
+	ColumnCloze  = "cloze"  // Text with at least one {{cN::...}} deletion
+)
+
+// Typography settings a deck schema column can declare
+const (
+	TypographyNone   = "none"
+	TypographyFrench = "french"
+	TypographyQuotes = "smart-quotes"
+	TypographyBoth   = "french+smart-quotes"
+	TypographyCJK    = "cjk" // CJK spacing; combines with the others, as in french+cjk
+)
+
+// SchemaColumn declares one column of a deck
+type SchemaColumn struct {
+	Name       string `yaml:"name"`
+	Source     string `yaml:"source,omitempty"`     // Input column renamed to Name, when the input names it differently
+	Type       string `yaml:"type,omitempty"`       // ColumnText (default), ColumnNumber or ColumnCloze
+	Required   bool   `yaml:"required,omitempty"`   // Every row needs a value
+	Optional   bool   `yaml:"optional,omitempty"`   // Input files may lack the column
+	Typography string `yaml:"typography,omitempty"` // Overrides --french/--smart-quotes/--cjk-spacing for the column
+}
+
+// DeckSchema declares the expected structure of a deck, so the settings of a
+// deck can be reviewed and versioned as one file instead of a list of flags
+type DeckSchema struct {
+	NoteType string          `yaml:"note_type,omitempty"`
+	Columns  []*SchemaColumn `yaml:"columns"`
+}
+
+// LoadDeckSchema reads and validates a YAML deck schema
+func LoadDeckSchema(path string) (*DeckSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema file: %v", err)
+	}
+
+	schema := &DeckSchema{}
+	if err := yaml.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %v", path, err)
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %v", path, err)
+	}
+
+	return schema, nil
+}
+
+// Save validates the schema and writes it to path as YAML
+func (s *DeckSchema) Save(path string) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Validate checks that every column has a unique name and known settings
+func (s *DeckSchema) Validate() error {
+	if len(s.Columns) == 0 {
+		return fmt.Errorf("no columns declared")
+	}
+
+	seen := make(map[string]bool)
+	sources := make(map[string]bool)
+	for i, column := range s.Columns {
+		if column.Name == "" {
+			return fmt.Errorf("column %d has no name", i+1)
+		}
+		if seen[column.Name] {
+			return fmt.Errorf("column %q is declared twice", column.Name)
+		}
+		seen[column.Name] = true
+		if column.Source != "" {
+			if sources[column.Source] {
+				return fmt.Errorf("column %q is the source of two columns", column.Source)
+			}
+			sources[column.Source] = true
+		}
+
+		switch column.Type {
+		case "", ColumnText, ColumnNumber, ColumnCloze:
+		default:
+			return fmt.Errorf("column %q: unknown type %q (must be text, number or cloze)", column.Name, column.Type)
+		}
+		if _, ok := parseTypography(column.Typography); !ok {
+			return fmt.Errorf("column %q: unknown typography %q (must be none, or french, smart-quotes and cjk joined by +)", column.Name, column.Typography)
+		}
+		if column.Required && column.Optional {
+			return fmt.Errorf("column %q cannot be both required and optional", column.Name)
+		}
+	}
+	return nil
+}
+
+// ColumnNames returns the declared columns, in order
+func (s *DeckSchema) ColumnNames() []string {
+	names := make([]string, len(s.Columns))
+	for i, column := range s.Columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// RenameColumns renames the input columns named as the source of a schema
+// column, so a file with a "Word" column fills a column declared as
+// {name: Front, source: Word}. A file that already has a column with the
+// declared name keeps its own names.
+func (s *DeckSchema) RenameColumns(inputFiles []*InputFile) {
+	renames := make(map[string]string)
+	for _, column := range s.Columns {
+		if column.Source != "" {
+			renames[column.Source] = column.Name
+		}
+	}
+	if len(renames) == 0 {
+		return
+	}
+
+	for _, inputFile := range inputFiles {
+		present := make(map[string]bool)
+		for _, header := range inputFile.Headers {
+			present[header] = true
+		}
+
+		for i, header := range inputFile.Headers {
+			if name, ok := renames[header]; ok && !present[name] {
+				inputFile.Headers[i] = name
+				present[name] = true
+			}
+		}
+	}
+}
+
+// ExpectedColumns returns the declared columns input files must have
+func (s *DeckSchema) ExpectedColumns() []string {
+	var names []string
+	for _, column := range s.Columns {
+		if !column.Optional {
+			names = append(names, column.Name)
+		}
+	}
+	return names
+}
+
+// TypographyRules returns the typography of the columns that declare one
+func (s *DeckSchema) TypographyRules() map[string]TypographyRule {
+	rules := make(map[string]TypographyRule)
+	for _, column := range s.Columns {
+		if column.Typography != "" {
+			rules[column.Name], _ = parseTypography(column.Typography)
+		}
+	}
+	return rules
+}
+
+// CheckEntry returns an issue for every value of entry that breaks the
+// schema: a missing required value or a value of the wrong type
+func (s *DeckSchema) CheckEntry(entry *DataEntry) []*Issue {
+	var issues []*Issue
+	problem := func(format string, args ...interface{}) {
+		issues = append(issues, NewIssue(IssueSchema, entry.Source, entry.LineNumber, fmt.Sprintf(format, args...)))
+	}
+	for _, column := range s.Columns {
+		value := strings.TrimSpace(entry.GetValue(column.Name))
+		if value == "" {
+			if column.Required {
+				problem("required column %q is empty", column.Name)
+			}
+			continue
+		}
+
+		switch column.Type {
+		case ColumnNumber:
+			if _, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64); err != nil {
+				problem("column %q should be a number, got %q", column.Name, value)
+			}
+		case ColumnCloze:
+			if !clozeStartPattern.MatchString(value) {
+				problem("column %q should contain a cloze deletion, got %q", column.Name, value)
+			}
+		}
+	}
+	return issues
+}
+
+// parseTypography converts a typography setting, none or settings joined by
+// + (french+smart-quotes), into a TypographyRule
+func parseTypography(setting string) (TypographyRule, bool) {
+	var rule TypographyRule
+	if setting == "" || setting == TypographyNone {
+		return rule, true
+	}
+	for _, part := range strings.Split(setting, "+") {
+		switch part {
+		case TypographyFrench:
+			rule.French = true
+		case TypographyQuotes:
+			rule.SmartQuotes = true
+		case TypographyCJK:
+			rule.CJKSpacing = true
+		default:
+			return TypographyRule{}, false
+		}
+	}
+	return rule, true
+}
@@ -0,0 +1,76 @@
+package models
+
+import "fmt"
+
+// DedupeSnapshot records the order and duplicate keys of entries before
+// RemoveDuplicates runs, so the result can be checked against the guarantee
+// that the first occurrence of each entry is kept, in input order. Keys are
+// taken up front because MergeTags changes the entries that are kept.
+type DedupeSnapshot struct {
+	entries []*DataEntry
+	keys    map[*DataEntry]string
+}
+
+// SnapshotDedupe records entries as hasher compares them. Hashers that can
+// return their key are compared by key, so the check does not share the
+// detector's hash collisions.
+func SnapshotDedupe(entries []*DataEntry, hasher Hasher) *DedupeSnapshot {
+	snapshot := &DedupeSnapshot{
+		entries: append([]*DataEntry(nil), entries...),
+		keys:    make(map[*DataEntry]string, len(entries)),
+	}
+	for _, entry := range entries {
+		if keyer, ok := hasher.(KeyHasher); ok {
+			snapshot.keys[entry] = keyer.Key(entry)
+		} else {
+			snapshot.keys[entry] = hasher.Hash(entry)
+		}
+	}
+	return snapshot
+}
+
+// Verify returns an error describing the first way kept breaks the
+// guarantee: an entry kept out of input order, a kept entry that duplicates
+// an earlier one, or the first entry of its kind removed
+func (s *DedupeSnapshot) Verify(kept []*DataEntry) error {
+	position := make(map[*DataEntry]int, len(s.entries))
+	for i, entry := range s.entries {
+		position[entry] = i
+	}
+
+	isKept := make(map[*DataEntry]bool, len(kept))
+	previous := -1
+	for _, entry := range kept {
+		i, ok := position[entry]
+		if !ok {
+			return fmt.Errorf("entry %s was kept but is not in the input", entryLocation(entry))
+		}
+		if i <= previous {
+			return fmt.Errorf("entry %s is kept after %s, which came later in the input",
+				entryLocation(entry), entryLocation(s.entries[previous]))
+		}
+		previous = i
+		isKept[entry] = true
+	}
+
+	first := make(map[string]*DataEntry)
+	for _, entry := range s.entries {
+		key := s.keys[entry]
+		original, seen := first[key]
+		switch {
+		case !seen && !isKept[entry]:
+			return fmt.Errorf("entry %s was removed but is the first of its kind", entryLocation(entry))
+		case seen && isKept[entry]:
+			return fmt.Errorf("entry %s was kept but duplicates %s, which came first",
+				entryLocation(entry), entryLocation(original))
+		case !seen:
+			first[key] = entry
+		}
+	}
+	return nil
+}
+
+// entryLocation returns where entry was read, as file:line
+func entryLocation(entry *DataEntry) EntryLocation {
+	return EntryLocation{Source: entry.Source, Line: entry.LineNumber}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Dictionary is a set of known words used to flag likely typos
+type Dictionary struct {
+	words map[string]bool
+}
+
+// htmlTagPattern matches HTML tags so markup is not spell-checked
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// NewDictionary creates an empty Dictionary instance
+func NewDictionary() *Dictionary {
+	return &Dictionary{
+		words: make(map[string]bool),
+	}
+}
+
+// LoadWordList adds the words of a plain wordlist (one word per line) or a
+// hunspell .dic file (leading word count, affix flags after "/") to the dictionary.
+// Affix rules are not expanded, so inflected forms must be listed explicitly.
+func (d *Dictionary) LoadWordList(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read dictionary: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// hunspell .dic files start with the approximate word count
+		if first {
+			first = false
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if slash := strings.Index(line, "/"); slash >= 0 {
+			line = line[:slash]
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			d.AddWord(fields[0])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("cannot read dictionary %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// AddWord adds a single word to the dictionary
+func (d *Dictionary) AddWord(word string) {
+	d.words[word] = true
+}
+
+// Size returns the number of words in the dictionary
+func (d *Dictionary) Size() int {
+	return len(d.words)
+}
+
+// Contains reports whether a word is known, accepting a lowercase match so
+// sentence-initial capitals are not flagged
+func (d *Dictionary) Contains(word string) bool {
+	return d.words[word] || d.words[strings.ToLower(word)]
+}
+
+// UnknownWords returns the words of text missing from the dictionary, in order
+// of first appearance. HTML tags, cloze markers and tokens containing digits are ignored.
+func (d *Dictionary) UnknownWords(text string) []string {
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = clozeStartPattern.ReplaceAllString(text, " ")
+
+	seen := make(map[string]bool)
+	var unknown []string
+
+	for _, word := range splitWords(text) {
+		if seen[word] || d.Contains(word) {
+			continue
+		}
+		seen[word] = true
+		unknown = append(unknown, word)
+	}
+
+	return unknown
+}
+
+// splitWords breaks text into words of two or more letters. Apostrophes split
+// elided forms ("l'homme" gives "homme") and hyphens are kept inside words.
+func splitWords(text string) []string {
+	var words []string
+
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	}) {
+		token = strings.Trim(token, "-")
+		if len([]rune(token)) < 2 || strings.IndexFunc(token, unicode.IsDigit) >= 0 {
+			continue
+		}
+		words = append(words, token)
+	}
+
+	return words
+}
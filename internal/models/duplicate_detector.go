@@ -0,0 +1,468 @@
+package models
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Duplicate detection strategies accepted by NewHasher
+const (
+	DedupeExact       = "exact"        // Every field identical (case-sensitive)
+	DedupeNormalized  = "normalized"   // Fields equal ignoring case and whitespace
+	DedupeKeyColumns  = "key-columns"  // Only the key columns identical
+	DedupeFuzzy       = "fuzzy"        // Same words ignoring order, punctuation, markup and accents
+	DedupeMergeFields = "merge-fields" // Every field identical except the merged columns
+)
+
+// Hasher computes the key under which entries are considered duplicates: two
+// entries are duplicates when their hashes are equal
+type Hasher interface {
+	Hash(entry *DataEntry) string
+}
+
+// NewHasher returns the Hasher for a strategy name; columns are required by
+// (and only used for) DedupeKeyColumns, which compares only them, and
+// DedupeMergeFields, which compares every column but them
+func NewHasher(strategy string, columns []string) (Hasher, error) {
+	switch strategy {
+	case DedupeExact:
+		return ExactHasher{}, nil
+	case DedupeNormalized:
+		return NormalizedHasher{}, nil
+	case DedupeKeyColumns:
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("dedupe strategy %q needs at least one key column", strategy)
+		}
+		return KeyColumnsHasher{Columns: columns}, nil
+	case DedupeFuzzy:
+		return FuzzyHasher{}, nil
+	case DedupeMergeFields:
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("dedupe strategy %q needs at least one merged column", strategy)
+		}
+		return MergeFieldsHasher{Merged: columns}, nil
+	default:
+		return nil, fmt.Errorf("unknown dedupe strategy %q (available: %s, %s, %s, %s, %s)",
+			strategy, DedupeExact, DedupeNormalized, DedupeKeyColumns, DedupeFuzzy, DedupeMergeFields)
+	}
+}
+
+// KeyHasher is a Hasher that can return the text it hashes, so the detector
+// can hash it with another Digest and compare keys to rule out collisions
+type KeyHasher interface {
+	Hasher
+	Key(entry *DataEntry) string
+}
+
+// ExactHasher treats entries as duplicates only when every field matches exactly
+type ExactHasher struct{}
+
+// Hash returns the entry's content hash
+func (ExactHasher) Hash(entry *DataEntry) string {
+	return entry.GetHash()
+}
+
+// Key returns every field of the entry, as hashed by GetHash
+func (ExactHasher) Key(entry *DataEntry) string {
+	return entry.hashContent()
+}
+
+// NormalizedHasher ignores case and differences in whitespace
+type NormalizedHasher struct{}
+
+// Hash returns a hash of all fields after lower-casing and collapsing whitespace
+func (h NormalizedHasher) Hash(entry *DataEntry) string {
+	return md5Hex(h.Key(entry))
+}
+
+// Key returns all fields lower-cased and with whitespace collapsed
+func (NormalizedHasher) Key(entry *DataEntry) string {
+	return joinFields(entry, sortedKeys(entry.Values), normalizeForComparison)
+}
+
+// KeyColumnsHasher compares only the listed columns, so notes with the same
+// front but different extra fields count as duplicates
+type KeyColumnsHasher struct {
+	Columns []string
+}
+
+// Hash returns a hash of the key columns' exact values
+func (h KeyColumnsHasher) Hash(entry *DataEntry) string {
+	return md5Hex(h.Key(entry))
+}
+
+// Key returns the key columns' exact values
+func (h KeyColumnsHasher) Key(entry *DataEntry) string {
+	return joinFields(entry, h.Columns, func(value string) string { return value })
+}
+
+// MergeFieldsHasher compares every column except the merged ones, so notes
+// that only differ by, say, their example sentences are duplicates whose
+// examples the detector can combine (see DuplicateDetector.MergeFields)
+type MergeFieldsHasher struct {
+	Merged []string
+}
+
+// Hash returns a hash of the exact values of the other columns
+func (h MergeFieldsHasher) Hash(entry *DataEntry) string {
+	return md5Hex(h.Key(entry))
+}
+
+// Key returns the exact values of every column except the merged ones
+func (h MergeFieldsHasher) Key(entry *DataEntry) string {
+	var columns []string
+	for _, column := range sortedKeys(entry.Values) {
+		if !slices.Contains(h.Merged, column) {
+			columns = append(columns, column)
+		}
+	}
+	return joinFields(entry, columns, func(value string) string { return value })
+}
+
+// FuzzyHasher treats entries as duplicates when every field holds the same set
+// of words, ignoring order, punctuation, HTML markup, case and common accents
+type FuzzyHasher struct{}
+
+// Hash returns a hash of each field's sorted, de-duplicated words
+func (h FuzzyHasher) Hash(entry *DataEntry) string {
+	return md5Hex(h.Key(entry))
+}
+
+// Key returns each field's sorted, de-duplicated words
+func (FuzzyHasher) Key(entry *DataEntry) string {
+	return joinFields(entry, sortedKeys(entry.Values), fuzzyKey)
+}
+
+// Hash algorithms accepted by NewDigest
+const (
+	HashMD5    = "md5"    // The default, as used by Hasher.Hash
+	HashFNV    = "fnv"    // 64-bit FNV-1a: fast, fine for small files
+	HashXXHash = "xxhash" // 64-bit xxHash: fastest on large files
+	HashSHA256 = "sha256" // Slowest, but collisions are practically impossible
+)
+
+// Digest hashes the key of an entry into the value duplicates are found by
+type Digest func(key string) string
+
+// NewDigest returns the Digest for a hash algorithm name
+func NewDigest(algorithm string) (Digest, error) {
+	switch algorithm {
+	case HashMD5:
+		return md5Hex, nil
+	case HashFNV:
+		return func(key string) string {
+			hash := fnv.New64a()
+			hash.Write([]byte(key))
+			return string(hash.Sum(nil))
+		}, nil
+	case HashXXHash:
+		return func(key string) string {
+			return strconv.FormatUint(xxhash.Sum64String(key), 16)
+		}, nil
+	case HashSHA256:
+		return func(key string) string {
+			sum := sha256.Sum256([]byte(key))
+			return string(sum[:])
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown hash algorithm %q (available: %s, %s, %s, %s)",
+			algorithm, HashMD5, HashFNV, HashXXHash, HashSHA256)
+	}
+}
+
+// md5Hex returns the hex MD5 digest of key
+func md5Hex(key string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(key)))
+}
+
+// accentFolder maps common accented Latin letters to their base letter
+var accentFolder = strings.NewReplacer(
+	"à", "a", "â", "a", "ä", "a", "á", "a", "ã", "a",
+	"ç", "c",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"î", "i", "ï", "i", "í", "i", "ì", "i",
+	"ñ", "n",
+	"ô", "o", "ö", "o", "ó", "o", "ò", "o", "õ", "o",
+	"ù", "u", "û", "u", "ü", "u", "ú", "u",
+	"ÿ", "y",
+	"œ", "oe", "æ", "ae",
+)
+
+// fuzzyKey reduces a value to its sorted set of folded words
+func fuzzyKey(value string) string {
+	value = htmlTagPattern.ReplaceAllString(value, " ")
+	value = accentFolder.Replace(strings.ToLower(value))
+
+	seen := make(map[string]bool)
+	var words []string
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// joinFields joins the normalized values of the given columns in order
+func joinFields(entry *DataEntry, columns []string, normalize func(string) string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = fmt.Sprintf("%s:%s", column, normalize(entry.GetValue(column)))
+	}
+	return strings.Join(parts, "|")
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DuplicateSources counts the duplicates found in one file of entries first
+// seen in another (or the same) file
+type DuplicateSources struct {
+	Original  string `json:"original"`  // File holding the kept entries
+	Duplicate string `json:"duplicate"` // File holding the removed duplicates
+	Count     int    `json:"count"`     // Number of duplicates
+}
+
+// CrossFile returns true if the duplicates come from a different file than the originals
+func (s *DuplicateSources) CrossFile() bool {
+	return s.Original != s.Duplicate
+}
+
+// EntryLocation is the file and line an entry was read from
+type EntryLocation struct {
+	Source string `json:"source"` // File path
+	Line   int    `json:"line"`   // Line the record starts on
+}
+
+// String returns the location as file:line
+func (l EntryLocation) String() string {
+	return fmt.Sprintf("%s:%d", l.Source, l.Line)
+}
+
+// DuplicateGroup is a kept entry together with the duplicates of it that
+// were removed
+type DuplicateGroup struct {
+	Kept    EntryLocation   `json:"kept"`    // Entry written to the output
+	Removed []EntryLocation `json:"removed"` // Duplicates removed, in input order
+}
+
+// RemovedRanges returns the removed locations grouped by file, with runs of
+// consecutive lines shown as ranges, e.g. "a.csv:5, b.csv:2-4, 9"
+func (g *DuplicateGroup) RemovedRanges() string {
+	var sources []string
+	lines := make(map[string][]int)
+	for _, removed := range g.Removed {
+		if _, ok := lines[removed.Source]; !ok {
+			sources = append(sources, removed.Source)
+		}
+		lines[removed.Source] = append(lines[removed.Source], removed.Line)
+	}
+
+	parts := make([]string, len(sources))
+	for i, source := range sources {
+		parts[i] = source + ":" + lineRanges(lines[source])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lineRanges formats ascending line numbers with consecutive runs collapsed,
+// e.g. "2-4, 9". Repeated lines, such as rows exploded from one record, are
+// shown once.
+func lineRanges(lines []int) string {
+	var ranges []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && (lines[j+1] == lines[j] || lines[j+1] == lines[j]+1) {
+			j++
+		}
+		if lines[j] > lines[i] {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		} else {
+			ranges = append(ranges, strconv.Itoa(lines[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// DuplicateDetector finds duplicate entries using an injected Hasher
+type DuplicateDetector struct {
+	MergeTags   bool        // Union the tags of removed duplicates into the kept entry
+	MergeFields []*ItemList // Columns whose items missing from the kept entry are added from removed duplicates
+
+	hasher     Hasher
+	digest     Digest
+	verify     bool
+	collisions int
+	seen       map[string][]*DataEntry
+	sources    []*DuplicateSources
+	groups     []*DuplicateGroup
+	groupOf    map[*DataEntry]*DuplicateGroup
+}
+
+// NewDuplicateDetector creates a new DuplicateDetector using hasher
+func NewDuplicateDetector(hasher Hasher) *DuplicateDetector {
+	return &DuplicateDetector{
+		hasher:  hasher,
+		seen:    make(map[string][]*DataEntry),
+		groupOf: make(map[*DataEntry]*DuplicateGroup),
+	}
+}
+
+// NewDuplicateDetectorWithHash creates a DuplicateDetector that hashes the
+// keys of hasher with the named algorithm (see NewDigest). With verify, entries
+// with the same hash are only duplicates when their keys match too (for
+// ExactHasher, when IsExactDuplicate says so), which rules out hash collisions
+// on very large inputs.
+func NewDuplicateDetectorWithHash(hasher Hasher, algorithm string, verify bool) (*DuplicateDetector, error) {
+	digest, err := NewDigest(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := hasher.(KeyHasher); !ok && algorithm != HashMD5 {
+		return nil, fmt.Errorf("hash algorithm %q is not supported by this dedupe strategy", algorithm)
+	}
+
+	detector := NewDuplicateDetector(hasher)
+	if _, ok := hasher.(KeyHasher); ok {
+		detector.digest = digest
+	}
+	detector.verify = verify
+	return detector, nil
+}
+
+// Check returns the earlier entry that entry duplicates, or nil if entry is the
+// first of its kind (it is then remembered for later checks)
+func (d *DuplicateDetector) Check(entry *DataEntry) *DataEntry {
+	key := d.hash(entry)
+	for _, original := range d.seen[key] {
+		if d.verify && !d.sameKey(original, entry) {
+			continue
+		}
+		d.countSources(original.Source, entry.Source)
+		d.addToGroup(original, entry)
+		return original
+	}
+	if len(d.seen[key]) > 0 {
+		d.collisions++
+	}
+	d.seen[key] = append(d.seen[key], entry)
+	return nil
+}
+
+// Collisions returns how many entries had the hash of an earlier entry but
+// were told apart by verification
+func (d *DuplicateDetector) Collisions() int {
+	return d.collisions
+}
+
+// hash returns the hash entry is looked up by
+func (d *DuplicateDetector) hash(entry *DataEntry) string {
+	if d.digest != nil {
+		return d.digest(d.hasher.(KeyHasher).Key(entry))
+	}
+	return d.hasher.Hash(entry)
+}
+
+// sameKey reports whether two entries with the same hash really are
+// duplicates under the hasher
+func (d *DuplicateDetector) sameKey(a, b *DataEntry) bool {
+	keyer, ok := d.hasher.(KeyHasher)
+	if _, exact := d.hasher.(ExactHasher); exact || !ok {
+		return a.IsExactDuplicate(b)
+	}
+	return keyer.Key(a) == keyer.Key(b)
+}
+
+// Sources returns how many duplicates were found per pair of files, in the
+// order each pair was first seen
+func (d *DuplicateDetector) Sources() []*DuplicateSources {
+	return d.sources
+}
+
+// Groups returns every kept entry that had duplicates removed, with where
+// they were read, in the order the first duplicate of each was found
+func (d *DuplicateDetector) Groups() []*DuplicateGroup {
+	return d.groups
+}
+
+func (d *DuplicateDetector) addToGroup(original, duplicate *DataEntry) {
+	group, ok := d.groupOf[original]
+	if !ok {
+		group = &DuplicateGroup{Kept: EntryLocation{Source: original.Source, Line: original.LineNumber}}
+		d.groupOf[original] = group
+		d.groups = append(d.groups, group)
+	}
+	group.Removed = append(group.Removed, EntryLocation{Source: duplicate.Source, Line: duplicate.LineNumber})
+}
+
+func (d *DuplicateDetector) countSources(original, duplicate string) {
+	for _, sources := range d.sources {
+		if sources.Original == original && sources.Duplicate == duplicate {
+			sources.Count++
+			return
+		}
+	}
+	d.sources = append(d.sources, &DuplicateSources{Original: original, Duplicate: duplicate, Count: 1})
+}
+
+// RemoveDuplicates returns the entries that are not duplicates of an earlier
+// entry, keeping the input order: the first occurrence of each entry is the
+// one kept, whatever the hasher, and entries are checked in a single pass so
+// the result never depends on map iteration order. With MergeTags, the tags
+// of each removed duplicate are added to the entry it duplicates, and so are
+// the items of the MergeFields columns.
+func (d *DuplicateDetector) RemoveDuplicates(entries []*DataEntry) []*DataEntry {
+	var unique []*DataEntry
+	for _, entry := range entries {
+		original := d.Check(entry)
+		if original == nil {
+			unique = append(unique, entry)
+			continue
+		}
+		if d.MergeTags {
+			original.MergeTags(entry)
+		}
+		for _, list := range d.MergeFields {
+			value := original.GetValue(list.Column)
+			if merged := list.Merge(value, entry.GetValue(list.Column)); merged != value {
+				original.SetValue(list.Column, merged)
+			}
+		}
+	}
+	return unique
+}
+
+// DetectDuplicatesAcrossFiles returns, per pair of different files, how many
+// entries of the second file duplicate entries of the first under hasher
+func DetectDuplicatesAcrossFiles(entries []*DataEntry, hasher Hasher) []*DuplicateSources {
+	detector := NewDuplicateDetector(hasher)
+	detector.RemoveDuplicates(entries)
+
+	var crossFile []*DuplicateSources
+	for _, sources := range detector.Sources() {
+		if sources.CrossFile() {
+			crossFile = append(crossFile, sources)
+		}
+	}
+	return crossFile
+}
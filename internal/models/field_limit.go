@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Actions for fields over the size limit
+const (
+	OversizeTruncate = "truncate" // Cut the field to the limit
+	OversizeSkip     = "skip"     // Drop the whole entry
+	OversizeError    = "error"    // Fail the run
+)
+
+// OversizeField is a field larger than the field size limit
+type OversizeField struct {
+	Entry  *DataEntry
+	Column string
+	Size   int // Size in bytes
+}
+
+// String describes the field as "file:line: column X is N bytes"
+func (f *OversizeField) String() string {
+	return fmt.Sprintf("%s:%d: column %s is %d bytes", f.Entry.Source, f.Entry.LineNumber, f.Column, f.Size)
+}
+
+// FindOversizeFields returns the fields of entries larger than maxBytes, in
+// entry and column order. A preserved header row is not checked.
+func FindOversizeFields(entries []*DataEntry, headers []string, maxBytes int) []*OversizeField {
+	var oversize []*OversizeField
+	for _, entry := range entries {
+		if entry.LineNumber == 0 {
+			continue
+		}
+		for _, header := range headers {
+			if size := len(entry.GetValue(header)); size > maxBytes {
+				oversize = append(oversize, &OversizeField{Entry: entry, Column: header, Size: size})
+			}
+		}
+	}
+	return oversize
+}
+
+// TruncateUTF8 cuts value to at most maxBytes without splitting a character
+func TruncateUTF8(value string, maxBytes int) string {
+	if len(value) <= maxBytes {
+		return value
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
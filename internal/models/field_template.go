@@ -0,0 +1,45 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TemplatePlaceholder marks where the cell value is inserted in a field template
+const TemplatePlaceholder = "{value}"
+
+// FieldTemplate wraps the values of a column in an HTML snippet
+type FieldTemplate struct {
+	Column   string // Column whose values are wrapped
+	Template string // HTML snippet containing TemplatePlaceholder
+}
+
+// NewFieldTemplate creates a new FieldTemplate instance
+func NewFieldTemplate(column, template string) *FieldTemplate {
+	return &FieldTemplate{
+		Column:   column,
+		Template: template,
+	}
+}
+
+// Validate checks if the field template meets all validation requirements
+func (t *FieldTemplate) Validate() error {
+	if strings.TrimSpace(t.Column) == "" {
+		return fmt.Errorf("template column name cannot be empty")
+	}
+
+	if !strings.Contains(t.Template, TemplatePlaceholder) {
+		return fmt.Errorf("template for column %q must contain %s", t.Column, TemplatePlaceholder)
+	}
+
+	return nil
+}
+
+// Apply inserts the value into the template; empty values are left empty
+// so Anki does not render a stray wrapper for missing fields
+func (t *FieldTemplate) Apply(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ReplaceAll(t.Template, TemplatePlaceholder, value)
+}
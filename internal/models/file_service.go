@@ -0,0 +1,198 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaleTempAge is how old a leftover temporary file must be before
+// RemoveStaleTempFiles deletes it
+const StaleTempAge = 7 * 24 * time.Hour
+
+// tempPattern matches the names of temporary files created by FileService
+const tempPattern = "ankiprep-*.tmp"
+
+// FileService creates output files through temporary files so an interrupted
+// run never leaves a partially written output behind. It is safe for concurrent
+// use, so a signal handler can clean up while processing is still running.
+type FileService struct {
+	KeepTemp bool         // Leave temporary files of failed runs in place for inspection
+	Retry    *RetryPolicy // Attempts at writing an output file; nil makes one
+	Verify   bool         // Read written files back and compare their size and SHA-256
+
+	MaxExtract int64 // Bytes ExtractZip writes for one archive at most; 0 means MaxZipExtract
+
+	mu        sync.Mutex
+	tempFiles []string // Temporary files not yet committed or removed
+	tempDirs  []string // Directories of files extracted from archives
+}
+
+// NewFileService creates a new FileService instance
+func NewFileService() *FileService {
+	return &FileService{}
+}
+
+// TempPath returns the temporary file used while writing target:
+// ankiprep-<target name>-<process id>.tmp in the same directory, so Commit can
+// rename it atomically and a kept file shows which output and run it belongs to
+func TempPath(target string) string {
+	name := fmt.Sprintf("ankiprep-%s-%d.tmp", filepath.Base(target), os.Getpid())
+	return filepath.Join(filepath.Dir(target), name)
+}
+
+// reservedNames are device names Windows refuses as file names, whatever the extension
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// IsReservedFileName returns true if name (a file name, not a path) is a
+// Windows device name such as CON or aux.csv
+func IsReservedFileName(name string) bool {
+	stem := strings.ToUpper(name)
+	if i := strings.Index(stem, "."); i >= 0 {
+		stem = stem[:i]
+	}
+	return reservedNames[strings.TrimRight(stem, " ")]
+}
+
+// CheckOutputPath returns an actionable error for output paths the platform
+// cannot create, such as Windows device names
+func CheckOutputPath(path string) error {
+	if checkReservedNames && IsReservedFileName(filepath.Base(path)) {
+		return fmt.Errorf("cannot write %s: %q is a reserved device name on Windows; choose another output name with -o", path, filepath.Base(path))
+	}
+	return nil
+}
+
+// CreateTemp creates (or truncates) the temporary file for target
+func (s *FileService) CreateTemp(target string) (*os.File, error) {
+	if err := CheckOutputPath(target); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(platformPath(TempPath(target)), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if !slices.Contains(s.tempFiles, file.Name()) {
+		s.tempFiles = append(s.tempFiles, file.Name())
+	}
+	s.mu.Unlock()
+	return file, nil
+}
+
+// UseNetworkSettings prepares the service for an output on a network
+// filesystem, where writes can fail or be cut short now and then: writing is
+// retried with NetworkRetryPolicy and every file is read back to verify it
+func (s *FileService) UseNetworkSettings() {
+	s.Retry = NetworkRetryPolicy()
+	s.Verify = true
+}
+
+// VerifyFile reads the file at path back and returns an error unless it has
+// the given size and SHA-256 digest, catching outputs that a network
+// filesystem truncated or failed to write
+func (s *FileService) VerifyFile(path string, size int64, digest []byte) error {
+	file, err := os.Open(platformPath(path))
+	if err != nil {
+		return fmt.Errorf("cannot verify %s: %v", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	read, err := io.Copy(hash, file)
+	if err != nil {
+		return fmt.Errorf("cannot verify %s: %v", path, err)
+	}
+	if read != size {
+		return fmt.Errorf("verifying %s: wrote %d bytes but read back %d", path, size, read)
+	}
+	if !bytes.Equal(hash.Sum(nil), digest) {
+		return fmt.Errorf("verifying %s: the content read back differs from what was written", path)
+	}
+	return nil
+}
+
+// Commit moves a closed temporary file created by CreateTemp to target
+func (s *FileService) Commit(tempPath, target string) error {
+	if err := os.Rename(platformPath(tempPath), platformPath(target)); err != nil {
+		return err
+	}
+	s.forget(tempPath)
+	return nil
+}
+
+// CleanupTempFiles removes every temporary file that was not committed and
+// returns how many were removed. With KeepTemp nothing is removed.
+func (s *FileService) CleanupTempFiles() int {
+	if s.KeepTemp {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for _, path := range s.tempFiles {
+		if err := os.Remove(path); err == nil {
+			removed++
+		}
+	}
+	s.tempFiles = nil
+	return removed
+}
+
+// TempFiles returns the temporary files that are currently tracked
+func (s *FileService) TempFiles() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.tempFiles...)
+}
+
+// RemoveStaleTempFiles deletes temporary files in dir left by earlier runs
+// (crashes or --keep-temp) that were last modified more than maxAge ago, and
+// returns how many were removed
+func (s *FileService) RemoveStaleTempFiles(dir string, maxAge time.Duration) (int, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, tempPattern))
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (s *FileService) forget(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, tracked := range s.tempFiles {
+		if tracked == path {
+			s.tempFiles = append(s.tempFiles[:i], s.tempFiles[i+1:]...)
+			return
+		}
+	}
+}
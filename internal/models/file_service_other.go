@@ -0,0 +1,11 @@
+//go:build !windows
+
+package models
+
+// checkReservedNames is off where CON, NUL and friends are ordinary file names
+const checkReservedNames = false
+
+// platformPath returns path unchanged; long paths need no special form here
+func platformPath(path string) string {
+	return path
+}
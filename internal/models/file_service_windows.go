@@ -0,0 +1,32 @@
+//go:build windows
+
+package models
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// checkReservedNames makes CheckOutputPath reject device names such as CON
+const checkReservedNames = true
+
+// maxPath is the classic Windows path length limit (MAX_PATH minus the NUL)
+const maxPath = 259
+
+// platformPath returns path in extended-length form (\\?\C:\... or
+// \\?\UNC\server\share\...) when it is too long for the classic Win32 APIs, so
+// deep OneDrive folders keep working even with relative paths
+func platformPath(path string) string {
+	if len(path) <= maxPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + strings.TrimPrefix(abs, `\\`)
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Fix is a machine-applicable change to an input file that resolves an
+// Issue. Safe fixes only change what the issue describes and leave a file
+// that reads as intended; --apply-fixes applies them to the text of the file
+// as it is read, never to the file itself.
+type Fix struct {
+	Description string     `json:"description"` // What the fix changes, such as "remove the stray ',' at the end of the header"
+	Safe        bool       `json:"safe"`        // Whether --apply-fixes may apply it
+	Applied     bool       `json:"applied"`     // Whether the run applied it
+	Edits       []TextEdit `json:"edits"`
+}
+
+// TextEdit replaces Length bytes of a file at byte Offset with Text. Line and
+// Column (1-based, in bytes) locate Offset for people and editors.
+type TextEdit struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	Text   string `json:"text"`
+}
+
+// ApplyFixes returns data with the edits of fixes made, and marks the fixes
+// applied. Edits that overlap are an error, and data is left unchanged.
+func ApplyFixes(data []byte, fixes []*Fix) ([]byte, error) {
+	var edits []TextEdit
+	for _, fix := range fixes {
+		edits = append(edits, fix.Edits...)
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Offset < edits[j].Offset })
+
+	var result []byte
+	pos := 0
+	for _, edit := range edits {
+		if edit.Offset < pos || edit.Offset+edit.Length > len(data) {
+			return data, fmt.Errorf("fix at line %d, column %d overlaps another fix or the end of the file", edit.Line, edit.Column)
+		}
+		result = append(result, data[pos:edit.Offset]...)
+		result = append(result, edit.Text...)
+		pos = edit.Offset + edit.Length
+	}
+	result = append(result, data[pos:]...)
+
+	for _, fix := range fixes {
+		fix.Applied = true
+	}
+	return result, nil
+}
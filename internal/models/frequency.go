@@ -0,0 +1,114 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// FrequencyList ranks words by their position in a frequency wordlist, most
+// frequent first
+type FrequencyList struct {
+	ranks map[string]int
+}
+
+// NewFrequencyList creates an empty FrequencyList instance
+func NewFrequencyList() *FrequencyList {
+	return &FrequencyList{
+		ranks: make(map[string]int),
+	}
+}
+
+// LoadFrequencyList reads a wordlist ordered from most to least frequent, one
+// word per line. Anything after the first field is ignored, so "word count"
+// lists such as the OpenSubtitles frequency lists work as they are. Blank
+// lines and lines starting with # are skipped.
+func LoadFrequencyList(path string) (*FrequencyList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read wordlist: %v", err)
+	}
+	defer file.Close()
+
+	list := NewFrequencyList()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list.Add(strings.Fields(line)[0])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read wordlist %s: %v", path, err)
+	}
+
+	return list, nil
+}
+
+// frequencyReplacer undoes the typography of a value: curly quotes become
+// straight and French narrow and no-break spaces become spaces
+var frequencyReplacer = strings.NewReplacer(
+	"\u2019", "'", "\u2018", "'", "\u201C", `"`, "\u201D", `"`,
+	"\u202F", " ", "\u00A0", " ",
+)
+
+// frequencyKey is the form in which words and cell values are compared:
+// without HTML markup or typography, in lower case
+func frequencyKey(value string) string {
+	value = htmlTagPattern.ReplaceAllString(value, "")
+	return strings.ToLower(strings.TrimSpace(frequencyReplacer.Replace(value)))
+}
+
+// Add gives word the next rank, unless it is already ranked
+func (f *FrequencyList) Add(word string) {
+	word = frequencyKey(word)
+	if _, exists := f.ranks[word]; !exists {
+		f.ranks[word] = len(f.ranks) + 1
+	}
+}
+
+// Size returns the number of ranked words
+func (f *FrequencyList) Size() int {
+	return len(f.ranks)
+}
+
+// Rank returns the 1-based frequency rank of a cell value, ignoring case,
+// HTML markup and typography (so c’est is ranked as c'est), and whether the
+// value is in the list at all
+func (f *FrequencyList) Rank(value string) (int, bool) {
+	rank, ok := f.ranks[frequencyKey(value)]
+	return rank, ok
+}
+
+// SortByFrequency stably sorts the entries by the rank of their value in
+// column, most frequent first. Values not in the list sort last in their
+// input order, and a preserved header row stays first. It returns the number
+// of entries whose value is not in the list.
+func SortByFrequency(entries []*DataEntry, column string, list *FrequencyList) int {
+	unknown := 0
+	ranks := make(map[*DataEntry]int, len(entries))
+	for _, entry := range entries {
+		if entry.LineNumber == 0 {
+			continue
+		}
+		rank, ok := list.Rank(entry.GetValue(column))
+		if !ok {
+			rank = list.Size() + 1
+			unknown++
+		}
+		ranks[entry] = rank
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].LineNumber == 0 || entries[j].LineNumber == 0 {
+			return entries[i].LineNumber == 0 && entries[j].LineNumber != 0
+		}
+		return ranks[entries[i]] < ranks[entries[j]]
+	})
+
+	return unknown
+}
@@ -0,0 +1,125 @@
+package models
+
+import "strings"
+
+// headerKey is the form in which similar header names compare equal
+func headerKey(header string) string {
+	return strings.ToLower(strings.TrimSpace(header))
+}
+
+// SimilarHeaders returns groups of header names from the input files that
+// differ only by case or surrounding whitespace, such as "Back" and "back ".
+// Each group lists the names in first-seen order.
+func SimilarHeaders(inputFiles []*InputFile) [][]string {
+	groups := make(map[string][]string)
+	var keys []string
+	for _, header := range MergeHeaders(inputFiles) {
+		key := headerKey(header)
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], header)
+	}
+
+	var similar [][]string
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			similar = append(similar, groups[key])
+		}
+	}
+	return similar
+}
+
+// CoalesceHeaders renames similar headers (see SimilarHeaders) to the first
+// spelling seen, so they merge into one column. A file that already has a
+// column with that spelling keeps its own names, since two of its columns
+// cannot become one.
+func CoalesceHeaders(inputFiles []*InputFile) {
+	canonical := make(map[string]string)
+	for _, header := range MergeHeaders(inputFiles) {
+		if _, exists := canonical[headerKey(header)]; !exists {
+			canonical[headerKey(header)] = header
+		}
+	}
+
+	for _, inputFile := range inputFiles {
+		present := make(map[string]bool)
+		for _, header := range inputFile.Headers {
+			present[header] = true
+		}
+
+		for i, header := range inputFile.Headers {
+			name := canonical[headerKey(header)]
+			if name != header && !present[name] {
+				inputFile.Headers[i] = name
+				present[name] = true
+			}
+		}
+	}
+}
+
+// headerAliases maps common names of the Front, Back and Tags columns in
+// other languages, compared as by headerKey, to the names Anki uses. Words
+// that are as often the name of another column (avant, retro, dorso) are
+// left out.
+var headerAliases = map[string]string{
+	// Front
+	"recto": "Front", "frente": "Front", "anverso": "Front",
+	"vorderseite": "Front", "fronte": "Front", "voorkant": "Front", "przód": "Front",
+	"表": "Front", "おもて": "Front", "正面": "Front", "앞면": "Front",
+	// Back
+	"verso": "Back", "arrière": "Back", "reverso": "Back",
+	"rückseite": "Back", "achterkant": "Back", "tył": "Back",
+	"裏": "Back", "うら": "Back", "背面": "Back", "反面": "Back", "뒷면": "Back",
+	// Tags
+	"étiquettes": TagsColumn, "etiquetas": TagsColumn, "schlagwörter": TagsColumn,
+	"タグ": TagsColumn, "标签": TagsColumn, "태그": TagsColumn,
+}
+
+// HeaderAlias returns the Anki name of a column named in another language,
+// such as Front for "Recto" or Back for "裏", and whether there is one
+func HeaderAlias(header string) (string, bool) {
+	name, ok := headerAliases[headerKey(header)]
+	return name, ok
+}
+
+// ApplyHeaderAliases renames the columns of the input files that have a
+// HeaderAlias, so "Recto,Verso" and "Frente,Verso" files merge with
+// "Front,Back" ones. A file that already has a column with the Anki name
+// keeps its own names, since two of its columns cannot become one. It
+// returns the renamed headers in first-seen order.
+func ApplyHeaderAliases(inputFiles []*InputFile) []string {
+	var renamed []string
+	seen := make(map[string]bool)
+	for _, inputFile := range inputFiles {
+		present := make(map[string]bool)
+		for _, header := range inputFile.Headers {
+			present[header] = true
+		}
+
+		for i, header := range inputFile.Headers {
+			name, ok := HeaderAlias(header)
+			if !ok || present[name] {
+				continue
+			}
+			inputFile.Headers[i] = name
+			present[name] = true
+			if !seen[header] {
+				seen[header] = true
+				renamed = append(renamed, header)
+			}
+		}
+	}
+	return renamed
+}
+
+// headerReplacer removes what would break the one-line #columns directive
+var headerReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// SanitizeHeader makes a column name safe for the #columns line of an Anki
+// import file: line breaks become spaces, since the directive is one line,
+// and a leading # is dropped. Separators and quotes are left to the quoting
+// of ColumnsDirective.
+func SanitizeHeader(header string) string {
+	return strings.TrimLeft(headerReplacer.Replace(header), "#")
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeat is how often a long stage reports that it is still running
+const DefaultHeartbeat = 30 * time.Second
+
+// heartbeatChunk is how many entries Each hands to a stage at a time
+const heartbeatChunk = 1000
+
+// Heartbeat prints a "still working" line at a fixed interval while a long
+// stage runs, with how far it got, so a stage that is silent for minutes is
+// not mistaken for a hang. Nothing is printed for stages that finish within
+// one interval. A nil Heartbeat runs stages without printing anything.
+type Heartbeat struct {
+	out      io.Writer
+	interval time.Duration
+
+	mu    sync.Mutex
+	stage string
+	done  int
+	total int
+	start time.Time
+}
+
+// NewHeartbeat creates a Heartbeat writing to out every interval; it returns
+// nil, which prints nothing, when interval is not positive
+func NewHeartbeat(out io.Writer, interval time.Duration) *Heartbeat {
+	if interval <= 0 {
+		return nil
+	}
+	return &Heartbeat{out: out, interval: interval}
+}
+
+// Each runs fn on consecutive chunks of entries, in order, as the stage
+// named stage, and stops at the first error fn returns. Running a stage in
+// chunks gives the same result as running it once on every entry for
+// stages that keep their state between calls, such as a DuplicateDetector.
+func (h *Heartbeat) Each(stage string, entries []*DataEntry, fn func(chunk []*DataEntry) error) error {
+	if h == nil {
+		return fn(entries)
+	}
+
+	h.mu.Lock()
+	h.stage, h.done, h.total, h.start = stage, 0, len(entries), time.Now()
+	h.mu.Unlock()
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go h.beat(stop, stopped)
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+
+	for i := 0; i < len(entries); i += heartbeatChunk {
+		end := min(i+heartbeatChunk, len(entries))
+		if err := fn(entries[i:end]); err != nil {
+			return err
+		}
+		h.mu.Lock()
+		h.done = end
+		h.mu.Unlock()
+	}
+	return nil
+}
+
+// beat prints a line every interval until stop is closed
+func (h *Heartbeat) beat(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			h.mu.Lock()
+			fmt.Fprintf(h.out, "still working: %s\n", h.status())
+			h.mu.Unlock()
+		}
+	}
+}
+
+// status returns the stage with how much of it is done, e.g.
+// "typography processing, 37% (2m10s elapsed)"; h.mu must be held
+func (h *Heartbeat) status() string {
+	elapsed := time.Since(h.start).Round(time.Second)
+	if h.total == 0 {
+		return fmt.Sprintf("%s (%s elapsed)", h.stage, elapsed)
+	}
+	return fmt.Sprintf("%s, %d%% (%s elapsed)", h.stage, h.done*100/h.total, elapsed)
+}
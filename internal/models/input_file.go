@@ -9,11 +9,14 @@ import (
 
 // InputFile represents a source CSV/TSV file to be processed
 type InputFile struct {
-	Path      string     // Absolute file path
-	Separator rune       // Field separator (comma or tab)
-	Headers   []string   // Column header names
-	Records   [][]string // Data rows (excluding header)
-	Encoding  string     // Character encoding (UTF-8 only)
+	Path        string            // Absolute file path
+	Separator   rune              // Field separator (comma or tab)
+	Headers     []string          // Column header names
+	Records     [][]string        // Data rows (excluding header)
+	LineNumbers []int             // Line where each record starts, parallel to Records
+	Encoding    string            // Character encoding (UTF-8 only)
+	HasHeader   bool              // Whether Headers came from the file (false when generated)
+	Languages   map[string]string // Column languages declared by an Anki import file's #languages line
 }
 
 // NewInputFile creates a new InputFile instance with the given path
@@ -22,6 +25,7 @@ func NewInputFile(path string) *InputFile {
 		Path:      path,
 		Separator: ',', // Default to comma
 		Encoding:  "UTF-8",
+		HasHeader: true,
 	}
 }
 
@@ -57,9 +61,189 @@ func (f *InputFile) Validate() error {
 	return nil
 }
 
-// DetectSeparator attempts to detect the file separator based on file extension
+// AddRecord appends a data row that starts at the given line
+func (f *InputFile) AddRecord(record []string, line int) {
+	f.Records = append(f.Records, record)
+	f.LineNumbers = append(f.LineNumbers, line)
+}
+
+// LineNumber returns the line where the record at index starts; records added
+// without a line number are assumed to occupy one line each after the header
+func (f *InputFile) LineNumber(index int) int {
+	if index < len(f.LineNumbers) {
+		return f.LineNumbers[index]
+	}
+	return index + 2
+}
+
+// MissingColumns returns the required column names absent from the file's headers
+func (f *InputFile) MissingColumns(required []string) []string {
+	present := make(map[string]bool)
+	for _, header := range f.Headers {
+		present[header] = true
+	}
+
+	var missing []string
+	for _, column := range required {
+		if !present[column] {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
+
+// GenerateHeaders returns column names Column1..Column<count>
+func GenerateHeaders(count int) []string {
+	headers := make([]string, count)
+	for i := range headers {
+		headers[i] = fmt.Sprintf("Column%d", i+1)
+	}
+	return headers
+}
+
+// UseGeneratedHeaders treats the parsed header row as the first data record
+// and names the columns Column1..N
+func (f *InputFile) UseGeneratedHeaders() {
+	if !f.HasHeader {
+		return
+	}
+
+	first := f.Headers
+	f.Records = append([][]string{first}, f.Records...)
+	f.LineNumbers = append([]int{1}, f.LineNumbers...)
+	f.Headers = GenerateHeaders(len(first))
+	f.HasHeader = false
+}
+
+// LooksHeaderless reports whether the header row looks like data rather than
+// column names; see HeaderSuspicion for the reason
+func (f *InputFile) LooksHeaderless() bool {
+	return f.HeaderSuspicion() != ""
+}
+
+// HeaderSuspicion explains why the header row probably isn't a header, or
+// returns "" when it looks like column names. Header rows never have empty or
+// repeated names, are rarely mostly numbers or URLs, and rarely share the
+// shape of the row below them.
+func (f *InputFile) HeaderSuspicion() string {
+	seen := make(map[string]bool)
+	numeric, urls := 0, 0
+	for _, header := range f.Headers {
+		name := strings.TrimSpace(header)
+		if name == "" {
+			return "it has empty column names"
+		}
+		if seen[name] {
+			return fmt.Sprintf("column name %q is repeated", name)
+		}
+		seen[name] = true
+
+		switch classifyField(name) {
+		case fieldNumber:
+			numeric++
+		case fieldURL:
+			urls++
+		}
+	}
+
+	if len(f.Headers) > 0 && numeric*2 >= len(f.Headers) {
+		return "it is mostly numbers"
+	}
+	if len(f.Headers) > 0 && urls*2 >= len(f.Headers) {
+		return "it is mostly URLs"
+	}
+
+	if len(f.Records) > 0 {
+		second := f.Records[0]
+		if equalStrings(f.Headers, second) {
+			return "it is identical to the second row"
+		}
+		if sameDistinctiveShape(f.Headers, second) {
+			return "it has the same shape as the second row"
+		}
+	}
+
+	return ""
+}
+
+// Field classes used to compare the shape of rows
+const (
+	fieldEmpty  = "empty"
+	fieldNumber = "number"
+	fieldURL    = "url"
+	fieldLong   = "long"
+	fieldWord   = "word"
+)
+
+// classifyField returns the shape class of a single value
+func classifyField(value string) string {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(value)
+	switch {
+	case value == "":
+		return fieldEmpty
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "www."):
+		return fieldURL
+	case isNumber(value):
+		return fieldNumber
+	case len([]rune(value)) > 40:
+		return fieldLong
+	default:
+		return fieldWord
+	}
+}
+
+// isNumber reports whether value is an integer or decimal number
+func isNumber(value string) bool {
+	digits := 0
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case (r == '-' || r == '+') && i == 0:
+		case r == '.' || r == ',':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// sameDistinctiveShape reports whether two rows have the same field classes and
+// at least one class that column names rarely have (number, URL, long text)
+func sameDistinctiveShape(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	distinctive := false
+	for i := range a {
+		class := classifyField(a[i])
+		if class != classifyField(b[i]) {
+			return false
+		}
+		if class == fieldNumber || class == fieldURL || class == fieldLong {
+			distinctive = true
+		}
+	}
+	return distinctive
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectSeparator attempts to detect the file separator based on file
+// extension, looking past a .gz or .zst suffix
 func (f *InputFile) DetectSeparator() {
-	ext := strings.ToLower(filepath.Ext(f.Path))
+	ext := strings.ToLower(filepath.Ext(TrimCompression(f.Path)))
 	switch ext {
 	case ".tsv":
 		f.Separator = '\t'
@@ -73,8 +257,13 @@ func (f *InputFile) DetectSeparator() {
 
 // GetSeparatorString returns the separator as a string for display purposes
 func (f *InputFile) GetSeparatorString() string {
-	if f.Separator == '\t' {
-		return "tab"
+	for name, separator := range ankiSeparators {
+		if f.Separator == separator {
+			return name
+		}
+	}
+	if f.Separator == 0 {
+		return "comma"
 	}
-	return "comma"
+	return string(f.Separator)
 }
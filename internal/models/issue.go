@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks an Issue
+type Severity string
+
+// Issue severities
+const (
+	SeverityError   Severity = "error"   // The input is wrong and the run stops or should not be trusted
+	SeverityWarning Severity = "warning" // The output probably differs from what was meant
+	SeverityInfo    Severity = "info"    // Something was changed as asked, recorded for review
+)
+
+// Codes of the issues ankiprep reports. Codes are stable identifiers for
+// scripts and plugins reading the report; plugins add codes of their own.
+const (
+	IssueDataURI          = "data-uri"           // A field embeds data URI images
+	IssueSlowCell         = "slow-cell"          // A field took too long to format
+	IssueFieldTruncated   = "field-truncated"    // A field was cut to --max-field-bytes
+	IssueFieldTooLarge    = "field-too-large"    // A row was skipped for a field over --max-field-bytes
+	IssuePossibleTypo     = "possible-typo"      // A word is not in the --spell-check dictionary
+	IssueSchema           = "schema"             // A value breaks the --schema
+	IssueSplitOverflow    = "split-overflow"     // A split column has more values than target columns
+	IssueSimilarHeaders   = "similar-headers"    // Columns differ only by case or spacing
+	IssueSeparator        = "separator-conflict" // An Anki export's #separator: contradicts its extension
+	IssueHeaderSuspect    = "header-suspect"     // A first row looks like data
+	IssueHeaderRenamed    = "header-renamed"     // A column name is changed for #columns
+	IssueNoteTypeMismatch = "note-type-fields"   // Columns do not line up with the note type's fields
+	IssueStraySeparator   = "stray-separator"    // The header ends in separators the rows do not have
+	IssueUnbalancedQuote  = "unbalanced-quote"   // A quoted field is not closed on its line
+	IssueImportSkipped    = "import-skipped"     // Anki would not import a row of the file
+	IssueImportMerged     = "import-merged"      // Anki would update an earlier row's note instead of adding one
+	IssueImportDropped    = "import-dropped"     // Anki would leave out some of a file's data
+	IssueNotUnique        = "not-unique"         // A --unique column has the same value in several rows
+	IssueInputSkipped     = "input-skipped"      // An input file could not be read and was left out
+	IssueFlagIgnored      = "flag-ignored"       // A flag has no effect with the other flags given
+)
+
+// Issue is a finding about the input or the output: a warning shown to the
+// user and recorded in the report. File, Line and Column locate it when
+// known (Line and Column count from 1; 0 means unknown).
+type Issue struct {
+	Severity   Severity `json:"severity"`
+	Code       string   `json:"code"`
+	File       string   `json:"file,omitempty"`
+	Line       int      `json:"line,omitempty"`
+	Column     int      `json:"column,omitempty"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"` // How to fix or silence it, such as a flag to use
+	Fix        *Fix     `json:"fix,omitempty"`        // Change to the input that resolves it, for codes that have one
+}
+
+// NewIssue creates a warning with code located at a line of a file
+func NewIssue(code, file string, line int, message string) *Issue {
+	return &Issue{Severity: SeverityWarning, Code: code, File: file, Line: line, Message: message}
+}
+
+// Location returns file:line:column, leaving out the parts that are unknown
+func (i *Issue) Location() string {
+	if i.File == "" {
+		return ""
+	}
+	location := i.File
+	if i.Line > 0 {
+		location += fmt.Sprintf(":%d", i.Line)
+		if i.Column > 0 {
+			location += fmt.Sprintf(":%d", i.Column)
+		}
+	}
+	return location
+}
+
+// String renders the issue as "file:line: message; suggestion"
+func (i *Issue) String() string {
+	var b strings.Builder
+	if location := i.Location(); location != "" {
+		b.WriteString(location)
+		b.WriteString(": ")
+	}
+	b.WriteString(i.Message)
+	if i.Suggestion != "" {
+		b.WriteString("; ")
+		b.WriteString(i.Suggestion)
+	}
+	return b.String()
+}
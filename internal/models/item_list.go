@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMergeDelimiter separates the items of columns merged from removed
+// duplicates unless the config declares another delimiter
+const DefaultMergeDelimiter = "<br>"
+
+// ItemList describes a column whose cells hold delimiter-separated items,
+// such as a list of synonyms
+type ItemList struct {
+	Column    string // Column holding the lists
+	Delimiter string // Text between items, e.g. ";" or ","
+}
+
+// NewItemList creates a new ItemList instance
+func NewItemList(column, delimiter string) *ItemList {
+	return &ItemList{
+		Column:    column,
+		Delimiter: delimiter,
+	}
+}
+
+// Validate checks if the item list meets all validation requirements
+func (l *ItemList) Validate() error {
+	if strings.TrimSpace(l.Column) == "" {
+		return fmt.Errorf("item list column name cannot be empty")
+	}
+
+	if strings.TrimSpace(l.Delimiter) == "" {
+		return fmt.Errorf("item list delimiter for column %q cannot be empty or whitespace", l.Column)
+	}
+
+	return nil
+}
+
+// Dedupe removes repeated items from value, keeping the first occurrence of
+// each, so "dog; dog; hound" becomes "dog; hound". Items are compared with
+// surrounding whitespace trimmed and empty items are dropped. The items are
+// rejoined with the delimiter as first written in value, including its
+// spacing. Values without repeated or empty items are returned unchanged.
+func (l *ItemList) Dedupe(value string) string {
+	parts := strings.Split(value, l.Delimiter)
+	if len(parts) < 2 {
+		return value
+	}
+
+	seen := make(map[string]bool)
+	var items []string
+	for _, part := range parts {
+		item := strings.TrimSpace(part)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+	if len(items) == len(parts) {
+		return value
+	}
+
+	return strings.Join(items, l.joiner(parts))
+}
+
+// Merge adds the items of other that value lacks after those of value, so
+// merging "Il pleut.<br>Il neige." with "Il neige.<br>Il vente." gives
+// "Il pleut.<br>Il neige.<br>Il vente.". Items are compared with surrounding
+// whitespace trimmed and empty items of other are dropped. Added items are
+// joined with the delimiter as first written in value, or as declared when
+// value holds a single item.
+func (l *ItemList) Merge(value, other string) string {
+	parts := strings.Split(value, l.Delimiter)
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		seen[strings.TrimSpace(part)] = true
+	}
+	joiner := l.Delimiter
+	if len(parts) > 1 {
+		joiner = l.joiner(parts)
+	}
+
+	merged := value
+	for _, part := range strings.Split(other, l.Delimiter) {
+		item := strings.TrimSpace(part)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		if strings.TrimSpace(merged) == "" {
+			merged = item
+		} else {
+			merged += joiner + item
+		}
+	}
+	return merged
+}
+
+// joiner returns the delimiter with the whitespace written around its first
+// occurrence, e.g. "; " for "dog; hound"
+func (l *ItemList) joiner(parts []string) string {
+	before := parts[0][len(strings.TrimRight(parts[0], " \t")):]
+	after := parts[1][:len(parts[1])-len(strings.TrimLeft(parts[1], " \t"))]
+	return before + l.Delimiter + after
+}
+
+// DedupeItems removes repeated items from the cells of the item list columns
+// and returns the number of cells changed
+func DedupeItems(entries []*DataEntry, lists []*ItemList) int {
+	changed := 0
+	for _, entry := range entries {
+		// Preserved header rows are not field content
+		if entry.LineNumber == 0 {
+			continue
+		}
+
+		for _, list := range lists {
+			value, ok := entry.Values[list.Column]
+			if !ok {
+				continue
+			}
+			if deduped := list.Dedupe(value); deduped != value {
+				entry.Values[list.Column] = deduped
+				changed++
+			}
+		}
+	}
+	return changed
+}
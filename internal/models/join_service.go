@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseJoinSpec splits a join specification such as "lookup.csv on Word"
+// into the lookup file path and the key column
+func ParseJoinSpec(spec string) (path, key string, err error) {
+	i := strings.LastIndex(spec, " on ")
+	if i < 0 {
+		return "", "", fmt.Errorf("invalid join %q: expected \"<file> on <column>\"", spec)
+	}
+
+	path = strings.TrimSpace(spec[:i])
+	key = strings.TrimSpace(spec[i+len(" on "):])
+	if path == "" || key == "" {
+		return "", "", fmt.Errorf("invalid join %q: expected \"<file> on <column>\"", spec)
+	}
+	return path, key, nil
+}
+
+// JoinService left-joins the columns of a lookup file onto entries by a key
+// column, such as adding a frequency rank or IPA from a dictionary file.
+// Keys are compared with surrounding whitespace trimmed; when the lookup file
+// repeats a key, its first row is used.
+type JoinService struct {
+	Lookup    string   // Path of the lookup file
+	Key       string   // Column holding the key in both files
+	Columns   []string // Lookup columns added to the entries, in lookup file order
+	Matched   int      // Entries that found their key in the lookup file
+	Unmatched []string // Distinct keys not found, in order of first appearance
+
+	rows      map[string][]string
+	unmatched map[string]bool
+}
+
+// NewJoinService indexes the rows of lookup by its key column
+func NewJoinService(lookup *InputFile, key string) (*JoinService, error) {
+	keyIndex := -1
+	for i, header := range lookup.Headers {
+		if header == key {
+			keyIndex = i
+			break
+		}
+	}
+	if keyIndex < 0 {
+		return nil, fmt.Errorf("join column %q not found in %s (available: %s)",
+			key, lookup.Path, strings.Join(lookup.Headers, ", "))
+	}
+
+	j := &JoinService{
+		Lookup:    lookup.Path,
+		Key:       key,
+		rows:      make(map[string][]string),
+		unmatched: make(map[string]bool),
+	}
+	for i, header := range lookup.Headers {
+		if i != keyIndex {
+			j.Columns = append(j.Columns, header)
+		}
+	}
+
+	for _, record := range lookup.Records {
+		if keyIndex >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[keyIndex])
+		if _, exists := j.rows[value]; exists || value == "" {
+			continue
+		}
+
+		var row []string
+		for i := range lookup.Headers {
+			if i == keyIndex {
+				continue
+			}
+			if i < len(record) {
+				row = append(row, record[i])
+			} else {
+				row = append(row, "")
+			}
+		}
+		j.rows[value] = row
+	}
+
+	return j, nil
+}
+
+// Headers returns headers with the joined columns they lack appended
+func (j *JoinService) Headers(headers []string) []string {
+	joined := append([]string(nil), headers...)
+	for _, column := range j.Columns {
+		if !containsHeader(joined, column) {
+			joined = append(joined, column)
+		}
+	}
+	return joined
+}
+
+// Join adds the lookup columns to each entry whose key is in the lookup
+// file. A value already in the entry is kept; the lookup only fills columns
+// that are missing or empty. Entries without a match are counted in
+// Unmatched and left as they are.
+func (j *JoinService) Join(entries []*DataEntry) {
+	for _, entry := range entries {
+		// A preserved header row names the joined columns
+		if entry.LineNumber == 0 {
+			for _, column := range j.Columns {
+				if entry.GetValue(column) == "" {
+					entry.SetValue(column, column)
+				}
+			}
+			continue
+		}
+
+		key := strings.TrimSpace(entry.GetValue(j.Key))
+		row, ok := j.rows[key]
+		if !ok {
+			if !j.unmatched[key] {
+				j.unmatched[key] = true
+				j.Unmatched = append(j.Unmatched, key)
+			}
+			continue
+		}
+
+		j.Matched++
+		for i, column := range j.Columns {
+			if entry.GetValue(column) == "" {
+				entry.SetValue(column, row[i])
+			}
+		}
+	}
+}
+
+func containsHeader(headers []string, header string) bool {
+	for _, h := range headers {
+		if h == header {
+			return true
+		}
+	}
+	return false
+}
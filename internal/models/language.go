@@ -0,0 +1,233 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Language codes returned by DetectLanguage
+const (
+	LanguageUnknown  = ""
+	LanguageFrench   = "fr"
+	LanguageEnglish  = "en"
+	LanguageJapanese = "ja"
+	LanguageChinese  = "zh"
+	LanguageKorean   = "ko"
+	LanguageRussian  = "ru"
+	LanguageGreek    = "el"
+	LanguageArabic   = "ar"
+	LanguageHebrew   = "he"
+)
+
+// frenchStopwords are frequent French words that are not English words
+var frenchStopwords = map[string]bool{
+	"le": true, "la": true, "les": true, "de": true, "des": true, "du": true,
+	"un": true, "une": true, "et": true, "est": true, "je": true, "tu": true,
+	"il": true, "elle": true, "nous": true, "vous": true, "ils": true, "elles": true,
+	"que": true, "qui": true, "pas": true, "ne": true, "au": true, "aux": true,
+	"ce": true, "cette": true, "ces": true, "sur": true, "pour": true, "avec": true,
+	"dans": true, "sont": true, "mais": true, "ou": true, "où": true, "très": true,
+	"mon": true, "ma": true, "mes": true, "son": true, "sa": true, "ses": true,
+	"leur": true, "être": true, "avoir": true, "faire": true, "y": true,
+}
+
+// englishStopwords are frequent English words that are not French words
+var englishStopwords = map[string]bool{
+	"the": true, "and": true, "is": true, "are": true, "of": true, "to": true,
+	"in": true, "it": true, "you": true, "he": true, "she": true, "we": true,
+	"they": true, "that": true, "this": true, "with": true, "for": true,
+	"not": true, "was": true, "be": true, "have": true, "has": true, "do": true,
+	"what": true, "my": true, "your": true, "his": true, "her": true, "their": true,
+	"from": true, "at": true, "by": true, "will": true, "would": true, "can": true,
+	"an": true, "i": true, "me": true, "there": true, "been": true, "were": true,
+}
+
+// frenchElisions precede an apostrophe; englishContractions follow one
+var frenchElisions = map[string]bool{
+	"l": true, "d": true, "j": true, "qu": true, "n": true, "c": true, "s": true,
+	"m": true, "t": true, "jusqu": true, "lorsqu": true, "puisqu": true,
+}
+var englishContractions = map[string]bool{
+	"s": true, "t": true, "re": true, "ll": true, "ve": true, "d": true, "m": true,
+}
+
+// frenchSuffixes and englishSuffixes give weak evidence for single words
+var frenchSuffixes = []string{"eau", "eaux", "aux", "eux", "euse", "oir", "oire", "ette", "ement", "ais", "ait", "aient", "ique", "ée", "er", "ez", "onne"}
+var englishSuffixes = []string{"ing", "ly", "ness", "ship", "ful", "less", "ough", "ight", "ed", "th", "ty", "ward", "wise"}
+
+// DetectLanguage returns a best-guess language code for text, or LanguageUnknown
+// when there is too little evidence. Non-Latin scripts are recognized by their
+// characters; Latin text is scored as French or English from stopwords, elision,
+// diacritics and word endings. This is a heuristic meant for flashcard-sized text.
+func DetectLanguage(text string) string {
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = clozeStartPattern.ReplaceAllString(text, " ")
+
+	if script := detectScript(text); script != LanguageUnknown {
+		return script
+	}
+
+	french, english := 0, 0
+	for _, r := range text {
+		switch r {
+		case 'é', 'è', 'ê', 'ë', 'à', 'â', 'ç', 'ù', 'û', 'ô', 'î', 'ï', 'œ', 'É', 'È', 'Ê', 'À', 'Ç', 'Œ', '«', '»':
+			french += 2
+		}
+	}
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’'
+	}) {
+		// Elided forms (l'homme, qu'il) are French; contractions (it's, don't) are English
+		if parts := strings.FieldsFunc(word, func(r rune) bool { return r == '\'' || r == '’' }); len(parts) == 2 {
+			if frenchElisions[parts[0]] {
+				french += 3
+				word = parts[1]
+			} else if englishContractions[parts[1]] {
+				english += 3
+				word = parts[0]
+			}
+		}
+
+		if frenchStopwords[word] {
+			french += 2
+		}
+		if englishStopwords[word] {
+			english += 2
+		}
+		if strings.ContainsAny(word, "wk") {
+			english++
+		}
+		if hasAnySuffix(word, frenchSuffixes) {
+			french++
+		}
+		if hasAnySuffix(word, englishSuffixes) {
+			english++
+		}
+	}
+
+	switch {
+	case french > english:
+		return LanguageFrench
+	case english > french:
+		return LanguageEnglish
+	default:
+		return LanguageUnknown
+	}
+}
+
+// detectScript identifies text written mostly in a non-Latin script
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	kana := 0
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+			counts[LanguageJapanese]++
+		case unicode.Is(unicode.Han, r):
+			counts[LanguageChinese]++
+		case unicode.Is(unicode.Hangul, r):
+			counts[LanguageKorean]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts[LanguageRussian]++
+		case unicode.Is(unicode.Greek, r):
+			counts[LanguageGreek]++
+		case unicode.Is(unicode.Arabic, r):
+			counts[LanguageArabic]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts[LanguageHebrew]++
+		}
+	}
+
+	if letters == 0 {
+		return LanguageUnknown
+	}
+
+	// Japanese mixes kanji with kana; any kana means Japanese
+	if kana > 0 {
+		return LanguageJapanese
+	}
+
+	best, bestCount := LanguageUnknown, 0
+	for language, count := range counts {
+		if count > bestCount || (count == bestCount && language < best) {
+			best, bestCount = language, count
+		}
+	}
+
+	if bestCount*2 < letters {
+		return LanguageUnknown
+	}
+	return best
+}
+
+func hasAnySuffix(word string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if len(word) > len(suffix)+1 && strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectSwappedColumns returns the entries whose values in columns a and b look
+// swapped relative to the other entries of the same source file. Each column's
+// expected language is the most common detected language in that file; a row is
+// suspect when either cell looks like the other column's language and neither
+// cell matches its own. Files where both columns share a language are skipped.
+func DetectSwappedColumns(entries []*DataEntry, a, b string) []*DataEntry {
+	var sources []string
+	bySource := make(map[string][]*DataEntry)
+	for _, entry := range entries {
+		if _, exists := bySource[entry.Source]; !exists {
+			sources = append(sources, entry.Source)
+		}
+		bySource[entry.Source] = append(bySource[entry.Source], entry)
+	}
+
+	var suspects []*DataEntry
+	for _, source := range sources {
+		group := bySource[source]
+
+		langA := majorityLanguage(group, a)
+		langB := majorityLanguage(group, b)
+		if langA == LanguageUnknown || langB == LanguageUnknown || langA == langB {
+			continue
+		}
+
+		for _, entry := range group {
+			detectedA := DetectLanguage(entry.GetValue(a))
+			detectedB := DetectLanguage(entry.GetValue(b))
+			if (detectedA == langB || detectedB == langA) && detectedA != langA && detectedB != langB {
+				suspects = append(suspects, entry)
+			}
+		}
+	}
+
+	return suspects
+}
+
+// majorityLanguage returns the most frequently detected language of a column
+func majorityLanguage(entries []*DataEntry, column string) string {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		if language := DetectLanguage(entry.GetValue(column)); language != LanguageUnknown {
+			counts[language]++
+		}
+	}
+
+	best, bestCount := LanguageUnknown, 0
+	for language, count := range counts {
+		if count > bestCount || (count == bestCount && language < best) {
+			best, bestCount = language, count
+		}
+	}
+	return best
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package models
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile always fails where memory maps are not supported, so callers fall
+// back to ordinary reads
+func mapFile(file *os.File) ([]byte, func() error, error) {
+	return nil, nil, errors.New("memory-mapped files are not supported on this platform")
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package models
+
+import "syscall"
+
+// networkFSTypes are the statfs magic numbers of network and remote
+// filesystems, as listed in linux/magic.h
+var networkFSTypes = map[uint32]bool{
+	0x6969:     true, // NFS
+	0x517B:     true, // SMB
+	0xFE534D42: true, // SMB2
+	0xFF534D42: true, // CIFS
+	0x5346414F: true, // AFS
+	0x00C36400: true, // Ceph
+	0x01021997: true, // 9P (WSL drives, VM shares)
+	0x65735546: true, // FUSE (sshfs, rclone and other remote mounts)
+}
+
+// IsNetworkPath reports whether path (an existing file or directory) lives on
+// a network filesystem such as an SMB share or an NFS mount
+func IsNetworkPath(path string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+	return networkFSTypes[uint32(stat.Type)]
+}
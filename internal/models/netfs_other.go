@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package models
+
+// IsNetworkPath reports whether path lives on a network filesystem; network
+// mounts are not detected on this platform, so use --network-fs on
+func IsNetworkPath(path string) bool {
+	return false
+}
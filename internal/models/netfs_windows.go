@@ -0,0 +1,34 @@
+//go:build windows
+
+package models
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// driveRemote is the GetDriveType result of a mapped network drive
+const driveRemote = 4
+
+var getDriveType = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDriveTypeW")
+
+// IsNetworkPath reports whether path (an existing file or directory) lives on
+// a network share: a UNC path such as \\server\share or a mapped drive
+func IsNetworkPath(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(abs, `\\?\UNC\`) || (strings.HasPrefix(abs, `\\`) && !strings.HasPrefix(abs, `\\?\`)) {
+		return true
+	}
+
+	root, err := syscall.UTF16PtrFromString(filepath.VolumeName(abs) + `\`)
+	if err != nil || getDriveType.Find() != nil {
+		return false
+	}
+	driveType, _, _ := getDriveType.Call(uintptr(unsafe.Pointer(root)))
+	return driveType == driveRemote
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NoteTypes maps note type names to their field names in order, as read from
+// a note type definition file:
+//
+//	{"Basic": ["Front", "Back"], "Cloze": ["Text", "Back Extra"]}
+type NoteTypes map[string][]string
+
+// LoadNoteTypes reads a note type definition file
+func LoadNoteTypes(path string) (NoteTypes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read note types: %v", err)
+	}
+
+	var noteTypes NoteTypes
+	if err := json.Unmarshal(data, &noteTypes); err != nil {
+		return nil, fmt.Errorf("invalid note types file %s: %v", path, err)
+	}
+	return noteTypes, nil
+}
+
+// CheckNoteTypeFields compares the output columns with the fields of a note
+// type and describes every mismatch Anki would not report. Anki maps columns
+// to fields by position, dropping extra columns and leaving missing fields
+// empty; a Tags column is mapped to the note's tags, and GUID, note type and
+// deck columns to their #directives, so they are not fields.
+func CheckNoteTypeFields(noteType string, columns, fields []string) []string {
+	var fieldColumns []string
+	for _, column := range columns {
+		if !IsTagsColumn(column) && !IsAnkiMetadataColumn(column) {
+			fieldColumns = append(fieldColumns, column)
+		}
+	}
+
+	var problems []string
+	if len(fieldColumns) > len(fields) {
+		problems = append(problems, fmt.Sprintf("%d column(s) but note type %q has %d field(s); Anki drops %s",
+			len(fieldColumns), noteType, len(fields), strings.Join(fieldColumns[len(fields):], ", ")))
+	} else if len(fieldColumns) < len(fields) {
+		problems = append(problems, fmt.Sprintf("%d column(s) but note type %q has %d field(s); %s stay empty",
+			len(fieldColumns), noteType, len(fields), strings.Join(fields[len(fieldColumns):], ", ")))
+	}
+
+	for i, column := range fieldColumns {
+		if i < len(fields) && !strings.EqualFold(strings.TrimSpace(column), fields[i]) {
+			problems = append(problems, fmt.Sprintf("column %d is %q but field %d of %q is %q", i+1, column, i+1, noteType, fields[i]))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+// DefaultBatchSize is the number of bytes output is collected into before it
+// is written; large batches keep the number of write calls low on network
+// filesystems
+const DefaultBatchSize = 1 << 20
+
+// NetworkBatchSize is the batch size used for outputs on network filesystems,
+// where every write call is a round trip to the server
+const NetworkBatchSize = 8 << 20
+
+// MaxBatchSize is the largest batch size accepted for --write-batch-bytes
+const MaxBatchSize = 64 << 20
+
+// BatchStats describes one batch written by a BatchWriter
+type BatchStats struct {
+	Number   int           // 1 for the first batch
+	Rows     int           // Records completed in the batch
+	Bytes    int           // Bytes written
+	Duration time.Duration // Time the write took
+}
+
+// RowsPerSecond returns the rate records were written at in the batch
+func (s BatchStats) RowsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Rows) / s.Duration.Seconds()
+}
+
+// MBPerSecond returns the rate bytes were written at in the batch, in MB
+// (10^6 bytes) per second
+func (s BatchStats) MBPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / 1e6 / s.Duration.Seconds()
+}
+
+// BatchWriter collects output in memory and writes it to the underlying
+// writer in one call per batch of at least Size bytes, instead of one call
+// per record. Writers that know their records, such as AnkiWriter, call
+// EndRecord after each one so batches end between records and report how
+// many rows they hold.
+type BatchWriter struct {
+	OnFlush func(BatchStats) // Called after every batch is written, if set
+
+	w        io.Writer
+	size     int
+	buf      []byte
+	rows     int
+	number   int
+	byRecord bool // EndRecord has been called, so batches end between records
+	err      error
+}
+
+// NewBatchWriter creates a BatchWriter writing to w in batches of size bytes;
+// a size of 0 or less uses DefaultBatchSize. The buffer grows as output
+// arrives, so a large size costs no memory a short output does not use.
+func NewBatchWriter(w io.Writer, size int) *BatchWriter {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	return &BatchWriter{w: w, size: size, buf: make([]byte, 0, min(size, DefaultBatchSize))}
+}
+
+// Write adds p to the current batch. Data from writers that never call
+// EndRecord is written once the batch is full.
+func (b *BatchWriter) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	b.buf = append(b.buf, p...)
+	if !b.byRecord && len(b.buf) >= b.size {
+		if err := b.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// EndRecord marks the end of a record, writing the batch if it is full
+func (b *BatchWriter) EndRecord() error {
+	if b.err != nil {
+		return b.err
+	}
+	b.byRecord = true
+	b.rows++
+	if len(b.buf) >= b.size {
+		return b.Flush()
+	}
+	return nil
+}
+
+// Flush writes the current batch, if any, and returns the first write error
+func (b *BatchWriter) Flush() error {
+	if b.err != nil || len(b.buf) == 0 {
+		return b.err
+	}
+
+	start := time.Now()
+	if _, err := b.w.Write(b.buf); err != nil {
+		b.err = err
+		return err
+	}
+	b.number++
+	if b.OnFlush != nil {
+		b.OnFlush(BatchStats{Number: b.number, Rows: b.rows, Bytes: len(b.buf), Duration: time.Since(start)})
+	}
+	b.buf = b.buf[:0]
+	b.rows = 0
+	return nil
+}
+
+// endRecord passes the record just written through writer on to w and marks
+// its end when w is a BatchWriter, so batches end between records
+func endRecord(w io.Writer, writer recordWriter) error {
+	batch, ok := w.(*BatchWriter)
+	if !ok {
+		return nil
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return batch.EndRecord()
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"hash"
+	"io"
+	"time"
+)
+
+// Output formats accepted by NewOutputFormat
+const (
+	FormatCSV     = "csv"     // Anki import file, comma-separated
+	FormatTSV     = "tsv"     // Anki import file, tab-separated
+	FormatMochi   = "mochi"   // Mochi markdown
+	FormatRemNote = "remnote" // RemNote CSV
+	FormatQuizlet = "quizlet" // Quizlet tab-separated import text
+)
+
+// OutputSink receives the processed entries at the end of a run. Files,
+// stdout and AnkiConnect have sinks; .apkg packages do not, since a package
+// holds an Anki SQLite collection and this module has no SQLite driver.
+// A sink stops with ctx's error once ctx is done.
+type OutputSink interface {
+	Write(ctx context.Context, entries []*DataEntry, headers []string) error
+}
+
+// OutputFormat writes entries to w in one file format
+type OutputFormat func(w io.Writer, entries []*DataEntry, headers []string) error
+
+// AnkiFormat returns the Anki import file format with the given separator
+func AnkiFormat(separator string) OutputFormat {
+	return func(w io.Writer, entries []*DataEntry, headers []string) error {
+		return WriteAnki(w, headers, entries, separator)
+	}
+}
+
+// AnkiLanguagesFormat returns AnkiFormat, also declaring the language of
+// the columns in languages in a #languages line
+func AnkiLanguagesFormat(separator string, languages map[string]string) OutputFormat {
+	return func(w io.Writer, entries []*DataEntry, headers []string) error {
+		return writeAnkiLanguages(w, headers, entries, separator, languages)
+	}
+}
+
+// PlainFormat returns a plain CSV (or, with SeparatorTab, TSV) format: a
+// header row of column names followed by the data rows, with no Anki
+// metadata lines. Fields are quoted as needed and line breaks are kept.
+func PlainFormat(separator string) OutputFormat {
+	return func(w io.Writer, entries []*DataEntry, headers []string) error {
+		writer := csv.NewWriter(w)
+		if separator == SeparatorTab {
+			writer.Comma = '\t'
+		}
+		if err := writer.Write(headers); err != nil {
+			return err
+		}
+		for _, entry := range DataEntries(entries) {
+			if err := writer.Write(entry.ToCSVRecord(headers)); err != nil {
+				return err
+			}
+			if err := endRecord(w, writer); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+}
+
+// NewOutputFormat returns the format named by format; ankiSeparator is used
+// when format is empty
+func NewOutputFormat(format, ankiSeparator string) (OutputFormat, bool) {
+	switch format {
+	case "":
+		return AnkiFormat(ankiSeparator), true
+	case FormatCSV:
+		return AnkiFormat(SeparatorComma), true
+	case FormatTSV:
+		return AnkiFormat(SeparatorTab), true
+	case FormatMochi:
+		return WriteMochi, true
+	case FormatRemNote:
+		return WriteRemNote, true
+	case FormatQuizlet:
+		return WriteQuizlet, true
+	}
+	return nil, false
+}
+
+// FormatExtension returns the file extension of default output names
+func FormatExtension(format, ankiSeparator string) string {
+	switch format {
+	case FormatTSV, FormatQuizlet:
+		return ".tsv"
+	case FormatMochi:
+		return ".md"
+	case FormatCSV, FormatRemNote:
+		return ".csv"
+	}
+	if ankiSeparator == SeparatorTab {
+		return ".tsv"
+	}
+	return ".csv"
+}
+
+// FormatSeparator returns the Anki separator written for an output format
+func FormatSeparator(format string) (string, bool) {
+	switch format {
+	case FormatCSV:
+		return SeparatorComma, true
+	case FormatTSV:
+		return SeparatorTab, true
+	}
+	return "", false
+}
+
+// FileSink writes an output file through a temporary file that is moved into
+// place once complete
+type FileSink struct {
+	Path      string
+	Format    OutputFormat
+	Files     *FileService
+	BatchSize int               // Bytes written per call; 0 uses DefaultBatchSize
+	Progress  *ProgressReporter // Receives the throughput of every batch, if set
+}
+
+// Write writes the entries to a temporary file and commits it to Path. A
+// failed write is tried again as the FileService's Retry policy allows. Once
+// ctx is done no attempt is started and no file is committed.
+func (s *FileSink) Write(ctx context.Context, entries []*DataEntry, headers []string) error {
+	return s.Files.Retry.Do(func() error {
+		return s.write(ctx, entries, headers)
+	})
+}
+
+// write makes one attempt at writing and committing the output. Errors of the
+// format itself, such as invalid column names, are permanent; I/O errors and
+// failed verifications are worth another attempt.
+func (s *FileSink) write(ctx context.Context, entries []*DataEntry, headers []string) error {
+	if err := ctx.Err(); err != nil {
+		return Permanent(err)
+	}
+
+	file, err := s.Files.CreateTemp(s.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	out := &digestWriter{w: file}
+	if s.Files.Verify {
+		out.hash = sha256.New()
+	}
+	if err := writeBatched(out, s.BatchSize, s.Progress, s.Format, entries, headers); err != nil {
+		if out.err == nil {
+			return Permanent(err)
+		}
+		return err
+	}
+	if s.Files.Verify {
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if s.Files.Verify {
+		if err := s.Files.VerifyFile(file.Name(), out.size, out.hash.Sum(nil)); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return Permanent(err)
+	}
+	return s.Files.Commit(file.Name(), s.Path)
+}
+
+// digestWriter passes writes on to w, adding up their size and, with a hash,
+// their digest, and keeping the first error
+type digestWriter struct {
+	w    io.Writer
+	hash hash.Hash // nil when the output is not verified
+	size int64
+	err  error
+}
+
+func (d *digestWriter) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	if d.hash != nil {
+		d.hash.Write(p[:n])
+	}
+	d.size += int64(n)
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return n, err
+}
+
+// StreamSink writes output to a stream such as stdout
+type StreamSink struct {
+	Writer    io.Writer
+	Format    OutputFormat
+	BatchSize int               // Bytes written per call; 0 uses DefaultBatchSize
+	Progress  *ProgressReporter // Receives the throughput of every batch, if set
+}
+
+// Write writes the entries to the stream, unless ctx is done
+func (s *StreamSink) Write(ctx context.Context, entries []*DataEntry, headers []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return writeBatched(s.Writer, s.BatchSize, s.Progress, s.Format, entries, headers)
+}
+
+// writeBatched writes the entries in format to w through a BatchWriter,
+// reporting the rows and bytes per second of every batch to progress
+func writeBatched(w io.Writer, size int, progress *ProgressReporter, format OutputFormat, entries []*DataEntry, headers []string) error {
+	batch := NewBatchWriter(w, size)
+	if progress != nil {
+		batch.OnFlush = func(stats BatchStats) {
+			progress.Printf("Wrote batch %d: %d rows, %d bytes in %s (%.0f rows/s, %.1f MB/s)",
+				stats.Number, stats.Rows, stats.Bytes, stats.Duration.Round(time.Microsecond), stats.RowsPerSecond(), stats.MBPerSecond())
+		}
+	}
+	if err := format(batch, entries, headers); err != nil {
+		return err
+	}
+	return batch.Flush()
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxVerifyProblems is how many bad records VerifyAnkiOutput describes one by
+// one; the rest are counted
+const maxVerifyProblems = 5
+
+// VerifyAnkiOutput reads back an Anki import file written with headers,
+// separator and rows records, as ParseAnkiExport reads Anki exports, and
+// describes every way it differs from what was written: directives that do
+// not declare the separator, HTML, columns and metadata columns, a different
+// number of records, records with the wrong number of fields and fields that
+// are not valid UTF-8. Quoting mistakes show up as parse errors or records of
+// the wrong size, which Anki would import as shifted or merged notes.
+func VerifyAnkiOutput(r io.Reader, path string, headers []string, separator string, rows int) []string {
+	file := NewInputFile(path)
+	got, err := ParseAnkiExport(r, file, false)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot be read back: %v", err)}
+	}
+
+	var problems []string
+	if want, err := expectedAnkiHeader(headers, separator); err != nil {
+		problems = append(problems, fmt.Sprintf("cannot compute the expected directives: %v", err))
+	} else {
+		if got.Separator != want.Separator {
+			problems = append(problems, fmt.Sprintf("#separator declares %q, expected %q", got.Separator, want.Separator))
+		}
+		if !got.HTML {
+			problems = append(problems, "#html:true is missing")
+		}
+		if !reflect.DeepEqual(got.Columns, want.Columns) {
+			problems = append(problems, fmt.Sprintf("#columns lists %s, expected %s", strings.Join(got.Columns, ", "), strings.Join(want.Columns, ", ")))
+		}
+		if !reflect.DeepEqual(got.Metadata, want.Metadata) {
+			problems = append(problems, fmt.Sprintf("metadata column directives map %v, expected %v", got.Metadata, want.Metadata))
+		}
+	}
+
+	if len(file.Records) != rows {
+		problems = append(problems, fmt.Sprintf("%d record(s) read back, %d written", len(file.Records), rows))
+	}
+
+	bad := 0
+	for i, record := range file.Records {
+		var problem string
+		if len(record) != len(headers) {
+			problem = fmt.Sprintf("line %d: %d field(s), expected %d", file.LineNumber(i), len(record), len(headers))
+		} else {
+			for j, field := range record {
+				if !utf8.ValidString(field) {
+					problem = fmt.Sprintf("line %d: column %s is not valid UTF-8", file.LineNumber(i), headers[j])
+					break
+				}
+			}
+		}
+		if problem == "" {
+			continue
+		}
+		if bad < maxVerifyProblems {
+			problems = append(problems, problem)
+		}
+		bad++
+	}
+	if bad > maxVerifyProblems {
+		problems = append(problems, fmt.Sprintf("%d more bad record(s)", bad-maxVerifyProblems))
+	}
+	return problems
+}
+
+// expectedAnkiHeader returns the directives AnkiWriter writes for headers
+// and separator, as ReadAnkiHeader reads them
+func expectedAnkiHeader(headers []string, separator string) (*AnkiHeader, error) {
+	columns, err := ColumnsDirective(headers, separator)
+	if err != nil {
+		return nil, err
+	}
+	lines := append([]string{"#separator:" + separator, "#html:true", columns}, metadataDirectives(headers)...)
+	return ReadAnkiHeader(bufio.NewReader(strings.NewReader(strings.Join(lines, "\n") + "\n")))
+}
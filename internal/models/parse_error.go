@@ -0,0 +1,202 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSnippetRunes is the width of the widest line Snippet shows whole;
+// longer lines are cut around the error
+const maxSnippetRunes = 100
+
+// recordedBytes is how much of the text read lineRecorder keeps
+const recordedBytes = 64 * 1024
+
+// ParseError is a malformed record of an input file, located by file, line
+// and column so it can be found among several inputs
+type ParseError struct {
+	File      string // Input file, "" when unknown
+	Line      int    // Line of the error, from 1
+	Column    int    // Character in the line, from 1 (a byte when Text is not kept); 0 when unknown
+	StartLine int    // Line the record starts on, when it is another line
+	Err       error  // What is wrong, such as csv.ErrBareQuote
+	Text      string // The line of the error, "" when it was not kept
+}
+
+// Error returns file:line:column: message, leaving out what is unknown
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File + ":")
+	}
+	fmt.Fprintf(&b, "%d:", e.Line)
+	if e.Column > 0 {
+		fmt.Fprintf(&b, "%d:", e.Column)
+	}
+	b.WriteString(" " + e.message())
+	return b.String()
+}
+
+// message describes the error without its position
+func (e *ParseError) message() string {
+	message := parseErrorMessage(e.Err)
+	if e.StartLine > 0 {
+		message += fmt.Sprintf(" (the record starts on line %d)", e.StartLine)
+	}
+	return message
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Snippet returns the line of the error with a caret under its column, or
+// "" when the line was not kept:
+//
+//	1042 | chat,le "chat"
+//	     |         ^
+func (e *ParseError) Snippet() string {
+	if e.Text == "" {
+		return ""
+	}
+	runes := []rune(strings.TrimRight(e.Text, "\r"))
+	column := e.Column
+	if column < 1 || column > len(runes)+1 {
+		column = len(runes) + 1
+	}
+
+	// Long lines are cut to the part around the error
+	prefix, suffix := "", ""
+	if start := column - maxSnippetRunes/2; len(runes) > maxSnippetRunes && start > 0 {
+		runes, column, prefix = runes[start:], column-start, "…"
+	}
+	if len(runes) > maxSnippetRunes {
+		runes, suffix = runes[:maxSnippetRunes], "…"
+	}
+
+	// The caret line keeps the tabs of the text, so it lines up
+	var caret strings.Builder
+	caret.WriteString(strings.Repeat(" ", utf8.RuneCountInString(prefix)))
+	for _, r := range runes[:min(column-1, len(runes))] {
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+
+	number := fmt.Sprint(e.Line)
+	margin := strings.Repeat(" ", len(number))
+	return fmt.Sprintf("%s | %s%s%s\n%s | %s", number, prefix, string(runes), suffix, margin, caret.String())
+}
+
+// parseErrorMessage describes err in words, without the position
+// encoding/csv puts in its messages
+func parseErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, csv.ErrBareQuote):
+		return "bare quote in non-quoted field"
+	case errors.Is(err, csv.ErrQuote):
+		return "extraneous or missing quote in quoted field"
+	case errors.Is(err, csv.ErrFieldCount):
+		return "wrong number of fields"
+	}
+	return err.Error()
+}
+
+// SkippedInputIssue returns the warning for an input file left out because
+// err kept it from being read, located at the parse error when there is one
+func SkippedInputIssue(err error) *Issue {
+	issue := &Issue{Severity: SeverityWarning, Code: IssueInputSkipped, Message: err.Error(), Suggestion: "skipping it"}
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		issue.File, issue.Line, issue.Column = parseErr.File, parseErr.Line, parseErr.Column
+		issue.Message = parseErr.message()
+	}
+	return issue
+}
+
+// ParseFailure returns the error of a failed parse of path: a ParseError
+// as it is, since it names its file, and other errors prefixed with path
+func ParseFailure(path string, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr
+	}
+	return fmt.Errorf("cannot parse %s: %v", path, err)
+}
+
+// lineRecorder passes reads of r through, keeping the last recordedBytes or
+// so of text so the line of a parse error can be shown
+type lineRecorder struct {
+	r       io.Reader
+	tail    []byte // Text most recently read
+	line    int    // Line the tail starts on, from 1
+	partial bool   // The tail starts in the middle of its first line
+}
+
+// newLineRecorder returns a lineRecorder reading r
+func newLineRecorder(r io.Reader) *lineRecorder {
+	return &lineRecorder{r: r, line: 1}
+}
+
+// Read reads from r, recording what it reads
+func (l *lineRecorder) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.tail = append(l.tail, p[:n]...)
+	if len(l.tail) > 2*recordedBytes {
+		drop := len(l.tail) - recordedBytes
+		l.line += bytes.Count(l.tail[:drop], []byte{'\n'})
+		l.partial = l.tail[drop-1] != '\n'
+		l.tail = append(l.tail[:0], l.tail[drop:]...)
+	}
+	return n, err
+}
+
+// text returns the line with the given number, if it is still kept whole
+func (l *lineRecorder) text(line int) (string, bool) {
+	if line < l.line || (line == l.line && l.partial) {
+		return "", false
+	}
+	tail := l.tail
+	for current := l.line; current < line; current++ {
+		i := bytes.IndexByte(tail, '\n')
+		if i < 0 {
+			return "", false
+		}
+		tail = tail[i+1:]
+	}
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[:i]
+	}
+	return string(tail), true
+}
+
+// locate turns a csv.ParseError into a ParseError of the file at path, with
+// the line it is on when that is still recorded and offset added to its line
+// numbers. Other errors are returned unchanged.
+func (l *lineRecorder) locate(err error, path string, offset int) error {
+	var csvErr *csv.ParseError
+	if !errors.As(err, &csvErr) {
+		return err
+	}
+
+	located := &ParseError{File: path, Line: csvErr.Line + offset, Column: csvErr.Column, Err: csvErr.Err}
+	if csvErr.StartLine != csvErr.Line {
+		located.StartLine = csvErr.StartLine + offset
+	}
+	if text, ok := l.text(csvErr.Line); ok {
+		located.Text = text
+		if before := min(csvErr.Column-1, len(text)); before >= 0 {
+			located.Column = utf8.RuneCountInString(text[:before]) + 1
+		}
+	}
+	return located
+}
@@ -0,0 +1,381 @@
+package models
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Output separators accepted by WriteAnki
+const (
+	SeparatorComma = "comma"
+	SeparatorTab   = "tab"
+)
+
+// MergeHeaders returns the union of the input files' headers in first-seen order
+func MergeHeaders(inputFiles []*InputFile) []string {
+	seen := make(map[string]bool)
+	var merged []string
+
+	for _, inputFile := range inputFiles {
+		for _, header := range inputFile.Headers {
+			if header != "" && !seen[header] {
+				seen[header] = true
+				merged = append(merged, header)
+			}
+		}
+	}
+
+	return merged
+}
+
+// BuildEntries converts parsed records into data entries keyed by merged
+// header and returns them with the number of data records. With keepHeader the
+// first file's header row is kept as an entry with line number 0.
+func BuildEntries(inputFiles []*InputFile, mergedHeaders []string, keepHeader bool) ([]*DataEntry, int) {
+	var allEntries []*DataEntry
+	totalRecords := 0
+
+	for _, inputFile := range inputFiles {
+		// Add header if keepHeader is true and this is the first file;
+		// generated column names are not part of the input
+		if keepHeader && inputFile.HasHeader && len(allEntries) == 0 {
+			headerEntry := NewDataEntry(make(map[string]string), inputFile.Path, 0)
+			for i, header := range inputFile.Headers {
+				if i < len(mergedHeaders) {
+					headerEntry.Values[mergedHeaders[i]] = header
+				}
+			}
+			allEntries = append(allEntries, headerEntry)
+		}
+
+		// Process data records
+		for index, record := range inputFile.Records {
+			entry := NewDataEntry(make(map[string]string), inputFile.Path, inputFile.LineNumber(index))
+			for i, value := range record {
+				if i < len(inputFile.Headers) && i < len(mergedHeaders) {
+					entry.Values[mergedHeaders[i]] = value
+				}
+			}
+			allEntries = append(allEntries, entry)
+			totalRecords++
+		}
+	}
+
+	return allEntries, totalRecords
+}
+
+// DataEntries returns the entries excluding a preserved header row
+func DataEntries(entries []*DataEntry) []*DataEntry {
+	var data []*DataEntry
+	for _, entry := range entries {
+		if entry.LineNumber != 0 {
+			data = append(data, entry)
+		}
+	}
+	return data
+}
+
+// SortEntries stably sorts the entries by the value of column, comparing bytes
+// rather than locale collation so the order is the same on every system. A
+// preserved header row stays first.
+func SortEntries(entries []*DataEntry, column string) {
+	sortEntries(entries, column, func(a, b string) bool { return a < b })
+}
+
+// IsEnglishColumn determines if a column header indicates English content
+// that should not have French typography rules applied
+func IsEnglishColumn(header string) bool {
+	header = strings.ToLower(strings.TrimSpace(header))
+	englishPatterns := []string{"english", "eng", "pronunciation", "phonetic"}
+
+	for _, pattern := range englishPatterns {
+		if strings.Contains(header, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// TypographyRule is the typography applied to one column, whatever the
+// command line flags and column language say
+type TypographyRule struct {
+	French      bool
+	SmartQuotes bool
+	CJKSpacing  bool
+}
+
+// ApplyTypography applies French spacing and/or smart quotes to every field.
+// French spacing skips English columns; with autoLang the cell's detected
+// language decides instead, unless the text gives too little evidence.
+func ApplyTypography(entries []*DataEntry, french, quotes, autoLang bool) {
+	ApplyTypographyRules(entries, nil, french, quotes, autoLang)
+}
+
+// ApplyTypographyRules is ApplyTypography, except that the columns in rules
+// get exactly the typography of their rule
+func ApplyTypographyRules(entries []*DataEntry, rules map[string]TypographyRule, french, quotes, autoLang bool) {
+	ApplyTypographyLimit(context.Background(), entries, rules, french, quotes, false, autoLang, 0)
+}
+
+// DefaultCellTimeout is the typography time limit per cell; ordinary cells
+// take microseconds, so only pathological ones (hundreds of KB of nested
+// quotes or clozes) reach it
+const DefaultCellTimeout = 2 * time.Second
+
+// SlowField is a field whose typography took longer than the per-cell time
+// limit; it is left unchanged
+type SlowField struct {
+	Entry  *DataEntry
+	Column string
+	Size   int // Size in bytes
+}
+
+// ApplyTypographyLimit is ApplyTypographyRules, also spacing CJK text by CJK
+// rules when cjk is set, and giving each cell at most cellTimeout (0: no
+// limit) so one pathological cell cannot stall the run.
+// Cells over the limit keep their value and are returned, by entry and
+// column; the error is set when ctx itself is done.
+func ApplyTypographyLimit(ctx context.Context, entries []*DataEntry, rules map[string]TypographyRule, french, quotes, cjk, autoLang bool, cellTimeout time.Duration) ([]*SlowField, error) {
+	var slow []*SlowField
+	for _, entry := range entries {
+		var slowColumns []string
+		for key, value := range entry.Values {
+			cellCtx, cancel := ctx, func() {}
+			if cellTimeout > 0 {
+				cellCtx, cancel = context.WithTimeout(ctx, cellTimeout)
+			}
+
+			var result string
+			var err error
+			if rule, ok := rules[key]; ok {
+				result = value
+				if (rule.French || rule.SmartQuotes || rule.CJKSpacing) && !IsAnkiMetadataColumn(key) {
+					processor := NewTypographyProcessor(rule.French, rule.SmartQuotes)
+					processor.CJKSpacing = rule.CJKSpacing
+					result, err = processor.ProcessTextContext(cellCtx, value)
+				}
+			} else {
+				result, err = formatField(cellCtx, key, value, french, quotes, cjk, autoLang)
+			}
+			cancel()
+
+			if err != nil {
+				if ctx.Err() != nil {
+					return slow, ctx.Err()
+				}
+				slowColumns = append(slowColumns, key)
+				continue
+			}
+			entry.Values[key] = result
+		}
+
+		sort.Strings(slowColumns)
+		for _, column := range slowColumns {
+			slow = append(slow, &SlowField{Entry: entry, Column: column, Size: len(entry.Values[column])})
+		}
+	}
+	return slow, nil
+}
+
+// FormatField returns value of column with the typography ApplyTypography
+// gives it
+func FormatField(column, value string, french, quotes, autoLang bool) string {
+	result, _ := formatField(context.Background(), column, value, french, quotes, false, autoLang)
+	return result
+}
+
+// formatField is FormatField, giving up when ctx is done
+func formatField(ctx context.Context, column, value string, french, quotes, cjk, autoLang bool) (string, error) {
+	// GUIDs, note type and deck names must reach Anki unchanged
+	if IsAnkiMetadataColumn(column) {
+		return value, nil
+	}
+
+	// Determine which typography rules to apply based on column header
+	isEnglish := IsEnglishColumn(column)
+
+	// Only apply French typography to non-English fields
+	applyFrench := french && !isEnglish
+
+	// With auto-detection the cell's detected language decides; the
+	// column name only decides when the text gives too little evidence
+	if french && autoLang {
+		if language := DetectLanguage(value); language != LanguageUnknown {
+			applyFrench = language == LanguageFrench
+		}
+	}
+
+	// Smart quotes apply to every column when enabled
+	processor := NewTypographyProcessor(applyFrench, quotes)
+	processor.CJKSpacing = cjk
+	return processor.ProcessTextContext(ctx, value)
+}
+
+// ApplyTemplates wraps the values of templated columns
+func ApplyTemplates(entries []*DataEntry, templates []*FieldTemplate) {
+	for _, entry := range entries {
+		// Preserved header rows are not field content
+		if entry.LineNumber == 0 {
+			continue
+		}
+		for _, template := range templates {
+			if value, exists := entry.Values[template.Column]; exists {
+				entry.Values[template.Column] = template.Apply(value)
+			}
+		}
+	}
+}
+
+// recordWriter is implemented by csv.Writer and TSVWriter
+type recordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// AnkiWriter streams entries as an Anki import file. The metadata lines are
+// written before the first entry, or by Flush when there are no entries.
+type AnkiWriter struct {
+	Languages map[string]string // Column languages to declare in a #languages line, if any
+
+	w         io.Writer
+	headers   []string
+	separator string
+	writer    recordWriter
+	started   bool
+}
+
+// NewAnkiWriter creates an AnkiWriter for the given columns and separator.
+// Comma output is standard CSV; tab output uses TSVWriter so every record
+// stays on one line.
+func NewAnkiWriter(w io.Writer, headers []string, separator string) *AnkiWriter {
+	var writer recordWriter
+	if separator == SeparatorTab {
+		writer = NewTSVWriter(w)
+	} else {
+		writer = csv.NewWriter(w)
+	}
+	return &AnkiWriter{w: w, headers: headers, separator: separator, writer: writer}
+}
+
+// Write writes one entry, preceded by the metadata lines on the first call
+func (a *AnkiWriter) Write(entry *DataEntry) error {
+	if err := a.start(); err != nil {
+		return err
+	}
+	if err := a.writer.Write(entry.ToCSVRecord(a.headers)); err != nil {
+		return err
+	}
+	return endRecord(a.w, a.writer)
+}
+
+// Flush writes any buffered records and returns the first write error
+func (a *AnkiWriter) Flush() error {
+	if err := a.start(); err != nil {
+		return err
+	}
+	a.writer.Flush()
+	return a.writer.Error()
+}
+
+// start writes the Anki metadata lines directly (not as CSV) once
+func (a *AnkiWriter) start() error {
+	if a.started {
+		return nil
+	}
+	a.started = true
+
+	columns, err := ColumnsDirective(a.headers, a.separator)
+	if err != nil {
+		return err
+	}
+
+	ankiHeaders := []string{
+		"#separator:" + a.separator,
+		"#html:true",
+		columns,
+	}
+	ankiHeaders = append(ankiHeaders, metadataDirectives(a.headers)...)
+	languages, err := LanguagesDirective(a.headers, a.Languages, a.separator)
+	if err != nil {
+		return err
+	}
+	if languages != "" {
+		ankiHeaders = append(ankiHeaders, languages)
+	}
+	for _, header := range ankiHeaders {
+		if _, err := io.WriteString(a.w, header+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ColumnsDirective returns the #columns line for headers. Anki reads the
+// names as one record of the file itself, so they are separated by the output
+// separator and quoted like fields when they contain it or a quote.
+func ColumnsDirective(headers []string, separator string) (string, error) {
+	names := make([]string, len(headers))
+	for i, header := range headers {
+		names[i] = SanitizeHeader(header)
+	}
+
+	var line strings.Builder
+	writer := csv.NewWriter(&line)
+	if separator == SeparatorTab {
+		writer.Comma = '\t'
+	}
+	if err := writer.Write(names); err != nil {
+		return "", err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return "#columns:" + strings.TrimSuffix(line.String(), "\n"), nil
+}
+
+// metadataDirectives maps the metadata columns of a re-processed Anki export
+// back onto their "#guid column:N" style directives so Anki does not import
+// them as fields. The tags column is only mapped alongside other metadata
+// columns, leaving the output of ordinary vocabulary files unchanged.
+func metadataDirectives(headers []string) []string {
+	directives := map[string]string{GUIDColumn: "guid", NoteTypeColumn: "notetype", DeckColumn: "deck"}
+
+	var lines []string
+	tagsColumn := 0
+	for i, header := range headers {
+		if name, ok := directives[header]; ok {
+			lines = append(lines, fmt.Sprintf("#%s column:%d", name, i+1))
+		} else if header == TagsColumn && tagsColumn == 0 {
+			tagsColumn = i + 1
+		}
+	}
+	if len(lines) > 0 && tagsColumn > 0 {
+		lines = append(lines, fmt.Sprintf("#tags column:%d", tagsColumn))
+	}
+	return lines
+}
+
+// WriteAnki writes the Anki metadata lines followed by one record per entry
+// with the given columns
+func WriteAnki(w io.Writer, headers []string, entries []*DataEntry, separator string) error {
+	return writeAnkiLanguages(w, headers, entries, separator, nil)
+}
+
+// writeAnkiLanguages is WriteAnki, also declaring the column languages
+func writeAnkiLanguages(w io.Writer, headers []string, entries []*DataEntry, separator string, languages map[string]string) error {
+	writer := NewAnkiWriter(w, headers, separator)
+	writer.Languages = languages
+	for _, entry := range entries {
+		if err := writer.Write(entry); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
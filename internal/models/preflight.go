@@ -0,0 +1,90 @@
+package models
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// PreflightSampleSize is the number of entries a pre-flight check samples
+const PreflightSampleSize = 10000
+
+// Preflight estimates, from a sample, what a run over a large input will
+// find, so a wrong merge can be caught before the run
+type Preflight struct {
+	Entries    int                // Data entries in the input
+	Sampled    int                // Entries in the sample
+	Duplicates int                // Estimated duplicate entries in the whole input
+	EmptyRates map[string]float64 // Share of empty cells per column in the sample (0.0-1.0)
+}
+
+// EstimatePreflight samples up to sampleSize data entries and estimates the
+// duplicates (compared by hasher) and the empty share of each column. The
+// sample is drawn with a fixed seed, so the same input gives the same
+// estimate; inputs no larger than the sample are counted exactly.
+func EstimatePreflight(entries []*DataEntry, columns []string, hasher Hasher, sampleSize int) *Preflight {
+	data := DataEntries(entries)
+	sample := sampleEntries(data, sampleSize)
+
+	preflight := &Preflight{
+		Entries:    len(data),
+		Sampled:    len(sample),
+		EmptyRates: make(map[string]float64),
+	}
+	if len(sample) == 0 {
+		return preflight
+	}
+
+	counts := make(map[string]int)
+	empty := make(map[string]int)
+	for _, entry := range sample {
+		counts[hasher.Hash(entry)]++
+		for _, column := range columns {
+			if strings.TrimSpace(entry.GetValue(column)) == "" {
+				empty[column]++
+			}
+		}
+	}
+	for _, column := range columns {
+		preflight.EmptyRates[column] = float64(empty[column]) / float64(len(sample))
+	}
+
+	if len(sample) == len(data) {
+		preflight.Duplicates = len(data) - len(counts)
+		return preflight
+	}
+
+	// Both copies of a duplicate pair are sampled with probability q², so
+	// the pairs found in the sample scale up by 1/q²; each pair stands for
+	// one removed entry, which overestimates entries repeated many times
+	pairs := 0
+	for _, count := range counts {
+		pairs += count * (count - 1) / 2
+	}
+	q := float64(len(sample)) / float64(len(data))
+	preflight.Duplicates = min(int(float64(pairs)/(q*q)), len(data)-1)
+	return preflight
+}
+
+// DuplicateRate returns the estimated share of duplicate entries (0.0-1.0)
+func (p *Preflight) DuplicateRate() float64 {
+	if p.Entries == 0 {
+		return 0
+	}
+	return float64(p.Duplicates) / float64(p.Entries)
+}
+
+// sampleEntries returns size entries spread evenly over entries, one at a
+// random position in each stretch, or all of them when there are fewer
+func sampleEntries(entries []*DataEntry, size int) []*DataEntry {
+	if len(entries) <= size {
+		return entries
+	}
+
+	random := rand.New(rand.NewSource(1))
+	sample := make([]*DataEntry, size)
+	for i := range sample {
+		start, end := i*len(entries)/size, (i+1)*len(entries)/size
+		sample[i] = entries[start+random.Intn(end-start)]
+	}
+	return sample
+}
@@ -7,12 +7,22 @@ import (
 
 // ProcessingReport contains summary of processing actions and statistics
 type ProcessingReport struct {
-	InputFiles        []string      // List of processed input file paths
-	TotalInputRecords int           // Count of records before deduplication
-	DuplicatesRemoved int           // Count of duplicate records removed
-	OutputRecords     int           // Final count of records in output
-	ProcessingTime    time.Duration // Total processing time
-	Errors            []string      // List of any processing errors
+	InputFiles        []string            `json:"input_files"`         // List of processed input file paths
+	TotalInputRecords int                 `json:"total_input_records"` // Count of records before deduplication
+	DuplicatesRemoved int                 `json:"duplicates_removed"`  // Count of duplicate records removed
+	SkippedRecords    int                 `json:"skipped_records"`     // Records left out for a field over --max-field-bytes
+	OutputRecords     int                 `json:"output_records"`      // Final count of records in output
+	ProcessingTime    time.Duration       `json:"processing_time_ns"`  // Total processing time
+	Stages            []StageProgress     `json:"stages,omitempty"`    // Time spent in each stage (parsing, merging, ..., writing)
+	Errors            []string            `json:"errors"`              // List of any processing errors
+	Issues            []*Issue            `json:"issues"`              // Non-fatal findings with severity, code and location
+	Columns           []*ColumnStats      `json:"columns"`             // Per-column statistics of the output
+	DuplicateSources  []*DuplicateSources `json:"duplicate_sources"`   // Removed duplicates per pair of files
+	Duplicates        []*DuplicateGroup   `json:"duplicates"`          // Kept entries with the file and line of each removed duplicate
+	Cards             *CardCount          `json:"cards"`               // Cards the output creates in Anki
+	UnmatchedKeys     []string            `json:"unmatched_keys"`      // --join keys not found in the lookup file
+	Replacements      int                 `json:"replacements"`        // Cells changed by the --replace-map substitutions
+	Checksums         map[string]string   `json:"sha256,omitempty"`    // SHA-256 of verified inputs and the written output, by path
 }
 
 // NewProcessingReport creates a new ProcessingReport instance
@@ -24,6 +34,11 @@ func NewProcessingReport() *ProcessingReport {
 		OutputRecords:     0,
 		ProcessingTime:    0,
 		Errors:            []string{},
+		Issues:            []*Issue{},
+		Columns:           []*ColumnStats{},
+		DuplicateSources:  []*DuplicateSources{},
+		Duplicates:        []*DuplicateGroup{},
+		UnmatchedKeys:     []string{},
 	}
 }
 
@@ -55,6 +70,14 @@ func (r *ProcessingReport) AddInputFile(path string) {
 	r.InputFiles = append(r.InputFiles, path)
 }
 
+// AddChecksum records the SHA-256 digest of the file at path
+func (r *ProcessingReport) AddChecksum(path, digest string) {
+	if r.Checksums == nil {
+		r.Checksums = make(map[string]string)
+	}
+	r.Checksums[path] = digest
+}
+
 // AddError adds an error message to the report
 func (r *ProcessingReport) AddError(err error) {
 	if err != nil {
@@ -67,6 +90,30 @@ func (r *ProcessingReport) AddErrorString(message string) {
 	r.Errors = append(r.Errors, message)
 }
 
+// AddIssue adds a finding to the report
+func (r *ProcessingReport) AddIssue(issue *Issue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+// HasIssues returns true if the report contains any issues
+func (r *ProcessingReport) HasIssues() bool {
+	return len(r.Issues) > 0
+}
+
+// CollectColumnStats gathers per-column statistics from the given entries
+func (r *ProcessingReport) CollectColumnStats(headers []string, entries []*DataEntry) {
+	r.Columns = make([]*ColumnStats, len(headers))
+	for i, header := range headers {
+		r.Columns[i] = NewColumnStats(header)
+	}
+
+	for _, entry := range entries {
+		for i, header := range headers {
+			r.Columns[i].Add(entry.GetValue(header))
+		}
+	}
+}
+
 // SetCounts sets the record counts in the report
 func (r *ProcessingReport) SetCounts(totalInput, duplicates, output int) {
 	r.TotalInputRecords = totalInput
@@ -79,6 +126,11 @@ func (r *ProcessingReport) SetProcessingTime(duration time.Duration) {
 	r.ProcessingTime = duration
 }
 
+// SetStages sets the time spent in each stage of the run
+func (r *ProcessingReport) SetStages(stages []StageProgress) {
+	r.Stages = stages
+}
+
 // HasErrors returns true if the report contains any errors
 func (r *ProcessingReport) HasErrors() bool {
 	return len(r.Errors) > 0
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileFiles holds the built-in processing profiles, one YAML file each
+//
+//go:embed profiles/*.yaml
+var profileFiles embed.FS
+
+// Profile bundles the settings of a common kind of deck under a name, so
+// they can be picked with one flag
+type Profile struct {
+	Name        string            `yaml:"-"`           // File name without .yaml
+	Description string            `yaml:"description"` // One line shown by 'ankiprep profiles'
+	Flags       map[string]string `yaml:"flags"`       // Flag values used unless the flag is given
+	Schema      *DeckSchema       `yaml:"schema"`      // Deck schema used unless --schema is given
+}
+
+// BuiltinProfiles returns the profiles embedded in the binary, sorted by name
+func BuiltinProfiles() ([]*Profile, error) {
+	files, err := profileFiles.ReadDir("profiles")
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []*Profile
+	for _, file := range files {
+		data, err := profileFiles.ReadFile(path.Join("profiles", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		profile := &Profile{Name: strings.TrimSuffix(file.Name(), ".yaml")}
+		if err := yaml.Unmarshal(data, profile); err != nil {
+			return nil, fmt.Errorf("invalid profile %s: %v", profile.Name, err)
+		}
+		if profile.Schema != nil {
+			if err := profile.Schema.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid profile %s: %v", profile.Name, err)
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// LookupProfile returns the built-in profile with the given name
+func LookupProfile(name string) (*Profile, error) {
+	profiles, err := BuiltinProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+		names = append(names, profile.Name)
+	}
+	return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// FlagNames returns the names of the flags the profile sets, sorted
+func (p *Profile) FlagNames() []string {
+	names := make([]string, 0, len(p.Flags))
+	for name := range p.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// StageProgress is the work done so far in one stage of a run
+type StageProgress struct {
+	Name     string        `json:"name"`
+	Items    int           `json:"records"`     // Records (or files) the stage handled
+	Duration time.Duration `json:"duration_ns"` // Time spent in the stage, summed over all workers
+}
+
+// ProgressReporter prints progress lines to a writer and adds up the work of
+// each stage. It is safe for concurrent use: every line is written whole, so
+// workers reporting at the same time never interleave their output.
+type ProgressReporter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	stages []*StageProgress
+}
+
+// NewProgressReporter creates a ProgressReporter writing to out; with a nil
+// out nothing is printed but stages are still counted
+func NewProgressReporter(out io.Writer) *ProgressReporter {
+	if out == nil {
+		out = io.Discard
+	}
+	return &ProgressReporter{out: out}
+}
+
+// Printf writes one formatted progress line; a trailing newline is added when
+// missing
+func (p *ProgressReporter) Printf(format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...)
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line += "\n"
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	io.WriteString(p.out, line)
+}
+
+// Add records that items were handled in stage, taking elapsed
+func (p *ProgressReporter) Add(stage string, items int, elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.stages {
+		if s.Name == stage {
+			s.Items += items
+			s.Duration += elapsed
+			return
+		}
+	}
+	p.stages = append(p.stages, &StageProgress{Name: stage, Items: items, Duration: elapsed})
+}
+
+// Stages returns a snapshot of every stage, in the order they were first added
+func (p *ProgressReporter) Stages() []StageProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stages := make([]StageProgress, len(p.stages))
+	for i, s := range p.stages {
+		stages[i] = *s
+	}
+	return stages
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AllColumns is the column of a RegexRule that applies to every column
+const AllColumns = "*"
+
+// sedGroupPattern matches sed-style group references (\1) in replacements
+var sedGroupPattern = regexp.MustCompile(`\\([0-9])`)
+
+// RegexRule is a sed-style find and replace for the values of one column,
+// written as Column:s/pattern/replacement/flags
+type RegexRule struct {
+	Column      string         // Column whose values are changed, or AllColumns
+	Pattern     *regexp.Regexp // Compiled pattern (Go RE2 syntax)
+	Replacement string         // Replacement with $1 (or sed's \1) group references
+	Global      bool           // Replace every match instead of the first (g flag)
+}
+
+// ParseRegexRule parses a rule such as `Back:s/\s+$//`. Any character may
+// follow the s as delimiter (`s|a/b|c|`); it is escaped with a backslash
+// inside the pattern or replacement. The flags are g (every match) and
+// i (ignore case).
+func ParseRegexRule(rule string) (*RegexRule, error) {
+	column, expression, ok := strings.Cut(rule, ":")
+	column = strings.TrimSpace(column)
+	if !ok || column == "" {
+		return nil, fmt.Errorf("expected Column:s/pattern/replacement/flags")
+	}
+	if !strings.HasPrefix(expression, "s") || len(expression) < 2 {
+		return nil, fmt.Errorf("expected s/pattern/replacement/flags after the column name")
+	}
+
+	delimiter, size := firstRune(expression[1:])
+	if delimiter == '\\' || delimiter == ' ' {
+		return nil, fmt.Errorf("%q cannot be used as delimiter", delimiter)
+	}
+	parts := splitUnescaped(expression[1+size:], delimiter)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected s%c pattern%c replacement%c flags", delimiter, delimiter, delimiter)
+	}
+
+	pattern, replacement, flags := parts[0], parts[1], parts[2]
+	r := &RegexRule{Column: column}
+	for _, flag := range flags {
+		switch flag {
+		case 'g':
+			r.Global = true
+		case 'i':
+			pattern = "(?i)" + pattern
+		default:
+			return nil, fmt.Errorf("unknown flag %q (supported: g, i)", flag)
+		}
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	r.Pattern = compiled
+	r.Replacement = sedGroupPattern.ReplaceAllString(replacement, "$${$1}")
+	return r, nil
+}
+
+// firstRune returns the first rune of s and its size in bytes
+func firstRune(s string) (rune, int) {
+	for _, r := range s {
+		return r, len(string(r))
+	}
+	return 0, 0
+}
+
+// splitUnescaped splits s at each delimiter not preceded by a backslash and
+// removes the backslash from escaped delimiters. Other escapes are kept for
+// the regular expression.
+func splitUnescaped(s string, delimiter rune) []string {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if r != delimiter {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == delimiter:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	return append(parts, current.String())
+}
+
+// Apply returns value with the first match, or every match with the g flag,
+// replaced
+func (r *RegexRule) Apply(value string) string {
+	if r.Global {
+		return r.Pattern.ReplaceAllString(value, r.Replacement)
+	}
+
+	match := r.Pattern.FindStringSubmatchIndex(value)
+	if match == nil {
+		return value
+	}
+	replaced := r.Pattern.ExpandString(nil, r.Replacement, value, match)
+	return value[:match[0]] + string(replaced) + value[match[1]:]
+}
+
+// AppliesTo determines if the rule changes the values of column
+func (r *RegexRule) AppliesTo(column string) bool {
+	return r.Column == AllColumns || r.Column == column
+}
+
+// ApplyRegexRules applies the rules in order to the values of the columns
+// they name and returns the number of cells changed
+func ApplyRegexRules(entries []*DataEntry, rules []*RegexRule) int {
+	changed := 0
+	for _, entry := range entries {
+		// Preserved header rows are not field content
+		if entry.LineNumber == 0 {
+			continue
+		}
+
+		for column, value := range entry.Values {
+			result := value
+			for _, rule := range rules {
+				if rule.AppliesTo(column) {
+					result = rule.Apply(result)
+				}
+			}
+			if result != value {
+				entry.Values[column] = result
+				changed++
+			}
+		}
+	}
+	return changed
+}
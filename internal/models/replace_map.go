@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// replaceMapColumns are the columns a --replace-map file must have
+var replaceMapColumns = []string{"Column", "From", "To"}
+
+// ReplaceMap substitutes whole cell values, such as expanding the part of
+// speech "n." to "noun". Rules apply to one column, or to every column when
+// their column is empty.
+type ReplaceMap struct {
+	rules map[string]map[string]string // Column ("" for all) to value to replacement
+	size  int
+}
+
+// NewReplaceMap creates an empty ReplaceMap instance
+func NewReplaceMap() *ReplaceMap {
+	return &ReplaceMap{
+		rules: make(map[string]map[string]string),
+	}
+}
+
+// LoadReplaceMap reads substitutions from a CSV or TSV file with the columns
+// Column, From and To. A later rule for the same column and value is an error,
+// since only one of them could apply.
+func LoadReplaceMap(path string) (*ReplaceMap, error) {
+	inputFile := NewInputFile(path)
+	inputFile.DetectSeparator()
+
+	parser := NewCSVParser()
+	parser.Header = HeaderPresent
+	err := parser.ParseFile(inputFile, func(record []string, line int) error {
+		inputFile.AddRecord(record, line)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read replace map %s: %v", path, err)
+	}
+
+	index := make(map[string]int)
+	for i, header := range inputFile.Headers {
+		index[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	for _, column := range replaceMapColumns {
+		if _, ok := index[strings.ToLower(column)]; !ok {
+			return nil, fmt.Errorf("replace map %s must have the columns %s", path, strings.Join(replaceMapColumns, ", "))
+		}
+	}
+
+	m := NewReplaceMap()
+	for i, record := range inputFile.Records {
+		field := func(name string) string {
+			if j := index[strings.ToLower(name)]; j < len(record) {
+				return record[j]
+			}
+			return ""
+		}
+		if err := m.Add(strings.TrimSpace(field("Column")), field("From"), field("To")); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, inputFile.LineNumber(i), err)
+		}
+	}
+
+	return m, nil
+}
+
+// Add adds a substitution of from by to in column ("" for every column)
+func (m *ReplaceMap) Add(column, from, to string) error {
+	if from == "" {
+		return fmt.Errorf("empty value to replace")
+	}
+	if m.rules[column] == nil {
+		m.rules[column] = make(map[string]string)
+	}
+	if _, exists := m.rules[column][from]; exists {
+		return fmt.Errorf("%q is replaced twice in column %q", from, column)
+	}
+	m.rules[column][from] = to
+	m.size++
+	return nil
+}
+
+// Size returns the number of substitutions
+func (m *ReplaceMap) Size() int {
+	return m.size
+}
+
+// Apply replaces the cell values that exactly match a rule for their column,
+// preferring a column's own rules over rules for every column, and returns
+// the number of cells replaced
+func (m *ReplaceMap) Apply(entries []*DataEntry) int {
+	replaced := 0
+	for _, entry := range entries {
+		// Preserved header rows are not field content
+		if entry.LineNumber == 0 {
+			continue
+		}
+
+		for column, value := range entry.Values {
+			to, ok := m.rules[column][value]
+			if !ok {
+				to, ok = m.rules[""][value]
+			}
+			if ok && to != value {
+				entry.Values[column] = to
+				replaced++
+			}
+		}
+	}
+	return replaced
+}
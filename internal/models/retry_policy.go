@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how often a network request is retried before a run
+// gives up on it, so a flaky connection does not fail a whole batch. The
+// AnkiConnect client, the notification webhook, the trace exporter and
+// FileService writes to network filesystems use it; ankiprep reads no URL
+// inputs and makes no text-to-speech requests, so there are none to retry.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts in total, including the first; below 1 means 1
+	Backoff     time.Duration // Wait before the second attempt; doubles after every failure
+	MaxBackoff  time.Duration // Upper bound of a single wait (0: no bound)
+	Jitter      float64       // Fraction of each wait added or removed at random (0.0-1.0)
+
+	Sleep func(time.Duration) // Waits between attempts; nil means time.Sleep
+}
+
+// Default retry settings for network requests
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBackoff  = 500 * time.Millisecond
+	defaultMaxBackoff    = 10 * time.Second
+	defaultRetryJitter   = 0.2
+)
+
+// NewRetryPolicy creates a RetryPolicy making up to attempts attempts, waiting
+// backoff (doubling, with some jitter) between them
+func NewRetryPolicy(attempts int, backoff time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: attempts,
+		Backoff:     backoff,
+		MaxBackoff:  defaultMaxBackoff,
+		Jitter:      defaultRetryJitter,
+	}
+}
+
+// DefaultRetryPolicy creates a RetryPolicy with the default settings
+func DefaultRetryPolicy() *RetryPolicy {
+	return NewRetryPolicy(DefaultRetryAttempts, DefaultRetryBackoff)
+}
+
+// Retry settings for writing outputs to network filesystems, whose hiccups
+// can last several seconds
+const (
+	networkRetryAttempts = 5
+	networkRetryBackoff  = 2 * time.Second
+	networkMaxBackoff    = 30 * time.Second
+)
+
+// NetworkRetryPolicy creates the RetryPolicy FileService uses for outputs on
+// network filesystems: more attempts and longer waits than DefaultRetryPolicy
+func NetworkRetryPolicy() *RetryPolicy {
+	policy := NewRetryPolicy(networkRetryAttempts, networkRetryBackoff)
+	policy.MaxBackoff = networkMaxBackoff
+	return policy
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, such as a request the server
+// understood and rejected; Do returns it at once
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do runs operation until it succeeds, returns a Permanent error or runs out
+// of attempts, and returns its last error. A nil policy makes one attempt.
+func (p *RetryPolicy) Do(operation func() error) error {
+	attempts := 1
+	if p != nil && p.MaxAttempts > 1 {
+		attempts = p.MaxAttempts
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = operation()
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= attempts {
+			return err
+		}
+		p.sleep(p.Delay(attempt))
+	}
+}
+
+// Delay returns the wait after the given failed attempt (1 for the first)
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration(float64(delay) * p.Jitter * (2*rand.Float64() - 1))
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+func (p *RetryPolicy) sleep(delay time.Duration) {
+	if p.Sleep != nil {
+		p.Sleep(delay)
+		return
+	}
+	time.Sleep(delay)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// RowExplode describes a column whose delimited values each deserve a row of
+// their own, such as a list of synonyms turned into one card per synonym
+type RowExplode struct {
+	Column    string // Column holding the values
+	Delimiter string // Text between values, e.g. ";"
+}
+
+// ParseRowExplode parses an explode specification such as "Synonyms|;": the
+// column and the delimiter, which may itself contain '|'
+func ParseRowExplode(spec string) (*RowExplode, error) {
+	column, delimiter, ok := strings.Cut(spec, "|")
+	if !ok {
+		return nil, fmt.Errorf("invalid explode %q: expected \"<column>|<delimiter>\"", spec)
+	}
+
+	explode := &RowExplode{Column: strings.TrimSpace(column), Delimiter: delimiter}
+	if explode.Column == "" {
+		return nil, fmt.Errorf("invalid explode %q: column name cannot be empty", spec)
+	}
+	if strings.TrimSpace(explode.Delimiter) == "" {
+		return nil, fmt.Errorf("invalid explode %q: delimiter cannot be empty or whitespace", spec)
+	}
+	return explode, nil
+}
+
+// Values returns the non-empty values of a cell, trimmed of surrounding
+// whitespace
+func (x *RowExplode) Values(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, x.Delimiter) {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// Apply returns entries with every entry replaced by one copy per value of
+// the column, the other fields copied as they are. Copies keep the source
+// and line of their entry; a GUID gets the copy's number appended ("abc-2"),
+// so Anki imports each copy as a note of its own. An entry with one value
+// keeps its GUID and has the value trimmed like the copies; entries with no
+// value, and a preserved header row, stay as they are.
+func (x *RowExplode) Apply(entries []*DataEntry) []*DataEntry {
+	exploded := make([]*DataEntry, 0, len(entries))
+	for _, entry := range entries {
+		values := x.Values(entry.GetValue(x.Column))
+		if entry.LineNumber == 0 || len(values) == 0 {
+			exploded = append(exploded, entry)
+			continue
+		}
+		if len(values) == 1 {
+			entry.SetValue(x.Column, values[0])
+			exploded = append(exploded, entry)
+			continue
+		}
+
+		guid := entry.GetValue(GUIDColumn)
+		for i, value := range values {
+			row := NewDataEntry(maps.Clone(entry.Values), entry.Source, entry.LineNumber)
+			row.SetValue(x.Column, value)
+			if guid != "" {
+				row.SetValue(GUIDColumn, fmt.Sprintf("%s-%d", guid, i+1))
+			}
+			exploded = append(exploded, row)
+		}
+	}
+	return exploded
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags a typography rule test case can set, named like the command line flags
+const (
+	RuleFlagFrench   = "french"
+	RuleFlagQuotes   = "smart-quotes"
+	RuleFlagAutoLang = "auto-lang"
+	RuleFlagCJK      = "cjk-spacing"
+)
+
+// RuleTest is one case of a typography rule test file: the input, the flags
+// it is formatted with and the text expected back. Column is the column the
+// value is in, for the rules that depend on it (English columns skip French
+// spacing; GUID, note type and deck columns are never changed).
+type RuleTest struct {
+	Name     string   `yaml:"name"`
+	Column   string   `yaml:"column"`
+	Flags    []string `yaml:"flags"`
+	Input    string   `yaml:"input"`
+	Expected *string  `yaml:"expected"`
+	Line     int      `yaml:"-"` // Line of the case in its file
+}
+
+// LoadRuleTests reads a YAML list of rule test cases, each a mapping with
+// name, column, flags, input and expected keys; any other key is an error,
+// so a misspelt key does not silently test nothing. Double-quoted YAML
+// strings accept \u escapes, so invisible characters can be written out.
+// Cases without a name are named after their line.
+func LoadRuleTests(path string) ([]*RuleTest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rule tests: %v", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid rule tests %s: %v", path, err)
+	}
+	if len(document.Content) == 0 {
+		return nil, fmt.Errorf("%s: no test cases", path)
+	}
+	list := document.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s:%d: expected a list of test cases", path, list.Line)
+	}
+
+	// Nodes cannot reject unknown keys, so the file is decoded strictly first
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var strict []RuleTest
+	if err := decoder.Decode(&strict); err != nil {
+		return nil, fmt.Errorf("invalid rule tests %s: %v", path, err)
+	}
+
+	tests := make([]*RuleTest, 0, len(list.Content))
+	for _, node := range list.Content {
+		test := &RuleTest{Line: node.Line}
+		if err := node.Decode(test); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, node.Line, err)
+		}
+		if test.Name == "" {
+			test.Name = fmt.Sprintf("line %d", node.Line)
+		}
+		if err := test.Validate(); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, node.Line, err)
+		}
+		tests = append(tests, test)
+	}
+	return tests, nil
+}
+
+// Validate checks that the case has an expected value and known flags
+func (t *RuleTest) Validate() error {
+	if t.Expected == nil {
+		return fmt.Errorf("test case %q has no expected value", t.Name)
+	}
+	for _, flag := range t.Flags {
+		switch flag {
+		case RuleFlagFrench, RuleFlagQuotes, RuleFlagAutoLang, RuleFlagCJK:
+		default:
+			return fmt.Errorf("test case %q: unknown flag %q (must be %s, %s, %s or %s)", t.Name, flag, RuleFlagFrench, RuleFlagQuotes, RuleFlagAutoLang, RuleFlagCJK)
+		}
+	}
+	return nil
+}
+
+// Run formats the input as a run with the case's flags would and returns
+// the result and whether it is the expected text
+func (t *RuleTest) Run() (string, bool) {
+	has := func(flag string) bool { return slices.Contains(t.Flags, flag) }
+	got, _ := formatField(context.Background(), t.Column, t.Input, has(RuleFlagFrench), has(RuleFlagQuotes), has(RuleFlagCJK), has(RuleFlagAutoLang))
+	return got, got == *t.Expected
+}
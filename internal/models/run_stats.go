@@ -0,0 +1,139 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RunStats records the metrics of one processing run in the local stats file
+type RunStats struct {
+	Time              time.Time     `json:"time"`               // When the run finished
+	InputFiles        []string      `json:"input_files"`        // Processed input file paths
+	InputRecords      int           `json:"input_records"`      // Records read
+	OutputRecords     int           `json:"output_records"`     // Records written
+	DuplicatesRemoved int           `json:"duplicates_removed"` // Records dropped as duplicates
+	Duration          time.Duration `json:"duration_ns"`        // Total processing time
+}
+
+// NewRunStats creates the stats entry for a finished run from its report
+func NewRunStats(report *ProcessingReport, finished time.Time) *RunStats {
+	return &RunStats{
+		Time:              finished,
+		InputFiles:        append([]string(nil), report.InputFiles...),
+		InputRecords:      report.TotalInputRecords,
+		OutputRecords:     report.OutputRecords,
+		DuplicatesRemoved: report.DuplicatesRemoved,
+		Duration:          report.ProcessingTime,
+	}
+}
+
+// RowsPerSecond returns the throughput of the run
+func (s *RunStats) RowsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.InputRecords) / s.Duration.Seconds()
+}
+
+// DedupeRate returns the percentage of input records removed as duplicates
+func (s *RunStats) DedupeRate() float64 {
+	if s.InputRecords == 0 {
+		return 0
+	}
+	return float64(s.DuplicatesRemoved) / float64(s.InputRecords) * 100.0
+}
+
+// DefaultStatsPath returns the stats file in the user's configuration
+// directory, e.g. ~/.config/ankiprep/stats.jsonl on Linux
+func DefaultStatsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ankiprep", "stats.jsonl"), nil
+}
+
+// AppendRunStats adds one run to the stats file at path (one JSON object per
+// line), creating the file and its directory if needed
+func AppendRunStats(path string, stats *RunStats) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadRunStats reads every run from the stats file at path, oldest first. A
+// missing file means no runs were recorded yet.
+func LoadRunStats(path string) ([]*RunStats, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var runs []*RunStats
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var stats RunStats
+		if err := json.Unmarshal(scanner.Bytes(), &stats); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid stats entry: %v", path, line, err)
+		}
+		runs = append(runs, &stats)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Time.Before(runs[j].Time)
+	})
+	return runs, nil
+}
+
+// MedianRowsPerSecond returns the median throughput of the runs that read any records
+func MedianRowsPerSecond(runs []*RunStats) float64 {
+	var rates []float64
+	for _, run := range runs {
+		if rate := run.RowsPerSecond(); rate > 0 {
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) == 0 {
+		return 0
+	}
+
+	sort.Float64s(rates)
+	middle := len(rates) / 2
+	if len(rates)%2 == 0 {
+		return (rates[middle-1] + rates[middle]) / 2
+	}
+	return rates[middle]
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SimilarityFunc scores how alike two strings are, from 0.0 (unrelated) to 1.0 (identical)
+type SimilarityFunc func(a, b string) float64
+
+// GetSimilarityFunc returns the similarity measure with the given name
+func GetSimilarityFunc(method string) (SimilarityFunc, error) {
+	switch strings.ToLower(method) {
+	case "jaccard":
+		return JaccardSimilarity, nil
+	case "levenshtein":
+		return LevenshteinSimilarity, nil
+	default:
+		return nil, fmt.Errorf("unknown similarity method %q (use jaccard or levenshtein)", method)
+	}
+}
+
+// LevenshteinSimilarity returns 1 minus the edit distance between the lowercased
+// strings divided by the length of the longer one
+func LevenshteinSimilarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.TrimSpace(a)))
+	rb := []rune(strings.ToLower(strings.TrimSpace(b)))
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1.0
+	}
+
+	// Two-row dynamic programming table
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1.0 - float64(prev[len(rb)])/float64(longest)
+}
+
+// JaccardSimilarity returns the size of the intersection of the lowercased word
+// sets divided by the size of their union, so word order and punctuation are
+// ignored ("to run" and "run (to)" score 1.0)
+func JaccardSimilarity(a, b string) float64 {
+	ta := tokenSet(a)
+	tb := tokenSet(b)
+
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1.0
+	}
+
+	intersection := 0
+	for token := range ta {
+		if tb[token] {
+			intersection++
+		}
+	}
+	union := len(ta) + len(tb) - intersection
+
+	return float64(intersection) / float64(union)
+}
+
+// tokenSet splits text into its set of lowercased words
+func tokenSet(text string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		tokens[token] = true
+	}
+	return tokens
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
+
+// ClusterSimilar groups entries whose values in the key column score at or above
+// the threshold, transitively (if A~B and B~C then A, B and C share a cluster).
+// Entries with an empty key are ignored and only clusters of two or more entries
+// are returned, in order of their first entry. Comparison is pairwise, O(n²).
+func ClusterSimilar(entries []*DataEntry, column string, threshold float64, similarity SimilarityFunc) [][]*DataEntry {
+	var candidates []*DataEntry
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.GetValue(column)) != "" {
+			candidates = append(candidates, entry)
+		}
+	}
+
+	// Union-find over candidate indexes
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			if similarity(candidates[i].GetValue(column), candidates[j].GetValue(column)) >= threshold {
+				ri, rj := find(i), find(j)
+				if ri < rj {
+					parent[rj] = ri
+				} else if rj < ri {
+					parent[ri] = rj
+				}
+			}
+		}
+	}
+
+	// Collect clusters keyed by root, preserving input order
+	groups := make(map[int][]*DataEntry)
+	var roots []int
+	for i, entry := range candidates {
+		root := find(i)
+		if _, exists := groups[root]; !exists {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], entry)
+	}
+
+	var clusters [][]*DataEntry
+	for _, root := range roots {
+		if len(groups[root]) > 1 {
+			clusters = append(clusters, groups[root])
+		}
+	}
+
+	return clusters
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/csv"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Other spaced-repetition tools have no notion of note types or fields, so
+// their formats are two-sided: the first column is the front and the other
+// columns, except tags, are joined into the back. A preserved header row is
+// not written.
+
+// brPattern matches HTML line breaks
+var brPattern = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+// tagPattern matches any HTML tag
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// cardSides splits an entry into front and back texts; the back columns are
+// joined with sep
+func cardSides(entry *DataEntry, headers []string, sep string) (string, string) {
+	var front string
+	var back []string
+	hasFront := false
+	for _, header := range headers {
+		if IsTagsColumn(header) {
+			continue
+		}
+		value := entry.GetValue(header)
+		if !hasFront {
+			front, hasFront = value, true
+		} else if value != "" {
+			back = append(back, value)
+		}
+	}
+	return front, strings.Join(back, sep)
+}
+
+// plainText converts field HTML to plain text, with line breaks as newlines
+func plainText(value string) string {
+	value = brPattern.ReplaceAllString(value, "\n")
+	value = tagPattern.ReplaceAllString(value, "")
+	return html.UnescapeString(value)
+}
+
+// WriteMochi writes Mochi markdown: each card's front and back are separated
+// by a line of "---" and cards by a line of "***", the separators to choose in
+// Mochi's markdown import. HTML is kept, since Mochi renders it, but <br> tags
+// become line breaks.
+func WriteMochi(w io.Writer, entries []*DataEntry, headers []string) error {
+	first := true
+	for _, entry := range DataEntries(entries) {
+		front, back := cardSides(entry, headers, "\n\n")
+		card := brPattern.ReplaceAllString(front, "\n") + "\n---\n" + brPattern.ReplaceAllString(back, "\n") + "\n"
+		if !first {
+			card = "***\n" + card
+		}
+		first = false
+		if _, err := io.WriteString(w, card); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRemNote writes a RemNote CSV: question and answer columns without a
+// header row, as plain text
+func WriteRemNote(w io.Writer, entries []*DataEntry, headers []string) error {
+	writer := csv.NewWriter(w)
+	for _, entry := range DataEntries(entries) {
+		front, back := cardSides(entry, headers, "\n")
+		if err := writer.Write([]string{plainText(front), plainText(back)}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteQuizlet writes "term<tab>definition" lines as plain text, the default
+// layout of Quizlet's import box. Quizlet cannot import line breaks inside a
+// term, so they become " / ".
+func WriteQuizlet(w io.Writer, entries []*DataEntry, headers []string) error {
+	oneLine := strings.NewReplacer("\r\n", " / ", "\n", " / ", "\t", " ")
+	for _, entry := range DataEntries(entries) {
+		front, back := cardSides(entry, headers, "\n")
+		line := oneLine.Replace(plainText(front)) + "\t" + oneLine.Replace(plainText(back)) + "\n"
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
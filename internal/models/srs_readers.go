@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Column names given to the two sides of cards read from other tools, so they
+// merge with Front,Back CSV files
+const (
+	FrontColumn = "Front"
+	BackColumn  = "Back"
+)
+
+// separatorNames are the names accepted by ParseSeparatorName
+var separatorNames = map[string]string{
+	"tab":       "\t",
+	"comma":     ",",
+	"semicolon": ";",
+	"newline":   "\n",
+}
+
+// ParseSeparatorName returns the separator named tab, comma, semicolon or
+// newline, or name itself for a custom separator such as " - "
+func ParseSeparatorName(name string) (string, error) {
+	if separator, ok := separatorNames[strings.ToLower(name)]; ok {
+		return separator, nil
+	}
+	if name == "" {
+		return "", fmt.Errorf("separator cannot be empty")
+	}
+	return name, nil
+}
+
+// ParseQuizlet reads a Quizlet export into inputFile: one card per row, term
+// and definition split at the first termSep. Quizlet exports have no header,
+// so the columns are named Front and Back. Blank rows are skipped.
+func ParseQuizlet(r io.Reader, inputFile *InputFile, termSep, rowSep string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	content := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\uFEFF")
+
+	inputFile.Headers = []string{FrontColumn, BackColumn}
+	inputFile.HasHeader = false
+
+	line := 1
+	for _, row := range strings.Split(content, rowSep) {
+		rowLine := line
+		line += strings.Count(row, "\n") + strings.Count(rowSep, "\n")
+
+		if strings.TrimSpace(row) == "" {
+			continue
+		}
+		term, definition, found := strings.Cut(row, termSep)
+		if !found {
+			return fmt.Errorf("line %d: no term separator %q in %q", rowLine, termSep, row)
+		}
+		inputFile.AddRecord([]string{strings.TrimSpace(term), strings.TrimSpace(definition)}, rowLine)
+	}
+
+	if len(inputFile.Records) == 0 {
+		return fmt.Errorf("file contains no data")
+	}
+	return nil
+}
+
+// NormalizeMemrise renames the first two columns of a parsed Memrise export
+// (the learnable and its definition, named after the course languages) to
+// Front and Back; other columns such as Level are kept
+func NormalizeMemrise(inputFile *InputFile) error {
+	if len(inputFile.Headers) < 2 {
+		return fmt.Errorf("memrise export needs at least 2 columns, found %d", len(inputFile.Headers))
+	}
+	inputFile.Headers[0] = FrontColumn
+	inputFile.Headers[1] = BackColumn
+	return nil
+}
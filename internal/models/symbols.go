@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Symbol normalization modes
+const (
+	SymbolsASCII     = "ascii"     // Fullwidth letters, digits and punctuation (：！？) become ASCII
+	SymbolsFullwidth = "fullwidth" // ASCII sentence punctuation (:!?) becomes fullwidth, for Chinese and Japanese
+	SymbolsEmoji     = "emoji"     // Only emoji variation selectors are normalized
+)
+
+// Emoji variation selectors ask for the text (VS15) or emoji (VS16)
+// presentation of the character before them
+const (
+	textVariation  = '\uFE0E'
+	emojiVariation = '\uFE0F'
+	keycap         = '\u20E3' // Combining keycap, which needs a VS16 before it
+	zeroWidthJoin  = '\u200D' // Joins emoji into one, such as the rainbow flag, after a VS16 it keeps
+)
+
+// fullwidthPunctuation is the ASCII punctuation the fullwidth mode converts;
+// the rest stays ASCII since it is syntax (HTML, entities, clozes) as often
+// as punctuation
+const fullwidthPunctuation = "!?:;,()"
+
+// fullwidthProtectedPattern matches, at the start of the text, what the
+// fullwidth mode leaves alone besides markup: Anki media references such as
+// [sound:ni_hao.mp3], URLs, and the separator of digit groups and times
+// (1,000 and 12:30), whose punctuation is not sentence punctuation
+var fullwidthProtectedPattern = regexp.MustCompile(`^(?:\[sound:[^\]]*\]|(?i:https?|ftp)://[^\s<>"]+|[0-9][,:][0-9])`)
+
+// fullwidthOffset is the distance from ASCII ! to ~ to their fullwidth forms
+const fullwidthOffset = 0xFEE0
+
+// SymbolRule normalizes the emoji and lookalike symbols of one column,
+// written as Column:mode
+type SymbolRule struct {
+	Column string // Column whose values are normalized, or AllColumns
+	Mode   string // SymbolsASCII, SymbolsFullwidth or SymbolsEmoji
+}
+
+// ParseSymbolRule parses a rule such as "Front:ascii"
+func ParseSymbolRule(rule string) (*SymbolRule, error) {
+	column, mode, ok := strings.Cut(rule, ":")
+	if !ok {
+		return nil, fmt.Errorf("expected Column:mode")
+	}
+	r := &SymbolRule{Column: strings.TrimSpace(column), Mode: strings.TrimSpace(mode)}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate checks that the rule names a column and a known mode
+func (r *SymbolRule) Validate() error {
+	if r.Column == "" {
+		return fmt.Errorf("symbol rule column name cannot be empty")
+	}
+	switch r.Mode {
+	case SymbolsASCII, SymbolsFullwidth, SymbolsEmoji:
+		return nil
+	}
+	return fmt.Errorf("column %q: unknown symbol mode %q (must be ascii, fullwidth or emoji)", r.Column, r.Mode)
+}
+
+// NormalizeSymbols returns value in the given mode; the fullwidth mode leaves
+// markup, media references, URLs and digit groups as they are. Every mode
+// drops the emoji variation selectors that only choose a presentation, so a
+// heart with and without its VS16 compare equal; a VS16 a keycap or joined
+// emoji needs is kept.
+func NormalizeSymbols(value, mode string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		if mode == SymbolsFullwidth {
+			markup := bidiMarkupPattern.FindString(value[i:])
+			if markup == "" {
+				markup = fullwidthProtectedPattern.FindString(value[i:])
+			}
+			if markup != "" {
+				b.WriteString(markup)
+				i += len(markup)
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(value[i:])
+		i += size
+		switch {
+		case r == textVariation:
+			continue
+		case r == emojiVariation:
+			if next, _ := utf8.DecodeRuneInString(value[i:]); next != keycap && next != zeroWidthJoin {
+				continue
+			}
+		case mode == SymbolsASCII && r == '\u3000': // Ideographic space
+			r = ' '
+		case mode == SymbolsASCII && r >= '\uFF01' && r <= '\uFF5E':
+			r -= fullwidthOffset
+		case mode == SymbolsFullwidth && strings.ContainsRune(fullwidthPunctuation, r):
+			r += fullwidthOffset
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ApplySymbolRules normalizes the values of the columns of rules, in order,
+// and returns how many cells changed
+func ApplySymbolRules(entries []*DataEntry, rules []*SymbolRule) int {
+	changed := 0
+	for _, entry := range DataEntries(entries) {
+		for column, value := range entry.Values {
+			if IsAnkiMetadataColumn(column) {
+				continue
+			}
+			result := value
+			for _, rule := range rules {
+				if rule.Column == column || rule.Column == AllColumns {
+					result = NormalizeSymbols(result, rule.Mode)
+				}
+			}
+			if result != value {
+				entry.Values[column] = result
+				changed++
+			}
+		}
+	}
+	return changed
+}
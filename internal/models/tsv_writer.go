@@ -0,0 +1,64 @@
+package models
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// tsvFieldReplacer translates characters that would break a tab-separated row
+// into their HTML equivalents, which Anki renders the same way with #html:true
+var tsvFieldReplacer = strings.NewReplacer(
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+	"\t", "&#9;",
+)
+
+// TSVWriter writes tab-separated records that Anki can import. Unlike
+// encoding/csv with a tab separator, it never lets a field span several lines:
+// newlines become <br> and tabs become &#9;. Fields containing double quotes are
+// quoted, since Anki treats a leading quote as the start of a quoted field.
+type TSVWriter struct {
+	w *bufio.Writer
+}
+
+// NewTSVWriter creates a new TSVWriter writing to w
+func NewTSVWriter(w io.Writer) *TSVWriter {
+	return &TSVWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes a single record; the output is buffered until Flush
+func (t *TSVWriter) Write(record []string) error {
+	for i, field := range record {
+		if i > 0 {
+			if err := t.w.WriteByte('\t'); err != nil {
+				return err
+			}
+		}
+		if _, err := t.w.WriteString(EscapeTSVField(field)); err != nil {
+			return err
+		}
+	}
+	return t.w.WriteByte('\n')
+}
+
+// Flush writes any buffered data to the underlying writer
+func (t *TSVWriter) Flush() {
+	t.w.Flush()
+}
+
+// Error reports any error from a previous Write or Flush
+func (t *TSVWriter) Error() error {
+	_, err := t.w.Write(nil)
+	return err
+}
+
+// EscapeTSVField returns field as it is written by TSVWriter
+func EscapeTSVField(field string) string {
+	field = tsvFieldReplacer.Replace(field)
+	if strings.Contains(field, `"`) {
+		field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+	}
+	return field
+}
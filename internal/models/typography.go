@@ -96,13 +96,13 @@ type TypographyResult struct {
 	// Number of warnings logged (malformed blocks)
 	WarningCount int
 	// Processing errors (non-fatal)
-	Warnings []string
+	Warnings []*Issue
 }
 
 // NewTypographyResult creates a new TypographyResult with validation.
-func NewTypographyResult(processedText string, clozeCount int, warnings []string) *TypographyResult {
+func NewTypographyResult(processedText string, clozeCount int, warnings []*Issue) *TypographyResult {
 	if warnings == nil {
-		warnings = []string{}
+		warnings = []*Issue{}
 	}
 
 	return &TypographyResult{
@@ -114,7 +114,7 @@ func NewTypographyResult(processedText string, clozeCount int, warnings []string
 }
 
 // AddWarning adds a warning to the result and updates the warning count.
-func (tr *TypographyResult) AddWarning(warning string) {
+func (tr *TypographyResult) AddWarning(warning *Issue) {
 	tr.Warnings = append(tr.Warnings, warning)
 	tr.WarningCount = len(tr.Warnings)
 }
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TypographyCache keeps the typography of each input file's cells between
+// runs, so re-running a batch where most files are unchanged only formats
+// the cells of the changed ones. Each input file has one segment file in the
+// cache directory, named by a hash of the file's content and one of the
+// typography options: a changed file or option simply finds no segment, so
+// nothing is ever invalidated by time or file dates.
+//
+// An entry is only taken from the cache when every one of its cells is
+// found; the others are formatted as usual and stored by Store.
+type TypographyCache struct {
+	Hits   int // Entries whose cells all came from the cache
+	Misses int // Entries formatted because a cell was not cached
+
+	segments map[string]*cacheSegment         // By input file path
+	pending  map[*DataEntry]map[string]string // Values of missed entries before typography
+}
+
+// cacheSegment is the cached typography of the cells of one input file
+type cacheSegment struct {
+	path  string
+	cells map[string]string // Formatted value by cell key
+	used  map[string]string // Cells looked up or stored in this run
+	dirty bool
+}
+
+// NewTypographyCache opens the cache in dir, creating it if needed, for the
+// given input files and typography options. Options must describe
+// everything that changes how a cell is formatted (the program version
+// included), as they are only compared by hash.
+func NewTypographyCache(dir string, inputFiles []*InputFile, options ...string) (*TypographyCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create cache directory: %v", err)
+	}
+
+	optionsHash := sha256.Sum256([]byte(strings.Join(options, "\x00")))
+	cache := &TypographyCache{
+		segments: make(map[string]*cacheSegment),
+		pending:  make(map[*DataEntry]map[string]string),
+	}
+	for _, inputFile := range inputFiles {
+		name := fmt.Sprintf("%x-%x.json", inputFile.contentHash()[:16], optionsHash[:8])
+		cache.segments[inputFile.Path] = &cacheSegment{path: filepath.Join(dir, name)}
+	}
+	return cache, nil
+}
+
+// contentHash returns a SHA-256 hash of the headers and records of the file
+func (f *InputFile) contentHash() []byte {
+	hash := sha256.New()
+	for _, row := range append([][]string{f.Headers}, f.Records...) {
+		for _, value := range row {
+			fmt.Fprintf(hash, "%d:%s", len(value), value)
+		}
+		hash.Write([]byte{'\n'})
+	}
+	return hash.Sum(nil)
+}
+
+// cellKey returns the key a cell's formatted value is cached under
+func cellKey(column, value string) string {
+	return column + "\x00" + value
+}
+
+// Apply gives the entries found in the cache their cached values and returns
+// the others, which still need typography. A nil cache returns entries.
+func (c *TypographyCache) Apply(entries []*DataEntry) []*DataEntry {
+	if c == nil {
+		return entries
+	}
+
+	var missed []*DataEntry
+	for _, entry := range entries {
+		segment := c.segment(entry.Source)
+		if segment == nil {
+			missed = append(missed, entry)
+			continue
+		}
+
+		cached := make(map[string]string, len(entry.Values))
+		for column, value := range entry.Values {
+			result, ok := segment.cells[cellKey(column, value)]
+			if !ok {
+				break
+			}
+			cached[column] = result
+		}
+		if len(cached) < len(entry.Values) {
+			c.pending[entry] = maps.Clone(entry.Values)
+			c.Misses++
+			missed = append(missed, entry)
+			continue
+		}
+
+		for column, value := range entry.Values {
+			segment.used[cellKey(column, value)] = cached[column]
+		}
+		for column, result := range cached {
+			entry.Values[column] = result
+		}
+		c.Hits++
+	}
+	return missed
+}
+
+// Store caches the typography of entries returned by Apply, once it has run.
+// Cells left unformatted because they were too slow are not cached.
+func (c *TypographyCache) Store(entries []*DataEntry, slow []*SlowField) {
+	if c == nil {
+		return
+	}
+
+	skip := make(map[*DataEntry]map[string]bool)
+	for _, field := range slow {
+		if skip[field.Entry] == nil {
+			skip[field.Entry] = make(map[string]bool)
+		}
+		skip[field.Entry][field.Column] = true
+	}
+
+	for _, entry := range entries {
+		original, ok := c.pending[entry]
+		segment := c.segment(entry.Source)
+		if !ok || segment == nil {
+			continue
+		}
+		for column, value := range original {
+			if !skip[entry][column] {
+				segment.used[cellKey(column, value)] = entry.GetValue(column)
+				segment.dirty = true
+			}
+		}
+		delete(c.pending, entry)
+	}
+}
+
+// Save writes the segments that gained cells in this run. Each segment is
+// rewritten with only the cells of this run, so it does not keep the
+// formatting of values that were since changed by other options.
+func (c *TypographyCache) Save() error {
+	if c == nil {
+		return nil
+	}
+
+	for _, segment := range c.segments {
+		if !segment.dirty {
+			continue
+		}
+		data, err := json.Marshal(segment.used)
+		if err != nil {
+			return err
+		}
+		tempPath := TempPath(segment.path)
+		if err := os.WriteFile(tempPath, data, 0644); err != nil {
+			return fmt.Errorf("cannot write cache segment: %v", err)
+		}
+		if err := os.Rename(tempPath, segment.path); err != nil {
+			os.Remove(tempPath)
+			return fmt.Errorf("cannot write cache segment: %v", err)
+		}
+	}
+	return nil
+}
+
+// segment returns the segment of an input file, reading it on first use; a
+// missing or unreadable segment file is an empty cache. It returns nil for
+// entries from no known input file.
+func (c *TypographyCache) segment(source string) *cacheSegment {
+	segment := c.segments[source]
+	if segment == nil || segment.cells != nil {
+		return segment
+	}
+
+	segment.cells = make(map[string]string)
+	segment.used = make(map[string]string)
+	if data, err := os.ReadFile(segment.path); err == nil {
+		if err := json.Unmarshal(data, &segment.cells); err != nil {
+			segment.cells = make(map[string]string)
+		}
+	}
+	return segment
+}
@@ -0,0 +1,78 @@
+package models
+
+import "strings"
+
+// DefaultChunkBytes is the chunk size TypographyProcessor uses for large
+// cells, such as HTML notes of several hundred KB
+const DefaultChunkBytes = 64 << 10
+
+// typographyChunks cuts text (with its code already protected) into chunks
+// of at least size bytes that typography can process one at a time with the
+// same result as the whole text. A chunk ends after a newline or an HTML tag,
+// before anything a typography rule looks back at (:;!? or »), only where
+// every quote pair before the cut is closed and never inside a cloze
+// deletion. Quotes inside cloze deletions are not counted, as smart quotes
+// converts them on their own. Text without such a place stays in one chunk.
+func typographyChunks(text string, size int) []string {
+	var chunks []string
+	start, scanned := 0, 0
+	doubleQuotes, singleQuotes := 0, 0
+	clozes, next := scanClozes(text), 0
+
+	for cut := 1; cut < len(text); cut++ {
+		if c := text[cut-1]; c != '\n' && c != '>' {
+			continue
+		}
+		if strings.ContainsRune(":;!?", rune(text[cut])) || strings.HasPrefix(text[cut:], "»") {
+			continue
+		}
+
+		// No rule matches across a newline or >, so the pieces between
+		// candidate cuts can be counted on their own
+		for next < len(clozes) && clozes[next].end <= scanned {
+			next++
+		}
+		double, single := quoteCounts(text, scanned, cut, clozes[next:])
+		doubleQuotes += double
+		singleQuotes += single
+		scanned = cut
+
+		for next < len(clozes) && clozes[next].end <= cut {
+			next++
+		}
+		inCloze := next < len(clozes) && clozes[next].start < cut
+
+		if cut-start >= size && doubleQuotes%2 == 0 && singleQuotes%2 == 0 && !inCloze {
+			chunks = append(chunks, text[start:cut])
+			start = cut
+			doubleQuotes, singleQuotes = 0, 0
+		}
+	}
+	return append(chunks, text[start:])
+}
+
+// quoteCounts returns the double quotes and the single quotes that are not
+// apostrophes in text[from:to], leaving out the parts inside clozes, which
+// are the deletions that do not end before from, in order
+func quoteCounts(text string, from, to int, clozes []clozeSpan) (int, int) {
+	double, single := 0, 0
+	count := func(piece string) {
+		double += strings.Count(piece, `"`)
+		single += strings.Count(piece, "'") - len(apostrophePattern.FindAllStringIndex(piece, -1))
+	}
+
+	pos := from
+	for _, cloze := range clozes {
+		if cloze.start >= to {
+			break
+		}
+		if cloze.start > pos {
+			count(text[pos:cloze.start])
+		}
+		pos = max(pos, cloze.end)
+	}
+	if pos < to {
+		count(text[pos:to])
+	}
+	return double, single
+}
@@ -1,16 +1,48 @@
 package models
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 )
 
+// codePattern matches code that typography leaves alone: fenced ``` blocks,
+// inline `code` spans and HTML <pre> and <code> elements
+var codePattern = regexp.MustCompile("(?is)```.*?```|`[^`\n]+`|<pre\\b[^>]*>.*?</pre>|<code\\b[^>]*>.*?</code>")
+
+// apostrophePattern matches the apostrophes of contractions and possessives
+var apostrophePattern = regexp.MustCompile(`(\w)'(\w)`)
+
+// Patterns of the typography rules, compiled once since every cell (and
+// every chunk of a large cell) runs them
+var (
+	doubleQuotePattern    = regexp.MustCompile(`"([^"]*)"`)
+	singleQuotePattern    = regexp.MustCompile(`'([^']*)'`)
+	guillemetOpenPattern  = regexp.MustCompile("«([^\u202F\\s])")
+	guillemetClosePattern = regexp.MustCompile("([^\u202F\\s])»")
+)
+
+// frenchPunctuation are the marks French typography puts a NNBSP before, and
+// frenchPunctuationPatterns match each directly after a word character
+var (
+	frenchPunctuation         = []string{":", ";", "!", "?"}
+	frenchPunctuationPatterns = map[string]*regexp.Regexp{
+		":": regexp.MustCompile(`(\w):`),
+		";": regexp.MustCompile(`(\w);`),
+		"!": regexp.MustCompile(`(\w)!`),
+		"?": regexp.MustCompile(`(\w)\?`),
+	}
+)
+
 // TypographyProcessor handles text formatting transformations
 type TypographyProcessor struct {
 	FrenchMode         bool // Whether French typography rules are enabled
 	ConvertSmartQuotes bool // Whether to convert straight quotes to smart quotes
+	CJKSpacing         bool // Whether to space CJK text by CJK rules (see ApplyCJKSpacing)
+	ChunkBytes         int  // Process longer texts in chunks of about this size (0: whole)
 }
 
 // NewTypographyProcessor creates a new TypographyProcessor instance
@@ -18,37 +50,236 @@ func NewTypographyProcessor(frenchMode, smartQuotes bool) *TypographyProcessor {
 	return &TypographyProcessor{
 		FrenchMode:         frenchMode,
 		ConvertSmartQuotes: smartQuotes,
+		ChunkBytes:         DefaultChunkBytes,
 	}
 }
 
 // ProcessText applies all typography transformations to the input text
 func (tp *TypographyProcessor) ProcessText(text string) string {
+	result, _ := tp.ProcessTextContext(context.Background(), text)
+	return result
+}
+
+// ProcessTextContext is ProcessText, giving up when ctx is done: it then
+// returns text unchanged with the context's error. Cancellation is checked
+// between steps, chunks and protected regions, so a pathological cell stops
+// soon after its deadline.
+func (tp *TypographyProcessor) ProcessTextContext(ctx context.Context, text string) (string, error) {
 	if tp == nil {
-		return text
+		return text, nil
+	}
+
+	// Protect code samples, where quotes and spacing are syntax
+	result, code, err := protectCode(ctx, text)
+	if err != nil {
+		return text, err
+	}
+
+	// Large cells (long HTML notes) are processed a chunk at a time
+	if tp.ChunkBytes > 0 && len(result) > tp.ChunkBytes {
+		var b strings.Builder
+		for _, chunk := range typographyChunks(result, tp.ChunkBytes) {
+			processed, err := tp.processChunk(ctx, chunk)
+			if err != nil {
+				return text, err
+			}
+			b.WriteString(processed)
+		}
+		result = b.String()
+	} else if result, err = tp.processChunk(ctx, result); err != nil {
+		return text, err
 	}
 
-	result := text
+	return restoreCode(result, code), nil
+}
+
+// processChunk applies the enabled typography rules to text whose code
+// samples are already protected
+func (tp *TypographyProcessor) processChunk(ctx context.Context, text string) (string, error) {
+	// CJK text gets its own spacing, which French typography then keeps
+	if tp.CJKSpacing {
+		text = ApplyCJKSpacing(text)
+	}
 
 	// Apply French typography if enabled
 	if tp.FrenchMode {
-		result = tp.applyFrenchTypography(result)
-		result = tp.applyGuillemetSpacing(result)
+		// Hebrew, Arabic and CJK phrases keep their own spacing and
+		// punctuation
+		var kept *protectedRegions
+		text, kept = protectSpans(text, mergeSpans(rtlSpans(text), cjkSpans(text)), "SCRIPT")
+
+		var err error
+		if text, err = tp.applyFrenchTypography(ctx, text); err != nil {
+			return "", err
+		}
+		text = tp.applyGuillemetSpacing(text)
+
+		// FINAL STEP: Ensure all NBSP are converted to NNBSP for consistency
+		// This is a final cleanup to catch any NBSP that might have been missed
+		const nbsp = "\u00A0"
+		const nnbsp = "\u202F"
+		text = strings.ReplaceAll(text, nbsp, nnbsp)
+
+		text = kept.restore(text)
 	}
 
 	// Apply smart quotes if enabled
 	if tp.ConvertSmartQuotes {
-		result = tp.convertSmartQuotes(result)
+		var err error
+		if text, err = tp.applySmartQuotes(ctx, text); err != nil {
+			return "", err
+		}
 	}
 
-	// FINAL STEP: Ensure all NBSP are converted to NNBSP for consistency
-	// This is a final cleanup to catch any NBSP that might have been missed
-	if tp.FrenchMode {
-		const nbsp = "\u00A0"
-		const nnbsp = "\u202F"
-		result = strings.ReplaceAll(result, nbsp, nnbsp)
+	return text, nil
+}
+
+// protectCode replaces each code region of text with a numbered placeholder
+// and returns the regions for restoreCode
+func protectCode(ctx context.Context, text string) (string, *protectedRegions, error) {
+	return protectRegions(ctx, text, codePattern, "CODE")
+}
+
+// restoreCode puts the code regions back in place of their placeholders
+func restoreCode(text string, code *protectedRegions) string {
+	return code.restore(text)
+}
+
+// protectClozes replaces each cloze deletion of text, as found by
+// scanClozes, with a numbered placeholder; an unclosed deletion has only its
+// opening protected, so its :: survives
+func protectClozes(ctx context.Context, text string) (string, *protectedRegions, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	var spans [][2]int
+	for _, cloze := range scanClozes(text) {
+		spans = append(spans, [2]int{cloze.start, cloze.end})
 	}
+	protected, clozes := protectSpans(text, spans, "CLOZE")
+	return protected, clozes, nil
+}
 
-	return result
+// protectedRegions are the regions protectSpans replaced with placeholders
+type protectedRegions struct {
+	prefix  string // Start of every placeholder, followed by its index and "__"
+	regions []string
+}
+
+// protectRegions replaces every match of pattern with a numbered placeholder
+// of the given kind. Finding a match can take long in pathological text, so
+// ctx is checked before each.
+func protectRegions(ctx context.Context, text string, pattern *regexp.Regexp, kind string) (string, *protectedRegions, error) {
+	var spans [][2]int
+	for pos := 0; pos < len(text); {
+		if err := ctx.Err(); err != nil {
+			return "", nil, err
+		}
+		loc := pattern.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		spans = append(spans, [2]int{pos + loc[0], pos + loc[1]})
+		pos += loc[1]
+	}
+	protected, regions := protectSpans(text, spans, kind)
+	return protected, regions, nil
+}
+
+// protectSpans replaces the given [start, end) spans of text, in order and
+// not overlapping, with numbered placeholders of the given kind, in one pass
+// so thousands of regions stay cheap
+func protectSpans(text string, spans [][2]int, kind string) (string, *protectedRegions) {
+	// Text that already contains a placeholder gets longer ones, so it is
+	// never mistaken for a protected region
+	protected := &protectedRegions{prefix: "__" + kind + "_PLACEHOLDER_"}
+	for strings.Contains(text, protected.prefix) {
+		protected.prefix = "_" + protected.prefix
+	}
+	if len(spans) == 0 {
+		return text, protected
+	}
+
+	var b strings.Builder
+	pos := 0
+	for _, span := range spans {
+		b.WriteString(text[pos:span[0]])
+		fmt.Fprintf(&b, "%s%d__", protected.prefix, len(protected.regions))
+		protected.regions = append(protected.regions, text[span[0]:span[1]])
+		pos = span[1]
+	}
+	b.WriteString(text[pos:])
+	return b.String(), protected
+}
+
+// restore puts each region back in place of its placeholder
+func (p *protectedRegions) restore(text string) string {
+	if len(p.regions) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(text, p.prefix)
+		if i < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		rest := text[i+len(p.prefix):]
+
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		index, err := strconv.Atoi(rest[:digits])
+		if err != nil || index >= len(p.regions) || !strings.HasPrefix(rest[digits:], "__") {
+			// Not a placeholder after all; look again one byte further,
+			// since a placeholder may overlap what looked like one
+			b.WriteString(text[:i+1])
+			text = text[i+1:]
+			continue
+		}
+		b.WriteString(text[:i])
+		b.WriteString(p.regions[index])
+		text = rest[digits+2:]
+	}
+}
+
+// applySmartQuotes converts straight quotes to smart quotes outside cloze
+// deletions and in their answers on their own. Cloze hints keep their quotes,
+// which are often deliberate (a hint quoting the original spelling).
+func (tp *TypographyProcessor) applySmartQuotes(ctx context.Context, text string) (string, error) {
+	text, clozeDeletions, err := protectClozes(ctx, text)
+	if err != nil {
+		return "", err
+	}
+
+	text = tp.convertSmartQuotes(text)
+	for i, cloze := range clozeDeletions.regions {
+		if clozeDeletions.regions[i], err = tp.convertClozeQuotes(ctx, cloze); err != nil {
+			return "", err
+		}
+	}
+
+	return clozeDeletions.restore(text), nil
+}
+
+// convertClozeQuotes converts the quotes of the answer of a cloze deletion
+// ({{c1::answer::hint}}), leaving its hint as written; deletions nested in
+// the answer are handled the same way
+func (tp *TypographyProcessor) convertClozeQuotes(ctx context.Context, cloze string) (string, error) {
+	spans := scanClozes(cloze)
+	if len(spans) == 0 || !spans[0].closed {
+		// The opening of an unclosed deletion has no answer yet
+		return cloze, nil
+	}
+
+	span := spans[0]
+	answer, err := tp.applySmartQuotes(ctx, cloze[span.content:span.answerEnd()])
+	if err != nil {
+		return "", err
+	}
+	return cloze[:span.content] + answer + cloze[span.answerEnd():], nil
 }
 
 // convertSmartQuotes converts straight quotes to smart quotes
@@ -64,10 +295,7 @@ func (tp *TypographyProcessor) convertSmartQuotes(text string) string {
 
 // convertDoubleQuotes converts straight double quotes to smart quotes
 func (tp *TypographyProcessor) convertDoubleQuotes(text string) string {
-	// Pattern to find quoted text
-	re := regexp.MustCompile(`"([^"]*)"`)
-
-	result := re.ReplaceAllStringFunc(text, func(match string) string {
+	result := doubleQuotePattern.ReplaceAllStringFunc(text, func(match string) string {
 		// Remove the surrounding quotes and replace with smart quotes
 		content := match[1 : len(match)-1]
 		return "\u201c" + content + "\u201d" // " and "
@@ -79,12 +307,10 @@ func (tp *TypographyProcessor) convertDoubleQuotes(text string) string {
 // convertSingleQuotes converts straight single quotes to smart apostrophes
 func (tp *TypographyProcessor) convertSingleQuotes(text string) string {
 	// Convert apostrophes in contractions and possessives
-	re := regexp.MustCompile(`(\w)'(\w)`)
-	text = re.ReplaceAllString(text, `$1\u2019$2`) // '
+	text = apostrophePattern.ReplaceAllString(text, `$1\u2019$2`) // '
 
 	// Convert single quotes around text
-	re = regexp.MustCompile(`'([^']*)'`)
-	text = re.ReplaceAllStringFunc(text, func(match string) string {
+	text = singleQuotePattern.ReplaceAllStringFunc(text, func(match string) string {
 		content := match[1 : len(match)-1]
 		return "\u2018" + content + "\u2019" // ' and '
 	})
@@ -93,7 +319,7 @@ func (tp *TypographyProcessor) convertSingleQuotes(text string) string {
 }
 
 // applyFrenchTypography applies French typography rules (NNBSP before punctuation)
-func (tp *TypographyProcessor) applyFrenchTypography(text string) string {
+func (tp *TypographyProcessor) applyFrenchTypography(ctx context.Context, text string) (string, error) {
 	// NNBSP (U+202F) - Narrow No-Break Space
 	const nnbsp = "\u202F"
 	// NBSP (U+00A0) - Non-Breaking Space (convert all to NNBSP)
@@ -103,27 +329,25 @@ func (tp *TypographyProcessor) applyFrenchTypography(text string) string {
 	text = strings.ReplaceAll(text, nbsp, nnbsp)
 
 	// STEP 2: Protect cloze deletion syntax from French typography rules
-	// Find all cloze deletions and temporarily replace them with placeholders
-	clozePattern := regexp.MustCompile(`\{\{c\d+::[^}]*\}\}`)
-	clozeDeletions := clozePattern.FindAllString(text, -1)
-
-	// Replace cloze deletions with numbered placeholders
-	for i, cloze := range clozeDeletions {
-		placeholder := fmt.Sprintf("__CLOZE_PLACEHOLDER_%d__", i)
-		text = strings.Replace(text, cloze, placeholder, 1)
+	// Replace all cloze deletions with numbered placeholders; the opening of
+	// an unclosed deletion is protected too, so its :: survives
+	text, clozeDeletions, err := protectClozes(ctx, text)
+	if err != nil {
+		return "", err
 	}
 
 	// STEP 3: Apply NNBSP before French punctuation marks: : ; ! ?
-	punctuation := []string{":", ";", "!", "?"}
+	for _, punct := range frenchPunctuation {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 
-	for _, punct := range punctuation {
 		// Replace regular space + punctuation with NNBSP + punctuation
 		text = strings.ReplaceAll(text, " "+punct, nnbsp+punct)
 
 		// Handle cases where there's no space before punctuation
 		// Use regex to find word character directly followed by punctuation
-		pattern := regexp.MustCompile(`(\w)` + regexp.QuoteMeta(punct))
-		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+		text = frenchPunctuationPatterns[punct].ReplaceAllStringFunc(text, func(match string) string {
 			// Extract the word character and punctuation
 			wordChar := match[:len(match)-1]
 
@@ -137,15 +361,12 @@ func (tp *TypographyProcessor) applyFrenchTypography(text string) string {
 	}
 
 	// STEP 4: Restore cloze deletions from placeholders
-	for i, cloze := range clozeDeletions {
-		placeholder := fmt.Sprintf("__CLOZE_PLACEHOLDER_%d__", i)
-		text = strings.Replace(text, placeholder, cloze, 1)
-	}
+	text = clozeDeletions.restore(text)
 
 	// Handle French guillemets (quotation marks)
 	text = tp.applyGuillemetSpacing(text)
 
-	return text
+	return text, nil
 }
 
 // applyGuillemetSpacing applies proper spacing to French guillemets
@@ -165,12 +386,10 @@ func (tp *TypographyProcessor) applyGuillemetSpacing(text string) string {
 	// Only work with NNBSP now since all NBSP should be converted
 
 	// Opening guillemets: « followed by non-NNBSP character (but not space)
-	openPattern := regexp.MustCompile("«([^" + regexp.QuoteMeta(nnbsp) + `\s])`)
-	text = openPattern.ReplaceAllString(text, "«"+nnbsp+"$1")
+	text = guillemetOpenPattern.ReplaceAllString(text, "«"+nnbsp+"$1")
 
 	// Closing guillemets: non-NNBSP character followed by » (but not space)
-	closePattern := regexp.MustCompile("([^" + regexp.QuoteMeta(nnbsp) + `\s])»`)
-	text = closePattern.ReplaceAllString(text, "$1"+nnbsp+"»")
+	text = guillemetClosePattern.ReplaceAllString(text, "$1"+nnbsp+"»")
 
 	return text
 } // convertLineBreaks converts embedded newlines to HTML line breaks
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffContext is the number of unchanged lines UnifiedDiff shows around each
+// change, as in diff -u
+const DiffContext = 3
+
+// maxDiffTable bounds the cells of the table diffLines fills to find the
+// longest common subsequence of the changed middle of two texts; beyond it,
+// the middle is shown as removed then added rather than using gigabytes
+const maxDiffTable = 16 << 20
+
+// diffLine is one line of an edit script: ' ' kept, '-' removed, '+' added
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns the differences between the texts from and to in
+// unified diff format, labelled with fromName and toName, or "" when they
+// are equal
+func UnifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+
+	script := diffLines(splitLines(from), splitLines(to))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range diffHunks(script) {
+		writeHunk(&b, script, hunk[0], hunk[1])
+	}
+	return b.String()
+}
+
+// splitLines splits text into lines, keeping a missing final newline visible
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes an edit script turning a into b. The lines a and b
+// start and end with are kept as they are; the shortest script for the rest
+// comes from their longest common subsequence, unless the table it needs is
+// over maxDiffTable cells.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var script []diffLine
+	for _, line := range a[:prefix] {
+		script = append(script, diffLine{' ', line})
+	}
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(middleA)+1)*(len(middleB)+1) > maxDiffTable {
+		for _, line := range middleA {
+			script = append(script, diffLine{'-', line})
+		}
+		for _, line := range middleB {
+			script = append(script, diffLine{'+', line})
+		}
+	} else {
+		script = append(script, commonSubsequenceScript(middleA, middleB)...)
+	}
+	for _, line := range a[len(a)-suffix:] {
+		script = append(script, diffLine{' ', line})
+	}
+	return script
+}
+
+// commonSubsequenceScript computes the shortest edit script turning a into b
+// from their longest common subsequence
+func commonSubsequenceScript(a, b []string) []diffLine {
+	// common[i][j] is the length of the LCS of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var script []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, diffLine{' ', a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			script = append(script, diffLine{'-', a[i]})
+			i++
+		default:
+			script = append(script, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		script = append(script, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		script = append(script, diffLine{'+', b[j]})
+	}
+	return script
+}
+
+// diffHunks returns the [start, end) ranges of script to print: every change
+// with DiffContext lines around it, merging changes that are close together
+func diffHunks(script []diffLine) [][2]int {
+	var hunks [][2]int
+	for i, line := range script {
+		if line.op == ' ' {
+			continue
+		}
+		start := max(i-DiffContext, 0)
+		end := min(i+1+DiffContext, len(script))
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = end
+			continue
+		}
+		hunks = append(hunks, [2]int{start, end})
+	}
+	return hunks
+}
+
+// writeHunk writes script[start:end] with its @@ header
+func writeHunk(b *strings.Builder, script []diffLine, start, end int) {
+	// Line numbers of the hunk start in both texts
+	fromLine, toLine := 1, 1
+	for _, line := range script[:start] {
+		if line.op != '+' {
+			fromLine++
+		}
+		if line.op != '-' {
+			toLine++
+		}
+	}
+
+	fromCount, toCount := 0, 0
+	for _, line := range script[start:end] {
+		if line.op != '+' {
+			fromCount++
+		}
+		if line.op != '-' {
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount))
+	for _, line := range script[start:end] {
+		b.WriteByte(line.op)
+		b.WriteString(line.text)
+		if !strings.HasSuffix(line.text, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start and length of a hunk side; an empty side
+// starts at the line before it, as diff -u does
+func hunkRange(line, count int) string {
+	if count == 0 {
+		line--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
@@ -0,0 +1,39 @@
+package models
+
+import "strings"
+
+// UniqueCollision is a value that more than one entry has in a column that
+// must be unique
+type UniqueCollision struct {
+	Column    string
+	Value     string
+	Locations []EntryLocation // Entries with the value, in input order
+}
+
+// FindCollisions returns the values of each of columns that more than one
+// entry has, in the order of their first entry. Values are compared without
+// surrounding spaces, and empty values are left out.
+func FindCollisions(entries []*DataEntry, columns []string) []*UniqueCollision {
+	var collisions []*UniqueCollision
+	for _, column := range columns {
+		var values []string
+		locations := make(map[string][]EntryLocation)
+		for _, entry := range DataEntries(entries) {
+			value := strings.TrimSpace(entry.GetValue(column))
+			if value == "" {
+				continue
+			}
+			if _, ok := locations[value]; !ok {
+				values = append(values, value)
+			}
+			locations[value] = append(locations[value], EntryLocation{Source: entry.Source, Line: entry.LineNumber})
+		}
+
+		for _, value := range values {
+			if len(locations[value]) > 1 {
+				collisions = append(collisions, &UniqueCollision{Column: column, Value: value, Locations: locations[value]})
+			}
+		}
+	}
+	return collisions
+}
@@ -0,0 +1,152 @@
+// Package notify tells a webhook or a mailbox that a run has ended, for
+// unattended (cron or watch) runs whose import file someone is waiting for.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+// Run statuses sent in an Event
+const (
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// Event is the JSON body posted when a run ends
+type Event struct {
+	Status string                   `json:"status"`           // StatusCompleted, StatusFailed or StatusCancelled
+	Error  string                   `json:"error,omitempty"`  // Why a failed or cancelled run stopped
+	Output string                   `json:"output,omitempty"` // Path of the import file written
+	Report *models.ProcessingReport `json:"report"`           // The report so far (complete for a completed run)
+}
+
+// Notifier delivers an Event
+type Notifier interface {
+	Notify(event *Event) error
+}
+
+// Webhook posts events as JSON to a URL
+type Webhook struct {
+	URL   string
+	HTTP  *http.Client
+	Retry *models.RetryPolicy // Retries posts that fail on the way; nil tries once
+}
+
+// NewWebhook creates a new Webhook posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:   url,
+		HTTP:  &http.Client{Timeout: 30 * time.Second},
+		Retry: models.DefaultRetryPolicy(),
+	}
+}
+
+// Notify posts event; any status other than 2xx is an error. Server errors
+// and unreachable webhooks are retried by w.Retry.
+func (w *Webhook) Notify(event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return w.Retry.Do(func() error {
+		return w.post(body)
+	})
+}
+
+// post sends one request; client errors other than 429 Too Many Requests are
+// Permanent
+func (w *Webhook) post(body []byte) error {
+	resp, err := w.HTTP.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot reach webhook %s: %v", w.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("webhook %s: %s", w.URL, resp.Status)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return models.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Email sends events as plain-text mail through an SMTP server
+type Email struct {
+	Server   string // host:port of the SMTP server
+	From     string
+	To       []string
+	Username string // Optional; with Password, authenticates with PLAIN auth
+	Password string
+}
+
+// Notify mails event, with the report as indented JSON in the body
+func (e *Email) Notify(event *Event) error {
+	message, err := e.Message(event)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if e.Username != "" {
+		host := e.Server
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	if err := smtp.SendMail(e.Server, auth, e.From, e.To, message); err != nil {
+		return fmt.Errorf("cannot send mail through %s: %v", e.Server, err)
+	}
+	return nil
+}
+
+// Message returns the mail sent for event
+func (e *Email) Message(event *Event) ([]byte, error) {
+	report, err := json.MarshalIndent(event.Report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	subject := "ankiprep run " + event.Status
+	var body strings.Builder
+	switch {
+	case event.Status == StatusCancelled:
+		fmt.Fprintf(&body, "%s\r\n\r\n", event.Error)
+	case event.Error != "":
+		fmt.Fprintf(&body, "The run failed: %s\r\n\r\n", event.Error)
+	case event.Output != "":
+		fmt.Fprintf(&body, "The notes were written to %s\r\n\r\n", event.Output)
+		subject += ": " + event.Output
+	}
+	body.WriteString(strings.ReplaceAll(string(report), "\n", "\r\n"))
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", headerValue(e.From))
+	fmt.Fprintf(&message, "To: %s\r\n", headerValue(strings.Join(e.To, ", ")))
+	fmt.Fprintf(&message, "Subject: %s\r\n", headerValue(subject))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	message.WriteString(body.String())
+	message.WriteString("\r\n")
+	return message.Bytes(), nil
+}
+
+// headerLineBreaks turns the line breaks in a header value into spaces, so an
+// output path or address cannot end the header and start another
+var headerLineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// headerValue returns s made safe to put on one mail header line
+func headerValue(s string) string {
+	return headerLineBreaks.Replace(s)
+}
@@ -0,0 +1,285 @@
+// Package server exposes the ankiprep pipeline over HTTP for the serve
+// command: CSV uploads are converted to Anki import files, and single strings
+// can be run through the typography rules.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ankiprep/internal/models"
+	"ankiprep/internal/tracing"
+	"ankiprep/pkg/ankiprep"
+)
+
+// MaxUploadBytes limits the size of a /convert request
+const MaxUploadBytes = 32 << 20
+
+// TypographyRequest is the JSON body of a /typography request
+type TypographyRequest struct {
+	Text        string `json:"text"`
+	French      bool   `json:"french"`       // Add thin spaces before French punctuation
+	SmartQuotes bool   `json:"smart_quotes"` // Convert straight quotes to curly quotes
+}
+
+// TypographyResponse is the JSON reply of a /typography request
+type TypographyResponse struct {
+	Text string `json:"text"`
+}
+
+// errorResponse is the JSON reply of a failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the HTTP handler serving /convert, /typography and /health
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", handleConvert)
+	mux.HandleFunc("/typography", handleTypography)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	return mux
+}
+
+// handleTypography applies the requested typography rules to one string
+func handleTypography(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+
+	var request TypographyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxUploadBytes)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+
+	processor := models.NewTypographyProcessor(request.French, request.SmartQuotes)
+	writeJSON(w, http.StatusOK, TypographyResponse{Text: processor.ProcessText(request.Text)})
+}
+
+// handleConvert converts the uploaded "files" of a multipart form into one
+// Anki import file. Form fields mirror the command-line flags: french,
+// smart_quotes, auto_lang, skip_duplicates, dedupe_strategy, dedupe_columns,
+// keep_header, no_header, columns and format (csv or tsv).
+func handleConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadBytes)
+	if err := r.ParseMultipartForm(MaxUploadBytes); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid upload: %v", err))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	uploads := r.MultipartForm.File["files"]
+	if len(uploads) == 0 {
+		writeError(w, http.StatusBadRequest, `no CSV/TSV files in the "files" field`)
+		return
+	}
+
+	opts, format, err := convertOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The pipeline reads files and picks the separator from the extension, so
+	// uploads are saved under their base names in a private directory
+	dir, err := os.MkdirTemp("", "ankiprep-serve-")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	span := tracing.SpanFromContext(r.Context())
+	var inputPaths []string
+	for i, upload := range uploads {
+		name := filepath.Base(upload.Filename)
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".csv" && ext != ".tsv" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("%s: only .csv and .tsv files are supported", name))
+			return
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%d-%s", i, name))
+		uploadSpan := span.Child("save upload")
+		uploadSpan.SetAttribute("file.name", name)
+		uploadSpan.SetAttribute("file.size", int(upload.Size))
+		err := saveUpload(upload, path)
+		if err != nil {
+			uploadSpan.Fail(err.Error())
+		}
+		uploadSpan.End()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		inputPaths = append(inputPaths, path)
+	}
+
+	var output bytes.Buffer
+	convertSpan := span.Child("convert")
+	convertSpan.SetAttribute("input.files", len(inputPaths))
+	result, err := ankiprep.Process(inputPaths, append(opts, ankiprep.WithWriter(&output))...)
+	if err != nil {
+		convertSpan.Fail(err.Error())
+		convertSpan.End()
+		// Error messages name the temporary directory; the client only knows
+		// the uploaded names
+		writeError(w, http.StatusUnprocessableEntity, strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), ""))
+		return
+	}
+
+	convertSpan.SetAttribute("input.records", result.InputRecords)
+	convertSpan.SetAttribute("output.records", result.OutputRecords)
+	convertSpan.SetAttribute("duplicates.removed", result.DuplicatesRemoved)
+	convertSpan.End()
+
+	contentType := "text/csv; charset=utf-8"
+	if format == models.FormatTSV {
+		contentType = "text/tab-separated-values; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", outputName(uploads[0].Filename, len(uploads), format)))
+	w.Header().Set("X-Ankiprep-Records", strconv.Itoa(result.OutputRecords))
+	w.Header().Set("X-Ankiprep-Duplicates-Removed", strconv.Itoa(result.DuplicatesRemoved))
+	w.Write(output.Bytes())
+}
+
+// convertOptions reads the pipeline options from the form fields
+func convertOptions(r *http.Request) ([]ankiprep.Option, string, error) {
+	var opts []ankiprep.Option
+	flags := []struct {
+		field  string
+		option ankiprep.Option
+	}{
+		{"french", ankiprep.WithFrenchTypography()},
+		{"smart_quotes", ankiprep.WithSmartQuotes()},
+		{"auto_lang", ankiprep.WithAutoLanguage()},
+		{"keep_header", ankiprep.WithKeepHeader()},
+		{"no_header", ankiprep.WithNoHeader()},
+	}
+	for _, flag := range flags {
+		set, err := formBool(r, flag.field)
+		if err != nil {
+			return nil, "", err
+		}
+		if set {
+			opts = append(opts, flag.option)
+		}
+	}
+
+	skip, err := formBool(r, "skip_duplicates")
+	if err != nil {
+		return nil, "", err
+	}
+	if skip {
+		strategy := r.FormValue("dedupe_strategy")
+		switch strategy {
+		case "":
+			opts = append(opts, ankiprep.WithDedupe(ankiprep.DedupeExact))
+		case ankiprep.DedupeKeyColumns:
+			opts = append(opts, ankiprep.WithDedupeKey(formList(r, "dedupe_columns")...))
+		default:
+			opts = append(opts, ankiprep.WithDedupe(strategy))
+		}
+	}
+
+	if columns := formList(r, "columns"); len(columns) > 0 {
+		opts = append(opts, ankiprep.WithColumns(columns...))
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = models.FormatCSV
+	}
+	separator, ok := models.FormatSeparator(format)
+	if !ok {
+		return nil, "", fmt.Errorf("invalid format %q: must be csv or tsv", format)
+	}
+	if separator == models.SeparatorTab {
+		opts = append(opts, ankiprep.WithTabSeparated())
+	}
+
+	return opts, format, nil
+}
+
+// formBool reads a checkbox-style form field; absent means false
+func formBool(r *http.Request, field string) (bool, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return false, nil
+	}
+	if value == "on" {
+		return true, nil
+	}
+	set, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", field, value)
+	}
+	return set, nil
+}
+
+// formList reads a comma-separated form field
+func formList(r *http.Request, field string) []string {
+	var values []string
+	for _, value := range strings.Split(r.FormValue(field), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// saveUpload copies an uploaded file to path
+func saveUpload(upload *multipart.FileHeader, path string) error {
+	src, err := upload.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// outputName returns the download name, following the CLI's default names
+func outputName(firstUpload string, uploads int, format string) string {
+	if uploads > 1 {
+		return "merged_output." + format
+	}
+	base := filepath.Base(firstUpload)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_processed." + format
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
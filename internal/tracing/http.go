@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+)
+
+// spanKey is the context key of the request span
+type spanKey struct{}
+
+// ContextWithSpan returns ctx carrying span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span ctx carries, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Handler wraps next so every request is recorded as a server span, which
+// handlers can extend through SpanFromContext. Spans are exported in the
+// background once the request is done; onError receives failed exports.
+func (t *Tracer) Handler(next http.Handler, onError func(error)) http.Handler {
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := t.Start(r.Method+" "+r.URL.Path, nil)
+		span.kind = kindServer
+		span.SetAttribute("http.request.method", r.Method)
+		span.SetAttribute("url.path", r.URL.Path)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ContextWithSpan(r.Context(), span)))
+
+		span.SetAttribute("http.response.status_code", recorder.status)
+		if recorder.status >= 500 {
+			span.Fail(http.StatusText(recorder.status))
+		}
+		span.End()
+		go func() {
+			if err := t.Flush(); err != nil && onError != nil {
+				onError(err)
+			}
+		}()
+	})
+}
+
+// statusRecorder remembers the status code a handler replied with
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
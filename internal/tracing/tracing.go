@@ -0,0 +1,246 @@
+// Package tracing records the spans of a run or of an HTTP request and exports
+// them to an OpenTelemetry collector over OTLP/HTTP with the JSON encoding, so
+// batch deployments and the serve command can be observed with standard
+// tooling. A nil *Tracer and a nil *Span do nothing, so tracing stays fully
+// opt-in: code is instrumented unconditionally and costs nothing untraced.
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+// TracesPath is the OTLP/HTTP path traces are posted to when the endpoint
+// given to New has no path of its own
+const TracesPath = "/v1/traces"
+
+// Span kinds and status codes of the OTLP protocol
+const (
+	kindInternal = 1
+	kindServer   = 2
+
+	statusError = 2
+)
+
+// Tracer collects ended spans until Flush exports them
+type Tracer struct {
+	Endpoint string // URL spans are posted to
+	Service  string // service.name of the exported resource
+	HTTP     *http.Client
+	Retry    *models.RetryPolicy // Retries exports that fail on the way; nil tries once
+
+	mu    sync.Mutex
+	ended []*Span
+}
+
+// New creates a Tracer exporting to an OTLP/HTTP collector at endpoint, such
+// as http://localhost:4318; TracesPath is added when endpoint has no path
+func New(endpoint, service string) (*Tracer, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: expected an http:// or https:// URL", endpoint)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = TracesPath
+	}
+	return &Tracer{
+		Endpoint: parsed.String(),
+		Service:  service,
+		HTTP:     &http.Client{Timeout: 10 * time.Second},
+		Retry:    models.DefaultRetryPolicy(),
+	}, nil
+}
+
+// Span is one timed operation, such as a stage of a run or the parsing of an
+// input file
+type Span struct {
+	tracer     *Tracer
+	traceID    [16]byte
+	spanID     [8]byte
+	parentID   [8]byte
+	hasParent  bool
+	name       string
+	kind       int
+	start      time.Time
+	end        time.Time
+	attributes []attribute
+	err        string
+}
+
+// attribute is a key with a string, int or bool value
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+// Start begins a span named name, as a child of parent or, with a nil
+// parent, as the root of a new trace
+func (t *Tracer) Start(name string, parent *Span) *Span {
+	return t.StartAt(name, parent, time.Now())
+}
+
+// StartAt is Start for a span that began at start, for operations that are
+// only known to have happened once they are done
+func (t *Tracer) StartAt(name string, parent *Span, start time.Time) *Span {
+	if t == nil {
+		return nil
+	}
+	span := &Span{tracer: t, name: name, kind: kindInternal, start: start}
+	if parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+		span.hasParent = true
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	return span
+}
+
+// Child begins a span named name as a child of s
+func (s *Span) Child(name string) *Span {
+	if s == nil {
+		return nil
+	}
+	return s.tracer.Start(name, s)
+}
+
+// SetAttribute adds an attribute to the span; value is a string, an int or a
+// bool (anything else is recorded as its fmt.Sprint text)
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attributes = append(s.attributes, attribute{key: key, value: value})
+}
+
+// Fail marks the span as failed with message
+func (s *Span) Fail(message string) {
+	if s == nil {
+		return
+	}
+	s.err = message
+}
+
+// End ends the span and queues it for the next Flush
+func (s *Span) End() {
+	if s == nil || !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	s.tracer.mu.Lock()
+	s.tracer.ended = append(s.tracer.ended, s)
+	s.tracer.mu.Unlock()
+}
+
+// Flush exports the spans ended since the last Flush in one request
+func (t *Tracer) Flush() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	spans := t.ended
+	t.ended = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(t.export(spans))
+	if err != nil {
+		return err
+	}
+	return t.Retry.Do(func() error {
+		return t.post(body)
+	})
+}
+
+// post sends one export request; client errors are not retried
+func (t *Tracer) post(body []byte) error {
+	resp, err := t.HTTP.Post(t.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("OTLP collector %s replied %s", t.Endpoint, resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return models.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// export returns the OTLP/JSON ExportTraceServiceRequest for spans
+func (t *Tracer) export(spans []*Span) map[string]interface{} {
+	encoded := make([]map[string]interface{}, len(spans))
+	for i, span := range spans {
+		encoded[i] = span.encode()
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": encodeAttributes([]attribute{{"service.name", t.Service}}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": t.Service},
+				"spans": encoded,
+			}},
+		}},
+	}
+}
+
+// encode returns the OTLP/JSON form of the span: IDs in hex and times as
+// decimal strings of Unix nanoseconds
+func (s *Span) encode() map[string]interface{} {
+	span := map[string]interface{}{
+		"traceId":           hex.EncodeToString(s.traceID[:]),
+		"spanId":            hex.EncodeToString(s.spanID[:]),
+		"name":              s.name,
+		"kind":              s.kind,
+		"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+		"attributes":        encodeAttributes(s.attributes),
+	}
+	if s.hasParent {
+		span["parentSpanId"] = hex.EncodeToString(s.parentID[:])
+	}
+	if s.err != "" {
+		span["status"] = map[string]interface{}{"code": statusError, "message": s.err}
+	}
+	return span
+}
+
+// encodeAttributes returns attributes as OTLP/JSON key-value pairs
+func encodeAttributes(attributes []attribute) []map[string]interface{} {
+	encoded := make([]map[string]interface{}, len(attributes))
+	for i, attr := range attributes {
+		var value map[string]interface{}
+		switch v := attr.value.(type) {
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		encoded[i] = map[string]interface{}{"key": attr.key, "value": value}
+	}
+	return encoded
+}
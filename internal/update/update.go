@@ -0,0 +1,181 @@
+// Package update finds, downloads and verifies ankiprep release binaries
+// published on GitHub, for the self-update command.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repository is the GitHub repository releases are published to
+const Repository = "NicholasDunham/ankiprep"
+
+// ChecksumsAsset is the release asset listing SHA-256 sums of every binary,
+// in the format written by sha256sum
+const ChecksumsAsset = "checksums.txt"
+
+// Release is a published GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Asset returns the release asset with the given name
+func (r *Release) Asset(name string) (*Asset, bool) {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i], true
+		}
+	}
+	return nil, false
+}
+
+// Client talks to the GitHub releases API
+type Client struct {
+	BaseURL    string // API root, https://api.github.com by default
+	Repository string // owner/name
+	HTTP       *http.Client
+}
+
+// NewClient creates a new Client for the ankiprep repository
+func NewClient() *Client {
+	return &Client{
+		BaseURL:    "https://api.github.com",
+		Repository: Repository,
+		HTTP:       &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// LatestRelease returns the most recent published release
+func (c *Client) LatestRelease() (*Release, error) {
+	data, err := c.Download(fmt.Sprintf("%s/repos/%s/releases/latest", c.BaseURL, c.Repository))
+	if err != nil {
+		return nil, fmt.Errorf("cannot check for updates: %v", err)
+	}
+
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("cannot read release information: %v", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("cannot read release information: missing tag name")
+	}
+	return &release, nil
+}
+
+// Download fetches url and returns the response body
+func (c *Client) Download(url string) ([]byte, error) {
+	resp, err := c.HTTP.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// AssetName returns the name of the release binary for a platform, such as
+// ankiprep_darwin_arm64 or ankiprep_windows_amd64.exe
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("ankiprep_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// ParseChecksums reads sha256sum output ("<hex>  <name>" per line) into a map
+// from file name to lower-case hex digest
+func ParseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks binary mode with a leading '*'
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// VerifyChecksum returns an error unless data has the expected SHA-256 digest.
+// It catches corrupted downloads; it says nothing about who published data.
+func VerifyChecksum(data []byte, expected string) error {
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); actual != strings.ToLower(expected) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// IsNewer returns true if version latest is greater than current. Versions are
+// compared numerically per dot-separated part, ignoring a leading "v" and any
+// pre-release or build suffix.
+func IsNewer(latest, current string) bool {
+	a, b := versionParts(latest), versionParts(current)
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// PackageManager returns the name of the package manager that installed the
+// executable at path (Homebrew or Scoop), or "" for a manual install
+func PackageManager(path string) string {
+	// Compare with forward slashes so Windows paths match on any platform
+	path = strings.ReplaceAll(strings.ToLower(path), `\`, "/")
+	switch {
+	case strings.Contains(path, "/cellar/") || strings.Contains(path, "/homebrew/"):
+		return "Homebrew"
+	case strings.Contains(path, "/scoop/apps/"):
+		return "Scoop"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,317 @@
+// Package ankiprep converts CSV/TSV files into Anki import files. It runs the
+// same pipeline as the ankiprep command (merge, dedupe, typography, field
+// templates) configured through functional options instead of flags:
+//
+//	var out bytes.Buffer
+//	result, err := ankiprep.Process([]string{"vocab.csv"},
+//		ankiprep.WithFrenchTypography(),
+//		ankiprep.WithDedupeKey("Front"),
+//		ankiprep.WithWriter(&out))
+//
+// Applications that want the processed entries rather than a file use
+// ForEachProcessedEntry instead.
+package ankiprep
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+// Result summarizes a finished run
+type Result struct {
+	InputRecords      int           // Data records read from all input files
+	OutputRecords     int           // Entries written (excluding a kept header row)
+	DuplicatesRemoved int           // Entries removed as duplicates
+	Columns           []string      // Output columns in order
+	Duration          time.Duration // Total processing time
+}
+
+// Process reads the input files, runs the pipeline and writes the Anki import
+// file to the writer set with WithWriter
+func Process(inputPaths []string, opts ...Option) (*Result, error) {
+	startTime := time.Now()
+	options := NewOptions(opts...)
+	if options.Writer == nil {
+		return nil, fmt.Errorf("no output writer; use WithWriter")
+	}
+
+	run, err := prepare(fileInputs(inputPaths), options)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := run.write(options.Writer)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// run holds the parsed input of one call, ready to be processed by its stages
+type run struct {
+	options      Options
+	columns      []string
+	templates    []*models.FieldTemplate
+	entries      []*Entry // With every input column, in merged order
+	totalRecords int
+
+	deduper    Deduper
+	typography Typography
+	formatter  FormatterFunc
+	reporter   Reporter
+}
+
+// input is one input file, read from disk unless content is set
+type input struct {
+	path    string
+	content string
+	inline  bool
+}
+
+// fileInputs returns inputs read from the given paths
+func fileInputs(paths []string) []input {
+	inputs := make([]input, len(paths))
+	for i, path := range paths {
+		inputs[i] = input{path: path}
+	}
+	return inputs
+}
+
+// prepare validates the options, picks the stages, then parses and merges
+// the inputs
+func prepare(inputs []input, options Options) (*run, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no input files")
+	}
+
+	r := &run{
+		options:    options,
+		templates:  options.config().FieldTemplates(),
+		deduper:    options.Deduper,
+		typography: options.Typography,
+		formatter:  options.Formatter,
+		reporter:   options.Reporter,
+	}
+	if r.typography == nil && (options.FrenchTypography || options.SmartQuotes) {
+		r.typography = &fieldTypography{french: options.FrenchTypography, quotes: options.SmartQuotes, autoLang: options.AutoLanguage}
+	}
+	if r.formatter == nil {
+		separator := models.SeparatorComma
+		if options.TabSeparated {
+			separator = models.SeparatorTab
+		}
+		r.formatter = newAnkiFormatter(separator)
+	}
+
+	parser := options.Parser
+	if parser == nil {
+		parser = csvParser{}
+	}
+
+	var inputFiles []*models.InputFile
+	for _, in := range inputs {
+		start := time.Now()
+		inputFile, err := parseInput(in, parser, options)
+		if err != nil {
+			return nil, models.ParseFailure(in.path, err)
+		}
+		inputFiles = append(inputFiles, inputFile)
+		r.report("parsing", len(inputFile.Records), time.Since(start))
+	}
+	headers := models.MergeHeaders(inputFiles)
+
+	columns, err := selectColumns(headers, options.Columns)
+	if err != nil {
+		return nil, err
+	}
+	r.columns = columns
+
+	if r.deduper == nil && options.SkipDuplicates {
+		for _, column := range options.DedupeKey {
+			if !containsString(headers, column) {
+				return nil, fmt.Errorf("dedupe key column %q not found (available: %s)", column, strings.Join(headers, ", "))
+			}
+		}
+		mergeLists := options.config().MergeLists()
+		for _, list := range mergeLists {
+			if !containsString(headers, list.Column) {
+				return nil, fmt.Errorf("merged column %q not found (available: %s)", list.Column, strings.Join(headers, ", "))
+			}
+		}
+		hasher, err := models.NewHasher(options.dedupeStrategy(), options.hasherColumns())
+		if err != nil {
+			return nil, err
+		}
+		r.deduper = &detectorDeduper{hasher: hasher, mergeTags: options.MergeTags, mergeFields: mergeLists}
+	}
+
+	entries, totalRecords := models.BuildEntries(inputFiles, headers, options.KeepHeader)
+	for _, entry := range entries {
+		r.entries = append(r.entries, newEntry(entry, headers))
+	}
+	r.totalRecords = totalRecords
+	return r, nil
+}
+
+// write processes the entries into the output written to w
+func (r *run) write(w io.Writer) (*Result, error) {
+	formatter := r.formatter(w, r.columns)
+
+	start := time.Now()
+	result, err := r.each(context.Background(), formatter.Write)
+	if err != nil {
+		return nil, err
+	}
+	if err := formatter.Flush(); err != nil {
+		return nil, err
+	}
+	r.report("writing", result.OutputRecords, time.Since(start))
+	return result, nil
+}
+
+// each removes duplicates, then applies typography and templates to one entry
+// at a time and passes it, with the output columns, to fn. It stops at the
+// first error from fn or when ctx is done. Duplicates are removed up front
+// since merged tags can change an entry until the last duplicate has been seen.
+func (r *run) each(ctx context.Context, fn func(*Entry) error) (*Result, error) {
+	entries := r.entries
+	if r.deduper != nil {
+		start := time.Now()
+		entries = r.deduper.Dedupe(entries)
+		r.report("deduplication", len(r.entries), time.Since(start))
+	}
+
+	result := &Result{
+		InputRecords:      r.totalRecords,
+		DuplicatesRemoved: len(r.entries) - len(entries),
+		Columns:           r.columns,
+	}
+	var typographyTime time.Duration
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if r.typography != nil {
+			start := time.Now()
+			r.typography.Apply(entry)
+			typographyTime += time.Since(start)
+		}
+		r.applyTemplates(entry)
+
+		if err := fn(entry.project(r.columns)); err != nil {
+			return nil, err
+		}
+		if !entry.IsHeader() {
+			result.OutputRecords++
+		}
+	}
+	if r.typography != nil {
+		r.report("typography", len(entries), typographyTime)
+	}
+
+	return result, nil
+}
+
+// applyTemplates wraps the values of templated columns; a kept header row is
+// left alone
+func (r *run) applyTemplates(entry *Entry) {
+	if entry.IsHeader() {
+		return
+	}
+	for _, template := range r.templates {
+		for i, column := range entry.Columns {
+			if column == template.Column {
+				entry.Values[i] = template.Apply(entry.Values[i])
+			}
+		}
+	}
+}
+
+// report passes the work of a stage to the Reporter, if any
+func (r *run) report(stage string, items int, elapsed time.Duration) {
+	if r.reporter != nil {
+		r.reporter.Add(stage, items, elapsed)
+	}
+}
+
+// parseInput reads one input with parser; a first row is always taken as the
+// header unless NoHeader is set, since a library cannot ask the user. Files
+// get their separator from the extension, inline content from its first line.
+func parseInput(in input, parser Parser, options Options) (*models.InputFile, error) {
+	inputFile := models.NewInputFile(in.path)
+
+	var first []string
+	var records []Record
+	if in.inline {
+		inputFile.Separator = sniffSeparator(in.content)
+		var err error
+		if first, records, err = parser.Parse(strings.NewReader(in.content), inputFile.Separator); err != nil {
+			return nil, err
+		}
+	} else {
+		inputFile.DetectSeparator()
+		file, err := models.OpenInput(in.path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		if first, records, err = parser.Parse(file, inputFile.Separator); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.NoHeader {
+		inputFile.Headers = models.GenerateHeaders(len(first))
+		inputFile.AddRecord(first, 1)
+	} else {
+		inputFile.Headers = first
+		inputFile.HasHeader = true
+	}
+	for _, record := range records {
+		inputFile.AddRecord(record.Values, record.Line)
+	}
+	return inputFile, nil
+}
+
+// sniffSeparator picks tab for content whose first line has more tabs than
+// commas, and comma otherwise
+func sniffSeparator(content string) rune {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	if strings.Count(firstLine, "\t") > strings.Count(firstLine, ",") {
+		return '\t'
+	}
+	return ','
+}
+
+// selectColumns returns the selected columns in order, or all headers
+func selectColumns(headers, selected []string) ([]string, error) {
+	if len(selected) == 0 {
+		return headers, nil
+	}
+	for _, column := range selected {
+		if !containsString(headers, column) {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", column, strings.Join(headers, ", "))
+		}
+	}
+	return selected, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package ankiprep
+
+import (
+	"io"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+// The stages of a run are interfaces, so a program can replace any of them
+// (for example with a streaming parser or its own duplicate matching) through
+// WithParser, WithDeduper, WithTypography, WithFormatter and WithReporter.
+// Stages left unset use the built-in implementations configured by the other
+// options.
+
+// Record is one row read by a Parser, with the line it starts on
+type Record struct {
+	Line   int
+	Values []string
+}
+
+// Parser reads one input into its first row and the records after it. The
+// first row is used as the header unless WithNoHeader is given.
+type Parser interface {
+	Parse(r io.Reader, separator rune) (first []string, records []Record, err error)
+}
+
+// Deduper removes duplicate entries, keeping the order of the others. The
+// entries carry every input column, not only the output columns.
+type Deduper interface {
+	Dedupe(entries []*Entry) []*Entry
+}
+
+// Typography rewrites the values of an entry in place
+type Typography interface {
+	Apply(entry *Entry)
+}
+
+// Formatter writes processed entries, then Flush completes the output
+type Formatter interface {
+	Write(entry *Entry) error
+	Flush() error
+}
+
+// FormatterFunc creates the Formatter writing the output columns to w
+type FormatterFunc func(w io.Writer, columns []string) Formatter
+
+// Reporter receives the work done in each stage: "parsing", "deduplication",
+// "typography" and "writing"
+type Reporter interface {
+	Add(stage string, items int, elapsed time.Duration)
+}
+
+// csvParser is the built-in Parser for CSV and TSV input
+type csvParser struct{}
+
+func (csvParser) Parse(r io.Reader, separator rune) ([]string, []Record, error) {
+	inputFile := models.NewInputFile("")
+	inputFile.Separator = separator
+
+	parser := models.NewCSVParser()
+	parser.Header = models.HeaderPresent
+
+	var records []Record
+	err := parser.Parse(r, inputFile, func(record []string, line int) error {
+		records = append(records, Record{Line: line, Values: record})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputFile.Headers, records, nil
+}
+
+// detectorDeduper is the built-in Deduper, comparing entries with a hasher
+type detectorDeduper struct {
+	hasher      models.Hasher
+	mergeTags   bool
+	mergeFields []*models.ItemList
+}
+
+func (d *detectorDeduper) Dedupe(entries []*Entry) []*Entry {
+	data := make([]*models.DataEntry, len(entries))
+	for i, entry := range entries {
+		data[i] = entry.dataEntry()
+	}
+
+	detector := models.NewDuplicateDetector(d.hasher)
+	detector.MergeTags = d.mergeTags
+	detector.MergeFields = d.mergeFields
+	unique := detector.RemoveDuplicates(data)
+
+	kept := make([]*Entry, len(unique))
+	for i, entry := range unique {
+		kept[i] = newEntry(entry, entries[0].Columns)
+	}
+	return kept
+}
+
+// fieldTypography is the built-in Typography: French spacing and smart quotes
+type fieldTypography struct {
+	french, quotes, autoLang bool
+}
+
+func (t *fieldTypography) Apply(entry *Entry) {
+	for i, column := range entry.Columns {
+		entry.Values[i] = models.FormatField(column, entry.Values[i], t.french, t.quotes, t.autoLang)
+	}
+}
+
+// ankiFormatter is the built-in Formatter writing an Anki import file
+type ankiFormatter struct {
+	writer *models.AnkiWriter
+}
+
+// newAnkiFormatter returns a FormatterFunc writing comma- or tab-separated
+// Anki import files
+func newAnkiFormatter(separator string) FormatterFunc {
+	return func(w io.Writer, columns []string) Formatter {
+		return &ankiFormatter{writer: models.NewAnkiWriter(w, columns, separator)}
+	}
+}
+
+func (f *ankiFormatter) Write(entry *Entry) error {
+	return f.writer.Write(entry.dataEntry())
+}
+
+func (f *ankiFormatter) Flush() error {
+	return f.writer.Flush()
+}
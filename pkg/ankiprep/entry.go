@@ -0,0 +1,80 @@
+package ankiprep
+
+import (
+	"context"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+// Entry is one processed note, with its fields in output column order
+type Entry struct {
+	Source  string   // Input file the entry came from
+	Line    int      // Line the record starts on; 0 for a kept header row
+	Columns []string // Output column names
+	Values  []string // Field values, parallel to Columns
+}
+
+// Get returns the value of column, or an empty string if there is no such column
+func (e *Entry) Get(column string) string {
+	for i, name := range e.Columns {
+		if name == column {
+			return e.Values[i]
+		}
+	}
+	return ""
+}
+
+// IsHeader reports whether the entry is the header row kept by WithKeepHeader
+func (e *Entry) IsHeader() bool {
+	return e.Line == 0
+}
+
+// newEntry converts a pipeline entry into an Entry with the given columns
+func newEntry(entry *models.DataEntry, columns []string) *Entry {
+	return &Entry{
+		Source:  entry.Source,
+		Line:    entry.LineNumber,
+		Columns: columns,
+		Values:  entry.ToCSVRecord(columns),
+	}
+}
+
+// dataEntry converts the entry back for the pipeline
+func (e *Entry) dataEntry() *models.DataEntry {
+	values := make(map[string]string, len(e.Columns))
+	for i, column := range e.Columns {
+		values[column] = e.Values[i]
+	}
+	return models.NewDataEntry(values, e.Source, e.Line)
+}
+
+// project returns the entry with only columns, in that order
+func (e *Entry) project(columns []string) *Entry {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = e.Get(column)
+	}
+	return &Entry{Source: e.Source, Line: e.Line, Columns: columns, Values: values}
+}
+
+// ForEachProcessedEntry runs the pipeline on the input files and passes every
+// processed entry to fn in output order, without writing an Anki file. The
+// writer set with WithWriter is ignored. Iteration stops with the error
+// returned by fn, or with ctx.Err() once ctx is cancelled.
+func ForEachProcessedEntry(ctx context.Context, inputPaths []string, fn func(*Entry) error, opts ...Option) (*Result, error) {
+	startTime := time.Now()
+
+	run, err := prepare(fileInputs(inputPaths), NewOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := run.each(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
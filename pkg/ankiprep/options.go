@@ -0,0 +1,228 @@
+package ankiprep
+
+import (
+	"fmt"
+	"io"
+
+	"ankiprep/internal/models"
+)
+
+// Dedupe strategies accepted by WithDedupe
+const (
+	DedupeExact       = models.DedupeExact       // Every field identical (case-sensitive)
+	DedupeNormalized  = models.DedupeNormalized  // Fields equal ignoring case and whitespace
+	DedupeKeyColumns  = models.DedupeKeyColumns  // Only the key columns identical (see WithDedupeKey)
+	DedupeFuzzy       = models.DedupeFuzzy       // Same words ignoring order, punctuation, markup and accents
+	DedupeMergeFields = models.DedupeMergeFields // Every field identical except the merged columns (see WithMergeField)
+)
+
+// Options configures a run. Build it with NewOptions and functional options
+// rather than filling it in by hand; the zero value of every field is the
+// behavior of ankiprep without flags.
+type Options struct {
+	FrenchTypography bool              // Add narrow no-break spaces before French punctuation
+	AutoLanguage     bool              // With FrenchTypography, decide per cell from the detected language
+	SmartQuotes      bool              // Convert straight quotes to curly quotes
+	SkipDuplicates   bool              // Remove duplicate entries
+	DedupeStrategy   string            // How duplicates are compared (DedupeExact if empty)
+	DedupeKey        []string          // Key columns for DedupeKeyColumns
+	MergeTags        bool              // Add the tags of removed duplicates to the kept entry
+	MergeFields      map[string]string // Column name to the delimiter between the items added from removed duplicates
+	KeepHeader       bool              // Keep the first file's header row as an entry
+	NoHeader         bool              // Input files have no header row; columns are named Column1..N
+	Columns          []string          // Output only these columns, in this order (all if empty)
+	TabSeparated     bool              // Write tab-separated instead of comma-separated output
+	Templates        map[string]string // Column name to HTML template wrapping its values
+	Writer           io.Writer         // Destination of the Anki import file
+
+	// Stages replacing the built-in ones when set (see Parser)
+	Parser     Parser        // Reads input files instead of the CSV/TSV parser
+	Deduper    Deduper       // Removes duplicates instead of the dedupe options
+	Typography Typography    // Formats values instead of FrenchTypography, AutoLanguage and SmartQuotes
+	Formatter  FormatterFunc // Writes the output instead of the Anki import file writer
+	Reporter   Reporter      // Receives the work done per stage
+}
+
+// Option changes one setting of Options
+type Option func(*Options)
+
+// NewOptions returns the Options produced by applying opts in order
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		DedupeStrategy: DedupeExact,
+		MergeFields:    map[string]string{},
+		Templates:      map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithFrenchTypography adds narrow no-break spaces before French punctuation
+// (except in English columns)
+func WithFrenchTypography() Option {
+	return func(o *Options) { o.FrenchTypography = true }
+}
+
+// WithAutoLanguage applies French spacing per cell only when the text looks
+// French; it has no effect without WithFrenchTypography
+func WithAutoLanguage() Option {
+	return func(o *Options) { o.AutoLanguage = true }
+}
+
+// WithSmartQuotes converts straight quotes to curly quotes
+func WithSmartQuotes() Option {
+	return func(o *Options) { o.SmartQuotes = true }
+}
+
+// WithDedupe removes duplicate entries compared with strategy
+func WithDedupe(strategy string) Option {
+	return func(o *Options) {
+		o.SkipDuplicates = true
+		o.DedupeStrategy = strategy
+	}
+}
+
+// WithDedupeKey removes entries whose key columns match an earlier entry
+func WithDedupeKey(columns ...string) Option {
+	return func(o *Options) {
+		o.SkipDuplicates = true
+		o.DedupeStrategy = DedupeKeyColumns
+		o.DedupeKey = columns
+	}
+}
+
+// WithMergeTags adds the tags of removed duplicates to the entry that is kept
+func WithMergeTags() Option {
+	return func(o *Options) { o.MergeTags = true }
+}
+
+// WithMergeField removes entries that match an earlier entry in every
+// column but the merged ones, adding the delimiter-separated items of
+// column (e.g. "<br>" between example sentences) that the kept entry lacks
+func WithMergeField(column, delimiter string) Option {
+	return func(o *Options) {
+		o.SkipDuplicates = true
+		o.DedupeStrategy = DedupeMergeFields
+		if o.MergeFields == nil {
+			o.MergeFields = map[string]string{}
+		}
+		o.MergeFields[column] = delimiter
+	}
+}
+
+// WithKeepHeader keeps the first file's header row as the first entry
+func WithKeepHeader() Option {
+	return func(o *Options) { o.KeepHeader = true }
+}
+
+// WithNoHeader reads input files that have no header row
+func WithNoHeader() Option {
+	return func(o *Options) { o.NoHeader = true }
+}
+
+// WithColumns selects and orders the output columns
+func WithColumns(columns ...string) Option {
+	return func(o *Options) { o.Columns = columns }
+}
+
+// WithTabSeparated writes tab-separated output with newlines as <br>
+func WithTabSeparated() Option {
+	return func(o *Options) { o.TabSeparated = true }
+}
+
+// WithTemplate wraps the values of column in template, which must contain {value}
+func WithTemplate(column, template string) Option {
+	return func(o *Options) {
+		if o.Templates == nil {
+			o.Templates = map[string]string{}
+		}
+		o.Templates[column] = template
+	}
+}
+
+// WithWriter sets the destination of the Anki import file
+func WithWriter(w io.Writer) Option {
+	return func(o *Options) { o.Writer = w }
+}
+
+// WithParser reads input files with parser instead of the CSV/TSV parser
+func WithParser(parser Parser) Option {
+	return func(o *Options) { o.Parser = parser }
+}
+
+// WithDeduper removes duplicates with deduper instead of the built-in
+// strategies; WithDedupe, WithDedupeKey, WithMergeTags and WithMergeField
+// are then ignored
+func WithDeduper(deduper Deduper) Option {
+	return func(o *Options) { o.Deduper = deduper }
+}
+
+// WithTypography formats values with typography instead of the built-in
+// French spacing and smart quotes
+func WithTypography(typography Typography) Option {
+	return func(o *Options) { o.Typography = typography }
+}
+
+// WithFormatter writes the output through the Formatter that newFormatter
+// creates instead of as an Anki import file; WithTabSeparated is then ignored
+func WithFormatter(newFormatter FormatterFunc) Option {
+	return func(o *Options) { o.Formatter = newFormatter }
+}
+
+// WithReporter passes the work done in each stage to reporter
+func WithReporter(reporter Reporter) Option {
+	return func(o *Options) { o.Reporter = reporter }
+}
+
+// Validate checks if the options describe a run that can be performed
+func (o Options) Validate() error {
+	if o.SkipDuplicates && o.Deduper == nil {
+		if _, err := models.NewHasher(o.dedupeStrategy(), o.hasherColumns()); err != nil {
+			return err
+		}
+	}
+	if o.MergeTags && !o.SkipDuplicates && o.Deduper == nil {
+		return fmt.Errorf("MergeTags requires a dedupe option")
+	}
+	if len(o.MergeFields) > 0 && !o.SkipDuplicates && o.Deduper == nil {
+		return fmt.Errorf("MergeFields requires a dedupe option")
+	}
+	if o.KeepHeader && o.NoHeader {
+		return fmt.Errorf("KeepHeader and NoHeader cannot be used together")
+	}
+	return o.config().Validate()
+}
+
+// config returns the templates and merged fields as a pipeline configuration
+func (o Options) config() *models.Config {
+	config := models.NewConfig()
+	for column, template := range o.Templates {
+		config.Templates[column] = template
+	}
+	for column, delimiter := range o.MergeFields {
+		config.MergeFields[column] = delimiter
+	}
+	return config
+}
+
+// hasherColumns returns the columns the dedupe strategy is given: the key
+// columns for DedupeKeyColumns, the merged columns for DedupeMergeFields
+func (o Options) hasherColumns() []string {
+	if o.dedupeStrategy() != DedupeMergeFields {
+		return o.DedupeKey
+	}
+	var columns []string
+	for _, list := range o.config().MergeLists() {
+		columns = append(columns, list.Column)
+	}
+	return columns
+}
+
+func (o Options) dedupeStrategy() string {
+	if o.DedupeStrategy == "" {
+		return DedupeExact
+	}
+	return o.DedupeStrategy
+}
@@ -0,0 +1,32 @@
+package ankiprep
+
+import (
+	"strings"
+	"time"
+)
+
+// InlineSource is the source name given to entries of ProcessCSVString input
+const InlineSource = "input"
+
+// ProcessCSVString runs the pipeline on CSV or TSV content held in memory and
+// returns the Anki import file as a string. The separator is taken from the
+// header line. It does no file IO, so it also runs in the browser
+// (GOOS=js GOARCH=wasm); the writer set with WithWriter is ignored.
+func ProcessCSVString(content string, opts ...Option) (string, *Result, error) {
+	startTime := time.Now()
+	options := NewOptions(opts...)
+
+	run, err := prepare([]input{{path: InlineSource, content: content, inline: true}}, options)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var output strings.Builder
+	result, err := run.write(&output)
+	if err != nil {
+		return "", nil, err
+	}
+
+	result.Duration = time.Since(startTime)
+	return output.String(), result, nil
+}
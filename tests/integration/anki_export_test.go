@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAnkiExportRoundTrip tests reading an Anki "Notes in Plain Text" export
+// and writing it back with its metadata directives
+func TestAnkiExportRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "Notes in Plain Text.txt")
+	content := "#separator:tab\n#html:true\n#guid column:1\n#notetype column:2\n#deck column:3\n#tags column:6\n" +
+		"Fx!a1\tBasic\tFrench::Vocab\tchat\t<b>cat</b>\tanimals\n" +
+		"Gq?b2\tBasic\tFrench::Vocab\tquoi?\twhat\t\n" +
+		"Fx!a1\tBasic\tFrench::Vocab\tchat\t<b>cat</b>\tanimals\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "cleaned.txt")
+	cmd := exec.Command("ankiprep", inputFile, "--skip-duplicates", "-f", "--format", "tsv", "-o", outputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:tab\n#html:true\n#columns:GUID\tNote Type\tDeck\tColumn4\tColumn5\tTags\n" +
+		"#guid column:1\n#notetype column:2\n#deck column:3\n#tags column:6\n" +
+		"Fx!a1\tBasic\tFrench::Vocab\tchat\t<b>cat</b>\tanimals\n" +
+		"Gq?b2\tBasic\tFrench::Vocab\tquoi\u202f?\twhat\t\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+}
+
+// TestAnkiExportSeparatorConflict tests that #separator: wins over the
+// file extension, with a warning
+func TestAnkiExportSeparatorConflict(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "notes.csv")
+	if err := os.WriteFile(inputFile, []byte("#separator:tab\n#columns:Front\tBack\nchat, le\tcat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	cmd := exec.Command("ankiprep", inputFile, "-o", outputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Warning: "+inputFile+": #separator:tab contradicts the .csv extension") {
+		t.Errorf("Expected separator conflict warning, got: %s", output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\n\"chat, le\",cat\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+}
@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestApplyFixes tests that a file with a stray header separator and an
+// unclosed quote fails with fix-it warnings, and reads correctly with
+// --apply-fixes without being modified
+func TestApplyFixes(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	content := "Front,Back,\nchat,\"cat\nchien,dog\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+
+	t.Run("explains the error", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", inputFile, "-o", outputFile).CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		want := "Warning: " + inputFile + `:1:11: header ends with 1 stray "," the rows do not have; remove them, or rerun with --apply-fixes`
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected %q, got: %s", want, output)
+		}
+	})
+
+	t.Run("applies the fixes", func(t *testing.T) {
+		reportFile := filepath.Join(tmpDir, "report.json")
+		output, err := exec.Command("ankiprep", inputFile, "-o", outputFile, "--apply-fixes", "--report", reportFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		for _, want := range []string{
+			"Fixed " + inputFile + `:1:11: removed 1 stray "," at the end of the header`,
+			"Fixed " + inputFile + ":2:6: closed the quote at the end of line 2",
+		} {
+			if !strings.Contains(string(output), want) {
+				t.Errorf("Expected %q, got: %s", want, output)
+			}
+		}
+
+		result, _ := os.ReadFile(outputFile)
+		if !strings.HasSuffix(string(result), "#columns:Front,Back\nchat,cat\nchien,dog\n") {
+			t.Errorf("Unexpected output: %s", result)
+		}
+		if original, _ := os.ReadFile(inputFile); string(original) != content {
+			t.Errorf("Expected the input file to be left alone, got %q", original)
+		}
+
+		data, err := os.ReadFile(reportFile)
+		if err != nil {
+			t.Fatalf("Failed to read report: %v", err)
+		}
+		var report struct {
+			Issues []struct {
+				Code string `json:"code"`
+				Fix  struct {
+					Applied bool `json:"applied"`
+				} `json:"fix"`
+			} `json:"issues"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("Report is not valid JSON: %v", err)
+		}
+		if len(report.Issues) != 2 || report.Issues[0].Code != "stray-separator" || report.Issues[1].Code != "unbalanced-quote" || !report.Issues[1].Fix.Applied {
+			t.Errorf("Expected both fixes recorded as applied, got %+v", report.Issues)
+		}
+	})
+
+	t.Run("leaves valid multi-line fields alone", func(t *testing.T) {
+		multiline := filepath.Join(tmpDir, "multiline.csv")
+		if err := os.WriteFile(multiline, []byte("Front,Back\nchat,\"a \"big\" cat\nthat purrs\"\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+		output, err := exec.Command("ankiprep", multiline, "-o", outputFile, "--apply-fixes").CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if strings.Contains(string(output), "Fixed") {
+			t.Errorf("Expected no fixes, got: %s", output)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAssertStable tests that duplicate removal keeps first occurrences in
+// input order across files, and that --assert-stable checks it
+func TestAssertStable(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := filepath.Join(tmpDir, "a.csv")
+	second := filepath.Join(tmpDir, "b.csv")
+	if err := os.WriteFile(first, []byte("Front,Back\nzèbre,zebra\nchat,cat\nâne,donkey\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("Front,Back\nâne,ass\nbœuf,ox\nchat,tomcat\nzèbre,zebra\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+
+	// The same run repeated must keep the same rows in the same order
+	var previous string
+	for i := 0; i < 5; i++ {
+		cmd := exec.Command("ankiprep", "-s", "--dedupe-strategy", "key-columns", "--dedupe-columns", "Front",
+			"--assert-stable", "-v", first, second, "-o", outputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), "first occurrences kept in input order") {
+			t.Errorf("Expected stability check in verbose output, got: %s", output)
+		}
+
+		result, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		expected := "#separator:comma\n#html:true\n#columns:Front,Back\n" +
+			"zèbre,zebra\nchat,cat\nâne,donkey\nbœuf,ox\n"
+		if string(result) != expected {
+			t.Fatalf("Expected:\n%q\nGot:\n%q", expected, string(result))
+		}
+		if previous != "" && string(result) != previous {
+			t.Fatalf("Run %d differs from the previous one", i+1)
+		}
+		previous = string(result)
+	}
+
+	t.Run("requires skip-duplicates", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--assert-stable", first, "-o", outputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--assert-stable requires --skip-duplicates") {
+			t.Errorf("Expected flag error, got: %v, %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAutoLangTypography tests that --auto-lang limits French spacing to French cells
+func TestAutoLangTypography(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Notes
+Bonjour !,Is this formal? Not really.
+Merci,C'est très poli : à utiliser partout.
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "output.csv")
+	cmd := exec.Command("ankiprep", "-f", "--auto-lang", "-o", outputFile, inputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	resultStr := string(result)
+
+	// English sentence in the Notes column is left alone
+	if !strings.Contains(resultStr, "Is this formal? Not really.") {
+		t.Errorf("Expected English note without French spacing, got:\n%s", resultStr)
+	}
+	// French sentence in the same column gets NNBSP
+	if !strings.Contains(resultStr, "poli\u202F:") {
+		t.Errorf("Expected French note with NNBSP, got:\n%s", resultStr)
+	}
+	// Undetectable short cells fall back to the column rule
+	if !strings.Contains(resultStr, "Bonjour\u202F!") {
+		t.Errorf("Expected short French cell to keep column-based spacing, got:\n%s", resultStr)
+	}
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCacheDir tests that --cache-dir reuses the typography of unchanged
+// input files and gives the same output as a run without it
+func TestCacheDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	cacheDir := filepath.Join(tmpDir, "cache")
+
+	first := filepath.Join(tmpDir, "a.csv")
+	second := filepath.Join(tmpDir, "b.csv")
+	if err := os.WriteFile(first, []byte("Front,Back\nQuoi ?,\"\"\"what\"\"\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("Front,Back\nOui !,yes\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	run := func(output string, args ...string) string {
+		t.Helper()
+		args = append([]string{"-f", "-q", "-v", first, second, "-o", output}, args...)
+		result, err := exec.Command("ankiprep", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, result)
+		}
+		return string(result)
+	}
+	read := func(path string) string {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		return string(content)
+	}
+
+	uncached := filepath.Join(tmpDir, "uncached.csv")
+	run(uncached)
+
+	cached := filepath.Join(tmpDir, "cached.csv")
+	if output := run(cached, "--cache-dir", cacheDir); !strings.Contains(output, "Reusing cached typography: 0 of 2 entries") {
+		t.Errorf("Expected an empty cache, got: %s", output)
+	}
+	if output := run(cached, "--cache-dir", cacheDir); !strings.Contains(output, "Reusing cached typography: 2 of 2 entries") {
+		t.Errorf("Expected every entry from the cache, got: %s", output)
+	}
+	if read(cached) != read(uncached) {
+		t.Errorf("Expected the cached output to match:\n%q\nGot:\n%q", read(uncached), read(cached))
+	}
+
+	// Only the changed file is formatted again
+	if err := os.WriteFile(second, []byte("Front,Back\nOui !,yes\nNon !,no\n"), 0644); err != nil {
+		t.Fatalf("Failed to update test input file: %v", err)
+	}
+	if output := run(cached, "--cache-dir", cacheDir); !strings.Contains(output, "Reusing cached typography: 1 of 3 entries") {
+		t.Errorf("Expected the unchanged file from the cache, got: %s", output)
+	}
+	if !strings.Contains(read(cached), "Non\u202F!,no") {
+		t.Errorf("Expected the new row formatted, got: %q", read(cached))
+	}
+}
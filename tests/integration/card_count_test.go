@@ -0,0 +1,31 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCardCount tests that the summary reports the cards cloze notes create
+func TestCardCount(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Text,Extra\n" +
+		"{{c1::Paris}} is the capital of {{c2::France}},geography\n" +
+		"{{c1::Berlin}} is in {{c1::Germany}},\n" +
+		"no cloze here,\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	output, err := exec.Command("ankiprep", inputFile).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Cards: 3 from 2 cloze note(s) (up to 2 per note), plus 1 note(s) without clozes") {
+		t.Errorf("Expected card count, got: %s", output)
+	}
+}
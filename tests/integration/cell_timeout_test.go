@@ -0,0 +1,39 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCellTimeout tests that a pathological cell is left unformatted with a
+// warning instead of stalling the run
+func TestCellTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pathological := strings.Repeat("{{c1::", 200000)
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\nchat,cat : x\nbig," + pathological + "\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+
+	output, err := exec.Command("ankiprep", inputFile, "-f", "--cell-timeout", "100ms", "-o", outputFile).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), inputFile+":3: column Back (1200000 bytes) took over 100ms to format; left unchanged") {
+		t.Errorf("Expected a slow cell warning, got: %s", output)
+	}
+
+	result, _ := os.ReadFile(outputFile)
+	if !strings.Contains(string(result), "chat,cat\u202f: x\n") {
+		t.Errorf("Expected the other cells to be formatted, got:\n%.200s", result)
+	}
+	if !strings.Contains(string(result), "big,"+pathological+"\n") {
+		t.Errorf("Expected the slow cell to be left unchanged")
+	}
+}
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestVerifyChecksums tests that --verify-checksums stops on a changed input
+// and records input and output hashes in the report
+func TestVerifyChecksums(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := []byte("Front,Back\nchat,cat\n")
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	if err := os.WriteFile(inputFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	inputDigest := hex.EncodeToString(sum[:])
+	manifest := filepath.Join(tmpDir, "manifest.sha256")
+	if err := os.WriteFile(manifest, []byte(inputDigest+"  vocab.csv\n"), 0644); err != nil {
+		t.Fatalf("Failed to create manifest: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	reportFile := filepath.Join(tmpDir, "report.json")
+
+	cmd := exec.Command("ankiprep", inputFile, "--verify-checksums", manifest, "-o", outputFile, "--report", reportFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report struct {
+		Checksums map[string]string `json:"sha256"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	sum = sha256.Sum256(output)
+	if report.Checksums[inputFile] != inputDigest || report.Checksums[outputFile] != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the input and output hashes in the report, got %v", report.Checksums)
+	}
+
+	// A changed input stops the run before any output is written
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,dog\n"), 0644); err != nil {
+		t.Fatalf("Failed to change test input file: %v", err)
+	}
+	os.Remove(outputFile)
+	cmd = exec.Command("ankiprep", inputFile, "--verify-checksums", manifest, "-o", outputFile)
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "checksum mismatch for "+inputFile) {
+		t.Errorf("Expected a checksum mismatch, got %v: %s", err, out)
+	}
+	if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+		t.Errorf("Expected no output after a checksum mismatch")
+	}
+}
@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCJKColumns tests that French spacing leaves Japanese text alone and
+// that --cjk-spacing removes the spaces around its fullwidth punctuation
+func TestCJKColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\nLe chat?,猫 ですか ？\nLe mot « livre »,「 本 」\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "output.csv")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"french only", []string{"-f"}, "Le chat\u202F?,猫 ですか ？\nLe mot «\u202Flivre\u202F»,「 本 」\n"},
+		{"cjk spacing", []string{"-f", "--cjk-spacing"}, "Le chat\u202F?,猫 ですか？\nLe mot «\u202Flivre\u202F»,「本」\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(tt.args, "-o", outputFile, inputFile)
+			if output, err := exec.Command("ankiprep", args...).CombinedOutput(); err != nil {
+				t.Fatalf("Command failed: %v, output: %s", err, output)
+			}
+
+			result, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to read output file: %v", err)
+			}
+			if !strings.HasSuffix(string(result), tt.want) {
+				t.Errorf("Expected %q at the end, got %q", tt.want, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestColumnLanguages tests that config languages route typography, are
+// written with --emit-languages and are read back from the import file
+func TestColumnLanguages(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nQuoi ?,What ?\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	configFile := filepath.Join(tmpDir, "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"languages": {"Front": "fr", "Back": "en"}}`), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "output.csv")
+	cmd := exec.Command("ankiprep", "-f", "--config", configFile, "--emit-languages", inputFile, "-o", outputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\n#languages:Front=fr,Back=en\n" +
+		"Quoi\u202F?,What ?\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("read back", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "-f", "-v", outputFile, "-o", filepath.Join(tmpDir, "again.csv")).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), "Column languages: Back=en, Front=fr") {
+			t.Errorf("Expected the languages of the import file, got: %s", output)
+		}
+	})
+
+	t.Run("non-Anki format", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--emit-languages", "--format", "mochi", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--emit-languages writes an Anki directive and cannot be combined with --format mochi") {
+			t.Errorf("Expected flag error, got: %v, %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestColumnsFlag tests selecting and ordering output columns
+func TestColumnsFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Back,Notes,Front,Tags
+cat,ignore me,chat,animal
+dog,,chien,animal
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	t.Run("selects and orders columns", func(t *testing.T) {
+		outputFile := filepath.Join(tmpDir, "output.csv")
+		cmd := exec.Command("ankiprep", "--columns", "Front,Back,Tags", "-o", outputFile, inputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+
+		result, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		resultStr := string(result)
+
+		if !strings.Contains(resultStr, "#columns:Front,Back,Tags\n") {
+			t.Errorf("Expected selected columns in header, got:\n%s", resultStr)
+		}
+		if !strings.Contains(resultStr, "\nchat,cat,animal\nchien,dog,animal\n") {
+			t.Errorf("Expected reordered rows, got:\n%s", resultStr)
+		}
+		if strings.Contains(resultStr, "ignore me") {
+			t.Errorf("Expected unlisted column to be dropped, got:\n%s", resultStr)
+		}
+	})
+
+	t.Run("unknown column lists available columns", func(t *testing.T) {
+		outputFile := filepath.Join(tmpDir, "unknown.csv")
+		cmd := exec.Command("ankiprep", "--columns", "Front,Extra", "-o", outputFile, inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		if !strings.Contains(string(output), `unknown column "Extra"`) ||
+			!strings.Contains(string(output), "available: Back, Notes, Front, Tags") {
+			t.Errorf("Expected error listing available columns, got: %s", output)
+		}
+		if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+			t.Errorf("Expected no output file on error")
+		}
+	})
+}
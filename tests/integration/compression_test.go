@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// TestCompressedInput tests that gzip and zstd inputs are read and merged
+func TestCompressedInput(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var gz bytes.Buffer
+	writer := gzip.NewWriter(&gz)
+	writer.Write([]byte("Front,Back\nchat,cat\n"))
+	writer.Close()
+	gzFile := filepath.Join(tmpDir, "french.csv.gz")
+	if err := os.WriteFile(gzFile, gz.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create zstd encoder: %v", err)
+	}
+	zstFile := filepath.Join(tmpDir, "german.tsv.zst")
+	if err := os.WriteFile(zstFile, encoder.EncodeAll([]byte("Front\tBack\nHund\tdog\n"), nil), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", gzFile, zstFile, "-o", "-")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	for _, want := range []string{"chat,cat", "Hund,dog"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+}
+
+// TestCompressOutput tests that --compress writes gzip output with a .gz name
+func TestCompressOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", inputFile, "--compress")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	outputFile := filepath.Join(tmpDir, "input_processed.csv.gz")
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Expected compressed output %s: %v", outputFile, err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Expected gzip output: %v", err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decompress output: %v", err)
+	}
+	if !strings.Contains(string(content), "#columns:Front,Back") || !strings.Contains(string(content), "chat,cat") {
+		t.Errorf("Expected the Anki import file, got: %s", content)
+	}
+
+	// The output can be read back as input, with the same notes
+	cmd = exec.Command("ankiprep", outputFile, "-o", "-")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "chat,cat") {
+		t.Errorf("Expected the compressed output to be readable, got: %s", output)
+	}
+}
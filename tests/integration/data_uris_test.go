@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDataURIs tests detecting, stripping and extracting inline images
+func TestDataURIs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\n\"chat<img src=\"\"data:image/gif;base64,R0lGODlhAQABAAAAACw=\"\">\",cat\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "input_processed.csv")
+
+	t.Run("keep warns", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), "column Front embeds 1 data URI image(s)") {
+			t.Errorf("Expected data URI warning, got: %s", output)
+		}
+	})
+
+	t.Run("strip", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--data-uris", "strip", inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		result, _ := os.ReadFile(outputFile)
+		if !strings.Contains(string(result), "\nchat,cat\n") {
+			t.Errorf("Expected image removed, got:\n%s", result)
+		}
+	})
+
+	t.Run("extract", func(t *testing.T) {
+		mediaDir := filepath.Join(tmpDir, "collection.media")
+		output, err := exec.Command("ankiprep", "--data-uris", "extract", "--media-dir", mediaDir, inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		files, _ := filepath.Glob(filepath.Join(mediaDir, "ankiprep-*.gif"))
+		if len(files) != 1 {
+			t.Fatalf("Expected 1 extracted GIF, got %v", files)
+		}
+		result, _ := os.ReadFile(outputFile)
+		if !strings.Contains(string(result), filepath.Base(files[0])) || strings.Contains(string(result), "data:") {
+			t.Errorf("Expected reference to %s, got:\n%s", filepath.Base(files[0]), result)
+		}
+	})
+
+	t.Run("extract requires media dir", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--data-uris", "extract", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "requires --media-dir") {
+			t.Errorf("Expected media dir error, got: %v, %s", err, output)
+		}
+	})
+
+	t.Run("invalid mode before reading input", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.csv")
+		output, err := exec.Command("ankiprep", "--data-uris", "inline", missing).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `invalid --data-uris "inline"`) {
+			t.Errorf("Expected flag error before the input is read, got: %v, %s", err, output)
+		}
+	})
+}
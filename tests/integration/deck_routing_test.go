@@ -0,0 +1,35 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDeckFromColumn tests that --deck-from-column adds a Deck column and the
+// #deck column directive Anki routes notes with
+func TestDeckFromColumn(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back,Chapter\nchat,cat,1\nchien,dog,\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", inputFile, "-o", "-", "--deck-from-column", "Chapter", "--deck-prefix", "French::Course::", "--columns", "Front,Back,Deck")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back,Deck\n#deck column:3\nchat,cat,French::Course::1\nchien,dog,French::Course\n"
+	if !strings.HasPrefix(string(output), expected) {
+		t.Errorf("Expected output to start with %q, got: %s", expected, output)
+	}
+
+	cmd = exec.Command("ankiprep", inputFile, "-o", "-", "--deck-from-column", "Lesson")
+	if output, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(output), `--deck-from-column "Lesson" not found`) {
+		t.Errorf("Expected an unknown column error, got error %v, output: %s", err, output)
+	}
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestDedupeItemsFromConfig tests removing repeated items from configured
+// columns before duplicate detection
+func TestDedupeItemsFromConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "French,English\n" +
+		"chien,dog; dog; hound\n" +
+		"chien,dog; hound; hound\n" +
+		"chat,cat\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, "deck.json")
+	if err := os.WriteFile(configFile, []byte(`{"dedupe_items": {"English": ";"}}`), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "output.csv")
+	cmd := exec.Command("ankiprep", "--config", configFile, "-s", "-o", outputFile, inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:French,English\n" +
+		"chien,dog; hound\n" +
+		"chat,cat\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("empty delimiter fails", func(t *testing.T) {
+		badConfig := filepath.Join(tmpDir, "bad.json")
+		if err := os.WriteFile(badConfig, []byte(`{"dedupe_items": {"English": ""}}`), 0644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+		cmd := exec.Command("ankiprep", "--config", badConfig, "-o", filepath.Join(tmpDir, "bad.csv"), inputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+}
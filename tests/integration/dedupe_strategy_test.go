@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDedupeStrategy tests choosing how --skip-duplicates compares entries
+func TestDedupeStrategy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Back
+chat,cat
+Chat ,cat
+chat,kitty
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		notes int
+	}{
+		{"exact", []string{"-s"}, 3},
+		{"normalized", []string{"-s", "--dedupe-strategy", "normalized"}, 2},
+		{"key-columns", []string{"-s", "--dedupe-strategy", "key-columns", "--dedupe-columns", "Front"}, 2},
+		{"xxhash", []string{"-s", "--dedupe-strategy", "normalized", "--dedupe-hash", "xxhash"}, 2},
+		{"sha256 verified", []string{"-s", "--dedupe-strategy", "normalized", "--dedupe-hash", "sha256", "--verify-duplicates"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tmpDir, tt.name+".csv")
+			args := append(tt.args, "-o", outputFile, inputFile)
+			if output, err := exec.Command("ankiprep", args...).CombinedOutput(); err != nil {
+				t.Fatalf("Command failed: %v, output: %s", err, output)
+			}
+
+			result, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to read output file: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(result)), "\n")
+			if notes := len(lines) - 3; notes != tt.notes {
+				t.Errorf("Expected %d notes, got %d:\n%s", tt.notes, notes, result)
+			}
+		})
+	}
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "-s", "--dedupe-strategy", "phonetic", "-o", filepath.Join(tmpDir, "bad.csv"), inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `unknown dedupe strategy "phonetic"`) {
+			t.Errorf("Expected unknown strategy error, got %v: %s", err, output)
+		}
+	})
+
+	t.Run("unknown hash", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "-s", "--dedupe-hash", "crc32", "-o", filepath.Join(tmpDir, "bad.csv"), inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `unknown hash algorithm "crc32"`) {
+			t.Errorf("Expected unknown hash algorithm error, got %v: %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDeterministic tests that --deterministic output does not depend on the
+// order input files are listed in, and that reports carry no timings
+func TestDeterministic(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fileA := filepath.Join(tmpDir, "a.csv")
+	fileB := filepath.Join(tmpDir, "b.csv")
+	if err := os.WriteFile(fileA, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	if err := os.WriteFile(fileB, []byte("Front,Tags\nchien,animal\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	run := func(name string, files ...string) ([]byte, []byte) {
+		output := filepath.Join(tmpDir, name+".csv")
+		report := filepath.Join(tmpDir, name+".json")
+		args := append([]string{"--deterministic", "-o", output, "--report", report}, files...)
+		if out, err := exec.Command("ankiprep", args...).CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, out)
+		}
+		result, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		reportData, err := os.ReadFile(report)
+		if err != nil {
+			t.Fatalf("Failed to read report: %v", err)
+		}
+		return result, reportData
+	}
+
+	first, firstReport := run("first", fileA, fileB)
+	second, secondReport := run("second", fileB, fileA)
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("Expected identical output, got:\n%s\nand:\n%s", first, second)
+	}
+	if !strings.Contains(string(first), "#columns:Front,Back,Tags\n") {
+		t.Errorf("Expected columns in file name order, got:\n%s", first)
+	}
+	if !bytes.Equal(firstReport, secondReport) {
+		t.Errorf("Expected identical reports, got:\n%s\nand:\n%s", firstReport, secondReport)
+	}
+	if !strings.Contains(string(firstReport), `"processing_time_ns": 0`) {
+		t.Errorf("Expected no processing time in report, got:\n%s", firstReport)
+	}
+	if strings.Contains(string(firstReport), `"stages"`) {
+		t.Errorf("Expected no stage timings in report, got:\n%s", firstReport)
+	}
+}
+
+// TestSortBy tests sorting output rows by a column
+func TestSortBy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nzèbre,zebra\nchat,cat\nÉcole,school\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "--sort-by", "Front", "--keep-header", inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(filepath.Join(tmpDir, "input_processed.csv"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nFront,Back\nchat,cat\nzèbre,zebra\nÉcole,school\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("unknown column", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--sort-by", "Missing", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `--sort-by column "Missing" not found`) {
+			t.Errorf("Expected unknown column error, got: %v, %s", err, output)
+		}
+	})
+
+	t.Run("collate", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--sort-by", "Front", "--collate", "fr", inputFile, "-o", "-").CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), "chat,cat\nÉcole,school\nzèbre,zebra\n") {
+			t.Errorf("Expected French collation order, got: %s", output)
+		}
+	})
+
+	t.Run("collate without sort-by", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--collate", "fr", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--collate requires --sort-by") {
+			t.Errorf("Expected flag error, got: %v, %s", err, output)
+		}
+	})
+
+	t.Run("unknown collation before reading input", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.csv")
+		output, err := exec.Command("ankiprep", "--sort-by", "Front", "--collate", "not a language", missing).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--collate:") {
+			t.Errorf("Expected collation error before the input is read, got: %v, %s", err, output)
+		}
+	})
+}
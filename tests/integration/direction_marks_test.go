@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRightToLeftColumns tests that French spacing leaves Arabic text alone
+// and that --direction-marks marks the mixed cells of the given columns
+func TestRightToLeftColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Arabic\nQuel livre?,كتاب: جديد! (kitāb)\nLe stylo,قلم\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "output.csv")
+
+	cmd := exec.Command("ankiprep", "-f", "--direction-marks", "Arabic", "-o", outputFile, inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	want := "Quel livre\u202F?,كتاب: جديد!\u200F (kitāb)\u200E\nLe stylo,قلم\n"
+	if !strings.HasSuffix(string(result), want) {
+		t.Errorf("Expected %q at the end, got %q", want, result)
+	}
+
+	t.Run("unknown column", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--direction-marks", "Hebrew", "-o", outputFile, inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `column "Hebrew" not found for --direction-marks`) {
+			t.Errorf("Expected an unknown column error, got %v: %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDuplicateSourcesSummary tests reporting which files removed duplicates came from
+func TestDuplicateSourcesSummary(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fileA := filepath.Join(tmpDir, "a.csv")
+	if err := os.WriteFile(fileA, []byte("Front,Back\nchat,cat\nchien,dog\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	fileB := filepath.Join(tmpDir, "b.csv")
+	if err := os.WriteFile(fileB, []byte("Front,Back\nchat,cat\nchien,dog\nloup,wolf\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "-s", "-o", filepath.Join(tmpDir, "output.csv"), fileA, fileB)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	expected := "2 duplicates between " + fileA + " and " + fileB
+	if !strings.Contains(string(output), expected) {
+		t.Errorf("Expected %q in output, got: %s", expected, output)
+	}
+}
+
+// TestShowDuplicates tests printing the file and line of kept and removed
+// duplicates, and recording them in the report
+func TestShowDuplicates(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fileA := filepath.Join(tmpDir, "a.csv")
+	if err := os.WriteFile(fileA, []byte("Front,Back\nchat,cat\nchat,cat\nchat,cat\nchien,dog\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	fileB := filepath.Join(tmpDir, "b.csv")
+	if err := os.WriteFile(fileB, []byte("Front,Back\nchien,dog\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	reportPath := filepath.Join(tmpDir, "report.json")
+
+	cmd := exec.Command("ankiprep", "-s", "--show-duplicates", "--report", reportPath, "-o", filepath.Join(tmpDir, "output.csv"), fileA, fileB)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	for _, expected := range []string{
+		"Duplicate: kept " + fileA + ":2, removed " + fileA + ":3-4, " + fileB + ":3\n",
+		"Duplicate: kept " + fileA + ":5, removed " + fileB + ":2\n",
+	} {
+		if !strings.Contains(string(output), expected) {
+			t.Errorf("Expected %q in output, got: %s", expected, output)
+		}
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	expected := `{"source":"` + fileB + `","line":2}`
+	if !strings.Contains(strings.Join(strings.Fields(string(report)), ""), expected) {
+		t.Errorf("Expected %s in the report duplicates, got: %s", expected, report)
+	}
+}
@@ -0,0 +1,34 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExplode tests that --explode makes a row per value and that
+// --skip-duplicates removes the repeated rows it creates
+func TestExplode(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	input := "Word,Synonyms\nchat,\"matou; minou ;matou\"\nchien,\n"
+	if err := os.WriteFile(inputFile, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", inputFile, "-o", "-", "--explode", "Synonyms|;", "-s")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	expected := "#separator:comma\n#html:true\n#columns:Word,Synonyms\nchat,matou\nchat,minou\nchien,\n"
+	if !strings.HasPrefix(string(output), expected) {
+		t.Errorf("Expected output to start with %q, got: %s", expected, output)
+	}
+	if !strings.Contains(string(output), "1 duplicate within") {
+		t.Errorf("Expected the repeated synonym to be removed as a duplicate, got: %s", output)
+	}
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFieldTemplatesFromConfig tests the --config flag wrapping columns in HTML templates
+func TestFieldTemplatesFromConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Back,Example
+chat,cat,Le chat dort.
+chien,dog,
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, "deck.json")
+	configContent := `{"templates": {"Example": "<i class=\"ex\">{value}</i>"}}`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	t.Run("wraps configured column", func(t *testing.T) {
+		outputFile := filepath.Join(tmpDir, "output.csv")
+
+		// Smart quotes must not touch the template markup
+		cmd := exec.Command("ankiprep", "-q", "--config", configFile, "-o", outputFile, inputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+
+		result, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		resultStr := string(result)
+
+		if !strings.Contains(resultStr, `"<i class=""ex"">Le chat dort.</i>"`) {
+			t.Errorf("Expected wrapped Example value, got:\n%s", resultStr)
+		}
+		if !strings.Contains(resultStr, "chien,dog,\n") {
+			t.Errorf("Expected empty Example value to stay empty, got:\n%s", resultStr)
+		}
+	})
+
+	t.Run("invalid config fails", func(t *testing.T) {
+		badConfig := filepath.Join(tmpDir, "bad.json")
+		if err := os.WriteFile(badConfig, []byte(`{"templates": {"Example": "<i></i>"}}`), 0644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+
+		cmd := exec.Command("ankiprep", "--config", badConfig, "-o", filepath.Join(tmpDir, "bad.csv"), inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		if !strings.Contains(string(output), "{value}") {
+			t.Errorf("Expected error to mention the placeholder, got: %s", output)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"encoding/csv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedFixtureRoundTrip tests that every generated edge case survives processing
+func TestGeneratedFixtureRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "edge.csv")
+	outputFile := filepath.Join(tmpDir, "output.csv")
+
+	// The default kinds leave out ragged rows, which the parser rejects
+	cmd := exec.Command("ankiprep", "gen-fixture", "--rows", "50", "-o", inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gen-fixture failed: %v, output: %s", err, output)
+	}
+
+	cmd = exec.Command("ankiprep", "-f", "-q", "-s", "-o", outputFile, inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Processing fixture failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	// Strip Anki metadata lines, then parse the rest as CSV
+	var body []string
+	for _, line := range strings.SplitAfter(string(result), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			body = append(body, line)
+		}
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.Join(body, ""))).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+
+	if len(records) != 50 {
+		t.Errorf("Expected 50 notes, got %d", len(records))
+	}
+	if !strings.Contains(string(result), "#columns:col1,col2,col3\n") {
+		t.Errorf("Expected BOM-free column header, got:\n%s", result)
+	}
+}
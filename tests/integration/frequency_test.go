@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestOrderByFrequency tests ordering rows by a frequency wordlist
+func TestOrderByFrequency(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	wordlist := filepath.Join(tmpDir, "fr_50k.txt")
+	files := map[string]string{
+		inputFile: "English,French\nowl,hibou\ncat,chat\nto be,être\n",
+		wordlist:  "être 9000\nchat 40\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	cmd := exec.Command("ankiprep", inputFile, "--order-by-frequency", wordlist, "--frequency-column", "French", "-o", outputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Frequency order: 1 row(s) with words not in "+wordlist+" placed last") {
+		t.Errorf("Expected unknown word count, got: %s", output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:English,French\n" +
+		"to be,être\n" +
+		"cat,chat\n" +
+		"owl,hibou\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("default column is the first output column", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "--order-by-frequency", wordlist, "-o", outputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), "Frequency order: 3 row(s)") {
+			t.Errorf("Expected every English value to be unknown, got: %s", output)
+		}
+	})
+
+	t.Run("conflicts with --sort-by", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "--order-by-frequency", wordlist, "--sort-by", "French", "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHeaderAliases tests that column names in other languages merge with
+// Front and Back with --header-aliases, and keep their names without it
+func TestHeaderAliases(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	french := filepath.Join(tmpDir, "french.csv")
+	spanish := filepath.Join(tmpDir, "spanish.csv")
+	japanese := filepath.Join(tmpDir, "japanese.csv")
+	files := map[string]string{
+		french:   "Recto,Verso\nchat,cat\n",
+		spanish:  "Frente,Verso\nperro,dog\n",
+		japanese: "表,裏\n猫,cat\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	output, err := exec.Command("ankiprep", french, spanish, japanese, "-o", "-", "--header-aliases").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nchat,cat\nperro,dog\n猫,cat\n"
+	if !strings.HasPrefix(string(output), expected) {
+		t.Errorf("Expected output to start with %q, got: %s", expected, output)
+	}
+
+	output, err = exec.Command("ankiprep", french, spanish, "-o", "-").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "#columns:Recto,Verso,Frente\n") {
+		t.Errorf("Expected the original column names without --header-aliases, got: %s", output)
+	}
+
+	// Flags name the columns as they are in the files
+	output, err = exec.Command("ankiprep", french, "-o", "-", "--columns", "Verso,Recto").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "#columns:Verso,Recto\ncat,chat\n") {
+		t.Errorf("Expected the selected columns, got: %s", output)
+	}
+}
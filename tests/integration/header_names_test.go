@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHeaderSanitization tests that column names cannot corrupt #columns
+func TestHeaderSanitization(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	if err := os.WriteFile(inputFile, []byte("#Word,\"Part, of speech\"\nchat,noun\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	cmd := exec.Command("ankiprep", inputFile, "-o", outputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), `Warning: column "#Word" is written as "Word" in #columns`) {
+		t.Errorf("Expected sanitization warning, got: %s", output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Word,\"Part, of speech\"\nchat,noun\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("other formats have no #columns", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "--format", "quizlet", "-o", filepath.Join(tmpDir, "out.tsv"))
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if strings.Contains(string(output), "Warning") {
+			t.Errorf("Expected no warning, got: %s", output)
+		}
+	})
+}
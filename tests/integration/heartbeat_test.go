@@ -0,0 +1,38 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHeartbeat tests that quick runs print no heartbeat and that it can be
+// turned off
+func TestHeartbeat(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\nchat,cat\nQuoi ?,What?\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"-s", "-f", inputFile},
+		{"-s", "-f", "--heartbeat", "0", inputFile},
+	} {
+		output, err := exec.Command("ankiprep", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command %v failed: %v, output: %s", args, err, output)
+		}
+		if strings.Contains(string(output), "still working") {
+			t.Errorf("Expected no heartbeat for %v, got: %s", args, output)
+		}
+	}
+
+	output, err := exec.Command("ankiprep", "--heartbeat", "soon", inputFile).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "invalid argument \"soon\"") {
+		t.Errorf("Expected flag error, got: %v, %s", err, output)
+	}
+}
@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInspectValues tests the inspect --values column dictionary report
+func TestInspectValues(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Word,POS,Tags
+chat,noun,animals
+chien,Noun,animals pets
+courir,verb,
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "inspect", "--values", "--max-distinct", "3", inputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	outputStr := string(output)
+
+	expected := []string{
+		"Merged columns (3): Word, POS, Tags",
+		`Warning: near-matching values "Noun", "noun"`,
+		"Tags: 2 distinct values",
+	}
+	for _, want := range expected {
+		if !strings.Contains(outputStr, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, outputStr)
+		}
+	}
+
+	// Inspect must not write any output file
+	if _, err := os.Stat(filepath.Join(tmpDir, "input_processed.csv")); !os.IsNotExist(err) {
+		t.Errorf("Inspect should not create an output file")
+	}
+}
@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestJoinColumns tests that --join-columns combines columns into one,
+// including columns made by --split-column
+func TestJoinColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	input := "Word,Example1,Example2,Example3\nchat,Le chat dort,,Un chat noir\nloup,\"a;b\",c,\n"
+	if err := os.WriteFile(inputFile, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", inputFile, "-o", "-", "--join-columns", "Example1+Example2+Example3=Examples:<br>")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	for _, want := range []string{"#columns:Word,Examples\n", "chat,Le chat dort<br>Un chat noir\n", "loup,a;b<br>c\n"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+
+	cmd = exec.Command("ankiprep", inputFile, "-o", "-", "--split-column", "Example1|;|First,Second", "--join-columns", "Second+Example2=Rest: / ", "--columns", "Word,Rest")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "loup,b / c\n") {
+		t.Errorf("Expected the split column to be joined, got: %s", output)
+	}
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestJoinLookupFile tests adding columns from a lookup file with --join
+func TestJoinLookupFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	lookupFile := filepath.Join(tmpDir, "frequency.csv")
+	files := map[string]string{
+		inputFile:  "Word,English\nchat,cat\nchien,dog\nhibou,owl\n",
+		lookupFile: "Word,Rank\nchien,120\nchat,340\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	cmd := exec.Command("ankiprep", inputFile, "--join", lookupFile+" on Word", "-o", outputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Joined "+lookupFile+" on Word: 2 matched, 1 unmatched key(s)\n  \"hibou\"\n") {
+		t.Errorf("Expected unmatched key report, got: %s", output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Word,English,Rank\n" +
+		"chat,cat,340\n" +
+		"chien,dog,120\n" +
+		"hibou,owl,\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("unknown key column", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "--join", lookupFile+" on Lemma", "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "--join", lookupFile, "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestKeepTemp tests that failed runs only leave temporary files with --keep-temp
+func TestKeepTemp(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	// A directory as output path makes the final move fail after writing
+	outputDir := filepath.Join(tmpDir, "out")
+	if err := os.Mkdir(outputDir, 0755); err != nil {
+		t.Fatalf("Failed to create output directory: %v", err)
+	}
+
+	tempFiles := func() []string {
+		matches, _ := filepath.Glob(filepath.Join(tmpDir, "ankiprep-*.tmp"))
+		return matches
+	}
+
+	t.Run("removed by default", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "-o", outputDir, inputFile).CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		if leftovers := tempFiles(); len(leftovers) != 0 {
+			t.Errorf("Expected no temporary files, found %v", leftovers)
+		}
+	})
+
+	t.Run("kept with --keep-temp", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--keep-temp", "-o", outputDir, inputFile).CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		leftovers := tempFiles()
+		if len(leftovers) != 1 {
+			t.Fatalf("Expected one kept temporary file, found %v", leftovers)
+		}
+		if !strings.Contains(string(output), "Keeping temporary file "+leftovers[0]) {
+			t.Errorf("Expected kept file to be reported, got: %s", output)
+		}
+
+		content, err := os.ReadFile(leftovers[0])
+		if err != nil || !strings.Contains(string(content), "chat,cat") {
+			t.Errorf("Expected kept file to hold the output, got %q, %v", content, err)
+		}
+	})
+}
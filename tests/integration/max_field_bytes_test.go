@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMaxFieldBytes tests truncating, skipping and rejecting oversize fields
+func TestMaxFieldBytes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\nchat,cat\nessay," + strings.Repeat("word ", 10) + "\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "input_processed.csv")
+
+	t.Run("truncate", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--max-field-bytes", "9", inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), inputFile+":3: column Back is 50 bytes, truncated to 9") {
+			t.Errorf("Expected truncation warning, got: %s", output)
+		}
+		result, _ := os.ReadFile(outputFile)
+		if !strings.Contains(string(result), "essay,word word\n") {
+			t.Errorf("Expected truncated field, got:\n%s", result)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		reportFile := filepath.Join(tmpDir, "report.json")
+		output, err := exec.Command("ankiprep", "--max-field-bytes", "9", "--on-oversize", "skip", "--report", reportFile, inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		result, _ := os.ReadFile(outputFile)
+		if strings.Contains(string(result), "essay") || !strings.Contains(string(result), "chat,cat") {
+			t.Errorf("Expected oversize row skipped, got:\n%s", result)
+		}
+
+		// Skipped rows are not duplicates
+		var report struct {
+			DuplicatesRemoved int `json:"duplicates_removed"`
+			SkippedRecords    int `json:"skipped_records"`
+			OutputRecords     int `json:"output_records"`
+		}
+		data, err := os.ReadFile(reportFile)
+		if err != nil {
+			t.Fatalf("Failed to read report: %v", err)
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("Invalid report: %v", err)
+		}
+		if report.DuplicatesRemoved != 0 || report.SkippedRecords != 1 || report.OutputRecords != 1 {
+			t.Errorf("Expected 0 duplicates, 1 skipped and 1 output record, got %+v", report)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--max-field-bytes", "9", "--on-oversize", "error", "-o", filepath.Join(tmpDir, "rejected.csv"), inputFile).CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		if !strings.Contains(string(output), "1 field(s) exceed --max-field-bytes 9") {
+			t.Errorf("Expected size error, got: %s", output)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "rejected.csv")); !os.IsNotExist(err) {
+			t.Error("Expected no output file")
+		}
+	})
+
+	t.Run("invalid mode before reading input", func(t *testing.T) {
+		missing := filepath.Join(tmpDir, "missing.csv")
+		output, err := exec.Command("ankiprep", "--on-oversize", "bogus", missing).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `invalid --on-oversize "bogus"`) {
+			t.Errorf("Expected flag error before the input is read, got: %v, %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMergeFields tests that duplicates differing only in their examples are
+// merged into the surviving row
+func TestMergeFields(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back,Examples\n" +
+		"chat,cat,Le chat dort.\n" +
+		"chat,cat,Le chat mange.<br>Le chat dort.\n" +
+		"chat,kitty,Quel joli chat !\n" +
+		"chien,dog,\n" +
+		"chien,dog,Le chien aboie.\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "input_processed.csv")
+
+	cmd := exec.Command("ankiprep", "-s", "--dedupe-strategy", "merge-fields", "--merge-fields", "Examples", inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back,Examples\n" +
+		"chat,cat,Le chat dort.<br>Le chat mange.\n" +
+		"chat,kitty,Quel joli chat !\n" +
+		"chien,dog,Le chien aboie.\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("config delimiter", func(t *testing.T) {
+		configFile := filepath.Join(tmpDir, "config.json")
+		if err := os.WriteFile(configFile, []byte(`{"merge_fields": {"Examples": " | "}}`), 0644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+
+		cmd := exec.Command("ankiprep", "-s", "--dedupe-strategy", "merge-fields", "--config", configFile, inputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+
+		result, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		if !strings.Contains(string(result), "chat,cat,Le chat dort. | Le chat mange.<br>Le chat dort.\n") {
+			t.Errorf("Expected examples merged with the config delimiter, got:\n%s", result)
+		}
+	})
+
+	t.Run("requires skip-duplicates", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--merge-fields", "Examples", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--merge-fields requires --skip-duplicates") {
+			t.Errorf("Expected flag error, got: %v, %s", err, output)
+		}
+	})
+
+	t.Run("strategy needs merged columns", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "-s", "--dedupe-strategy", "merge-fields", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "needs at least one merged column") {
+			t.Errorf("Expected strategy error, got: %v, %s", err, output)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "-s", "--merge-fields", "Notes", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `column "Notes" not found for --merge-fields`) {
+			t.Errorf("Expected column error, got: %v, %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMergeTags tests that removed duplicates' tags are kept on the surviving row
+func TestMergeTags(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back,Tags\nchat,cat,animals\nchat,cat (pet),lesson3 animals\nchien,dog,\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "-s", "--dedupe-strategy", "key-columns", "--dedupe-columns", "Front", "--merge-tags", inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(filepath.Join(tmpDir, "input_processed.csv"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back,Tags\n" +
+		"chat,cat,animals lesson3\n" +
+		"chien,dog,\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("requires skip-duplicates", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--merge-tags", inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--merge-tags requires --skip-duplicates") {
+			t.Errorf("Expected flag error, got: %v, %s", err, output)
+		}
+	})
+
+	t.Run("checked before reading input", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.csv")
+		output, err := exec.Command("ankiprep", "--merge-tags", missing).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--merge-tags requires --skip-duplicates") {
+			t.Errorf("Expected flag error before the input is read, got: %v, %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNoHeaderFlag tests processing files without a header row
+func TestNoHeaderFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `chat,cat,
+chien,dog,animal
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	t.Run("generates column names", func(t *testing.T) {
+		outputFile := filepath.Join(tmpDir, "output.csv")
+		cmd := exec.Command("ankiprep", "--no-header", "-k", "-o", outputFile, inputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+
+		result, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		resultStr := string(result)
+
+		if !strings.Contains(resultStr, "#columns:Column1,Column2,Column3\n") {
+			t.Errorf("Expected generated column names, got:\n%s", resultStr)
+		}
+		// First row is data, and --keep-header has no generated header to keep
+		if !strings.Contains(resultStr, "\nchat,cat,\nchien,dog,animal\n") {
+			t.Errorf("Expected both rows as data, got:\n%s", resultStr)
+		}
+	})
+
+	t.Run("warns when first row looks like data", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "-o", filepath.Join(tmpDir, "warn.csv"), inputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), "probably isn't a header") {
+			t.Errorf("Expected headerless warning, got: %s", output)
+		}
+	})
+
+	t.Run("assume-header skips checks", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--assume-header", "-o", filepath.Join(tmpDir, "assume.csv"), inputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if strings.Contains(string(output), "header") {
+			t.Errorf("Expected no header warning with --assume-header, got: %s", output)
+		}
+	})
+
+	t.Run("conflicting overrides fail", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--assume-header", "--no-header", "-o", filepath.Join(tmpDir, "both.csv"), inputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected --assume-header with --no-header to fail, output: %s", output)
+		}
+	})
+}
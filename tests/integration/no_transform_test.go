@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNoTransform tests that --no-transform merges and dedupes files while
+// leaving every cell as it was read, and rejects flags that change cells
+func TestNoTransform(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := filepath.Join(tmpDir, "first.csv")
+	second := filepath.Join(tmpDir, "second.csv")
+	files := map[string]string{
+		first:  "\uFEFFFront,Back\n\"Bonjour : monde\",\"line one\nline two\"\nchat,\"\uFEFF\"\"cat\"\" \"\n",
+		second: "Front,Back\nchat,\"\uFEFF\"\"cat\"\" \"\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	output, err := exec.Command("ankiprep", first, second, "-s", "--no-transform", "-o", "-").Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\n" +
+		"Bonjour : monde,\"line one\nline two\"\nchat,\"\uFEFF\"\"cat\"\" \"\n"
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	for _, flags := range [][]string{
+		{"--french"},
+		{"--regex", "Back:s/a/b/"},
+		{"--output-separator", "tab"},
+		{"--format", "mochi"},
+		{"--profile", "french-vocab"},
+	} {
+		args := append([]string{first, "--no-transform", "-o", "-"}, flags...)
+		output, err := exec.Command("ankiprep", args...).CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected %v to be rejected with --no-transform, got: %s", flags, output)
+		} else if !strings.Contains(string(output), "--no-transform") {
+			t.Errorf("Expected an error naming --no-transform for %v, got: %s", flags, output)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNormalizeSymbols tests that --normalize-symbols makes entries pasted
+// with fullwidth punctuation or emoji variation selectors duplicates of the
+// plain ones, and that French spacing then sees the punctuation
+func TestNormalizeSymbols(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\nQuoi?,what\nQuoi？,what\nJ'aime ❤\uFE0F,I love\nJ'aime ❤,I love\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "output.csv")
+
+	cmd := exec.Command("ankiprep", "-s", "-f", "--normalize-symbols", "Front:ascii", "-o", outputFile, inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	want := "#columns:Front,Back\nQuoi\u202F?,what\nJ'aime ❤,I love\n"
+	if !strings.HasSuffix(string(result), want) {
+		t.Errorf("Expected %q at the end, got %q", want, result)
+	}
+
+	t.Run("invalid mode", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--normalize-symbols", "Front:latin", "-o", outputFile, inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `unknown symbol mode "latin"`) {
+			t.Errorf("Expected an unknown mode error, got %v: %s", err, output)
+		}
+	})
+}
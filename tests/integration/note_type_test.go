@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNoteTypeCheck tests warnings for columns that do not match the note type
+func TestNoteTypeCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back,Notes,Tags\nchat,cat,pet,animals\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	noteTypes := filepath.Join(tmpDir, "note-types.json")
+	if err := os.WriteFile(noteTypes, []byte(`{"Basic": ["Front", "Back"]}`), 0644); err != nil {
+		t.Fatalf("Failed to create note types file: %v", err)
+	}
+
+	output, err := exec.Command("ankiprep", "--note-type", "Basic", "--note-types", noteTypes, inputFile).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), `Warning: 3 column(s) but note type "Basic" has 2 field(s); Anki drops Notes`) {
+		t.Errorf("Expected field count warning, got: %s", output)
+	}
+
+	t.Run("matching columns", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--note-type", "Basic", "--note-types", noteTypes, "--columns", "Front,Back,Tags", inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if strings.Contains(string(output), "Warning") {
+			t.Errorf("Expected no warnings, got: %s", output)
+		}
+	})
+
+	t.Run("file runs do not ask AnkiConnect", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--note-type", "Basic", "--ankiconnect-url", "http://127.0.0.1:1", inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if strings.Contains(string(output), "cannot check the fields") {
+			t.Errorf("Expected no AnkiConnect check, got: %s", output)
+		}
+	})
+
+	t.Run("unknown note type", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "--note-type", "Cloze", "--note-types", noteTypes, inputFile).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), `note type "Cloze" not found`) {
+			t.Errorf("Expected unknown note type error, got: %v, %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestNotifyWebhook tests that --notify-webhook receives the report when a run
+// completes and when it fails
+func TestNotifyWebhook(t *testing.T) {
+	events := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Failed to decode webhook body: %v", err)
+		}
+		events <- event
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\nchien,dog\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+
+	t.Run("completed", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "-o", outputFile, "--notify-webhook", server.URL)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+
+		event := <-events
+		if event["status"] != "completed" || event["output"] != outputFile {
+			t.Errorf("Unexpected event: %v", event)
+		}
+		report, _ := event["report"].(map[string]interface{})
+		if report["output_records"] != float64(2) {
+			t.Errorf("Expected report with 2 output records, got %v", event["report"])
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "-o", outputFile, "--require", "Notes", "--notify-webhook", server.URL)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+
+		event := <-events
+		if event["status"] != "failed" {
+			t.Errorf("Expected failed status, got %v", event)
+		}
+		errors, _ := event["report"].(map[string]interface{})["errors"].([]interface{})
+		if len(errors) != 1 || errors[0] != event["error"] {
+			t.Errorf("Expected the error in the report, got %v", event)
+		}
+	})
+
+	t.Run("unreachable webhook is a warning", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "-o", outputFile, "--notify-webhook", "http://127.0.0.1:1/", "--retries", "1")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("Expected run to succeed, got %v, output: %s", err, output)
+		}
+	})
+}
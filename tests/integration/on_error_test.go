@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestOnError tests how --on-error handles an input file that cannot be read
+func TestOnError(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	goodFile := filepath.Join(tmpDir, "good.csv")
+	badFile := filepath.Join(tmpDir, "bad.csv")
+	files := map[string]string{
+		goodFile: "Front,Back\nchat,cat\n",
+		badFile:  "",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nchat,cat\n"
+
+	tests := []struct {
+		policy   string
+		exitCode int
+		output   bool
+	}{
+		{"fail", 1, false},
+		{"skip", 0, true},
+		{"abort-at-end", 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			os.Remove(outputFile)
+			cmd := exec.Command("ankiprep", badFile, goodFile, "--on-error", tt.policy, "-o", outputFile)
+			output, err := cmd.CombinedOutput()
+
+			exitCode := 0
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("Command failed to run: %v", err)
+			}
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d, output: %s", tt.exitCode, exitCode, output)
+			}
+
+			result, err := os.ReadFile(outputFile)
+			if tt.output {
+				if err != nil || string(result) != expected {
+					t.Errorf("Expected output %q, got %q (%v)", expected, result, err)
+				}
+				if !strings.Contains(string(output), "Warning: cannot parse "+badFile) {
+					t.Errorf("Expected a warning about %s, got: %s", badFile, output)
+				}
+			} else if err == nil {
+				t.Errorf("Expected no output file, got %q", result)
+			}
+		})
+	}
+
+	t.Run("no readable files", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", badFile, "--on-error", "skip", "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", goodFile, "--on-error", "ignore", "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestOutputSeparatorTab tests writing Anki-parseable TSV output
+func TestOutputSeparatorTab(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\n\"line one\nline two\",\"a\tb\"\nchat,cat\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "--output-separator", "tab", inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(filepath.Join(tmpDir, "input_processed.tsv"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	expected := "#separator:tab\n#html:true\n#columns:Front\tBack\n" +
+		"line one<br>line two\ta&#9;b\n" +
+		"chat\tcat\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("invalid separator", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--output-separator", "semicolon", inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		if !strings.Contains(string(output), "must be comma or tab") {
+			t.Errorf("Expected separator error, got: %s", output)
+		}
+	})
+}
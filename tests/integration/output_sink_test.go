@@ -0,0 +1,311 @@
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestOutputFormatTSV tests that --format tsv selects tab-separated output
+func TestOutputFormatTSV(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "--format", "tsv", inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(filepath.Join(tmpDir, "input_processed.tsv"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if !strings.HasPrefix(string(result), "#separator:tab\n") || !strings.Contains(string(result), "chat\tcat\n") {
+		t.Errorf("Expected TSV output, got:\n%s", result)
+	}
+
+	t.Run("conflicting separator", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--format", "tsv", "--output-separator", "comma", inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "disagree") {
+			t.Errorf("Expected conflict error, got: %v, %s", err, output)
+		}
+	})
+}
+
+// TestOutputStdout tests that -o - writes the import file to stdout and the
+// summary to stderr
+func TestOutputStdout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "-o", "-", inputFile)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v, stderr: %s", err, stderr.String())
+	}
+
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nchat,cat\n"
+	if string(stdout) != expected {
+		t.Errorf("Expected stdout:\n%q\nGot:\n%q", expected, stdout)
+	}
+	if !strings.Contains(stderr.String(), "Done.") {
+		t.Errorf("Expected summary on stderr, got: %s", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "input_processed.csv")); !os.IsNotExist(err) {
+		t.Error("Expected no output file")
+	}
+
+	t.Run("verbose", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "-o", "-", "-v", "-f", inputFile)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		stdout, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("Command failed: %v, stderr: %s", err, stderr.String())
+		}
+		if string(stdout) != expected {
+			t.Errorf("Expected only the import file on stdout, got:\n%q", stdout)
+		}
+		for _, want := range []string{"Processing 1 input file(s)...", "Processing Summary:", "Column statistics:"} {
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("Expected %q on stderr, got: %s", want, stderr.String())
+			}
+		}
+	})
+}
+
+// TestStatusStderr tests that summary lines go to stderr when writing a file,
+// leaving stdout empty
+func TestStatusStderr(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "-v", "-o", filepath.Join(tmpDir, "out.csv"), inputFile)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v, stderr: %s", err, stderr.String())
+	}
+	if len(stdout) != 0 {
+		t.Errorf("Expected nothing on stdout, got: %q", stdout)
+	}
+	if !strings.Contains(stderr.String(), "Done.") {
+		t.Errorf("Expected summary on stderr, got: %s", stderr.String())
+	}
+}
+
+// TestPlainHeader tests that --plain-header writes a header row instead of
+// Anki metadata
+func TestPlainHeader(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,\"cat\nfeline\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--plain-header"}, "Front,Back\nchat,\"cat\nfeline\"\n"},
+		{[]string{"--plain-header", "--keep-header", "--format", "tsv"}, "Front\tBack\nchat\t\"cat\nfeline\"\n"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"-o", "-", inputFile}, tt.args...)
+		stdout, err := exec.Command("ankiprep", args...).Output()
+		if err != nil {
+			t.Fatalf("%v: command failed: %v", tt.args, err)
+		}
+		if string(stdout) != tt.expected {
+			t.Errorf("%v: expected:\n%q\nGot:\n%q", tt.args, tt.expected, stdout)
+		}
+	}
+
+	t.Run("other formats", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--plain-header", "--format", "mochi", inputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+}
+
+// TestPushAnkiConnect tests adding notes through a fake AnkiConnect server
+func TestPushAnkiConnect(t *testing.T) {
+	var received struct {
+		Action string `json:"action"`
+		Params struct {
+			Notes []struct {
+				DeckName  string            `json:"deckName"`
+				ModelName string            `json:"modelName"`
+				Fields    map[string]string `json:"fields"`
+				Tags      []string          `json:"tags"`
+			} `json:"notes"`
+		} `json:"params"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		io.WriteString(w, `{"result": [1, null], "error": null}`)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back,Tags\nchat,cat,animals fr\nchien,dog,animals\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "--push", "--deck", "French", "--ankiconnect-url", server.URL, inputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	if !strings.Contains(string(output), `Added 1 of 2 notes to deck "French"`) {
+		t.Errorf("Expected added count, got: %s", output)
+	}
+	if received.Action != "addNotes" || len(received.Params.Notes) != 2 {
+		t.Fatalf("Expected addNotes with 2 notes, got %+v", received)
+	}
+	note := received.Params.Notes[0]
+	if note.DeckName != "French" || note.ModelName != "Basic" || note.Fields["Front"] != "chat" {
+		t.Errorf("Unexpected note: %+v", note)
+	}
+	if len(note.Tags) != 2 || note.Tags[0] != "animals" || note.Tags[1] != "fr" {
+		t.Errorf("Expected tags [animals fr], got %v", note.Tags)
+	}
+	if _, ok := note.Fields["Tags"]; ok {
+		t.Error("Expected Tags column not to be sent as a field")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "input_processed.csv")); !os.IsNotExist(err) {
+		t.Error("Expected no output file")
+	}
+}
+
+// TestOutputFormatProfiles tests writing files for other spaced-repetition tools
+func TestOutputFormatProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	tests := []struct {
+		format   string
+		output   string
+		expected string
+	}{
+		{"mochi", "input_processed.md", "chat\n---\ncat\n"},
+		{"remnote", "input_processed.csv", "chat,cat\n"},
+		{"quizlet", "input_processed.tsv", "chat\tcat\n"},
+	}
+
+	for _, tt := range tests {
+		output, err := exec.Command("ankiprep", "--format", tt.format, inputFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: command failed: %v, output: %s", tt.format, err, output)
+		}
+		result, err := os.ReadFile(filepath.Join(tmpDir, tt.output))
+		if err != nil {
+			t.Fatalf("%s: failed to read output file: %v", tt.format, err)
+		}
+		if string(result) != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.format, tt.expected, result)
+		}
+	}
+}
+
+// TestWriteBatches tests that -v reports the throughput of each output batch
+// and that batching leaves the output unchanged
+func TestWriteBatches(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var csv strings.Builder
+	csv.WriteString("Front,Back\n")
+	for i := 0; i < 200; i++ {
+		csv.WriteString("chat" + strings.Repeat("x", i%7) + ",\"a cat\nof sorts\"\n")
+	}
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte(csv.String()), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	unbatched := filepath.Join(tmpDir, "unbatched.csv")
+	if output, err := exec.Command("ankiprep", "-o", unbatched, inputFile).CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	batched := filepath.Join(tmpDir, "batched.csv")
+	output, err := exec.Command("ankiprep", "-v", "--write-batch-bytes", "1000", "-o", batched, inputFile).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	if strings.Count(string(output), "Wrote batch ") < 3 || !strings.Contains(string(output), "rows/s") {
+		t.Errorf("Expected several batches with their throughput, got: %s", output)
+	}
+	want, _ := os.ReadFile(unbatched)
+	got, _ := os.ReadFile(batched)
+	if string(got) != string(want) {
+		t.Errorf("Batched output differs:\n%s", got)
+	}
+
+	output, err = exec.Command("ankiprep", "--write-batch-bytes", "0", "-o", batched, inputFile).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "--write-batch-bytes must be at least 1") {
+		t.Errorf("Expected an error for --write-batch-bytes 0, got %v: %s", err, output)
+	}
+	output, err = exec.Command("ankiprep", "--write-batch-bytes", "1000000000000", "-o", batched, inputFile).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "--write-batch-bytes must be at most") {
+		t.Errorf("Expected an error for a huge --write-batch-bytes, got %v: %s", err, output)
+	}
+}
+
+// TestNetworkFS tests that --network-fs on writes a verified output
+func TestNetworkFS(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "output.csv")
+
+	output, err := exec.Command("ankiprep", "-v", "--network-fs", "on", "-o", outputFile, inputFile).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Writing to a network filesystem") {
+		t.Errorf("Expected the network filesystem settings to be reported, got: %s", output)
+	}
+	if result, _ := os.ReadFile(outputFile); !strings.HasSuffix(string(result), "chat,cat\n") {
+		t.Errorf("Expected the notes in the output, got:\n%s", result)
+	}
+
+	output, err = exec.Command("ankiprep", "--network-fs", "maybe", "-o", outputFile, inputFile).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), `invalid --network-fs "maybe"`) {
+		t.Errorf("Expected an error for --network-fs maybe, got %v: %s", err, output)
+	}
+}
@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPreflight tests the --preflight summary of a merge of the same notes
+func TestPreflight(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back,Notes\nchat,cat,\nchien,dog,\nchat,cat,\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+
+	// Without a terminal on stdin the run goes on without asking
+	cmd := exec.Command("ankiprep", inputFile, "--preflight", "-o", outputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	for _, want := range []string{
+		"Pre-flight check (all 3 entries):",
+		"Duplicates: 1 (33%)",
+		"Mostly empty columns: Notes (100% empty)",
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+	if strings.Contains(string(output), "Continue?") {
+		t.Errorf("Expected no prompt without a terminal, got: %s", output)
+	}
+	if _, err := os.Stat(outputFile); err != nil {
+		t.Errorf("Expected the run to complete: %v", err)
+	}
+}
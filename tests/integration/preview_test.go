@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPreviewCommand tests printing processed rows without writing output
+func TestPreviewCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Back
+"Bonjour !","Hello<br>world"
+"Ça va ?",Fine
+Troisième,Third
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "preview", "-n", "2", "-f", inputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	outputStr := string(output)
+
+	if !strings.Contains(outputStr, "Row 1 ("+inputFile+":2)") {
+		t.Errorf("Expected row heading with source line, got:\n%s", outputStr)
+	}
+	if !strings.Contains(outputStr, "Bonjour[NNBSP]!") {
+		t.Errorf("Expected visible NNBSP marker, got:\n%s", outputStr)
+	}
+	if !strings.Contains(outputStr, "Hello<br>↵") {
+		t.Errorf("Expected visible line break marker, got:\n%s", outputStr)
+	}
+	if strings.Contains(outputStr, "Troisième") {
+		t.Errorf("Expected only 2 rows, got:\n%s", outputStr)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "input_processed.csv")); !os.IsNotExist(err) {
+		t.Errorf("Preview should not write an output file")
+	}
+}
+
+// TestPreviewDiff tests the per-field diff of original against processed text
+func TestPreviewDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Back
+"Bonjour !","Il dit ""oui"""
+chat,cat
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "preview", "-f", "-q", "--diff", inputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	outputStr := string(output)
+
+	for _, want := range []string{
+		"--- Front (original)\n+++ Front (processed)\n@@ -1 +1 @@\n-Bonjour !\n+Bonjour[NNBSP]!\n  (1 NNBSP(s) inserted)\n",
+		"-Il dit \"oui\"\n+Il dit “oui”\n  (2 quote(s) converted)\n",
+		"1 of 2 row(s) changed",
+	} {
+		if !strings.Contains(outputStr, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, outputStr)
+		}
+	}
+	if strings.Contains(outputStr, "cat") {
+		t.Errorf("Expected unchanged fields to be left out, got:\n%s", outputStr)
+	}
+}
@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestProfiles tests that built-in profiles are listed and applied, and that
+// flags given on the command line override them
+func TestProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	input := "Front,Back,Text,Notes\n\"Quoi ?\",what,{{c1::Quoi}} ?,x\n\"quoi ?\",what,{{c1::Quoi}} ?,x\n"
+	if err := os.WriteFile(inputFile, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	output, err := exec.Command("ankiprep", "profiles").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" && !strings.HasPrefix(line, " ") {
+			names = append(names, line)
+		}
+	}
+	if !strings.Contains(string(output), "french-vocab\n") || !strings.Contains(string(output), "--skip-duplicates true") {
+		t.Errorf("Expected the french-vocab profile and its flags to be listed, got: %s", output)
+	}
+
+	// Every profile only sets flags the pipeline commands have
+	for _, name := range names {
+		output, err := exec.Command("ankiprep", "preview", inputFile, "--profile", name).CombinedOutput()
+		if err != nil {
+			t.Errorf("Profile %s failed: %v, output: %s", name, err, output)
+		}
+	}
+
+	output, err = exec.Command("ankiprep", inputFile, "-o", "-", "--profile", "french-vocab").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nQuoi\u202F?,what\n"
+	if !strings.HasPrefix(string(output), expected) || strings.Contains(string(output), "quoi\u202F?") {
+		t.Errorf("Expected output to start with %q and the normalized duplicate removed, got: %s", expected, output)
+	}
+
+	output, err = exec.Command("ankiprep", inputFile, "-o", "-", "--profile", "french-vocab", "--dedupe-strategy", "exact").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "quoi\u202F?,what\n") {
+		t.Errorf("Expected --dedupe-strategy exact to override the profile and keep both rows, got: %s", output)
+	}
+
+	output, err = exec.Command("ankiprep", inputFile, "--profile", "german-vocab").CombinedOutput()
+	if err == nil || !strings.Contains(string(output), `unknown profile "german-vocab"`) {
+		t.Errorf("Expected an unknown profile error, got error %v, output: %s", err, output)
+	}
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRegexRules tests sed-style --regex rules and regex rules from --config
+func TestRegexRules(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	configFile := filepath.Join(tmpDir, "deck.json")
+	files := map[string]string{
+		inputFile:  "Front,Back\ncourir,\"to run  \"\nmanger,to eat\n",
+		configFile: `{"regex": ["Back:s/^to //"]}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	cmd := exec.Command("ankiprep", inputFile, "--config", configFile, "--regex", `Back:s/\s+$//`, "--regex", "Back:s/$/ (v.)/", "-o", outputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\n" +
+		"courir,run (v.)\n" +
+		"manger,eat (v.)\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("compile error names the rule", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "--regex", "Back:s/x//", "--regex", "Back:s/(//", "-o", outputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+		if !strings.Contains(string(output), `--regex #2 "Back:s/(//"`) {
+			t.Errorf("Expected error to locate the rule, got: %s", output)
+		}
+	})
+
+	t.Run("invalid config rule", func(t *testing.T) {
+		badConfig := filepath.Join(tmpDir, "bad.json")
+		if err := os.WriteFile(badConfig, []byte(`{"regex": ["Back:s/[//"]}`), 0644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+		cmd := exec.Command("ankiprep", inputFile, "--config", badConfig, "-o", outputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "regex rule 1") {
+			t.Errorf("Expected config error naming rule 1, got: %v, %s", err, output)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "--regex", "Extra:s/a/b/", "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestReplaceMap tests exact substitutions from a --replace-map file
+func TestReplaceMap(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	mapFile := filepath.Join(tmpDir, "pos.csv")
+	files := map[string]string{
+		inputFile: "French,English,POS\nchat,cat,n.\ncourir,to run,v.\nvite,fast,adv.\n",
+		mapFile:   "Column,From,To\nPOS,n.,noun\nPOS,v.,verb\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	reportFile := filepath.Join(tmpDir, "report.json")
+	cmd := exec.Command("ankiprep", inputFile, "--replace-map", mapFile, "--report", reportFile, "-o", outputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:French,English,POS\n" +
+		"chat,cat,noun\n" +
+		"courir,to run,verb\n" +
+		"vite,fast,adv.\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report struct {
+		Replacements int `json:"replacements"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v\n%s", err, data)
+	}
+	if report.Replacements != 2 {
+		t.Errorf("Expected 2 replacements in the report, got %d", report.Replacements)
+	}
+}
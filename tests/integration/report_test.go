@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestJSONReport tests the --report flag writing counts and column statistics
+func TestJSONReport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Back,Extra
+chat,cat,
+chien,dog,
+chat,cat,
+maison,house,note
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	reportFile := filepath.Join(tmpDir, "report.json")
+	cmd := exec.Command("ankiprep", "-s", "--report", reportFile, "-o", filepath.Join(tmpDir, "output.csv"), inputFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	var report struct {
+		TotalInputRecords int `json:"total_input_records"`
+		DuplicatesRemoved int `json:"duplicates_removed"`
+		OutputRecords     int `json:"output_records"`
+		Columns           []struct {
+			Column   string `json:"column"`
+			NonEmpty int    `json:"non_empty"`
+			Distinct int    `json:"distinct"`
+		} `json:"columns"`
+		Stages []struct {
+			Name     string `json:"name"`
+			Records  int    `json:"records"`
+			Duration int64  `json:"duration_ns"`
+		} `json:"stages"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v\n%s", err, data)
+	}
+
+	if report.TotalInputRecords != 4 || report.DuplicatesRemoved != 1 || report.OutputRecords != 3 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+	if len(report.Columns) != 3 {
+		t.Fatalf("Expected 3 columns, got %d", len(report.Columns))
+	}
+	if report.Columns[2].Column != "Extra" || report.Columns[2].NonEmpty != 1 {
+		t.Errorf("Unexpected Extra stats: %+v", report.Columns[2])
+	}
+
+	var stages []string
+	for _, stage := range report.Stages {
+		stages = append(stages, stage.Name)
+		if stage.Duration < 0 {
+			t.Errorf("Stage %s has a negative duration", stage.Name)
+		}
+	}
+	if got := strings.Join(stages, ","); got != "parsing,merging,normalizing,deduplication,writing" {
+		t.Errorf("Unexpected stages: %s", got)
+	}
+
+	t.Run("kept header is not a record", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "-k", "--report", reportFile, "-o", filepath.Join(tmpDir, "output.csv"), inputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		data, err := os.ReadFile(reportFile)
+		if err != nil {
+			t.Fatalf("Failed to read report: %v", err)
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("Report is not valid JSON: %v\n%s", err, data)
+		}
+		if report.TotalInputRecords != 4 || report.DuplicatesRemoved != 0 || report.OutputRecords != 4 {
+			t.Errorf("Unexpected counts with --keep-header: %+v", report)
+		}
+	})
+}
+
+// TestReportIssues tests that warnings are recorded in the report as issues
+// with severity, code, location and suggestion
+func TestReportIssues(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := filepath.Join(tmpDir, "a.csv")
+	second := filepath.Join(tmpDir, "b.csv")
+	files := map[string]string{
+		first:  "Front,Back\nchat,cat\nessay," + strings.Repeat("word ", 10) + "\n",
+		second: "Front,back \nchien,dog\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	reportFile := filepath.Join(tmpDir, "report.json")
+	cmd := exec.Command("ankiprep", first, second, "--max-field-bytes", "9", "--report", reportFile, "-o", filepath.Join(tmpDir, "out.csv"))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Issues (1):\n  warning[field-truncated] "+first+":3: column Back is 50 bytes, truncated to 9") {
+		t.Errorf("Expected the row issue in the summary, got: %s", output)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report struct {
+		Issues []map[string]interface{} `json:"issues"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v\n%s", err, data)
+	}
+	if len(report.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", report.Issues)
+	}
+
+	similar, truncated := report.Issues[0], report.Issues[1]
+	if similar["severity"] != "warning" || similar["code"] != "similar-headers" || similar["suggestion"] != "use --merge-similar-headers to merge them" {
+		t.Errorf("Unexpected similar headers issue: %v", similar)
+	}
+	if _, ok := similar["file"]; ok {
+		t.Errorf("Expected no location for a column issue, got %v", similar)
+	}
+	if truncated["code"] != "field-truncated" || truncated["file"] != first || truncated["line"] != float64(3) {
+		t.Errorf("Unexpected truncation issue: %v", truncated)
+	}
+	if truncated["message"] != "column Back is 50 bytes, truncated to 9" {
+		t.Errorf("Unexpected message: %v", truncated["message"])
+	}
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRequireFlag tests failing fast when input files lack required columns
+func TestRequireFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	complete := filepath.Join(tmpDir, "complete.csv")
+	if err := os.WriteFile(complete, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	broken := filepath.Join(tmpDir, "broken.csv")
+	if err := os.WriteFile(broken, []byte("Front,Notes\nchien,x\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	t.Run("all columns present", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--require", "Front,Back", "-o", filepath.Join(tmpDir, "ok.csv"), complete)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+	})
+
+	t.Run("missing column fails with validation exit code", func(t *testing.T) {
+		outputFile := filepath.Join(tmpDir, "fail.csv")
+		cmd := exec.Command("ankiprep", "--require", "Front,Back", "-o", outputFile, complete, broken)
+		output, err := cmd.CombinedOutput()
+
+		var exitError *exec.ExitError
+		if !errors.As(err, &exitError) || exitError.ExitCode() != 3 {
+			t.Fatalf("Expected exit code 3, got %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), broken+" is missing required column(s): Back") {
+			t.Errorf("Expected file and missing column in error, got: %s", output)
+		}
+		if strings.Contains(string(output), complete+" is missing") {
+			t.Errorf("Complete file should not be reported, got: %s", output)
+		}
+		if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+			t.Errorf("Expected no output file on validation error")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDeckSchema tests that --schema selects, checks and formats columns
+func TestDeckSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	schemaFile := filepath.Join(tmpDir, "deck.yaml")
+	files := map[string]string{
+		inputFile: "Back,Front,Rank,Source\nWhat?,Quoi?,12,book\n\"Why?\",,many,web\n",
+		schemaFile: `columns:
+  - name: Front
+    required: true
+    typography: french
+  - name: Back
+  - name: Rank
+    type: number
+  - name: Audio
+    optional: true
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	cmd := exec.Command("ankiprep", inputFile, "--schema", schemaFile, "-o", outputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	for _, want := range []string{
+		inputFile + `:3: required column "Front" is empty`,
+		inputFile + `:3: column "Rank" should be a number, got "many"`,
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected warning %q, got: %s", want, output)
+		}
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back,Rank\n" +
+		"Quoi\u202f?,What?,12\n" +
+		",Why?,many\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("missing column", func(t *testing.T) {
+		otherFile := filepath.Join(tmpDir, "other.csv")
+		if err := os.WriteFile(otherFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+		cmd := exec.Command("ankiprep", otherFile, "--schema", schemaFile, "-o", outputFile)
+		output, err := cmd.CombinedOutput()
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || exitErr.ExitCode() != 3 {
+			t.Errorf("Expected exit code 3, got %v, output: %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestShowInvisibles tests marking invisible characters in preview and inspect
+func TestShowInvisibles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\u00A0\n\"prix\u00A0: 5\",\"mot\u200Bcoupé\"\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	t.Run("preview", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "preview", "--show-invisibles", inputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		for _, expected := range []string{"Back[NBSP]", "prix[NBSP]: 5", "mot[ZWSP]coupé"} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected %q in output, got:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "inspect", "--values", "--show-invisibles", inputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		for _, expected := range []string{"Columns: Front, Back[NBSP]", `"prix[NBSP]: 5"`, `"mot[ZWSP]coupé"`} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected %q in output, got:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("preview without flag marks only NNBSP", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "preview", inputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if strings.Contains(string(output), "[NBSP]") {
+			t.Errorf("Expected no NBSP marker without --show-invisibles, got:\n%s", output)
+		}
+	})
+}
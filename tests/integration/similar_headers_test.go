@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSimilarHeaders tests the warning about and merging of column names
+// that differ only by case or spacing
+func TestSimilarHeaders(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := filepath.Join(tmpDir, "a.csv")
+	second := filepath.Join(tmpDir, "b.csv")
+	files := map[string]string{
+		first:  "Front,Back\nchat,cat\n",
+		second: "Front,back \nchien,dog\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+
+	t.Run("warns", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", first, second, "-o", outputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if !strings.Contains(string(output), `Warning: columns "Back", "back " differ only by case or spacing`) {
+			t.Errorf("Expected similar columns warning, got: %s", output)
+		}
+
+		result, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		if !strings.Contains(string(result), "#columns:Front,Back,back \n") {
+			t.Errorf("Expected separate columns without merging, got:\n%s", result)
+		}
+	})
+
+	t.Run("merges", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", first, second, "--merge-similar-headers", "-o", outputFile)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if strings.Contains(string(output), "Warning") {
+			t.Errorf("Expected no warning, got: %s", output)
+		}
+
+		result, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		expected := "#separator:comma\n#html:true\n#columns:Front,Back\nchat,cat\nchien,dog\n"
+		if string(result) != expected {
+			t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSimulateImport tests that simulate reports the notes Anki would import
+// from ankiprep output and the rows it would skip
+func TestSimulateImport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back\nchat,le chat\n,sans recto\nchien,le chien\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "output.csv")
+	if output, err := exec.Command("ankiprep", "-o", outputFile, inputFile).CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	output, err := exec.Command("ankiprep", "simulate", outputFile, "--fields", "Front,Back,Example").CombinedOutput()
+	if err != nil {
+		t.Fatalf("simulate failed: %v, output: %s", err, output)
+	}
+	for _, want := range []string{
+		"Separator: comma\n",
+		"Fields (3): Front (column 1), Back (column 2), Example (empty)\n",
+		"Rows: 3\n",
+		"Notes: 2\n",
+		"warning[import-skipped] " + outputFile + ":5: first field is empty",
+		"field(s) Example have no column and stay empty",
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		output, err := exec.Command("ankiprep", "simulate", filepath.Join(tmpDir, "missing.csv")).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "missing.csv") {
+			t.Errorf("Expected an error naming the file, got %v: %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSpellCheckWarnings tests that --spell-dict flags typos without changing content
+func TestSpellCheckWarnings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Back
+le chat,cat
+le chein,dog
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	dictFile := filepath.Join(tmpDir, "fr.dic")
+	if err := os.WriteFile(dictFile, []byte("2\nle\nchat\nchien\n"), 0644); err != nil {
+		t.Fatalf("Failed to create dictionary: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "output.csv")
+	cmd := exec.Command("ankiprep", "--spell-dict", dictFile, "--spell-columns", "Front", "-o", outputFile, inputFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	want := inputFile + `:3: possible typo "chein" in column Front`
+	if !strings.Contains(string(output), want) {
+		t.Errorf("Expected warning %q, got:\n%s", want, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if !strings.Contains(string(result), "le chein,dog") {
+		t.Errorf("Spell-check must not modify content, got:\n%s", result)
+	}
+
+	t.Run("unknown column fails", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--spell-dict", dictFile, "--spell-columns", "Example", "-o", outputFile, inputFile)
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected failure for unknown column, output: %s", output)
+		}
+	})
+}
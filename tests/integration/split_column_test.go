@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSplitColumn tests that --split-column spreads a packed cell over
+// several columns and handles extra values as --split-overflow says
+func TestSplitColumn(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "vocab.csv")
+	input := "Word,Examples,Tags\nchat,\"Le chat dort ; Un chat noir\",animals\nloup,a;b;c;d,animals\n"
+	if err := os.WriteFile(inputFile, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	tests := []struct {
+		overflow string
+		exitCode int
+		want     []string
+	}{
+		{"join", 0, []string{"#columns:Word,Example1,Example2,Example3,Tags", "chat,Le chat dort,Un chat noir,,animals", "loup,a,b,c;d,animals"}},
+		{"drop", 0, []string{"loup,a,b,c,animals", `vocab.csv:3: column "Examples" has 4 values for 3 columns; dropped "d"`}},
+		{"error", 1, []string{`vocab.csv:3: column "Examples" has 4 values for 3 columns`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.overflow, func(t *testing.T) {
+			cmd := exec.Command("ankiprep", inputFile, "-o", "-", "--split-column", "Examples|;|Example1,Example2,Example3", "--split-overflow", tt.overflow)
+			output, err := cmd.CombinedOutput()
+
+			exitCode := 0
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("Command failed to run: %v", err)
+			}
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d, output: %s", tt.exitCode, exitCode, output)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(output), want) {
+					t.Errorf("Expected output to contain %q, got: %s", want, output)
+				}
+			}
+		})
+	}
+
+	t.Run("existing target", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", inputFile, "-o", "-", "--split-column", "Examples|;|Tags,Example2")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "the column already exists") {
+			t.Errorf("Expected a clash with the Tags column, got error %v, output: %s", err, output)
+		}
+	})
+}
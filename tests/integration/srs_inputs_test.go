@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestQuizletAndMemriseInputs tests merging Quizlet and Memrise exports with a CSV
+func TestQuizletAndMemriseInputs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	csvFile := filepath.Join(tmpDir, "vocab.csv")
+	quizletFile := filepath.Join(tmpDir, "quizlet.txt")
+	memriseFile := filepath.Join(tmpDir, "memrise.csv")
+	files := map[string]string{
+		csvFile:     "Front,Back\nchat,cat\n",
+		quizletFile: "chien - dog;oiseau - bird",
+		memriseFile: "French,English,Level\nchat,cat,1\npoisson,fish,2\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(tmpDir, "out.csv")
+	cmd := exec.Command("ankiprep", csvFile, "-s", "-o", outputFile,
+		"--quizlet", quizletFile, "--quizlet-term-sep", " - ", "--quizlet-row-sep", "semicolon",
+		"--memrise", memriseFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	result, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back,Level\n" +
+		"chat,cat,\n" +
+		"chien,dog,\n" +
+		"oiseau,bird,\n" +
+		"chat,cat,1\n" +
+		"poisson,fish,2\n"
+	if string(result) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, string(result))
+	}
+
+	t.Run("quizlet only", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--quizlet", quizletFile, "--quizlet-term-sep", " - ", "--quizlet-row-sep", ";", "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+	})
+
+	t.Run("no inputs", func(t *testing.T) {
+		if output, err := exec.Command("ankiprep").CombinedOutput(); err == nil {
+			t.Errorf("Expected command to fail, output: %s", output)
+		}
+	})
+}
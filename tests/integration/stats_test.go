@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecordStats tests the opt-in local run statistics
+func TestRecordStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	statsFile := filepath.Join(tmpDir, "stats.jsonl")
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\nchat,cat\nchien,dog\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	// Without --record-stats nothing is written
+	if output, err := exec.Command("ankiprep", "--stats-file", statsFile, inputFile).CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if _, err := os.Stat(statsFile); !os.IsNotExist(err) {
+		t.Fatalf("Expected no stats file without --record-stats")
+	}
+
+	if output, err := exec.Command("ankiprep", "-s", "--record-stats", "--stats-file", statsFile, inputFile).CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	output, err := exec.Command("ankiprep", "stats", "--stats-file", statsFile).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	outputStr := string(output)
+	if !strings.Contains(outputStr, "1 run(s) recorded") {
+		t.Errorf("Expected one recorded run, got:\n%s", outputStr)
+	}
+	if !strings.Contains(outputStr, "33.3%") {
+		t.Errorf("Expected dedupe rate of the run, got:\n%s", outputStr)
+	}
+}
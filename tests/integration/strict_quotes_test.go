@@ -0,0 +1,42 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStrictQuotesFlag tests that --strict-quotes reports malformed quoting
+func TestStrictQuotesFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := `Front,Back
+chat,cat
+say "hi,dog
+`
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	t.Run("lenient by default", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "-o", filepath.Join(tmpDir, "lenient.csv"), inputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Expected lenient parsing to succeed: %v, output: %s", err, output)
+		}
+	})
+
+	t.Run("strict reports line and column", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--strict-quotes", "-o", filepath.Join(tmpDir, "strict.csv"), inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected strict parsing to fail, output: %s", output)
+		}
+		want := "Error: " + inputFile + ":3:5: bare quote in non-quoted field\n3 | say \"hi,dog\n  |     ^\n"
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected %q, got: %s", want, output)
+		}
+	})
+}
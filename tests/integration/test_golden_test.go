@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTestGolden tests that test-golden records, passes and diffs fixtures
+func TestTestGolden(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"vocab.csv":      "Front,Back\nchat,\"the \"\"cat\"\"\"\n",
+		"vocab.csv.args": "--smart-quotes --columns Back,Front\n",
+		"vocab.tsv":      "Front\tBack\nchien\tdog\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+
+	run := func(args ...string) (string, int) {
+		cmd := exec.Command("ankiprep", append([]string{"test-golden", tmpDir}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return string(output), exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatalf("Command failed to run: %v", err)
+		}
+		return string(output), 0
+	}
+
+	t.Run("missing expected output", func(t *testing.T) {
+		output, code := run()
+		if code != 1 || !strings.Contains(output, "create it with --update") {
+			t.Errorf("Expected a failure asking for --update, got exit code %d, output: %s", code, output)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		if output, code := run("--update"); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d, output: %s", code, output)
+		}
+		golden, err := os.ReadFile(filepath.Join(tmpDir, "vocab.csv.golden"))
+		if err != nil {
+			t.Fatalf("Expected vocab.csv.golden to be written: %v", err)
+		}
+		if !strings.Contains(string(golden), "#columns:Back,Front\nthe “cat”,chat\n") {
+			t.Errorf("Expected the .args flags to apply, got %q", golden)
+		}
+		golden, err = os.ReadFile(filepath.Join(tmpDir, "vocab.tsv.golden"))
+		if err != nil {
+			t.Fatalf("Expected vocab.tsv.golden to be written: %v", err)
+		}
+		if !strings.Contains(string(golden), "chien,dog\n") {
+			t.Errorf("Expected the output of vocab.tsv alone, got %q", golden)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		output, code := run()
+		if code != 0 || !strings.Contains(output, "2 of 2 fixture(s) passed") {
+			t.Errorf("Expected every fixture to pass, got exit code %d, output: %s", code, output)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		output, code := run("--", "--french")
+		if code != 0 {
+			t.Fatalf("Expected --french to leave these fixtures unchanged, got exit code %d, output: %s", code, output)
+		}
+
+		output, code = run("--", "--columns", "Back")
+		if code != 1 {
+			t.Errorf("Expected exit code 1, got %d, output: %s", code, output)
+		}
+		for _, want := range []string{"FAIL " + filepath.Join(tmpDir, "vocab.tsv"), "@@ ", "-#columns:Front,Back", "+#columns:Back", "0 of 2 fixture(s) passed"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected output to contain %q, got: %s", want, output)
+			}
+		}
+	})
+}
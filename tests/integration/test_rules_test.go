@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTestRules tests that test-rules runs YAML cases and reports failures
+func TestTestRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	rulesPath := filepath.Join(tmpDir, "rules_test.yaml")
+	rules := `- name: space before a colon
+  flags: [french]
+  input: "Bonjour : monde"
+  expected: "Bonjour\u202F: monde"
+
+- name: question mark without a space
+  flags: [french]
+  input: "a ? b"
+  expected: "a ? b"
+`
+	if err := os.WriteFile(rulesPath, []byte(rules), 0644); err != nil {
+		t.Fatalf("Failed to create test rules file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", "test-rules", rulesPath)
+	output, err := cmd.CombinedOutput()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("Expected exit code 1, got error %v, output: %s", err, output)
+	}
+
+	for _, want := range []string{
+		"ok   space before a colon",
+		"FAIL question mark without a space (" + rulesPath + ":6)",
+		`got:      "a[NNBSP]? b"`,
+		"1 of 2 case(s) passed",
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestOtelEndpoint tests that --otel-endpoint exports a run span with a child
+// span per stage and per input file
+func TestOtelEndpoint(t *testing.T) {
+	type span struct {
+		SpanID       string `json:"spanId"`
+		ParentSpanID string `json:"parentSpanId"`
+		Name         string `json:"name"`
+	}
+	var spans []span
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []span `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode OTLP body: %v", err)
+		}
+		for _, resource := range body.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				spans = append(spans, scope.Spans...)
+			}
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "a.csv")
+	second := filepath.Join(tmpDir, "b.csv")
+	if err := os.WriteFile(first, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("Front,Back\nchat,cat\nchien,dog\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	outputFile := filepath.Join(tmpDir, "out.csv")
+
+	cmd := exec.Command("ankiprep", first, second, "-o", outputFile, "-s", "--otel-endpoint", server.URL)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	var root span
+	names := map[string]int{}
+	for _, s := range spans {
+		if s.ParentSpanID == "" {
+			root = s
+		}
+		names[s.Name]++
+	}
+	if root.Name != "ankiprep" {
+		t.Fatalf("Expected an ankiprep root span, got %+v", spans)
+	}
+	for _, s := range spans {
+		if s.ParentSpanID != "" && s.ParentSpanID != root.SpanID {
+			t.Errorf("Expected %q to be a child of the run span", s.Name)
+		}
+	}
+	if names["parse file"] != 2 {
+		t.Errorf("Expected a parse span per input file, got %v", names)
+	}
+	for _, stage := range []string{"merging", "normalizing", "deduplication", "writing"} {
+		if names[stage] != 1 {
+			t.Errorf("Expected one %s span, got %v", stage, names)
+		}
+	}
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", first, "-o", outputFile, "--otel-endpoint", "localhost:4318")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail, output: %s", output)
+		}
+	})
+
+	t.Run("untraced runs export nothing", func(t *testing.T) {
+		spans = nil
+		cmd := exec.Command("ankiprep", first, "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Command failed: %v, output: %s", err, output)
+		}
+		if len(spans) != 0 {
+			t.Errorf("Expected no spans, got %d", len(spans))
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestUniqueColumns tests that --unique fails with the location of every
+// row sharing a value, and that rows removed as duplicates do not count
+func TestUniqueColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"a.csv": "Front,Back\nchat,cat\nchien,dog\n",
+		"b.csv": "Front,Back\nchat,tomcat\nchien,dog\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+	}
+	fileA, fileB := filepath.Join(tmpDir, "a.csv"), filepath.Join(tmpDir, "b.csv")
+	outputFile := filepath.Join(tmpDir, "output.csv")
+
+	cmd := exec.Command("ankiprep", fileA, fileB, "--unique", "Front", "-s", "-o", outputFile)
+	output, err := cmd.CombinedOutput()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 3 {
+		t.Fatalf("Expected exit code 3, got %v, output: %s", err, output)
+	}
+	want := `Front "chat": ` + fileA + ":2, " + fileB + ":2"
+	if !strings.Contains(string(output), want) {
+		t.Errorf("Expected %q in output, got: %s", want, output)
+	}
+	if strings.Contains(string(output), `"chien"`) {
+		t.Errorf("Expected the removed duplicate not to be reported, got: %s", output)
+	}
+	if _, err := os.Stat(outputFile); err == nil {
+		t.Error("Expected no output file")
+	}
+
+	t.Run("unique", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", fileA, "--unique", "Front,Back", "-o", outputFile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("Command failed: %v, output: %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestVerifyOutput tests that --verify reads the written import file back
+// and that it is rejected where no import file is written
+func TestVerifyOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	csvContent := "Front,Back,Tags\nchat,\"le \"\"chat\"\",\nnoir\",animal\nchien,le chien,\n"
+	if err := os.WriteFile(inputFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"-o", filepath.Join(tmpDir, "output.csv")},
+		{"--output-separator", "tab", "-o", filepath.Join(tmpDir, "output.tsv")},
+		{"--compress", "-o", filepath.Join(tmpDir, "output.csv.gz")},
+	} {
+		cmd := exec.Command("ankiprep", append([]string{"--verify", "-v", inputFile}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command %v failed: %v, output: %s", args, err, output)
+		}
+		if !strings.Contains(string(output), "2 record(s) of 3 column(s) read back as written") {
+			t.Errorf("Expected a verification message for %v, got: %s", args, output)
+		}
+	}
+
+	t.Run("stdout", func(t *testing.T) {
+		cmd := exec.Command("ankiprep", "--verify", "-o", "-", inputFile)
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--verify reads back an Anki import file and cannot be combined with -o -") {
+			t.Errorf("Expected --verify to be rejected, got %v: %s", err, output)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestVersionCommand tests the machine-readable build information
+func TestVersionCommand(t *testing.T) {
+	output, err := exec.Command("ankiprep", "version", "--json").Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	var info struct {
+		Version   string   `json:"version"`
+		Commit    string   `json:"commit"`
+		BuildDate string   `json:"build_date"`
+		GoVersion string   `json:"go_version"`
+		Features  []string `json:"features"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, output)
+	}
+
+	if info.Version == "" {
+		t.Error("Expected a version")
+	}
+	if !strings.HasPrefix(info.GoVersion, "go") {
+		t.Errorf("Expected Go version, got %q", info.GoVersion)
+	}
+	if len(info.Features) == 0 {
+		t.Error("Expected enabled features")
+	}
+
+	// --version reports the same version
+	plain, err := exec.Command("ankiprep", "--version").Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if !strings.Contains(string(plain), info.Version) {
+		t.Errorf("Expected --version to report %s, got: %s", info.Version, plain)
+	}
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWizard tests that the wizard writes a schema from the answers and
+// converts the file with it
+func TestWizard(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "vocab.csv")
+	input := "Word,Meaning,Labels\nchat,\"the \"\"cat\"\"\",animals\nchien,dog,animals\n"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	// Front and Back keep their defaults, Labels becomes the tags, there is
+	// no deck column, and Back gets smart quotes after one invalid answer
+	answers := "\n\nLabels\n\n\nitalian\nsmart-quotes\n\n\n\n"
+	cmd := exec.Command("ankiprep", "wizard", inputPath)
+	cmd.Stdin = strings.NewReader(answers)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	for _, want := range []string{`1. Word  e.g. "chat"`, `"italian" is not one of`, "--schema " + filepath.Join(tmpDir, "vocab.schema.yaml")} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+
+	schema, err := os.ReadFile(filepath.Join(tmpDir, "vocab.schema.yaml"))
+	if err != nil {
+		t.Fatalf("Expected the schema to be written: %v", err)
+	}
+	for _, want := range []string{"name: Front\n      source: Word", "source: Meaning\n      typography: smart-quotes", "name: Tags\n      source: Labels"} {
+		if !strings.Contains(string(schema), want) {
+			t.Errorf("Expected schema to contain %q, got:\n%s", want, schema)
+		}
+	}
+
+	converted, err := os.ReadFile(filepath.Join(tmpDir, "vocab_processed.csv"))
+	if err != nil {
+		t.Fatalf("Expected the file to be converted: %v", err)
+	}
+	for _, want := range []string{"#columns:Front,Back,Tags", "chat,the “cat”,animals"} {
+		if !strings.Contains(string(converted), want) {
+			t.Errorf("Expected output file to contain %q, got:\n%s", want, converted)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeZip creates a .zip archive holding the given files in order
+func writeZip(t *testing.T, path string, files [][2]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test archive: %v", err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for _, f := range files {
+		entry, err := writer.Create(f[0])
+		if err != nil {
+			t.Fatalf("Failed to add %s to test archive: %v", f[0], err)
+		}
+		entry.Write([]byte(f[1]))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to write test archive: %v", err)
+	}
+}
+
+// TestZipInput tests that the CSV files of a .zip are merged like separate inputs
+func TestZipInput(t *testing.T) {
+	tmpDir := t.TempDir()
+	archive := filepath.Join(tmpDir, "course.zip")
+	writeZip(t, archive, [][2]string{
+		{"chapter2.csv", "Front,Back\nchien,dog\n"},
+		{"chapter1.csv", "Front,Back,Notes\nchat,cat,pet\n"},
+		{"syllabus.pdf", "%PDF"},
+	})
+	reportFile := filepath.Join(tmpDir, "report.json")
+
+	cmd := exec.Command("ankiprep", archive, "--deterministic", "--report", reportFile)
+	cmd.Dir = tmpDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "merged_output.csv"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	// --deterministic merges the archive's files by name
+	for _, want := range []string{"#columns:Front,Back,Notes", "chat,cat,pet\nchien,dog,\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, content)
+		}
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report struct {
+		InputFiles []string `json:"input_files"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	want := []string{archive + ":chapter1.csv", archive + ":chapter2.csv"}
+	if strings.Join(report.InputFiles, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected input files %q, got %q", want, report.InputFiles)
+	}
+}
+
+// TestZipInputErrors tests archives without CSV files under --on-error
+func TestZipInputErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	archive := filepath.Join(tmpDir, "slides.zip")
+	writeZip(t, archive, [][2]string{{"slides.pdf", "%PDF"}})
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	cmd := exec.Command("ankiprep", archive, inputFile, "-o", "-")
+	output, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "no .csv or .tsv files in the archive") {
+		t.Errorf("Expected the run to fail on an archive without CSV files, got %v: %s", err, output)
+	}
+
+	cmd = exec.Command("ankiprep", archive, inputFile, "-o", "-", "--on-error", "skip")
+	output, err = cmd.Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v, output: %s", err, output)
+	}
+	if !strings.Contains(string(output), "chat,cat") {
+		t.Errorf("Expected the other input to be processed, got: %s", output)
+	}
+}
@@ -1,13 +1,14 @@
 package performance
 
 import (
-	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"ankiprep/internal/fixtures"
 )
 
 // TestCLIPerformance tests ankiprep CLI performance with various file sizes
@@ -49,7 +50,13 @@ func TestCLIPerformance(t *testing.T) {
 			inputFile := filepath.Join(tmpDir, "input.csv")
 			outputFile := filepath.Join(tmpDir, "output.csv")
 
-			if err := generateTestCSV(inputFile, tt.numRows, tt.numColumns); err != nil {
+			opts := fixtures.Options{
+				Rows:      tt.numRows,
+				Columns:   tt.numColumns,
+				Separator: ',',
+				Kinds:     []string{fixtures.KindBasic},
+			}
+			if err := fixtures.WriteFile(inputFile, opts); err != nil {
 				t.Fatalf("Failed to generate test CSV: %v", err)
 			}
 
@@ -107,34 +114,3 @@ func TestCLIPerformance(t *testing.T) {
 		})
 	}
 }
-
-// generateTestCSV creates a CSV file with the specified number of rows and columns
-func generateTestCSV(filename string, numRows, numColumns int) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Write header
-	var headers []string
-	for i := 0; i < numColumns; i++ {
-		headers = append(headers, fmt.Sprintf("col%d", i+1))
-	}
-	if _, err := file.WriteString(strings.Join(headers, ",") + "\n"); err != nil {
-		return err
-	}
-
-	// Write data rows
-	for row := 0; row < numRows; row++ {
-		var values []string
-		for col := 0; col < numColumns; col++ {
-			values = append(values, fmt.Sprintf("value_%d_%d", row, col))
-		}
-		if _, err := file.WriteString(strings.Join(values, ",") + "\n"); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
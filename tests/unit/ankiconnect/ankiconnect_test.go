@@ -0,0 +1,131 @@
+package ankiconnect_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/ankiconnect"
+	"ankiprep/internal/models"
+)
+
+func TestNewNote(t *testing.T) {
+	entry := models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat", "tags": "a  b"}, "input.csv", 2)
+	note := ankiconnect.NewNote(entry, []string{"Front", "Back", "tags"}, "Deck", "Basic")
+
+	if note.DeckName != "Deck" || note.ModelName != "Basic" {
+		t.Errorf("Unexpected deck or model: %+v", note)
+	}
+	if len(note.Fields) != 2 || note.Fields["Front"] != "chat" || note.Fields["Back"] != "cat" {
+		t.Errorf("Unexpected fields: %v", note.Fields)
+	}
+	if len(note.Tags) != 2 || note.Tags[0] != "a" || note.Tags[1] != "b" {
+		t.Errorf("Expected tags [a b], got %v", note.Tags)
+	}
+}
+
+func TestNewNote_DeckColumn(t *testing.T) {
+	headers := []string{"Front", models.DeckColumn}
+	routed := models.NewDataEntry(map[string]string{"Front": "chat", models.DeckColumn: "French::1"}, "input.csv", 2)
+	unrouted := models.NewDataEntry(map[string]string{"Front": "chien", models.DeckColumn: " "}, "input.csv", 3)
+
+	if note := ankiconnect.NewNote(routed, headers, "Default", "Basic"); note.DeckName != "French::1" || len(note.Fields) != 1 {
+		t.Errorf("Expected the note in deck French::1 with one field, got %+v", note)
+	}
+	if note := ankiconnect.NewNote(unrouted, headers, "Default", "Basic"); note.DeckName != "Default" {
+		t.Errorf("Expected an empty Deck value to keep the default deck, got %q", note.DeckName)
+	}
+}
+
+func TestSink_Write(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, `{"result": [101, 102], "error": null}`)
+	}))
+	defer server.Close()
+
+	sink := &ankiconnect.Sink{Client: ankiconnect.NewClient(server.URL), Deck: "Default", Model: "Basic"}
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "Front"}, "input.csv", 0),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "input.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien"}, "input.csv", 3),
+	}
+	if err := sink.Write(context.Background(), entries, []string{"Front"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if sink.Added != 2 || requests != 1 {
+		t.Errorf("Expected 2 notes added in 1 request, got %d in %d", sink.Added, requests)
+	}
+}
+
+func TestClient_AddNotesError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"result": null, "error": "model was not found: Basic"}`)
+	}))
+	defer server.Close()
+
+	_, err := ankiconnect.NewClient(server.URL).AddNotes(context.Background(), []*ankiconnect.Note{{DeckName: "Default", ModelName: "Basic"}})
+	if err == nil || !strings.Contains(err.Error(), "model was not found") {
+		t.Errorf("Expected AnkiConnect error, got %v", err)
+	}
+}
+
+func TestClient_ModelFieldNames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"modelFieldNames"`) || !strings.Contains(string(body), `"modelName":"Cloze"`) {
+			t.Errorf("Unexpected request %s", body)
+		}
+		io.WriteString(w, `{"result": ["Text", "Back Extra"], "error": null}`)
+	}))
+	defer server.Close()
+
+	fields, err := ankiconnect.NewClient(server.URL).ModelFieldNames(context.Background(), "Cloze")
+	if err != nil {
+		t.Fatalf("ModelFieldNames failed: %v", err)
+	}
+	if len(fields) != 2 || fields[0] != "Text" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+}
+
+func TestClient_RetriesUnavailable(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"result": ["Front", "Back"], "error": null}`)
+	}))
+	defer server.Close()
+
+	client := ankiconnect.NewClient(server.URL)
+	client.Retry = models.NewRetryPolicy(3, 0)
+	fields, err := client.ModelFieldNames(context.Background(), "Basic")
+	if err != nil || len(fields) != 2 || requests != 2 {
+		t.Errorf("Expected fields after a retry, got %v, %v after %d request(s)", fields, err, requests)
+	}
+}
+
+func TestClient_Cancelled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := ankiconnect.NewClient(server.URL)
+	client.Retry = models.NewRetryPolicy(3, 0)
+	if _, err := client.ModelFieldNames(ctx, "Basic"); !errors.Is(err, context.Canceled) || requests != 0 {
+		t.Errorf("Expected context.Canceled without a request, got %v after %d request(s)", err, requests)
+	}
+}
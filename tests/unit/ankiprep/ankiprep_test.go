@@ -0,0 +1,229 @@
+package ankiprep_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ankiprep/pkg/ankiprep"
+)
+
+func writeInput(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create input file: %v", err)
+	}
+	return path
+}
+
+func TestNewOptions(t *testing.T) {
+	var buf bytes.Buffer
+	options := ankiprep.NewOptions(
+		ankiprep.WithFrenchTypography(),
+		ankiprep.WithDedupeKey("Front"),
+		ankiprep.WithTemplate("Back", "<b>{value}</b>"),
+		ankiprep.WithWriter(&buf),
+	)
+
+	if !options.FrenchTypography {
+		t.Error("Expected FrenchTypography to be set")
+	}
+	if !options.SkipDuplicates || options.DedupeStrategy != ankiprep.DedupeKeyColumns {
+		t.Errorf("Expected key-columns dedupe, got %v %q", options.SkipDuplicates, options.DedupeStrategy)
+	}
+	if len(options.DedupeKey) != 1 || options.DedupeKey[0] != "Front" {
+		t.Errorf("Expected dedupe key [Front], got %v", options.DedupeKey)
+	}
+	if options.Templates["Back"] != "<b>{value}</b>" {
+		t.Errorf("Expected Back template, got %q", options.Templates["Back"])
+	}
+	if options.Writer != &buf {
+		t.Error("Expected writer to be set")
+	}
+	if err := options.Validate(); err != nil {
+		t.Errorf("Expected valid options, got %v", err)
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ankiprep.Option
+	}{
+		{"unknown strategy", []ankiprep.Option{ankiprep.WithDedupe("phonetic")}},
+		{"key columns without key", []ankiprep.Option{ankiprep.WithDedupeKey()}},
+		{"keep and no header", []ankiprep.Option{ankiprep.WithKeepHeader(), ankiprep.WithNoHeader()}},
+		{"template without placeholder", []ankiprep.Option{ankiprep.WithTemplate("Back", "<b></b>")}},
+		{"merge fields without column", []ankiprep.Option{ankiprep.WithDedupe(ankiprep.DedupeMergeFields)}},
+		{"merge field without delimiter", []ankiprep.Option{ankiprep.WithMergeField("Examples", "")}},
+	}
+
+	for _, tt := range tests {
+		if err := ankiprep.NewOptions(tt.opts...).Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	input := writeInput(t, "Front,Back\nbonjour,hello\nbonjour,hi\nmerci,thanks\n")
+
+	var buf bytes.Buffer
+	result, err := ankiprep.Process([]string{input},
+		ankiprep.WithDedupeKey("Front"),
+		ankiprep.WithColumns("Back", "Front"),
+		ankiprep.WithTemplate("Back", "<b>{value}</b>"),
+		ankiprep.WithWriter(&buf))
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if result.InputRecords != 3 || result.OutputRecords != 2 || result.DuplicatesRemoved != 1 {
+		t.Errorf("Unexpected counts: %+v", result)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "#columns:Back,Front\n") {
+		t.Errorf("Expected selected columns in output, got:\n%s", output)
+	}
+	if !strings.Contains(output, "<b>hello</b>,bonjour\n") {
+		t.Errorf("Expected templated first entry, got:\n%s", output)
+	}
+	if strings.Contains(output, "hi") {
+		t.Errorf("Expected duplicate to be removed, got:\n%s", output)
+	}
+}
+
+func TestProcess_MergeField(t *testing.T) {
+	input := writeInput(t, "Front,Examples\nchat,Le chat dort.\nchat,Le chat mange.\nchien,Le chien aboie.\n")
+
+	var buf bytes.Buffer
+	result, err := ankiprep.Process([]string{input}, ankiprep.WithMergeField("Examples", "<br>"), ankiprep.WithWriter(&buf))
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if result.DuplicatesRemoved != 1 {
+		t.Errorf("Expected 1 duplicate removed, got %+v", result)
+	}
+	if !strings.Contains(buf.String(), "chat,Le chat dort.<br>Le chat mange.\n") {
+		t.Errorf("Expected merged examples, got:\n%s", buf.String())
+	}
+}
+
+func TestProcess_TabSeparated(t *testing.T) {
+	input := writeInput(t, "Front,Back\nbonjour,hello\n")
+
+	var buf bytes.Buffer
+	if _, err := ankiprep.Process([]string{input}, ankiprep.WithTabSeparated(), ankiprep.WithWriter(&buf)); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "#separator:tab\n") || !strings.Contains(buf.String(), "bonjour\thello\n") {
+		t.Errorf("Expected tab-separated output, got:\n%s", buf.String())
+	}
+}
+
+func TestProcess_Errors(t *testing.T) {
+	input := writeInput(t, "Front,Back\nbonjour,hello\n")
+
+	if _, err := ankiprep.Process([]string{input}); err == nil {
+		t.Error("Expected an error without a writer")
+	}
+
+	var buf bytes.Buffer
+	if _, err := ankiprep.Process([]string{input}, ankiprep.WithColumns("Missing"), ankiprep.WithWriter(&buf)); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+	if _, err := ankiprep.Process([]string{filepath.Join(t.TempDir(), "missing.csv")}, ankiprep.WithWriter(&buf)); err == nil {
+		t.Error("Expected an error for a missing input file")
+	}
+}
+
+func TestForEachProcessedEntry(t *testing.T) {
+	input := writeInput(t, "Front,Back\nbonjour,hello\nbonjour,hello\nmerci,thanks\n")
+
+	var entries []*ankiprep.Entry
+	result, err := ankiprep.ForEachProcessedEntry(context.Background(), []string{input},
+		func(entry *ankiprep.Entry) error {
+			entries = append(entries, entry)
+			return nil
+		},
+		ankiprep.WithDedupe(ankiprep.DedupeExact),
+		ankiprep.WithTemplate("Back", "<b>{value}</b>"))
+	if err != nil {
+		t.Fatalf("ForEachProcessedEntry failed: %v", err)
+	}
+
+	if len(entries) != 2 || result.OutputRecords != 2 || result.DuplicatesRemoved != 1 {
+		t.Fatalf("Expected 2 entries and 1 duplicate, got %d entries, %+v", len(entries), result)
+	}
+	if entries[0].Get("Front") != "bonjour" || entries[0].Get("Back") != "<b>hello</b>" {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Source != input || entries[1].Line != 4 {
+		t.Errorf("Expected %s:4, got %s:%d", input, entries[1].Source, entries[1].Line)
+	}
+	if entries[0].Get("Missing") != "" {
+		t.Error("Expected empty value for unknown column")
+	}
+}
+
+func TestForEachProcessedEntry_StopsEarly(t *testing.T) {
+	input := writeInput(t, "Front,Back\nbonjour,hello\nmerci,thanks\n")
+	stop := errors.New("stop")
+
+	calls := 0
+	_, err := ankiprep.ForEachProcessedEntry(context.Background(), []string{input}, func(entry *ankiprep.Entry) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected the callback error after 1 call, got %v after %d", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ankiprep.ForEachProcessedEntry(ctx, []string{input}, func(entry *ankiprep.Entry) error {
+		t.Error("Callback should not run after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestProcess_NoEntries(t *testing.T) {
+	input := writeInput(t, "Front,Back\n")
+
+	var buf bytes.Buffer
+	if _, err := ankiprep.Process([]string{input}, ankiprep.WithWriter(&buf)); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if buf.String() != "#separator:comma\n#html:true\n#columns:Front,Back\n" {
+		t.Errorf("Expected metadata lines only, got:\n%s", buf.String())
+	}
+}
+
+func TestProcessCSVString(t *testing.T) {
+	output, result, err := ankiprep.ProcessCSVString("Front\tBack\nchat\tcat\nchat\tcat\n",
+		ankiprep.WithDedupe(ankiprep.DedupeExact))
+	if err != nil {
+		t.Fatalf("ProcessCSVString failed: %v", err)
+	}
+
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nchat,cat\n"
+	if output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+	if result.OutputRecords != 1 || result.DuplicatesRemoved != 1 {
+		t.Errorf("Unexpected counts: %+v", result)
+	}
+
+	if _, _, err := ankiprep.ProcessCSVString(""); err == nil {
+		t.Error("Expected an error for empty content")
+	}
+}
@@ -0,0 +1,134 @@
+package ankiprep_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"ankiprep/pkg/ankiprep"
+)
+
+// lineParser reads one record per line with fields split on the separator,
+// ignoring quotes
+type lineParser struct{}
+
+func (lineParser) Parse(r io.Reader, separator rune) ([]string, []ankiprep.Record, error) {
+	var first []string
+	var records []ankiprep.Record
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), string(separator))
+		if line == 1 {
+			first = fields
+			continue
+		}
+		records = append(records, ankiprep.Record{Line: line, Values: fields})
+	}
+	return first, records, scanner.Err()
+}
+
+// firstLetterDeduper keeps the first entry per initial of the Front column
+type firstLetterDeduper struct{}
+
+func (firstLetterDeduper) Dedupe(entries []*ankiprep.Entry) []*ankiprep.Entry {
+	seen := map[string]bool{}
+	var kept []*ankiprep.Entry
+	for _, entry := range entries {
+		initial := entry.Get("Front")[:1]
+		if !seen[initial] {
+			seen[initial] = true
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// upperTypography upper-cases every value
+type upperTypography struct{}
+
+func (upperTypography) Apply(entry *ankiprep.Entry) {
+	for i, value := range entry.Values {
+		entry.Values[i] = strings.ToUpper(value)
+	}
+}
+
+// lineFormatter writes Source:Line=values lines
+type lineFormatter struct {
+	w io.Writer
+}
+
+func (f *lineFormatter) Write(entry *ankiprep.Entry) error {
+	_, err := fmt.Fprintf(f.w, "%d=%s\n", entry.Line, strings.Join(entry.Values, "|"))
+	return err
+}
+
+func (f *lineFormatter) Flush() error { return nil }
+
+// stageRecorder remembers the items reported per stage
+type stageRecorder map[string]int
+
+func (s stageRecorder) Add(stage string, items int, elapsed time.Duration) {
+	s[stage] += items
+}
+
+func TestProcess_CustomStages(t *testing.T) {
+	input := writeInput(t, "Front,Back\nchat,\"cat, feline\"\nchien,dog\ncheval,horse\n")
+
+	var buf bytes.Buffer
+	stages := stageRecorder{}
+	result, err := ankiprep.Process([]string{input},
+		ankiprep.WithParser(lineParser{}),
+		ankiprep.WithDeduper(firstLetterDeduper{}),
+		ankiprep.WithTypography(upperTypography{}),
+		ankiprep.WithFormatter(func(w io.Writer, columns []string) ankiprep.Formatter {
+			return &lineFormatter{w: w}
+		}),
+		ankiprep.WithReporter(stages),
+		ankiprep.WithWriter(&buf))
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	// The line parser ignores quotes, so the first record has three fields
+	expected := "2=CHAT|\"CAT\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+	if result.OutputRecords != 1 || result.DuplicatesRemoved != 2 {
+		t.Errorf("Expected 1 output record and 2 duplicates, got %+v", result)
+	}
+	for stage, items := range map[string]int{"parsing": 3, "deduplication": 3, "typography": 1, "writing": 1} {
+		if stages[stage] != items {
+			t.Errorf("Expected %d item(s) in stage %s, got %v", items, stage, stages)
+		}
+	}
+}
+
+func TestProcess_DefaultStagesReport(t *testing.T) {
+	input := writeInput(t, "Front,Back\nchat,cat\nchat,cat\n")
+
+	var buf bytes.Buffer
+	stages := stageRecorder{}
+	_, err := ankiprep.Process([]string{input},
+		ankiprep.WithDedupe(ankiprep.DedupeExact),
+		ankiprep.WithReporter(stages),
+		ankiprep.WithWriter(&buf))
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nchat,cat\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+	if stages["parsing"] != 2 || stages["deduplication"] != 2 || stages["writing"] != 1 {
+		t.Errorf("Unexpected stages: %v", stages)
+	}
+	if _, ok := stages["typography"]; ok {
+		t.Error("Expected no typography stage without typography options")
+	}
+}
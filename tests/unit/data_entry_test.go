@@ -429,3 +429,23 @@ func TestDataEntry_ToCSVRecord(t *testing.T) {
 		})
 	}
 }
+
+func TestDataEntry_MergeTags(t *testing.T) {
+	entry := models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "animals French::Nouns"}, "a.csv", 2)
+	other := models.NewDataEntry(map[string]string{"Front": "Chat", "Tags": "French::Nouns  lesson3 animals"}, "b.csv", 5)
+
+	entry.MergeTags(other)
+
+	if got := entry.GetValue("Tags"); got != "animals French::Nouns lesson3" {
+		t.Errorf("Expected merged tags, got %q", got)
+	}
+	if got := entry.GetValue("Front"); got != "chat" {
+		t.Errorf("Expected other columns unchanged, got %q", got)
+	}
+
+	untagged := models.NewDataEntry(map[string]string{"Front": "chien"}, "a.csv", 3)
+	untagged.MergeTags(models.NewDataEntry(map[string]string{"Front": "chien", "tags": "animals"}, "b.csv", 6))
+	if got := untagged.GetValue("tags"); got != "animals" {
+		t.Errorf("Expected tags copied to an untagged entry, got %q", got)
+	}
+}
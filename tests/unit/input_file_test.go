@@ -285,6 +285,81 @@ func TestInputFile_HeadersManipulation(t *testing.T) {
 	}
 }
 
+func TestInputFile_LooksHeaderless(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		second  []string
+		want    bool
+	}{
+		{name: "named columns", headers: []string{"Front", "Back", "Tags"}, want: false},
+		{name: "empty name", headers: []string{"chat", "cat", ""}, want: true},
+		{name: "repeated name", headers: []string{"oui", "oui"}, want: true},
+		{name: "whitespace name", headers: []string{"Front", " "}, want: true},
+		{name: "mostly numbers", headers: []string{"12", "3.5", "Word"}, want: true},
+		{name: "mostly urls", headers: []string{"https://example.com/a", "chat"}, want: true},
+		{name: "identical to second row", headers: []string{"chat", "cat"}, second: []string{"chat", "cat"}, want: true},
+		{name: "same shape as second row", headers: []string{"chat", "42"}, second: []string{"chien", "7"}, want: true},
+		{name: "word shape only", headers: []string{"Front", "Back"}, second: []string{"chien", "dog"}, want: false},
+		{name: "header over numbers", headers: []string{"Word", "Rank"}, second: []string{"chien", "7"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputFile := models.NewInputFile("test.csv")
+			inputFile.Headers = tt.headers
+			if tt.second != nil {
+				inputFile.AddRecord(tt.second, 2)
+			}
+			if got := inputFile.LooksHeaderless(); got != tt.want {
+				t.Errorf("LooksHeaderless() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInputFile_UseGeneratedHeaders(t *testing.T) {
+	inputFile := models.NewInputFile("test.csv")
+	inputFile.Headers = []string{"chat", "cat"}
+	inputFile.AddRecord([]string{"chien", "dog"}, 2)
+
+	inputFile.UseGeneratedHeaders()
+
+	if inputFile.HasHeader {
+		t.Error("Expected HasHeader to be false")
+	}
+	if len(inputFile.Headers) != 2 || inputFile.Headers[0] != "Column1" || inputFile.Headers[1] != "Column2" {
+		t.Errorf("Headers = %v, want [Column1 Column2]", inputFile.Headers)
+	}
+	if len(inputFile.Records) != 2 || inputFile.Records[0][0] != "chat" {
+		t.Errorf("Expected first row to become a record, got %v", inputFile.Records)
+	}
+	if inputFile.LineNumber(0) != 1 || inputFile.LineNumber(1) != 2 {
+		t.Errorf("Line numbers = %d, %d, want 1, 2", inputFile.LineNumber(0), inputFile.LineNumber(1))
+	}
+
+	// Calling again must not demote another row
+	inputFile.UseGeneratedHeaders()
+	if len(inputFile.Records) != 2 {
+		t.Errorf("Expected UseGeneratedHeaders to be idempotent, got %d records", len(inputFile.Records))
+	}
+}
+
+// TestInputFile_MissingColumns tests listing required columns absent from the headers
+func TestInputFile_MissingColumns(t *testing.T) {
+	inputFile := models.NewInputFile("test.csv")
+	inputFile.Headers = []string{"Front", "Back", "Tags"}
+
+	if missing := inputFile.MissingColumns([]string{"Front", "Back"}); len(missing) != 0 {
+		t.Errorf("MissingColumns() = %v, want none", missing)
+	}
+
+	missing := inputFile.MissingColumns([]string{"Front", "Notes", "back"})
+	if len(missing) != 2 || missing[0] != "Notes" || missing[1] != "back" {
+		t.Errorf("MissingColumns() = %v, want [Notes back]", missing)
+	}
+}
+
 // Helper function to check if string contains substring
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || 
@@ -298,4 +373,4 @@ func findSubstring(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,184 @@
+package models_test
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+const ankiExport = "#separator:tab\n" +
+	"#html:true\n" +
+	"#guid column:1\n" +
+	"#notetype column:2\n" +
+	"#deck column:3\n" +
+	"#tags column:6\n" +
+	"Fx!a1\tBasic\tFrench::Vocab\tchat\t<b>cat</b>\tanimals\n" +
+	"Gq?b2\tBasic\tFrench::Vocab\tquoi ?\t\"<span class=\"\"hint\"\">what</span>\"\t\n"
+
+func TestParseAnkiExport(t *testing.T) {
+	inputFile := models.NewInputFile("Notes in Plain Text.txt")
+	header, err := models.ParseAnkiExport(strings.NewReader(ankiExport), inputFile, true)
+	if err != nil {
+		t.Fatalf("ParseAnkiExport failed: %v", err)
+	}
+
+	if !header.HTML || header.Separator != '\t' || header.Lines != 6 {
+		t.Errorf("Unexpected header %+v", header)
+	}
+	expectedHeaders := []string{"GUID", "Note Type", "Deck", "Column4", "Column5", "Tags"}
+	if !reflect.DeepEqual(inputFile.Headers, expectedHeaders) {
+		t.Errorf("Expected headers %q, got %q", expectedHeaders, inputFile.Headers)
+	}
+	if inputFile.HasHeader {
+		t.Error("Anki exports have no header row")
+	}
+	expected := [][]string{
+		{"Fx!a1", "Basic", "French::Vocab", "chat", "<b>cat</b>", "animals"},
+		{"Gq?b2", "Basic", "French::Vocab", "quoi ?", `<span class="hint">what</span>`, ""},
+	}
+	if !reflect.DeepEqual(inputFile.Records, expected) {
+		t.Errorf("Expected %q, got %q", expected, inputFile.Records)
+	}
+	if inputFile.LineNumber(0) != 7 || inputFile.LineNumber(1) != 8 {
+		t.Errorf("Expected records on lines 7 and 8, got %d and %d", inputFile.LineNumber(0), inputFile.LineNumber(1))
+	}
+}
+
+func TestParseAnkiExport_Columns(t *testing.T) {
+	inputFile := models.NewInputFile("out.csv")
+	content := "#separator:comma\n#html:true\n#columns:Front,Back,Tags\nchat,cat,animals\n"
+	if _, err := models.ParseAnkiExport(strings.NewReader(content), inputFile, true); err != nil {
+		t.Fatalf("ParseAnkiExport failed: %v", err)
+	}
+	if !reflect.DeepEqual(inputFile.Headers, []string{"Front", "Back", "Tags"}) {
+		t.Errorf("Unexpected headers %q", inputFile.Headers)
+	}
+	if inputFile.Separator != ',' {
+		t.Errorf("Expected comma separator, got %q", inputFile.Separator)
+	}
+}
+
+func TestReadAnkiHeader_Errors(t *testing.T) {
+	for _, content := range []string{"#separator:dash\n", "#guid column:zero\n"} {
+		if _, err := models.ReadAnkiHeader(bufio.NewReader(strings.NewReader(content))); err == nil {
+			t.Errorf("Expected error for %q", content)
+		}
+	}
+}
+
+func TestWriteAnki_MetadataDirectives(t *testing.T) {
+	headers := []string{"GUID", "Deck", "Front", "Back", "Tags"}
+	entry := models.NewDataEntry(map[string]string{"GUID": "Fx!a1", "Deck": "French", "Front": "chat", "Back": "cat", "Tags": "animals"}, "in.txt", 7)
+
+	var buf bytes.Buffer
+	if err := models.WriteAnki(&buf, headers, []*models.DataEntry{entry}, models.SeparatorTab); err != nil {
+		t.Fatalf("WriteAnki failed: %v", err)
+	}
+	expected := "#separator:tab\n#html:true\n#columns:GUID\tDeck\tFront\tBack\tTags\n" +
+		"#guid column:1\n#deck column:2\n#tags column:5\n" +
+		"Fx!a1\tFrench\tchat\tcat\tanimals\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+func TestApplyTypography_SkipsMetadata(t *testing.T) {
+	entry := models.NewDataEntry(map[string]string{"GUID": "Gq?b2", "Deck": "French::Vocab", "Front": "quoi?"}, "in.txt", 7)
+	models.ApplyTypography([]*models.DataEntry{entry}, true, false, false)
+
+	if entry.GetValue("GUID") != "Gq?b2" || entry.GetValue("Deck") != "French::Vocab" {
+		t.Errorf("Metadata columns changed: %q", entry.Values)
+	}
+	if entry.GetValue("Front") != "quoi\u202f?" {
+		t.Errorf("Expected typography on Front, got %q", entry.GetValue("Front"))
+	}
+}
+
+func TestSeparatorConflict(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		conflict bool
+		want     rune
+	}{
+		{"notes.csv", "#separator:tab\nchat\tcat\n", true, '\t'},
+		{"notes.tsv", "#separator:Comma\nchat,cat\n", true, ','},
+		{"notes.tsv", "#separator:tab\nchat\tcat\n", false, '\t'},
+		{"notes.txt", "#separator:semicolon\nchat;cat\n", false, ';'},
+		{"notes.csv", "#html:false\nchat,cat\n", false, ','},
+		{"notes.txt", "#html:false\nchat\tcat\n", false, '\t'},
+	}
+
+	for _, tt := range tests {
+		inputFile := models.NewInputFile(tt.path)
+		header, err := models.ParseAnkiExport(strings.NewReader(tt.content), inputFile, true)
+		if err != nil {
+			t.Fatalf("%s %q: ParseAnkiExport failed: %v", tt.path, tt.content, err)
+		}
+		if inputFile.Separator != tt.want || len(inputFile.Records[0]) != 2 {
+			t.Errorf("%s %q: expected separator %q, got %q", tt.path, tt.content, tt.want, inputFile.Separator)
+		}
+		if conflict := models.SeparatorConflict(tt.path, header); (conflict != "") != tt.conflict {
+			t.Errorf("%s %q: unexpected conflict %q", tt.path, tt.content, conflict)
+		}
+	}
+}
+
+// TestColumnsDirective_RoundTrip verifies column names survive writing and
+// reading the #columns line, which Anki parses as a quoted record
+func TestColumnsDirective_RoundTrip(t *testing.T) {
+	headers := []string{"Front", "Part, of speech", `Say "hi"`, "a\tb", " padded", ""}
+
+	for _, separator := range []string{models.SeparatorComma, models.SeparatorTab} {
+		directive, err := models.ColumnsDirective(headers, separator)
+		if err != nil {
+			t.Fatalf("ColumnsDirective failed: %v", err)
+		}
+		if strings.Contains(directive, "\n") {
+			t.Errorf("%s: directive spans lines: %q", separator, directive)
+		}
+
+		content := "#separator:" + separator + "\n" + directive + "\n"
+		header, err := models.ReadAnkiHeader(bufio.NewReader(strings.NewReader(content)))
+		if err != nil {
+			t.Fatalf("%s: ReadAnkiHeader failed: %v", separator, err)
+		}
+		if !reflect.DeepEqual(header.Columns, headers) {
+			t.Errorf("%s: expected %q, got %q from %q", separator, headers, header.Columns, directive)
+		}
+	}
+}
+
+func TestColumnsDirective_Quoting(t *testing.T) {
+	tests := []struct {
+		separator string
+		want      string
+	}{
+		{models.SeparatorComma, `#columns:Word,"Part, of speech","Say ""hi"""`},
+		{models.SeparatorTab, "#columns:Word\tPart, of speech\t\"Say \"\"hi\"\"\""},
+	}
+	for _, tt := range tests {
+		got, err := models.ColumnsDirective([]string{"Word", "Part, of speech", `Say "hi"`}, tt.separator)
+		if err != nil {
+			t.Fatalf("ColumnsDirective failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.separator, tt.want, got)
+		}
+	}
+}
+
+func TestReadAnkiHeader_LegacyColumns(t *testing.T) {
+	content := "#separator:tab\n#html:true\n#columns:Front,Back\n"
+	header, err := models.ReadAnkiHeader(bufio.NewReader(strings.NewReader(content)))
+	if err != nil {
+		t.Fatalf("ReadAnkiHeader failed: %v", err)
+	}
+	if !reflect.DeepEqual(header.Columns, []string{"Front", "Back"}) {
+		t.Errorf("Expected comma-separated names of older output, got %q", header.Columns)
+	}
+}
@@ -0,0 +1,101 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestSimulateAnkiImport(t *testing.T) {
+	content := "#separator:comma\n#html:true\n#columns:Front,Back,Tags\n" +
+		"chat,le chat,animal\n" +
+		",vide,\n" +
+		"#commentaire,x\n" +
+		"chat,encore,\n" +
+		"<br>,x,\n" +
+		"chien,\"le chien,\nnoir\",a,extra\n" +
+		"oiseau,l'oiseau,\n"
+
+	result, err := models.SimulateAnkiImport(strings.NewReader(content), "out.csv", nil)
+	if err != nil {
+		t.Fatalf("SimulateAnkiImport failed: %v", err)
+	}
+	if result.Separator != ',' || result.SeparatorGuessed || !result.HTML || result.HTMLGuessed {
+		t.Errorf("Unexpected settings: %+v", result)
+	}
+	if got := strings.Join(result.Fields, ","); got != "Front,Back,Tags" {
+		t.Errorf("Expected fields Front,Back,Tags, got %s", got)
+	}
+	if result.Rows != 6 || result.Notes != 3 {
+		t.Errorf("Expected 6 rows and 3 notes, got %d and %d", result.Rows, result.Notes)
+	}
+
+	want := []string{
+		"import-skipped out.csv:5",
+		"import-skipped out.csv:6",
+		"import-merged out.csv:7",
+		"import-skipped out.csv:8",
+		"import-dropped out.csv:9",
+	}
+	var got []string
+	for _, issue := range result.Issues {
+		got = append(got, issue.Code+" "+issue.Location())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected issues %v, got %v", want, got)
+	}
+}
+
+func TestSimulateAnkiImport_Guessed(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		separator rune
+		html      bool
+	}{
+		{"tab before comma", "chat\tle chat, le matou\n", '\t', false},
+		{"colon before comma", "chat,le chat: n.\n", ':', false},
+		{"no separator", "chat\n", ' ', false},
+		{"tags", "chat;<b>le chat</b>\n", ';', true},
+		{"entities", "chat|le&nbsp;chat\n", '|', true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := models.SimulateAnkiImport(strings.NewReader(tt.content), "notes.csv", nil)
+			if err != nil {
+				t.Fatalf("SimulateAnkiImport failed: %v", err)
+			}
+			if result.Separator != tt.separator || !result.SeparatorGuessed {
+				t.Errorf("Expected guessed separator %q, got %q", tt.separator, result.Separator)
+			}
+			if result.HTML != tt.html || !result.HTMLGuessed {
+				t.Errorf("Expected guessed HTML %v, got %v", tt.html, result.HTML)
+			}
+		})
+	}
+}
+
+func TestSimulateAnkiImport_Mapping(t *testing.T) {
+	content := "#separator:tab\n#html:true\n#columns:GUID\tFront\tBack\tTags\n#guid column:1\n#tags column:4\n" +
+		"g1\tchat\tle chat\tanimal\n" +
+		"g2\tchat\tle matou\t\n" +
+		"g1\tchat\tle chat\tanimal\n"
+
+	result, err := models.SimulateAnkiImport(strings.NewReader(content), "out.tsv", []string{"Front"})
+	if err != nil {
+		t.Fatalf("SimulateAnkiImport failed: %v", err)
+	}
+	if len(result.FieldColumns) != 1 || result.FieldColumns[0] != 2 {
+		t.Errorf("Expected Front to be filled by column 2, got %v", result.FieldColumns)
+	}
+	// Rows are matched by GUID, so the same first field is a new note
+	if result.Rows != 3 || result.Notes != 2 {
+		t.Errorf("Expected 3 rows and 2 notes, got %d and %d", result.Rows, result.Notes)
+	}
+	if len(result.Issues) != 2 || result.Issues[0].Line != 0 || !strings.Contains(result.Issues[0].Message, "Back fill no field") ||
+		result.Issues[1].Code != models.IssueImportMerged || result.Issues[1].Line != 8 {
+		t.Errorf("Unexpected issues: %v", result.Issues)
+	}
+}
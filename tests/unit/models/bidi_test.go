@@ -0,0 +1,86 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+const (
+	lrm = "\u200E"
+	rlm = "\u200F"
+)
+
+func TestAddDirectionMarks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"left to right only", "book!", "book!"},
+		{"right to left only", "كتاب!", "كتاب!"},
+		{"markup is not text", "<b>كتاب</b>", "<b>كتاب</b>"},
+		{"arabic then english", "كتاب book!", "كتاب" + rlm + " book!" + lrm},
+		{"punctuation follows its run", "The word שלום, means peace.", "The word" + lrm + " שלום," + rlm + " means peace." + lrm},
+		{"cloze syntax is skipped", "{{c1::كتاب}} is a book", "{{c1::كتاب" + rlm + "}} is a book" + lrm},
+		{"entity ends a run", "كتاب&nbsp;book", "كتاب" + rlm + "&nbsp;book" + lrm},
+		{"french spacing before punctuation", "שלום Quoi\u202f?", "שלום" + rlm + " Quoi\u202f?" + lrm},
+		{"space before punctuation after a run", "Quoi ? שלום !", "Quoi ?" + lrm + " שלום !" + rlm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := models.AddDirectionMarks(tt.text)
+			if got != tt.want {
+				t.Errorf("AddDirectionMarks(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if again := models.AddDirectionMarks(got); again != got {
+				t.Errorf("Expected marks to be added once, got %q", again)
+			}
+		})
+	}
+}
+
+func TestHasMixedDirection(t *testing.T) {
+	if !models.HasMixedDirection("שלום world") {
+		t.Error("Expected Hebrew and English to be mixed")
+	}
+	if models.HasMixedDirection("שלום 2024!") || models.HasMixedDirection("<i>שלום</i>") {
+		t.Error("Expected digits, punctuation and markup not to count")
+	}
+}
+
+func TestApplyDirectionMarks(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "book", "Back": "كتاب (kitāb)"}, "vocab.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "pen", "Back": "قلم"}, "vocab.csv", 3),
+	}
+	if changed := models.ApplyDirectionMarks(entries, []string{"Back"}); changed != 1 {
+		t.Errorf("Expected 1 changed field, got %d", changed)
+	}
+	if got := entries[0].GetValue("Back"); got != "كتاب"+rlm+" (kitāb)"+lrm {
+		t.Errorf("Unexpected value %q", got)
+	}
+	if got := entries[1].GetValue("Back"); got != "قلم" {
+		t.Errorf("Expected a right-to-left only value to be unchanged, got %q", got)
+	}
+}
+
+func TestFrenchTypography_RightToLeft(t *testing.T) {
+	processor := models.NewTypographyProcessor(true, false)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"שלום: עולם!", "שלום: עולם!"},
+		{"Bonjour: كتاب؟ et toi?", "Bonjour\u202F: كتاب؟ et toi\u202F?"},
+		{"Le mot « كتاب » veut dire livre!", "Le mot « كتاب » veut dire livre\u202F!"},
+		{"Question: שלום", "Question\u202F: שלום"},
+	}
+
+	for _, tt := range tests {
+		if got := processor.ProcessText(tt.text); got != tt.want {
+			t.Errorf("ProcessText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
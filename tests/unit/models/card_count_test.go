@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestClozeNumbers(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"no clozes", []int{}},
+		{"{{c1::Paris}} is the capital of {{c2::France}}", []int{1, 2}},
+		{"{{c2::a}} {{c1::b}} {{c2::c}}", []int{1, 2}},
+		{"{{c1::outer {{c3::inner}}}}", []int{1, 3}},
+		{"{{c10::ten}}", []int{10}},
+		{"{{c0::zero}}", []int{}},
+	}
+
+	for _, tt := range tests {
+		if got := models.ClozeNumbers(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ClozeNumbers(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCountCards(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Text": "Text", "Extra": "Extra"}, "a.csv", 0),
+		models.NewDataEntry(map[string]string{"Text": "{{c1::a}} {{c2::b}}", "Extra": "{{c3::c}}"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Text": "{{c1::a}} {{c1::b}}"}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Text": "plain"}, "a.csv", 4),
+	}
+
+	count := models.CountCards(entries, []string{"Text", "Extra"})
+	expected := &models.CardCount{ClozeNotes: 2, ClozeCards: 4, MaxPerNote: 3, OtherNotes: 1}
+	if !reflect.DeepEqual(count, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, count)
+	}
+}
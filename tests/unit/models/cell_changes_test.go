@@ -0,0 +1,24 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestCountCellChanges(t *testing.T) {
+	tests := []struct {
+		before, after string
+		want          models.CellChanges
+	}{
+		{"chat", "chat", models.CellChanges{}},
+		{"Bonjour !", "Bonjour\u202F!", models.CellChanges{NNBSPs: 1}},
+		{`Il dit "oui" : c'est ça`, "Il dit “oui”\u202F: c’est ça", models.CellChanges{NNBSPs: 1, Quotes: 3}},
+		{"«\u202Foui\u202F»", "oui", models.CellChanges{}},
+	}
+	for _, tt := range tests {
+		if got := models.CountCellChanges(tt.before, tt.after); got != tt.want {
+			t.Errorf("CountCellChanges(%q, %q) = %+v, want %+v", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
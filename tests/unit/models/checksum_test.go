@@ -0,0 +1,66 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestChecksumManifest_Verify(t *testing.T) {
+	tmpDir := t.TempDir()
+	// sha256 of "Front,Back\n", which other.csv does not match
+	const digest = "3a15ec2311d5315424c9dedd01ff9b8596a2f3e0f56194d85e4137e89942fe10"
+
+	input := filepath.Join(tmpDir, "vocab.csv")
+	if err := os.WriteFile(input, []byte("Front,Back\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	actual, err := models.FileSHA256(input)
+	if err != nil {
+		t.Fatalf("FileSHA256() error = %v", err)
+	}
+	if actual != digest {
+		t.Fatalf("FileSHA256() = %q, want %q", actual, digest)
+	}
+
+	manifestPath := filepath.Join(tmpDir, "manifest.sha256")
+	manifest := "# class materials\n" + strings.ToUpper(digest) + "  vocab.csv\n" + digest + " *other.csv\n"
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	other := filepath.Join(tmpDir, "other.csv")
+	if err := os.WriteFile(other, []byte("Front,Back\nchat,cat\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	unlisted := filepath.Join(tmpDir, "unlisted.csv")
+	if err := os.WriteFile(unlisted, []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	checksums, err := models.LoadChecksumManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("LoadChecksumManifest() error = %v", err)
+	}
+	if got, err := checksums.Verify(input); err != nil || got != digest {
+		t.Errorf("Verify(%s) = %q, %v; want %q", input, got, err, digest)
+	}
+	if _, err := checksums.Verify(other); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Verify(%s) error = %v, want a mismatch", other, err)
+	}
+	if _, err := checksums.Verify(unlisted); err == nil || !strings.Contains(err.Error(), "not listed") {
+		t.Errorf("Verify(%s) error = %v, want not listed", unlisted, err)
+	}
+}
+
+func TestLoadChecksumManifest_Malformed(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.sha256")
+	if err := os.WriteFile(manifestPath, []byte("abc123  vocab.csv\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := models.LoadChecksumManifest(manifestPath); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("LoadChecksumManifest() error = %v, want the bad line reported", err)
+	}
+}
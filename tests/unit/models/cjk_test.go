@@ -0,0 +1,75 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestIsCJK(t *testing.T) {
+	for _, r := range "日本ごカナー한글、「？ＡＢ" {
+		if !models.IsCJK(r) {
+			t.Errorf("Expected %q to be CJK", r)
+		}
+	}
+	for _, r := range "aé?«كש1 " {
+		if models.IsCJK(r) {
+			t.Errorf("Expected %q not to be CJK", r)
+		}
+	}
+}
+
+func TestFrenchTypography_CJK(t *testing.T) {
+	processor := models.NewTypographyProcessor(true, false)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"日本語 : はい!", "日本語 : はい!"},
+		{"Quoi？", "Quoi？"},
+		{"Bonjour: こんにちは? et toi?", "Bonjour\u202F: こんにちは? et toi\u202F?"},
+		{"Le mot « 本 » veut dire livre!", "Le mot « 本 » veut dire livre\u202F!"},
+		{"Question: <b>猫</b>", "Question\u202F: <b>猫</b>"},
+		{"{{c1::猫}} : le chat!", "{{c1::猫}} : le chat\u202F!"},
+	}
+
+	for _, tt := range tests {
+		if got := processor.ProcessText(tt.text); got != tt.want {
+			t.Errorf("ProcessText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestApplyCJKSpacing(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"space before punctuation", "はい ！", "はい！"},
+		{"spaces inside brackets", "「 はい 」", "「はい」"},
+		{"no-break spaces", "日本\u00A0。\u202F猫", "日本。猫"},
+		{"between words", "日本 語", "日本 語"},
+		{"next to latin text", "Oui 「はい」。 Non", "Oui 「はい」。 Non"},
+		{"ideographic space", "はい　。", "はい　。"},
+		{"line break", "はい\n。", "はい\n。"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.ApplyCJKSpacing(tt.text); got != tt.want {
+				t.Errorf("ApplyCJKSpacing(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypographyProcessor_CJKSpacing(t *testing.T) {
+	processor := models.NewTypographyProcessor(true, false)
+	processor.CJKSpacing = true
+
+	got := processor.ProcessText("Comment dit-on chat? 猫 ですか ？")
+	if want := "Comment dit-on chat\u202F? 猫 ですか？"; got != want {
+		t.Errorf("ProcessText = %q, want %q", got, want)
+	}
+}
@@ -201,3 +201,69 @@ func TestClozeDeletionBlock_PatternMatching(t *testing.T) {
 		})
 	}
 }
+
+// TestParseClozeBlocks_Scanner verifies deletions that a single regular
+// expression cannot delimit: line breaks, MathJax braces, nesting and hints
+func TestParseClozeBlocks_Scanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		content []string
+		hints   []string // "" for no hint
+	}{
+		{
+			name:    "multi-line content",
+			text:    "Capital: {{c1::Paris\nFrance}} and {{c2::Rome<br>Italie::city}}",
+			content: []string{"Paris\nFrance", "Rome<br>Italie"},
+			hints:   []string{"", "city"},
+		},
+		{
+			name:    "MathJax braces",
+			text:    `Euler: {{c1::\(e^{i\pi} + 1 = 0\)}} and {{c2::\(x^{y^{2}}\)::power}}`,
+			content: []string{`\(e^{i\pi} + 1 = 0\)`, `\(x^{y^{2}}\)`},
+			hints:   []string{"", "power"},
+		},
+		{
+			name:    "nested deletion",
+			text:    "{{c1::The {{c2::mitochondria::organelle}} is the powerhouse::biology}} !",
+			content: []string{"The {{c2::mitochondria::organelle}} is the powerhouse"},
+			hints:   []string{"biology"},
+		},
+		{
+			name:    "hint with colons",
+			text:    "{{c1::ratio::a::b}}",
+			content: []string{"ratio"},
+			hints:   []string{"a::b"},
+		},
+		{
+			name:    "unclosed deletion before a closed one",
+			text:    "{{c1::open {{c2::closed}} rest",
+			content: []string{"closed"},
+			hints:   []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := models.ParseClozeBlocks(tt.text)
+			if err != nil {
+				t.Fatalf("ParseClozeBlocks() error = %v", err)
+			}
+			if len(blocks) != len(tt.content) {
+				t.Fatalf("ParseClozeBlocks(%q) found %d blocks, want %d", tt.text, len(blocks), len(tt.content))
+			}
+			for i, block := range blocks {
+				hint := ""
+				if block.Hint != nil {
+					hint = *block.Hint
+				}
+				if block.Content != tt.content[i] || hint != tt.hints[i] {
+					t.Errorf("block %d = %q with hint %q, want %q with hint %q", i, block.Content, hint, tt.content[i], tt.hints[i])
+				}
+				if tt.text[block.StartPos:block.EndPos] != block.FullText {
+					t.Errorf("block %d positions [%d:%d] do not match %q", i, block.StartPos, block.EndPos, block.FullText)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestParseColumnJoin(t *testing.T) {
+	join, err := models.ParseColumnJoin("Example1+ Example2+Example3=Examples:<br>")
+	if err != nil {
+		t.Fatalf("ParseColumnJoin() error = %v", err)
+	}
+	if strings.Join(join.Sources, ",") != "Example1,Example2,Example3" || join.Target != "Examples" || join.Separator != "<br>" {
+		t.Errorf("ParseColumnJoin() = %+v", join)
+	}
+
+	if join, err := models.ParseColumnJoin("A+B=C: : "); err != nil || join.Separator != " : " {
+		t.Errorf("ParseColumnJoin() with a ':' separator = %+v, %v", join, err)
+	}
+
+	for _, spec := range []string{"A+B", "A+B=C", "A+B=C:", "A=C:;", "A+=C:;", "A+A=C:;", "A+B=:;"} {
+		if _, err := models.ParseColumnJoin(spec); err == nil {
+			t.Errorf("ParseColumnJoin(%q) expected an error", spec)
+		}
+	}
+}
+
+func TestColumnJoin_Apply(t *testing.T) {
+	join := &models.ColumnJoin{Sources: []string{"E2", "E1"}, Target: "Examples", Separator: "<br>"}
+	if got := strings.Join(join.Headers([]string{"Word", "E1", "Tags", "E2"}), ","); got != "Word,Examples,Tags" {
+		t.Errorf("Headers() = %s, want Word,Examples,Tags", got)
+	}
+
+	header := models.NewDataEntry(map[string]string{"E1": "E1", "E2": "E2"}, "vocab.csv", 0)
+	full := models.NewDataEntry(map[string]string{"E1": "un ", "E2": "deux"}, "vocab.csv", 2)
+	partial := models.NewDataEntry(map[string]string{"E1": "", "E2": "deux"}, "vocab.csv", 3)
+	join.Apply([]*models.DataEntry{header, full, partial})
+
+	tests := []struct {
+		entry *models.DataEntry
+		want  string
+	}{
+		{header, "Examples"},
+		{full, "deux<br>un"},
+		{partial, "deux"},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.GetValue("Examples"); got != tt.want {
+			t.Errorf("line %d: Examples = %q, want %q", tt.entry.LineNumber, got, tt.want)
+		}
+		if _, ok := tt.entry.Values["E1"]; ok {
+			t.Errorf("line %d: expected the source columns to be removed, got %v", tt.entry.LineNumber, tt.entry.Values)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package models_test
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestLanguageTypography(t *testing.T) {
+	languages := map[string]string{"Front": "fr", "Back": "en", "Reading": "ja"}
+
+	rules := models.LanguageTypography(languages, true, false, true)
+	expected := map[string]models.TypographyRule{
+		"Front":   {French: true},
+		"Back":    {},
+		"Reading": {CJKSpacing: true},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, rules)
+	}
+
+	// Without typography flags, languages change nothing
+	if rules := models.LanguageTypography(languages, false, false, false); len(rules) != 0 {
+		t.Errorf("Expected no rules, got %+v", rules)
+	}
+}
+
+func TestLanguageTypography_Routing(t *testing.T) {
+	// A French column named like an English one still gets French spacing,
+	// and an English column is left alone even when the text looks French
+	entry := models.NewDataEntry(map[string]string{"English": "Quoi ?", "Front": "Où est-il ?"}, "in.csv", 2)
+	rules := models.LanguageTypography(map[string]string{"English": "fr", "Front": "en"}, true, false, false)
+	models.ApplyTypographyRules([]*models.DataEntry{entry}, rules, true, false, true)
+
+	if got := entry.GetValue("English"); got != "Quoi\u202F?" {
+		t.Errorf("Expected French spacing in the French column, got %q", got)
+	}
+	if got := entry.GetValue("Front"); got != "Où est-il ?" {
+		t.Errorf("Expected the English column unchanged, got %q", got)
+	}
+}
+
+func TestLanguagesDirective_RoundTrip(t *testing.T) {
+	headers := []string{"Front", "Back, note", "Tags"}
+	languages := map[string]string{"Front": "fr", "Back, note": "en", "Other": "ja"}
+
+	for _, separator := range []string{models.SeparatorComma, models.SeparatorTab} {
+		entry := models.NewDataEntry(map[string]string{"Front": "chat", "Back, note": "cat"}, "in.csv", 2)
+		var buf bytes.Buffer
+		writer := models.NewAnkiWriter(&buf, headers, separator)
+		writer.Languages = languages
+		if err := writer.Write(entry); err != nil {
+			t.Fatalf("%s: Write failed: %v", separator, err)
+		}
+		if err := writer.Flush(); err != nil {
+			t.Fatalf("%s: Flush failed: %v", separator, err)
+		}
+
+		header, err := models.ReadAnkiHeader(bufio.NewReader(&buf))
+		if err != nil {
+			t.Fatalf("%s: ReadAnkiHeader failed: %v", separator, err)
+		}
+		expected := map[string]string{"Front": "fr", "Back, note": "en"}
+		if !reflect.DeepEqual(header.Languages, expected) {
+			t.Errorf("%s: expected %v, got %v", separator, expected, header.Languages)
+		}
+	}
+
+	directive, err := models.LanguagesDirective(headers, nil, models.SeparatorComma)
+	if err != nil || directive != "" {
+		t.Errorf("Expected no directive without languages, got %q, %v", directive, err)
+	}
+}
+
+func TestParseAnkiExport_Languages(t *testing.T) {
+	content := "#separator:comma\n#html:true\n#columns:Front,Back\n#languages:Front=fr,Back=xx\nchat,cat\n"
+	inputFile := models.NewInputFile("out.csv")
+	if _, err := models.ParseAnkiExport(strings.NewReader(content), inputFile, true); err != nil {
+		t.Fatalf("ParseAnkiExport failed: %v", err)
+	}
+	// Unknown languages are left out
+	if !reflect.DeepEqual(inputFile.Languages, map[string]string{"Front": "fr"}) {
+		t.Errorf("Unexpected languages %v", inputFile.Languages)
+	}
+
+	if _, err := models.ReadAnkiHeader(bufio.NewReader(strings.NewReader("#languages:Front\n"))); err == nil {
+		t.Error("Expected error for a declaration without language")
+	}
+}
+
+func TestLoadConfig_Languages(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path := filepath.Join(tmpDir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"languages": {"Front": "fr", "Back": "en"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, err := models.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if models.LanguageSummary(config.Languages) != "Back=en, Front=fr" {
+		t.Errorf("Unexpected languages %v", config.Languages)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"languages": {"Front": "french"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := models.LoadConfig(path); err == nil || !strings.Contains(err.Error(), `language "french" of column "Front"`) {
+		t.Errorf("Expected error for an unknown language, got %v", err)
+	}
+}
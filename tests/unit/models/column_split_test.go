@@ -0,0 +1,84 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestParseColumnSplit(t *testing.T) {
+	split, err := models.ParseColumnSplit("Examples|;|Example1, Example2,Example3")
+	if err != nil {
+		t.Fatalf("ParseColumnSplit() error = %v", err)
+	}
+	if split.Column != "Examples" || split.Delimiter != ";" || strings.Join(split.Targets, ",") != "Example1,Example2,Example3" {
+		t.Errorf("ParseColumnSplit() = %+v", split)
+	}
+
+	if split, err := models.ParseColumnSplit("Synonyms|||A,B"); err != nil || split.Delimiter != "|" {
+		t.Errorf("ParseColumnSplit() with a | delimiter = %+v, %v", split, err)
+	}
+
+	for _, spec := range []string{"Examples", "Examples|;", "Examples||A,B", "|;|A,B", "Examples| |A,B", "Examples|;|A,,B", "Examples|;|A,A"} {
+		if _, err := models.ParseColumnSplit(spec); err == nil {
+			t.Errorf("ParseColumnSplit(%q) expected an error", spec)
+		}
+	}
+}
+
+func TestColumnSplit_Split(t *testing.T) {
+	split := &models.ColumnSplit{Column: "Examples", Delimiter: ";", Targets: []string{"A", "B", "C"}}
+
+	tests := []struct {
+		value    string
+		join     bool
+		values   string
+		overflow string
+	}{
+		{"un; deux ;trois", false, "un|deux|trois", ""},
+		{"un", false, "un||", ""},
+		{"  ", false, "||", ""},
+		{"un;deux;trois;quatre; cinq", false, "un|deux|trois", "quatre|cinq"},
+		{"un;deux;trois;quatre; cinq", true, "un|deux|trois;quatre; cinq", ""},
+	}
+
+	for _, tt := range tests {
+		values, overflow := split.Split(tt.value, tt.join)
+		if got := strings.Join(values, "|"); got != tt.values {
+			t.Errorf("Split(%q, %v) values = %q, want %q", tt.value, tt.join, got, tt.values)
+		}
+		if got := strings.Join(overflow, "|"); got != tt.overflow {
+			t.Errorf("Split(%q, %v) overflow = %q, want %q", tt.value, tt.join, got, tt.overflow)
+		}
+	}
+
+	if got := strings.Join(split.Headers([]string{"Front", "Examples", "Tags"}), ","); got != "Front,A,B,C,Tags" {
+		t.Errorf("Headers() = %s, want Front,A,B,C,Tags", got)
+	}
+}
+
+func TestColumnSplit_Apply(t *testing.T) {
+	split := &models.ColumnSplit{Column: "Examples", Delimiter: ";", Targets: []string{"A", "B"}}
+	header := models.NewDataEntry(map[string]string{"Front": "Front", "Examples": "Examples"}, "vocab.csv", 0)
+	entry := models.NewDataEntry(map[string]string{"Front": "chat", "Examples": "un;deux;trois"}, "vocab.csv", 2)
+
+	var lost []string
+	err := split.Apply([]*models.DataEntry{header, entry}, false, func(e *models.DataEntry, overflow []string) error {
+		lost = append(lost, overflow...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if _, ok := entry.Values["Examples"]; ok || entry.GetValue("A") != "un" || entry.GetValue("B") != "deux" {
+		t.Errorf("Apply() entry values = %v", entry.Values)
+	}
+	if header.GetValue("A") != "A" || header.GetValue("B") != "B" {
+		t.Errorf("Apply() header values = %v", header.Values)
+	}
+	if strings.Join(lost, ",") != "trois" {
+		t.Errorf("Apply() overflow = %v, want [trois]", lost)
+	}
+}
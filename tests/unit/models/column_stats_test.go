@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"math"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestColumnStats_Add verifies counts and lengths are tracked in characters
+func TestColumnStats_Add(t *testing.T) {
+	stats := models.NewColumnStats("Front")
+	for _, v := range []string{"chat", "", "école", "chat", ""} {
+		stats.Add(v)
+	}
+
+	if stats.Total != 5 || stats.NonEmpty != 3 {
+		t.Errorf("Total/NonEmpty = %d/%d, want 5/3", stats.Total, stats.NonEmpty)
+	}
+	if stats.MaxLength != 5 {
+		t.Errorf("MaxLength = %d, want 5 (runes, not bytes)", stats.MaxLength)
+	}
+	if math.Abs(stats.AverageLength-13.0/3.0) > 1e-9 {
+		t.Errorf("AverageLength = %f, want %f", stats.AverageLength, 13.0/3.0)
+	}
+	if stats.DistinctCount != 2 {
+		t.Errorf("DistinctCount = %d, want 2", stats.DistinctCount)
+	}
+	if math.Abs(stats.FillRate()-60.0) > 1e-9 {
+		t.Errorf("FillRate() = %f, want 60", stats.FillRate())
+	}
+}
+
+// TestProcessingReport_CollectColumnStats verifies one stats entry per header
+func TestProcessingReport_CollectColumnStats(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien"}, "b.csv", 2),
+	}
+
+	report := models.NewProcessingReport()
+	report.CollectColumnStats([]string{"Front", "Back", "Extra"}, entries)
+
+	if len(report.Columns) != 3 {
+		t.Fatalf("Expected 3 column stats, got %d", len(report.Columns))
+	}
+	if report.Columns[1].NonEmpty != 1 || report.Columns[1].Total != 2 {
+		t.Errorf("Back stats = %d/%d, want 1/2", report.Columns[1].NonEmpty, report.Columns[1].Total)
+	}
+	if report.Columns[2].FillRate() != 0 {
+		t.Errorf("Extra fill rate = %f, want 0", report.Columns[2].FillRate())
+	}
+}
@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestColumnValues_SortedValues verifies values are ordered by count then name
+func TestColumnValues_SortedValues(t *testing.T) {
+	values := models.NewColumnValues("POS")
+	for _, v := range []string{"verb", "noun", "noun", "", "adj", "noun", "verb"} {
+		values.Add(v)
+	}
+
+	if values.DistinctCount() != 3 {
+		t.Errorf("DistinctCount() = %d, want 3 (empty cells ignored)", values.DistinctCount())
+	}
+
+	want := []models.ValueCount{
+		{Value: "noun", Count: 3},
+		{Value: "verb", Count: 2},
+		{Value: "adj", Count: 1},
+	}
+	if got := values.SortedValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedValues() = %v, want %v", got, want)
+	}
+}
+
+// TestColumnValues_NearMatches verifies case and whitespace variants are grouped
+func TestColumnValues_NearMatches(t *testing.T) {
+	values := models.NewColumnValues("POS")
+	for _, v := range []string{"noun", "Noun", "noun ", "verb", "past  participle", "past participle"} {
+		values.Add(v)
+	}
+
+	want := [][]string{
+		{"Noun", "noun", "noun "},
+		{"past  participle", "past participle"},
+	}
+	if got := values.NearMatches(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NearMatches() = %q, want %q", got, want)
+	}
+
+	clean := models.NewColumnValues("POS")
+	clean.Add("noun")
+	clean.Add("verb")
+	if got := clean.NearMatches(); len(got) != 0 {
+		t.Errorf("Expected no near-matches, got %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestCheckCSVText(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		code   string
+		line   int
+		column int
+		safe   bool
+		fixed  string
+	}{
+		{"clean", "Front,Back\nchat,cat\n", "", 0, 0, false, ""},
+		{"multiline field", "Front,Back\nchat,\"a\n\"\"b\"\"\"\n", "", 0, 0, false, ""},
+		{"trailing empty column", "Front,Back,\nchat,cat,\n", "", 0, 0, false, ""},
+		{"stray separator", "Front,Back,,\r\nchat,cat\r\n", models.IssueStraySeparator, 1, 11, true, "Front,Back\r\nchat,cat\r\n"},
+		{"quote reaching the end", "Front,Back\nchat,\"cat\nchien,dog\n", models.IssueUnbalancedQuote, 2, 6, true, "Front,Back\nchat,\"cat\"\nchien,dog\n"},
+		{"multiline field with bare quotes", "Front,Back\nchat,\"a \"big\" cat\nthat purrs\"\n", "", 0, 0, false, ""},
+		{"bare quote reaching the end", "Front,Back\nchat,\"a \"big\" cat\nchien,dog\n", models.IssueUnbalancedQuote, 2, 6, true, "Front,Back\nchat,\"a \"big\" cat\"\nchien,dog\n"},
+		{"quote leaving too few fields", "Front,Back\n\"chat,cat\nchien,dog\n", models.IssueUnbalancedQuote, 2, 1, false, ""},
+		{"quote in the header", "Front,\"Back\nchat,cat\n", models.IssueUnbalancedQuote, 1, 7, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := models.CheckCSVText("vocab.csv", []byte(tt.data), ',')
+			if tt.code == "" {
+				if len(issues) != 0 {
+					t.Fatalf("Expected no issues, got %v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %v", issues)
+			}
+
+			issue := issues[0]
+			if issue.Code != tt.code || issue.Line != tt.line || issue.Column != tt.column {
+				t.Errorf("Expected %s at %d:%d, got %s at %d:%d", tt.code, tt.line, tt.column, issue.Code, issue.Line, issue.Column)
+			}
+			if issue.Fix == nil || issue.Fix.Safe != tt.safe {
+				t.Fatalf("Expected a fix with safe=%v, got %+v", tt.safe, issue.Fix)
+			}
+			if !tt.safe {
+				return
+			}
+
+			fixed, err := models.ApplyFixes([]byte(tt.data), []*models.Fix{issue.Fix})
+			if err != nil {
+				t.Fatalf("ApplyFixes failed: %v", err)
+			}
+			if string(fixed) != tt.fixed {
+				t.Errorf("Expected %q, got %q", tt.fixed, fixed)
+			}
+			if !issue.Fix.Applied {
+				t.Error("Expected the fix to be marked applied")
+			}
+			if again := models.CheckCSVText("vocab.csv", fixed, ','); len(again) != 0 {
+				t.Errorf("Expected the fixed text to be clean, got %v", again)
+			}
+		})
+	}
+}
+
+func TestApplyFixes_Overlap(t *testing.T) {
+	data := []byte("Front,Back\n")
+	fixes := []*models.Fix{
+		{Edits: []models.TextEdit{{Line: 1, Column: 1, Offset: 0, Length: 5, Text: "Term"}}},
+		{Edits: []models.TextEdit{{Line: 1, Column: 3, Offset: 2, Length: 2}}},
+	}
+	result, err := models.ApplyFixes(data, fixes)
+	if err == nil {
+		t.Fatal("Expected overlapping fixes to fail")
+	}
+	if string(result) != string(data) || fixes[0].Applied {
+		t.Errorf("Expected nothing to be applied, got %q", result)
+	}
+}
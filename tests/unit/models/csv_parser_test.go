@@ -0,0 +1,194 @@
+package models_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// TestCSVParser_Parse verifies records stream with the line they start on
+func TestCSVParser_Parse(t *testing.T) {
+	input := "\uFEFFFront,Back\n" +
+		"chat,cat\n" +
+		"\"line one\nline two\",multi\n" +
+		"chien,dog\n"
+
+	inputFile := models.NewInputFile("test.csv")
+	var lines []int
+	var fronts []string
+
+	err := models.NewCSVParser().Parse(strings.NewReader(input), inputFile, func(record []string, line int) error {
+		lines = append(lines, line)
+		fronts = append(fronts, record[0])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(inputFile.Headers, []string{"Front", "Back"}) {
+		t.Errorf("Headers = %q, want BOM-free [Front Back]", inputFile.Headers)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 3, 5}) {
+		t.Errorf("Lines = %v, want [2 3 5]", lines)
+	}
+	if fronts[1] != "line one\nline two" {
+		t.Errorf("Expected embedded newline preserved, got %q", fronts[1])
+	}
+}
+
+// TestCSVParser_Errors verifies empty input and handler errors stop parsing
+func TestCSVParser_Errors(t *testing.T) {
+	parser := models.NewCSVParser()
+
+	if err := parser.Parse(strings.NewReader(""), models.NewInputFile("empty.csv"), func([]string, int) error {
+		return nil
+	}); err == nil {
+		t.Error("Expected error for empty input")
+	}
+
+	calls := 0
+	stop := parser.Parse(strings.NewReader("a\n1\n2\n3\n"), models.NewInputFile("stop.csv"), func([]string, int) error {
+		calls++
+		return errStop
+	})
+	if stop != errStop || calls != 1 {
+		t.Errorf("Expected handler error after 1 call, got %v after %d calls", stop, calls)
+	}
+}
+
+var errStop = errors.New("stop")
+
+// TestCSVParser_HeaderAbsent verifies the first row is data when there is no header
+func TestCSVParser_HeaderAbsent(t *testing.T) {
+	parser := models.NewCSVParser()
+	parser.Header = models.HeaderAbsent
+
+	inputFile := models.NewInputFile("test.csv")
+	var lines []int
+	err := parser.Parse(strings.NewReader("chat,cat\nchien,dog\n"), inputFile, func(record []string, line int) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if inputFile.HasHeader {
+		t.Error("Expected HasHeader to be false")
+	}
+	if !reflect.DeepEqual(inputFile.Headers, []string{"Column1", "Column2"}) {
+		t.Errorf("Headers = %q, want [Column1 Column2]", inputFile.Headers)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 2}) {
+		t.Errorf("Lines = %v, want [1 2]", lines)
+	}
+}
+
+// TestCSVParser_Mmap verifies memory-mapped reading gives the same records
+// and falls back to ordinary reads for files that cannot be mapped
+func TestCSVParser_Mmap(t *testing.T) {
+	dir := t.TempDir()
+	input := "Front,Back\nchat,cat\n\"line one\nline two\",multi\n"
+	path := filepath.Join(dir, "input.csv")
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+
+	parse := func(path string, mmap bool) ([][]string, []int, error) {
+		parser := models.NewCSVParser()
+		parser.Mmap = mmap
+		var records [][]string
+		var lines []int
+		err := parser.ParseFile(models.NewInputFile(path), func(record []string, line int) error {
+			records = append(records, record)
+			lines = append(lines, line)
+			return nil
+		})
+		return records, lines, err
+	}
+
+	records, lines, err := parse(path, false)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	mappedRecords, mappedLines, err := parse(path, true)
+	if err != nil {
+		t.Fatalf("ParseFile with Mmap failed: %v", err)
+	}
+	if !reflect.DeepEqual(mappedRecords, records) || !reflect.DeepEqual(mappedLines, lines) {
+		t.Errorf("Expected %q at %v, got %q at %v", records, lines, mappedRecords, mappedLines)
+	}
+
+	empty := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	if _, _, err := parse(empty, true); err == nil || !strings.Contains(err.Error(), "no data") {
+		t.Errorf("Expected an empty file to fall back and report no data, got %v", err)
+	}
+}
+
+// TestCSVParser_Compressed verifies .gz and .zst files are decompressed and
+// take their separator from the extension before the compression suffix
+func TestCSVParser_Compressed(t *testing.T) {
+	dir := t.TempDir()
+
+	var gz bytes.Buffer
+	gzWriter := gzip.NewWriter(&gz)
+	gzWriter.Write([]byte("Front,Back\nchat,cat\n"))
+	gzWriter.Close()
+
+	zst, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create zstd encoder: %v", err)
+	}
+	zstData := zst.EncodeAll([]byte("Front\tBack\nchien\tdog\n"), nil)
+
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"input.csv.gz", gz.Bytes(), []string{"chat", "cat"}},
+		{"input.TSV.zst", zstData, []string{"chien", "dog"}},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, tt.data, 0644); err != nil {
+			t.Fatalf("Failed to create test input file: %v", err)
+		}
+
+		inputFile := models.NewInputFile(path)
+		inputFile.DetectSeparator()
+		var records [][]string
+		err := models.NewCSVParser().ParseFile(inputFile, func(record []string, line int) error {
+			records = append(records, record)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: ParseFile failed: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(inputFile.Headers, []string{"Front", "Back"}) || len(records) != 1 || !reflect.DeepEqual(records[0], tt.want) {
+			t.Errorf("%s: expected Front,Back and %q, got %q and %q", tt.name, tt.want, inputFile.Headers, records)
+		}
+	}
+
+	// A .gz file that is not gzip data fails instead of parsing garbage
+	corrupt := filepath.Join(dir, "corrupt.csv.gz")
+	if err := os.WriteFile(corrupt, []byte("Front,Back\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test input file: %v", err)
+	}
+	if err := models.NewCSVParser().ParseFile(models.NewInputFile(corrupt), func([]string, int) error { return nil }); err == nil {
+		t.Error("Expected an error for a .gz file that is not gzip data")
+	}
+}
@@ -0,0 +1,78 @@
+package models_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+var pngData = []byte("\x89PNG\r\n\x1a\nfake image")
+
+func pngURI() string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)
+}
+
+func TestFindDataURIs(t *testing.T) {
+	value := `chat <img src="` + pngURI() + `"> and data:text/plain;charset=utf-8;base64,aGk=`
+	stats := models.FindDataURIs(value)
+	if stats.Count != 2 {
+		t.Errorf("Expected 2 data URIs, got %d", stats.Count)
+	}
+	if stats.Bytes != len(pngURI())+len("data:text/plain;charset=utf-8;base64,aGk=") {
+		t.Errorf("Unexpected byte count %d", stats.Bytes)
+	}
+
+	if stats := models.FindDataURIs("see https://example.com/data:x"); stats.Count != 0 {
+		t.Errorf("Expected no data URIs, got %d", stats.Count)
+	}
+}
+
+func TestStripDataURIs(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`chat<img src="` + pngURI() + `" alt="cat">`, "chat"},
+		{`<IMG SRC='` + pngURI() + `'/> chat`, " chat"},
+		{"bare " + pngURI(), "bare "},
+		{`<img src="cat.png">`, `<img src="cat.png">`},
+	}
+
+	for _, tt := range tests {
+		if got := models.StripDataURIs(tt.value); got != tt.want {
+			t.Errorf("StripDataURIs(%.40q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMediaStore_ExtractDataURIs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "media")
+	store := models.NewMediaStore(dir)
+
+	value := `<img src="` + pngURI() + `"><img src="` + pngURI() + `">`
+	got, err := store.ExtractDataURIs(value)
+	if err != nil {
+		t.Fatalf("ExtractDataURIs failed: %v", err)
+	}
+
+	if store.Saved != 1 {
+		t.Errorf("Expected identical images saved once, got %d files", store.Saved)
+	}
+	if strings.Contains(got, "data:") || !strings.Contains(got, `.png">`) {
+		t.Errorf("Expected file references, got %q", got)
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(got[:strings.Index(got, ">")], `<img src="`), `"`)
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("Failed to read media file: %v", err)
+	}
+	if !bytes.Equal(data, pngData) {
+		t.Errorf("Media file content does not match the image")
+	}
+}
@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestDeckRoute_Apply(t *testing.T) {
+	route := &models.DeckRoute{Column: "Chapter", Prefix: "French::Course::"}
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Chapter": "Chapter"}, "vocab.csv", 0),
+		models.NewDataEntry(map[string]string{"Chapter": " 3 "}, "vocab.csv", 2),
+		models.NewDataEntry(map[string]string{"Chapter": ""}, "vocab.csv", 3),
+	}
+	route.Apply(entries)
+
+	for i, want := range []string{models.DeckColumn, "French::Course::3", "French::Course"} {
+		if got := entries[i].GetValue(models.DeckColumn); got != want {
+			t.Errorf("entry %d: Deck = %q, want %q", i, got, want)
+		}
+	}
+
+	if got := (&models.DeckRoute{Column: "Chapter"}).DeckName(""); got != "" {
+		t.Errorf("DeckName() without prefix or value = %q, want empty", got)
+	}
+}
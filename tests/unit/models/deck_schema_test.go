@@ -0,0 +1,173 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func writeSchema(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "deck.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+	return path
+}
+
+func TestLoadDeckSchema(t *testing.T) {
+	path := writeSchema(t, `note_type: Basic (and reversed card)
+columns:
+  - name: Front
+    required: true
+    typography: french
+  - name: Back
+    typography: none
+  - name: Reading
+    typography: cjk+smart-quotes
+  - name: Rank
+    type: number
+    optional: true
+`)
+
+	schema, err := models.LoadDeckSchema(path)
+	if err != nil {
+		t.Fatalf("LoadDeckSchema failed: %v", err)
+	}
+	if schema.NoteType != "Basic (and reversed card)" {
+		t.Errorf("Unexpected note type %q", schema.NoteType)
+	}
+	if got := strings.Join(schema.ColumnNames(), ","); got != "Front,Back,Reading,Rank" {
+		t.Errorf("Expected columns Front,Back,Reading,Rank, got %s", got)
+	}
+	if got := strings.Join(schema.ExpectedColumns(), ","); got != "Front,Back,Reading" {
+		t.Errorf("Expected non-optional columns Front,Back,Reading, got %s", got)
+	}
+
+	rules := schema.TypographyRules()
+	if len(rules) != 3 || !rules["Front"].French || rules["Front"].SmartQuotes || rules["Back"].French {
+		t.Errorf("Unexpected typography rules: %+v", rules)
+	}
+	if reading := rules["Reading"]; reading.French || !reading.SmartQuotes || !reading.CJKSpacing {
+		t.Errorf("Unexpected typography rules: %+v", rules)
+	}
+}
+
+func TestLoadDeckSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"no columns", "note_type: Basic\n"},
+		{"unnamed column", "columns:\n  - type: text\n"},
+		{"duplicate column", "columns:\n  - name: Front\n  - name: Front\n"},
+		{"unknown type", "columns:\n  - name: Front\n    type: date\n"},
+		{"unknown typography", "columns:\n  - name: Front\n    typography: german\n"},
+		{"unknown combined typography", "columns:\n  - name: Front\n    typography: french+german\n"},
+		{"required and optional", "columns:\n  - name: Front\n    required: true\n    optional: true\n"},
+		{"duplicate source", "columns:\n  - name: Front\n    source: Word\n  - name: Back\n    source: Word\n"},
+		{"not yaml", "columns: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := models.LoadDeckSchema(writeSchema(t, tt.content)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestDeckSchema_RenameColumns(t *testing.T) {
+	schema := &models.DeckSchema{Columns: []*models.SchemaColumn{
+		{Name: "Front", Source: "Word"},
+		{Name: "Back", Source: "Meaning"},
+	}}
+
+	renamed := &models.InputFile{Headers: []string{"Word", "Meaning", "Notes"}}
+	kept := &models.InputFile{Headers: []string{"Word", "Front"}}
+	schema.RenameColumns([]*models.InputFile{renamed, kept})
+
+	if got := strings.Join(renamed.Headers, ","); got != "Front,Back,Notes" {
+		t.Errorf("Expected renamed headers Front,Back,Notes, got %s", got)
+	}
+	if got := strings.Join(kept.Headers, ","); got != "Word,Front" {
+		t.Errorf("Expected a file with a Front column to keep its headers, got %s", got)
+	}
+}
+
+func TestDeckSchema_Save(t *testing.T) {
+	schema := &models.DeckSchema{Columns: []*models.SchemaColumn{
+		{Name: "Front", Source: "Word", Required: true, Typography: models.TypographyFrench},
+		{Name: "Back"},
+	}}
+
+	path := filepath.Join(t.TempDir(), "deck.yaml")
+	if err := schema.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := models.LoadDeckSchema(path)
+	if err != nil {
+		t.Fatalf("LoadDeckSchema() error = %v", err)
+	}
+	if len(loaded.Columns) != 2 || *loaded.Columns[0] != *schema.Columns[0] || loaded.NoteType != "" {
+		t.Errorf("Expected the saved schema to load back unchanged, got %+v", loaded.Columns[0])
+	}
+
+	if err := (&models.DeckSchema{}).Save(path); err == nil {
+		t.Error("Expected an invalid schema not to be saved")
+	}
+}
+
+func TestDeckSchema_CheckEntry(t *testing.T) {
+	schema := &models.DeckSchema{Columns: []*models.SchemaColumn{
+		{Name: "Front", Required: true},
+		{Name: "Rank", Type: models.ColumnNumber},
+		{Name: "Text", Type: models.ColumnCloze},
+	}}
+
+	tests := []struct {
+		values   map[string]string
+		problems int
+	}{
+		{map[string]string{"Front": "chat", "Rank": "3,5", "Text": "{{c1::chat}}"}, 0},
+		{map[string]string{"Front": "chat", "Rank": "", "Text": ""}, 0},
+		{map[string]string{"Front": " ", "Rank": "3"}, 1},
+		{map[string]string{"Front": "chat", "Rank": "three", "Text": "chat"}, 2},
+	}
+
+	for _, tt := range tests {
+		entry := models.NewDataEntry(tt.values, "vocab.csv", 2)
+		problems := schema.CheckEntry(entry)
+		if len(problems) != tt.problems {
+			t.Errorf("%v: expected %d problem(s), got %v", tt.values, tt.problems, problems)
+		}
+		for _, problem := range problems {
+			if problem.Code != models.IssueSchema || problem.Location() != "vocab.csv:2" {
+				t.Errorf("Expected a schema issue at vocab.csv:2, got %+v", problem)
+			}
+		}
+	}
+}
+
+func TestApplyTypographyRules(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "Quoi?", "Back": "What?", "Notes": "Oui?"}, "vocab.csv", 2),
+	}
+	rules := map[string]models.TypographyRule{
+		"Back":  {French: true},
+		"Notes": {},
+	}
+
+	models.ApplyTypographyRules(entries, rules, true, false, false)
+
+	expected := map[string]string{"Front": "Quoi\u202f?", "Back": "What\u202f?", "Notes": "Oui?"}
+	for column, want := range expected {
+		if got := entries[0].GetValue(column); got != want {
+			t.Errorf("%s: expected %q, got %q", column, want, got)
+		}
+	}
+}
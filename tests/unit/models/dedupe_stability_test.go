@@ -0,0 +1,90 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func stabilityEntries() []*models.DataEntry {
+	return []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "a"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien", "Tags": ""}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "b"}, "b.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "oiseau", "Tags": ""}, "b.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "chien", "Tags": "c"}, "b.csv", 4),
+	}
+}
+
+func TestDedupeSnapshotVerify(t *testing.T) {
+	hasher := models.KeyColumnsHasher{Columns: []string{"Front"}}
+
+	t.Run("first occurrences in input order", func(t *testing.T) {
+		entries := stabilityEntries()
+		snapshot := models.SnapshotDedupe(entries, hasher)
+		detector := models.NewDuplicateDetector(hasher)
+		detector.MergeTags = true
+		kept := detector.RemoveDuplicates(entries)
+		if err := snapshot.Verify(kept); err != nil {
+			t.Errorf("Expected stable result, got %v", err)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		kept     func(entries []*models.DataEntry) []*models.DataEntry
+		expected string
+	}{
+		{
+			name: "out of order",
+			kept: func(e []*models.DataEntry) []*models.DataEntry {
+				return []*models.DataEntry{e[1], e[0], e[3]}
+			},
+			expected: "entry a.csv:2 is kept after a.csv:3, which came later in the input",
+		},
+		{
+			name: "later duplicate kept",
+			kept: func(e []*models.DataEntry) []*models.DataEntry {
+				return []*models.DataEntry{e[1], e[2], e[3]}
+			},
+			expected: "entry a.csv:2 was removed but is the first of its kind",
+		},
+		{
+			name: "both kept",
+			kept: func(e []*models.DataEntry) []*models.DataEntry {
+				return []*models.DataEntry{e[0], e[1], e[2], e[3]}
+			},
+			expected: "entry b.csv:2 was kept but duplicates a.csv:2, which came first",
+		},
+		{
+			name: "unknown entry",
+			kept: func(e []*models.DataEntry) []*models.DataEntry {
+				return []*models.DataEntry{models.NewDataEntry(map[string]string{"Front": "chat"}, "c.csv", 9)}
+			},
+			expected: "entry c.csv:9 was kept but is not in the input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := stabilityEntries()
+			snapshot := models.SnapshotDedupe(entries, hasher)
+			err := snapshot.Verify(tt.kept(entries))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestMergeTagsColumnOrder(t *testing.T) {
+	removed := models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "b", "tag": "y"}, "a.csv", 3)
+
+	for i := 0; i < 20; i++ {
+		entry := models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "a", "tag": "x"}, "a.csv", 2)
+		entry.MergeTags(removed)
+		if entry.GetValue("Tags") != "a b" || entry.GetValue("tag") != "x y" {
+			t.Fatalf("Unexpected tags %v", entry.Values)
+		}
+	}
+}
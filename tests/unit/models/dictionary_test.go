@@ -0,0 +1,78 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestDictionary_LoadWordList verifies plain wordlists and hunspell .dic files load
+func TestDictionary_LoadWordList(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	hunspell := filepath.Join(tmpDir, "fr.dic")
+	if err := os.WriteFile(hunspell, []byte("3\nchat/S\nchien/S\nmaison\n"), 0644); err != nil {
+		t.Fatalf("Failed to write dictionary: %v", err)
+	}
+
+	plain := filepath.Join(tmpDir, "extra.txt")
+	if err := os.WriteFile(plain, []byte("# custom words\nAnki\n\nl'homme\n"), 0644); err != nil {
+		t.Fatalf("Failed to write wordlist: %v", err)
+	}
+
+	dictionary := models.NewDictionary()
+	for _, path := range []string{hunspell, plain} {
+		if err := dictionary.LoadWordList(path); err != nil {
+			t.Fatalf("LoadWordList(%s) failed: %v", path, err)
+		}
+	}
+
+	if dictionary.Size() != 5 {
+		t.Errorf("Size() = %d, want 5", dictionary.Size())
+	}
+	for _, word := range []string{"chat", "Chat", "chien", "Anki"} {
+		if !dictionary.Contains(word) {
+			t.Errorf("Expected dictionary to contain %q", word)
+		}
+	}
+	if dictionary.Contains("3") {
+		t.Error("hunspell word count should not be loaded as a word")
+	}
+
+	if err := dictionary.LoadWordList(filepath.Join(tmpDir, "missing.dic")); err == nil {
+		t.Error("Expected error for missing dictionary file")
+	}
+}
+
+// TestDictionary_UnknownWords verifies tokenization ignores markup and numbers
+func TestDictionary_UnknownWords(t *testing.T) {
+	dictionary := models.NewDictionary()
+	for _, word := range []string{"le", "chat", "dort", "homme", "arc-en-ciel"} {
+		dictionary.AddWord(word)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "all known", text: "Le chat dort.", want: nil},
+		{name: "typo", text: "Le chta dort", want: []string{"chta"}},
+		{name: "html and cloze", text: "<b class=\"x\">{{c1::chat}}</b> dort", want: nil},
+		{name: "elision", text: "l'homme", want: nil},
+		{name: "hyphenated", text: "arc-en-ciel", want: nil},
+		{name: "numbers ignored", text: "chat 42 3e", want: nil},
+		{name: "reported once", text: "chta chta", want: []string{"chta"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dictionary.UnknownWords(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnknownWords(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestHashers(t *testing.T) {
+	tests := []struct {
+		name   string
+		hasher models.Hasher
+		a, b   map[string]string
+		want   bool // true if the entries are duplicates
+	}{
+		{"exact identical", models.ExactHasher{}, map[string]string{"Front": "chat", "Back": "cat"}, map[string]string{"Front": "chat", "Back": "cat"}, true},
+		{"exact case differs", models.ExactHasher{}, map[string]string{"Front": "Chat"}, map[string]string{"Front": "chat"}, false},
+		{"normalized case and spaces", models.NormalizedHasher{}, map[string]string{"Front": "Le  chat "}, map[string]string{"Front": "le chat"}, true},
+		{"normalized punctuation differs", models.NormalizedHasher{}, map[string]string{"Front": "chat!"}, map[string]string{"Front": "chat"}, false},
+		{"key columns ignore others", models.KeyColumnsHasher{Columns: []string{"Front"}}, map[string]string{"Front": "chat", "Back": "cat"}, map[string]string{"Front": "chat", "Back": "kitty"}, true},
+		{"key columns differ", models.KeyColumnsHasher{Columns: []string{"Front"}}, map[string]string{"Front": "chat"}, map[string]string{"Front": "chien"}, false},
+		{"fuzzy word order and markup", models.FuzzyHasher{}, map[string]string{"Back": "<b>run</b> (to)"}, map[string]string{"Back": "to run"}, true},
+		{"fuzzy accents", models.FuzzyHasher{}, map[string]string{"Front": "Été"}, map[string]string{"Front": "ete"}, true},
+		{"fuzzy different words", models.FuzzyHasher{}, map[string]string{"Front": "to run"}, map[string]string{"Front": "to walk"}, false},
+		{"merge fields ignore merged", models.MergeFieldsHasher{Merged: []string{"Examples"}}, map[string]string{"Front": "chat", "Examples": "Le chat dort."}, map[string]string{"Front": "chat", "Examples": "Le chat mange."}, true},
+		{"merge fields others differ", models.MergeFieldsHasher{Merged: []string{"Examples"}}, map[string]string{"Front": "chat", "Back": "cat"}, map[string]string{"Front": "chat", "Back": "kitty"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := models.NewDataEntry(tt.a, "a.csv", 2)
+			b := models.NewDataEntry(tt.b, "b.csv", 2)
+			if got := tt.hasher.Hash(a) == tt.hasher.Hash(b); got != tt.want {
+				t.Errorf("duplicate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	for _, strategy := range []string{models.DedupeExact, models.DedupeNormalized, models.DedupeFuzzy} {
+		if _, err := models.NewHasher(strategy, nil); err != nil {
+			t.Errorf("NewHasher(%q) error = %v", strategy, err)
+		}
+	}
+	if _, err := models.NewHasher(models.DedupeKeyColumns, []string{"Front"}); err != nil {
+		t.Errorf("NewHasher(key-columns) error = %v", err)
+	}
+	if _, err := models.NewHasher(models.DedupeKeyColumns, nil); err == nil {
+		t.Error("NewHasher(key-columns) without columns should fail")
+	}
+	if _, err := models.NewHasher(models.DedupeMergeFields, []string{"Examples"}); err != nil {
+		t.Errorf("NewHasher(merge-fields) error = %v", err)
+	}
+	if _, err := models.NewHasher(models.DedupeMergeFields, nil); err == nil {
+		t.Error("NewHasher(merge-fields) without columns should fail")
+	}
+	if _, err := models.NewHasher("phonetic", nil); err == nil {
+		t.Error("NewHasher(unknown) should fail")
+	}
+}
+
+// lengthHasher shows that custom strategies can be injected
+type lengthHasher struct{}
+
+func (lengthHasher) Hash(entry *models.DataEntry) string {
+	return string(rune('0' + len(entry.GetValue("Front"))))
+}
+
+func TestDuplicateDetector_RemoveDuplicates(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien"}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "loup"}, "b.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "ours"}, "b.csv", 3),
+	}
+
+	detector := models.NewDuplicateDetector(lengthHasher{})
+	unique := detector.RemoveDuplicates(entries)
+
+	if len(unique) != 2 || unique[0] != entries[0] || unique[1] != entries[1] {
+		t.Fatalf("RemoveDuplicates() kept %d entries, want first two in order", len(unique))
+	}
+
+	// Later checks still see the entries already processed
+	extra := models.NewDataEntry(map[string]string{"Front": "bear"}, "c.csv", 2)
+	if original := detector.Check(extra); original != entries[0] {
+		t.Errorf("Check() = %v, want first entry", original)
+	}
+}
+
+func TestDuplicateDetector_Sources(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "b.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien"}, "a.csv", 4),
+		models.NewDataEntry(map[string]string{"Front": "chien"}, "b.csv", 3),
+	}
+
+	detector := models.NewDuplicateDetector(models.ExactHasher{})
+	detector.RemoveDuplicates(entries)
+
+	sources := detector.Sources()
+	if len(sources) != 2 {
+		t.Fatalf("Sources() returned %d pairs, want 2", len(sources))
+	}
+	if sources[0].Original != "a.csv" || sources[0].Duplicate != "a.csv" || sources[0].Count != 1 || sources[0].CrossFile() {
+		t.Errorf("first pair = %+v, want 1 duplicate within a.csv", sources[0])
+	}
+	if sources[1].Original != "a.csv" || sources[1].Duplicate != "b.csv" || sources[1].Count != 2 || !sources[1].CrossFile() {
+		t.Errorf("second pair = %+v, want 2 duplicates between a.csv and b.csv", sources[1])
+	}
+
+	crossFile := models.DetectDuplicatesAcrossFiles(entries, models.ExactHasher{})
+	if len(crossFile) != 1 || crossFile[0].Count != 2 {
+		t.Errorf("DetectDuplicatesAcrossFiles() = %+v, want one pair with 2 duplicates", crossFile)
+	}
+}
+
+func TestDuplicateDetector_MergeTags(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "animals"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "lesson3"}, "b.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chat", "Tags": "animals verbs"}, "c.csv", 2),
+	}
+
+	detector := models.NewDuplicateDetector(models.KeyColumnsHasher{Columns: []string{"Front"}})
+	detector.MergeTags = true
+	unique := detector.RemoveDuplicates(entries)
+
+	if len(unique) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(unique))
+	}
+	if got := unique[0].GetValue("Tags"); got != "animals lesson3 verbs" {
+		t.Errorf("Expected merged tags, got %q", got)
+	}
+}
+
+func TestDuplicateDetector_MergeFields(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat", "Examples": "Le chat dort."}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chat", "Examples": "Le chat mange.<br>Le chat dort."}, "b.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien", "Examples": ""}, "b.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "chien", "Examples": "Le chien aboie."}, "c.csv", 2),
+	}
+
+	detector := models.NewDuplicateDetector(models.MergeFieldsHasher{Merged: []string{"Examples"}})
+	detector.MergeFields = []*models.ItemList{models.NewItemList("Examples", "<br>")}
+	unique := detector.RemoveDuplicates(entries)
+
+	if len(unique) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(unique))
+	}
+	if got := unique[0].GetValue("Examples"); got != "Le chat dort.<br>Le chat mange." {
+		t.Errorf("Expected merged examples, got %q", got)
+	}
+	if got := unique[1].GetValue("Examples"); got != "Le chien aboie." {
+		t.Errorf("Expected examples of the removed duplicate, got %q", got)
+	}
+}
+
+func TestDuplicateDetector_Groups(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien"}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 4),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 5),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 5),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 6),
+		models.NewDataEntry(map[string]string{"Front": "chien"}, "b.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "b.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "b.csv", 8),
+	}
+
+	detector := models.NewDuplicateDetector(models.ExactHasher{})
+	detector.RemoveDuplicates(entries)
+
+	groups := detector.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("Groups() returned %d groups, want 2", len(groups))
+	}
+	if got := groups[0].Kept.String(); got != "a.csv:2" {
+		t.Errorf("first group kept %s, want a.csv:2", got)
+	}
+	if len(groups[0].Removed) != 6 {
+		t.Errorf("first group removed %d entries, want 6", len(groups[0].Removed))
+	}
+	if got, want := groups[0].RemovedRanges(), "a.csv:4-6, b.csv:3, 8"; got != want {
+		t.Errorf("RemovedRanges() = %q, want %q", got, want)
+	}
+	if got, want := groups[1].Kept.String()+" "+groups[1].RemovedRanges(), "a.csv:3 b.csv:2"; got != want {
+		t.Errorf("second group = %q, want %q", got, want)
+	}
+}
+
+func TestDuplicateDetectorWithHash(t *testing.T) {
+	entries := func() []*models.DataEntry {
+		return []*models.DataEntry{
+			models.NewDataEntry(map[string]string{"Front": "Le chat"}, "a.csv", 2),
+			models.NewDataEntry(map[string]string{"Front": "chien"}, "a.csv", 3),
+			models.NewDataEntry(map[string]string{"Front": "le  chat"}, "b.csv", 2),
+			models.NewDataEntry(map[string]string{"Front": "loup"}, "b.csv", 3),
+		}
+	}
+
+	for _, algorithm := range []string{models.HashMD5, models.HashFNV, models.HashXXHash, models.HashSHA256} {
+		for _, verify := range []bool{false, true} {
+			detector, err := models.NewDuplicateDetectorWithHash(models.NormalizedHasher{}, algorithm, verify)
+			if err != nil {
+				t.Fatalf("NewDuplicateDetectorWithHash(%q) error = %v", algorithm, err)
+			}
+			if unique := detector.RemoveDuplicates(entries()); len(unique) != 3 {
+				t.Errorf("%s (verify %v) kept %d entries, want 3", algorithm, verify, len(unique))
+			}
+			if detector.Collisions() != 0 {
+				t.Errorf("%s (verify %v) found %d collisions, want 0", algorithm, verify, detector.Collisions())
+			}
+		}
+	}
+
+	if _, err := models.NewDuplicateDetectorWithHash(models.ExactHasher{}, "crc32", false); err == nil {
+		t.Error("NewDuplicateDetectorWithHash(unknown algorithm) should fail")
+	}
+	if _, err := models.NewDuplicateDetectorWithHash(lengthHasher{}, models.HashSHA256, false); err == nil {
+		t.Error("NewDuplicateDetectorWithHash() should fail for a hasher without keys")
+	}
+}
+
+func TestDuplicateDetector_VerifyCollisions(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "loup"}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "ours"}, "a.csv", 4),
+		models.NewDataEntry(map[string]string{"Front": "loup"}, "a.csv", 5),
+	}
+
+	// Every four-letter word has the same length hash; verification tells
+	// them apart and still removes the real duplicate
+	detector, err := models.NewDuplicateDetectorWithHash(lengthHasher{}, models.HashMD5, true)
+	if err != nil {
+		t.Fatalf("NewDuplicateDetectorWithHash() error = %v", err)
+	}
+	unique := detector.RemoveDuplicates(entries)
+	if len(unique) != 3 || unique[2] != entries[2] {
+		t.Errorf("RemoveDuplicates() kept %d entries, want the first three", len(unique))
+	}
+	if detector.Collisions() != 2 {
+		t.Errorf("Collisions() = %d, want 2", detector.Collisions())
+	}
+}
@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		value    string
+		maxBytes int
+		want     string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"été", 2, "é"},
+		{"été", 4, "ét"},
+		{"é", 1, ""},
+	}
+
+	for _, tt := range tests {
+		if got := models.TruncateUTF8(tt.value, tt.maxBytes); got != tt.want {
+			t.Errorf("TruncateUTF8(%q, %d) = %q, want %q", tt.value, tt.maxBytes, got, tt.want)
+		}
+	}
+}
+
+func TestFindOversizeFields(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "a very long header"}, "a.csv", 0),
+		models.NewDataEntry(map[string]string{"Front": "short", "Back": "much too long"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "fine", "Back": "fine"}, "a.csv", 3),
+	}
+
+	oversize := models.FindOversizeFields(entries, []string{"Front", "Back"}, 5)
+	if len(oversize) != 1 {
+		t.Fatalf("Expected 1 oversize field, got %d", len(oversize))
+	}
+	if got := oversize[0].String(); got != "a.csv:2: column Back is 13 bytes" {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
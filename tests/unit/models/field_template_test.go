@@ -0,0 +1,110 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestFieldTemplate_Apply verifies values are wrapped in the configured snippet
+func TestFieldTemplate_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		value    string
+		want     string
+	}{
+		{
+			name:     "wraps value",
+			template: `<i class="ex">{value}</i>`,
+			value:    "Il pleut.",
+			want:     `<i class="ex">Il pleut.</i>`,
+		},
+		{
+			name:     "empty value stays empty",
+			template: `<i class="ex">{value}</i>`,
+			value:    "",
+			want:     "",
+		},
+		{
+			name:     "repeated placeholder",
+			template: `<span title="{value}">{value}</span>`,
+			value:    "chat",
+			want:     `<span title="chat">chat</span>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := models.NewFieldTemplate("Example", tt.template)
+			if got := template.Apply(tt.value); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFieldTemplate_Validate verifies templates must reference the cell value
+func TestFieldTemplate_Validate(t *testing.T) {
+	if err := models.NewFieldTemplate("Example", "<i>{value}</i>").Validate(); err != nil {
+		t.Errorf("Expected valid template, got error: %v", err)
+	}
+
+	if err := models.NewFieldTemplate("Example", "<i></i>").Validate(); err == nil {
+		t.Error("Expected error for template without placeholder")
+	}
+
+	if err := models.NewFieldTemplate(" ", "<i>{value}</i>").Validate(); err == nil {
+		t.Error("Expected error for template without column name")
+	}
+}
+
+// TestLoadConfig verifies templates are read from a JSON config file
+func TestLoadConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("valid config", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "valid.json")
+		content := `{"templates": {"Example": "<i class=\"ex\">{value}</i>", "Audio": "[sound:{value}]"}}`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		config, err := models.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+
+		templates := config.FieldTemplates()
+		if len(templates) != 2 {
+			t.Fatalf("Expected 2 templates, got %d", len(templates))
+		}
+		if templates[0].Column != "Audio" || templates[1].Column != "Example" {
+			t.Errorf("Expected templates sorted by column, got %q, %q", templates[0].Column, templates[1].Column)
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "invalid.json")
+		if err := os.WriteFile(path, []byte(`{"templates": {"Example": "<i></i>"}}`), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		if _, err := models.LoadConfig(path); err == nil {
+			t.Error("Expected error for template without placeholder")
+		}
+	})
+
+	t.Run("malformed json", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "malformed.json")
+		if err := os.WriteFile(path, []byte(`{"templates": `), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		if _, err := models.LoadConfig(path); err == nil {
+			t.Error("Expected error for malformed JSON")
+		}
+	})
+}
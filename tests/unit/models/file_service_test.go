@@ -0,0 +1,359 @@
+package models_test
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+func TestFileService_CommitMovesTempFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "output.csv")
+	service := models.NewFileService()
+
+	file, err := service.CreateTemp(target)
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	if filepath.Dir(file.Name()) != tmpDir {
+		t.Errorf("temp file %s should be next to the target", file.Name())
+	}
+	if _, err := file.WriteString("content"); err != nil {
+		t.Fatalf("WriteString() error = %v", err)
+	}
+	file.Close()
+
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("target should not exist before Commit")
+	}
+	if err := service.Commit(file.Name(), target); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil || string(data) != "content" {
+		t.Errorf("target content = %q, %v; want %q", data, err, "content")
+	}
+	if len(service.TempFiles()) != 0 {
+		t.Errorf("committed file still tracked: %v", service.TempFiles())
+	}
+	if removed := service.CleanupTempFiles(); removed != 0 {
+		t.Errorf("CleanupTempFiles() removed %d files after commit, want 0", removed)
+	}
+}
+
+func TestFileService_CleanupTempFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	service := models.NewFileService()
+
+	file, err := service.CreateTemp(filepath.Join(tmpDir, "output.csv"))
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	file.Close()
+
+	if removed := service.CleanupTempFiles(); removed != 1 {
+		t.Errorf("CleanupTempFiles() = %d, want 1", removed)
+	}
+	if _, err := os.Stat(file.Name()); !os.IsNotExist(err) {
+		t.Errorf("temp file %s was not removed", file.Name())
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 0 {
+		t.Errorf("directory not empty after cleanup: %v", entries)
+	}
+}
+
+func TestFileService_TempPath(t *testing.T) {
+	path := models.TempPath(filepath.Join("decks", "french.csv"))
+	want := filepath.Join("decks", "ankiprep-french.csv-"+strconv.Itoa(os.Getpid())+".tmp")
+	if path != want {
+		t.Errorf("TempPath() = %q, want %q", path, want)
+	}
+}
+
+func TestFileService_KeepTemp(t *testing.T) {
+	service := models.NewFileService()
+	service.KeepTemp = true
+
+	file, err := service.CreateTemp(filepath.Join(t.TempDir(), "output.csv"))
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	file.Close()
+
+	if removed := service.CleanupTempFiles(); removed != 0 {
+		t.Errorf("CleanupTempFiles() = %d with KeepTemp, want 0", removed)
+	}
+	if _, err := os.Stat(file.Name()); err != nil {
+		t.Errorf("temp file should be kept: %v", err)
+	}
+}
+
+func TestFileService_RemoveStaleTempFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	old := time.Now().Add(-2 * models.StaleTempAge)
+
+	stale := filepath.Join(tmpDir, "ankiprep-old.csv-1.tmp")
+	recent := filepath.Join(tmpDir, "ankiprep-new.csv-2.tmp")
+	unrelated := filepath.Join(tmpDir, "notes.tmp")
+	for _, path := range []string{stale, recent, unrelated} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	for _, path := range []string{stale, unrelated} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	removed, err := models.NewFileService().RemoveStaleTempFiles(tmpDir, models.StaleTempAge)
+	if err != nil {
+		t.Fatalf("RemoveStaleTempFiles() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("RemoveStaleTempFiles() = %d, want 1", removed)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale temp file should be removed")
+	}
+	for _, path := range []string{recent, unrelated} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s should be kept: %v", path, err)
+		}
+	}
+}
+
+func TestFileService_ExtractZip(t *testing.T) {
+	tmpDir := t.TempDir()
+	archive := filepath.Join(tmpDir, "course.zip")
+
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	writer := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"chapter2.csv":               "Front,Back\nchien,dog\n",
+		"part1/words.csv":            "Front,Back\nchat,cat\n",
+		"part2/words.csv":            "Front,Back\noiseau,bird\n",
+		"readme.pdf":                 "%PDF",
+		"__MACOSX/part1/._words.csv": "resource fork",
+		"part1/.hidden.csv":          "x",
+	} {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create() error = %v", err)
+		}
+		entry.Write([]byte(content))
+	}
+	writer.Close()
+	file.Close()
+
+	service := models.NewFileService()
+	files, err := service.ExtractZip(archive, func(name string) bool { return strings.HasSuffix(name, ".csv") })
+	if err != nil {
+		t.Fatalf("ExtractZip() error = %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("ExtractZip() extracted %v, want the three .csv files", files)
+	}
+
+	contents := make(map[string]string)
+	for _, extracted := range files {
+		data, err := os.ReadFile(extracted.Path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		contents[extracted.Name] = string(data)
+		if filepath.Ext(extracted.Path) != ".csv" {
+			t.Errorf("extracted path %s should keep the .csv extension", extracted.Path)
+		}
+	}
+	if contents["part1/words.csv"] != "Front,Back\nchat,cat\n" || contents["part2/words.csv"] != "Front,Back\noiseau,bird\n" {
+		t.Errorf("entries with the same base name should be extracted separately, got %v", contents)
+	}
+
+	service.RemoveExtracted()
+	for _, extracted := range files {
+		if _, err := os.Stat(extracted.Path); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", extracted.Path)
+		}
+	}
+
+	if _, err := service.ExtractZip(filepath.Join(tmpDir, "missing.zip"), func(string) bool { return true }); err == nil {
+		t.Error("ExtractZip() of a missing archive should fail")
+	}
+}
+
+// writeZip creates an archive holding the given entries, in order
+func writeZip(t *testing.T, path string, entries [][2]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer file.Close()
+	writer := zip.NewWriter(file)
+	for _, entry := range entries {
+		w, err := writer.Create(entry[0])
+		if err != nil {
+			t.Fatalf("zip Create() error = %v", err)
+		}
+		w.Write([]byte(entry[1]))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("zip Close() error = %v", err)
+	}
+}
+
+func TestFileService_ExtractZipUnsafe(t *testing.T) {
+	tmpDir := t.TempDir()
+	all := func(string) bool { return true }
+
+	for _, name := range []string{"../../x.csv", `..\..\x.csv`, "part/../../x.csv", "/etc/x.csv"} {
+		archive := filepath.Join(tmpDir, "unsafe.zip")
+		writeZip(t, archive, [][2]string{{name, "Front\nx\n"}})
+		service := models.NewFileService()
+		if _, err := service.ExtractZip(archive, all); err == nil || !strings.Contains(err.Error(), "leaves the archive") {
+			t.Errorf("ExtractZip() of %q error = %v, want the name rejected", name, err)
+		}
+		service.RemoveExtracted()
+	}
+
+	archive := filepath.Join(tmpDir, "windows.zip")
+	writeZip(t, archive, [][2]string{{`part1\words.csv`, "Front\nchat\n"}})
+	service := models.NewFileService()
+	defer service.RemoveExtracted()
+	files, err := service.ExtractZip(archive, all)
+	if err != nil || len(files) != 1 || files[0].Name != "part1/words.csv" || filepath.Base(files[0].Path) != "1-words.csv" {
+		t.Errorf("ExtractZip() = %v, %v, want part1/words.csv extracted as 1-words.csv", files, err)
+	}
+
+	archive = filepath.Join(tmpDir, "bomb.zip")
+	writeZip(t, archive, [][2]string{{"a.csv", strings.Repeat("x", 600)}, {"b.csv", strings.Repeat("x", 600)}})
+	service.MaxExtract = 1000
+	if _, err := service.ExtractZip(archive, all); err == nil || !strings.Contains(err.Error(), "expands to over 1000 bytes") {
+		t.Errorf("ExtractZip() error = %v, want the size limit", err)
+	}
+}
+
+func TestIsReservedFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"CON", true},
+		{"con.csv", true},
+		{"Aux.tsv", true},
+		{"nul", true},
+		{"COM1.csv", true},
+		{"lpt9.txt", true},
+		{"CON .csv", true},
+		{"console.csv", false},
+		{"COM10.csv", false},
+		{"deck.con", false},
+		{"french.csv", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.IsReservedFileName(tt.name); got != tt.want {
+				t.Errorf("IsReservedFileName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileService_VerifyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.csv")
+	if err := os.WriteFile(path, []byte("chat,cat\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	service := models.NewFileService()
+	digest := sha256.Sum256([]byte("chat,cat\n"))
+
+	if err := service.VerifyFile(path, 9, digest[:]); err != nil {
+		t.Errorf("VerifyFile() error = %v", err)
+	}
+	if err := service.VerifyFile(path, 12, digest[:]); err == nil || !strings.Contains(err.Error(), "wrote 12 bytes but read back 9") {
+		t.Errorf("VerifyFile() with a truncated file error = %v", err)
+	}
+	other := sha256.Sum256([]byte("chien,dog"))
+	if err := service.VerifyFile(path, 9, other[:]); err == nil || !strings.Contains(err.Error(), "differs") {
+		t.Errorf("VerifyFile() with different content error = %v", err)
+	}
+}
+
+func TestFileSink_Retry(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "output.csv")
+	entries := []*models.DataEntry{models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat"}, "a.csv", 2)}
+
+	// An empty directory in the way makes the first commit fail; it is gone
+	// by the time the write is tried again
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	service := models.NewFileService()
+	service.UseNetworkSettings()
+	waits := 0
+	service.Retry.Sleep = func(time.Duration) {
+		waits++
+		os.Remove(target)
+	}
+
+	sink := &models.FileSink{Path: target, Format: models.AnkiFormat(models.SeparatorComma), Files: service}
+	if err := sink.Write(context.Background(), entries, []string{"Front", "Back"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if waits != 1 {
+		t.Errorf("Write() waited %d times, want 1", waits)
+	}
+	if data, _ := os.ReadFile(target); !strings.HasSuffix(string(data), "chat,cat\n") {
+		t.Errorf("output = %q", data)
+	}
+	if temps := service.TempFiles(); len(temps) != 0 {
+		t.Errorf("TempFiles() = %v, want none", temps)
+	}
+
+	// Errors of the format itself are not retried
+	calls := 0
+	sink.Format = func(w io.Writer, entries []*models.DataEntry, headers []string) error {
+		calls++
+		return errors.New("invalid column")
+	}
+	if err := sink.Write(context.Background(), entries, []string{"Front", "Back"}); err == nil || err.Error() != "invalid column" {
+		t.Errorf("Write() error = %v, want invalid column", err)
+	}
+	if calls != 1 {
+		t.Errorf("format called %d times, want 1", calls)
+	}
+}
+
+func TestFileSink_WriteCancelled(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.csv")
+	entries := []*models.DataEntry{models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat"}, "in.csv", 2)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sink := &models.FileSink{Path: target, Format: models.AnkiFormat(models.SeparatorComma), Files: models.NewFileService()}
+	if err := sink.Write(ctx, entries, []string{"Front", "Back"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Write() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("Expected no output after a cancelled write, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestLoadFrequencyList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fr_50k.txt")
+	content := "# OpenSubtitles\nde 2000\nLe 1500\n\nle 1400\nchat 30\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create wordlist: %v", err)
+	}
+
+	list, err := models.LoadFrequencyList(path)
+	if err != nil {
+		t.Fatalf("LoadFrequencyList failed: %v", err)
+	}
+	if list.Size() != 3 {
+		t.Errorf("Expected 3 ranked words, got %d", list.Size())
+	}
+	if rank, ok := list.Rank(" <b>Chat</b> "); !ok || rank != 3 {
+		t.Errorf("Expected chat at rank 3, got %d (%v)", rank, ok)
+	}
+	if _, ok := list.Rank("chien"); ok {
+		t.Error("Expected chien to be unranked")
+	}
+
+	// Values are ranked as they were before typography
+	list.Add("c'est")
+	if rank, ok := list.Rank("C\u2019est"); !ok || rank != 4 {
+		t.Errorf("Expected c\u2019est at rank 4, got %d (%v)", rank, ok)
+	}
+
+	if _, err := models.LoadFrequencyList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected error for missing wordlist")
+	}
+}
+
+func TestSortByFrequency(t *testing.T) {
+	list := models.NewFrequencyList()
+	for _, word := range []string{"être", "avoir", "chat"} {
+		list.Add(word)
+	}
+
+	header := models.NewDataEntry(map[string]string{"Word": "Word"}, "in.csv", 0)
+	entries := []*models.DataEntry{header}
+	for i, word := range []string{"hibou", "chat", "zèbre", "être", "avoir"} {
+		entries = append(entries, models.NewDataEntry(map[string]string{"Word": word}, "in.csv", i+2))
+	}
+
+	unknown := models.SortByFrequency(entries, "Word", list)
+	if unknown != 2 {
+		t.Errorf("Expected 2 unknown words, got %d", unknown)
+	}
+
+	expected := []string{"Word", "être", "avoir", "chat", "hibou", "zèbre"}
+	for i, entry := range entries {
+		if entry.GetValue("Word") != expected[i] {
+			t.Errorf("Position %d: expected %q, got %q", i, expected[i], entry.GetValue("Word"))
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"ankiprep/internal/models"
+)
+
+// The fuzz targets below run their seed corpus with go test; explore further with
+//   go test ./tests/unit/models -run '^$' -fuzz FuzzParseCSV -fuzztime 1m
+
+// FuzzParseCSV checks that no input, however malformed, makes the parser panic
+// or hand out a record without its line
+func FuzzParseCSV(f *testing.F) {
+	seeds := []string{
+		"Front,Back\nchat,cat\n",
+		"Front,Back\n\"multi\nline\",\"with \"\"quotes\"\"\"\n",
+		"\uFEFFFront\tBack\nchat\tcat",
+		"Front,Back\n\"unterminated,cat\n",
+		"Front,Back\nbare \"quote\" here,x\n",
+		"a,b\r\nc,d\r\n",
+		"\xff\xfe,\x80\n\xc3\x28,ok\n",
+		",,,\n,,\n,\n",
+		"",
+	}
+	for _, seed := range seeds {
+		f.Add(seed, byte(','), true, false)
+	}
+	f.Add("a\tb\n\"c\td\n", byte('\t'), false, true)
+
+	f.Fuzz(func(t *testing.T, data string, separator byte, lazyQuotes, noHeader bool) {
+		inputFile := models.NewInputFile("fuzz.csv")
+		inputFile.Separator = ','
+		if separator == '\t' || separator == ';' {
+			inputFile.Separator = rune(separator)
+		}
+
+		parser := models.NewCSVParser()
+		parser.LazyQuotes = lazyQuotes
+		if noHeader {
+			parser.Header = models.HeaderAbsent
+		}
+
+		lastLine := 0
+		err := parser.Parse(strings.NewReader(data), inputFile, func(record []string, line int) error {
+			if line < 1 || line < lastLine {
+				t.Errorf("Record %q reported at line %d after line %d", record, line, lastLine)
+			}
+			lastLine = line
+			return nil
+		})
+		if err == nil && len(inputFile.Headers) > 0 && strings.HasPrefix(inputFile.Headers[0], "\uFEFF") {
+			t.Errorf("Expected the BOM to be stripped from %q", inputFile.Headers[0])
+		}
+	})
+}
+
+// clozeOpening matches the start of a cloze deletion
+var clozeOpening = regexp.MustCompile(`\{\{c\d+::`)
+
+// FuzzProcessText checks that typography never panics, keeps valid UTF-8
+// valid, never breaks a cloze deletion and gives the same result in chunks,
+// whatever quoting or nesting the text has
+func FuzzProcessText(f *testing.F) {
+	seeds := []string{
+		"Bonjour : comment allez-vous ?",
+		"« Salut » dit-il ; \"c'est moi\" !",
+		"{{c1::Paris}} est la capitale : {{c2::France::pays}} ?",
+		"{{c1::outer {{c2::inner}} text}} : fin",
+		"{{c1::{{c2::{{c3::deep}}}}}}",
+		"{{c1::unclosed : deletion",
+		"\"\"\"\"'''' \"a \"b\" c\"",
+		"`code: \"kept\"` and <code>x ; y</code>",
+		"__CLOZE_PLACEHOLDER_0__ {{c1::a}} __CODE_PLACEHOLDER_0__",
+		"__CLOZE_PLACEHOLDER_0__>{{c0::",
+		"__CLOZE_PLACEHOLDER{{c0::",
+		"\xff\xfe : \xc3\x28 ?",
+		"<p>Il a dit : \"oui\"</p>\n<p>l'homme 'seul' !</p>\n<br>» fin\n{{c1::a\nb}} ; \"c\nd\"",
+		strings.Repeat("\"", 1000) + strings.Repeat("{{c1::", 100),
+	}
+	for _, seed := range seeds {
+		f.Add(seed, true, true)
+	}
+
+	f.Fuzz(func(t *testing.T, text string, french, quotes bool) {
+		processor := models.NewTypographyProcessor(french, quotes)
+		processor.ChunkBytes = 0
+		result := processor.ProcessText(text)
+
+		processor.ChunkBytes = 8
+		if chunked := processor.ProcessText(text); chunked != result {
+			t.Errorf("Expected chunked processing to match, got %q instead of %q from %q", chunked, result, text)
+		}
+
+		if !french && !quotes && result != text {
+			t.Errorf("Expected text to be unchanged with typography off, got %q from %q", result, text)
+		}
+		if utf8.ValidString(text) && !utf8.ValidString(result) {
+			t.Errorf("Expected valid UTF-8, got %q from %q", result, text)
+		}
+		if got, want := len(clozeOpening.FindAllString(result, -1)), len(clozeOpening.FindAllString(text, -1)); got != want {
+			t.Errorf("Expected %d cloze deletions, got %d in %q from %q", want, got, result, text)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package models_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func headerFile(path string, headers ...string) *models.InputFile {
+	inputFile := models.NewInputFile(path)
+	inputFile.Headers = headers
+	return inputFile
+}
+
+func TestSimilarHeaders(t *testing.T) {
+	inputFiles := []*models.InputFile{
+		headerFile("a.csv", "Front", "Back"),
+		headerFile("b.csv", "front", "back ", "Tags"),
+		headerFile("c.csv", "Front", "BACK"),
+	}
+
+	expected := [][]string{{"Front", "front"}, {"Back", "back ", "BACK"}}
+	if got := models.SimilarHeaders(inputFiles); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := models.SimilarHeaders([]*models.InputFile{headerFile("a.csv", "Front", "Back")}); got != nil {
+		t.Errorf("Expected no similar headers, got %q", got)
+	}
+}
+
+func TestCoalesceHeaders(t *testing.T) {
+	inputFiles := []*models.InputFile{
+		headerFile("a.csv", "Front", "Back"),
+		headerFile("b.csv", "front", "back ", "Tags"),
+		headerFile("c.csv", "Back", "back"),
+	}
+	models.CoalesceHeaders(inputFiles)
+
+	if !reflect.DeepEqual(inputFiles[1].Headers, []string{"Front", "Back", "Tags"}) {
+		t.Errorf("Unexpected headers %q", inputFiles[1].Headers)
+	}
+	// Two columns of one file are never merged
+	if !reflect.DeepEqual(inputFiles[2].Headers, []string{"Back", "back"}) {
+		t.Errorf("Unexpected headers %q", inputFiles[2].Headers)
+	}
+	if got := models.MergeHeaders(inputFiles[:2]); !reflect.DeepEqual(got, []string{"Front", "Back", "Tags"}) {
+		t.Errorf("Unexpected merged headers %q", got)
+	}
+}
+
+func TestApplyHeaderAliases(t *testing.T) {
+	inputFiles := []*models.InputFile{
+		headerFile("a.csv", "Recto", "verso "),
+		headerFile("b.csv", "Frente", "Verso", "Etiquetas"),
+		headerFile("c.csv", "表", "裏"),
+		headerFile("d.csv", "Front", "Recto"),
+	}
+
+	renamed := models.ApplyHeaderAliases(inputFiles)
+	if expected := []string{"Recto", "verso ", "Frente", "Verso", "Etiquetas", "表", "裏"}; !reflect.DeepEqual(renamed, expected) {
+		t.Errorf("Expected renamed headers %q, got %q", expected, renamed)
+	}
+	if got := models.MergeHeaders(inputFiles[:3]); !reflect.DeepEqual(got, []string{"Front", "Back", "Tags"}) {
+		t.Errorf("Unexpected merged headers %q", got)
+	}
+	// A file with a Front column keeps its Recto column
+	if !reflect.DeepEqual(inputFiles[3].Headers, []string{"Front", "Recto"}) {
+		t.Errorf("Unexpected headers %q", inputFiles[3].Headers)
+	}
+
+	for _, header := range []string{"Notes", "Avant", "Retro", "Dorso"} {
+		if _, ok := models.HeaderAlias(header); ok {
+			t.Errorf("Expected no alias for %s", header)
+		}
+	}
+}
+
+func TestSanitizeHeader(t *testing.T) {
+	tests := map[string]string{
+		"Back":            "Back",
+		"Part, of speech": "Part, of speech",
+		"Notes\r\nextra":  "Notes extra",
+		"a\tb":            "a\tb",
+		"#Word":           "Word",
+		"Word #2":         "Word #2",
+	}
+	for header, want := range tests {
+		if got := models.SanitizeHeader(header); got != want {
+			t.Errorf("SanitizeHeader(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestWriteAnki_SanitizedColumns(t *testing.T) {
+	headers := []string{"#Word", "Part of\nspeech"}
+	entry := models.NewDataEntry(map[string]string{"#Word": "chat", "Part of\nspeech": "noun"}, "in.csv", 2)
+
+	var buf bytes.Buffer
+	if err := models.WriteAnki(&buf, headers, []*models.DataEntry{entry}, models.SeparatorComma); err != nil {
+		t.Fatalf("WriteAnki failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "#columns:Word,Part of speech\nchat,noun\n") {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,90 @@
+package models_test
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+func heartbeatEntries(n int) []*models.DataEntry {
+	entries := make([]*models.DataEntry, n)
+	for i := range entries {
+		entries[i] = models.NewDataEntry(map[string]string{"Front": "chat"}, "a.csv", i+2)
+	}
+	return entries
+}
+
+func TestHeartbeat(t *testing.T) {
+	var out bytes.Buffer
+	heartbeat := models.NewHeartbeat(&out, 10*time.Millisecond)
+
+	var seen int
+	err := heartbeat.Each("typography processing", heartbeatEntries(3000), func(chunk []*models.DataEntry) error {
+		seen += len(chunk)
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each failed: %v", err)
+	}
+	if seen != 3000 {
+		t.Errorf("Expected every entry to be processed, got %d", seen)
+	}
+
+	line := regexp.MustCompile(`^still working: typography processing, \d+% \(\d+s elapsed\)$`)
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) < 2 {
+		t.Fatalf("Expected several heartbeat lines, got %q", out.String())
+	}
+	for _, l := range lines {
+		if !line.Match(l) {
+			t.Errorf("Unexpected heartbeat line %q", l)
+		}
+	}
+}
+
+func TestHeartbeat_Quiet(t *testing.T) {
+	// Stages finishing within one interval print nothing
+	var out bytes.Buffer
+	heartbeat := models.NewHeartbeat(&out, time.Hour)
+	if err := heartbeat.Each("duplicate removal", heartbeatEntries(2500), func([]*models.DataEntry) error { return nil }); err != nil {
+		t.Fatalf("Each failed: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no output, got %q", out.String())
+	}
+
+	// A zero interval turns the heartbeat off but still runs the stage, whole
+	if models.NewHeartbeat(&out, 0) != nil {
+		t.Fatal("Expected nil heartbeat for a zero interval")
+	}
+	var off *models.Heartbeat
+	calls := 0
+	off.Each("duplicate removal", heartbeatEntries(2500), func(chunk []*models.DataEntry) error {
+		calls++
+		if len(chunk) != 2500 {
+			t.Errorf("Expected one chunk of every entry, got %d", len(chunk))
+		}
+		return nil
+	})
+	if calls != 1 {
+		t.Errorf("Expected one call, got %d", calls)
+	}
+}
+
+func TestHeartbeat_Error(t *testing.T) {
+	heartbeat := models.NewHeartbeat(&bytes.Buffer{}, time.Hour)
+	failure := errors.New("cancelled")
+	calls := 0
+	err := heartbeat.Each("typography processing", heartbeatEntries(2500), func([]*models.DataEntry) error {
+		calls++
+		return failure
+	})
+	if err != failure || calls != 1 {
+		t.Errorf("Expected to stop at the first error, got %v after %d call(s)", err, calls)
+	}
+}
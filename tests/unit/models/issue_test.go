@@ -0,0 +1,40 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestIssue_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue models.Issue
+		want  string
+	}{
+		{"message only", models.Issue{Message: "columns differ"}, "columns differ"},
+		{"file", models.Issue{File: "vocab.csv", Message: "bad separator"}, "vocab.csv: bad separator"},
+		{"line", models.Issue{File: "vocab.csv", Line: 3, Message: "typo"}, "vocab.csv:3: typo"},
+		{"column", models.Issue{File: "vocab.csv", Line: 3, Column: 7, Message: "bare quote"}, "vocab.csv:3:7: bare quote"},
+		{"column without line", models.Issue{File: "vocab.csv", Column: 7, Message: "bare quote"}, "vocab.csv: bare quote"},
+		{"suggestion", models.Issue{File: "vocab.csv", Line: 2, Message: "embeds images", Suggestion: "use --data-uris strip"}, "vocab.csv:2: embeds images; use --data-uris strip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.issue.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIssue(t *testing.T) {
+	issue := models.NewIssue(models.IssueSchema, "vocab.csv", 4, "required column \"Back\" is empty")
+	if issue.Severity != models.SeverityWarning || issue.Code != models.IssueSchema {
+		t.Errorf("Unexpected issue %+v", issue)
+	}
+	if issue.Location() != "vocab.csv:4" {
+		t.Errorf("Expected location vocab.csv:4, got %q", issue.Location())
+	}
+}
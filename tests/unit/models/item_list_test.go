@@ -0,0 +1,88 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestItemList_Dedupe verifies repeated items are removed from a cell
+func TestItemList_Dedupe(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter string
+		value     string
+		want      string
+	}{
+		{"repeated synonym", ";", "dog; dog; hound", "dog; hound"},
+		{"keeps first occurrence order", ",", "hound, dog, hound", "hound, dog"},
+		{"differs only by spacing", ";", "dog ;dog; hound", "dog ;hound"},
+		{"drops empty items", ";", "dog;;hound", "dog;hound"},
+		{"multi-character delimiter", " / ", "chien / chien / toutou", "chien / toutou"},
+		{"no repeats unchanged", ";", "dog ;  hound", "dog ;  hound"},
+		{"single item unchanged", ";", " dog ", " dog "},
+		{"case matters", ";", "Dog; dog", "Dog; dog"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := models.NewItemList("Synonyms", tt.delimiter)
+			if got := list.Dedupe(tt.value); got != tt.want {
+				t.Errorf("Dedupe(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestItemList_Merge verifies missing items are appended in order
+func TestItemList_Merge(t *testing.T) {
+	tests := []struct {
+		name         string
+		delimiter    string
+		value, other string
+		want         string
+	}{
+		{"adds missing items", "<br>", "Il pleut.<br>Il neige.", "Il neige.<br>Il vente.", "Il pleut.<br>Il neige.<br>Il vente."},
+		{"nothing new unchanged", "<br>", "Il pleut.", " Il pleut. ", "Il pleut."},
+		{"fills empty value", "<br>", "", "Il pleut.<br><br>Il neige.", "Il pleut.<br>Il neige."},
+		{"keeps delimiter spacing", ";", "dog; hound", "cur", "dog; hound; cur"},
+		{"single item uses delimiter", ";", "dog", "hound;dog", "dog;hound"},
+		{"case matters", ";", "Dog", "dog", "Dog;dog"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := models.NewItemList("Examples", tt.delimiter)
+			if got := list.Merge(tt.value, tt.other); got != tt.want {
+				t.Errorf("Merge(%q, %q) = %q, want %q", tt.value, tt.other, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDedupeItems verifies only configured columns of data rows are cleaned
+func TestDedupeItems(t *testing.T) {
+	header := models.NewDataEntry(map[string]string{"Synonyms": "a; a"}, "in.csv", 0)
+	entry := models.NewDataEntry(map[string]string{"Synonyms": "dog; dog", "Notes": "x; x"}, "in.csv", 2)
+
+	changed := models.DedupeItems([]*models.DataEntry{header, entry}, []*models.ItemList{models.NewItemList("Synonyms", ";")})
+	if changed != 1 {
+		t.Errorf("Expected 1 cell changed, got %d", changed)
+	}
+	if entry.GetValue("Synonyms") != "dog" || entry.GetValue("Notes") != "x; x" || header.GetValue("Synonyms") != "a; a" {
+		t.Errorf("Unexpected values: %q, %q", entry.Values, header.Values)
+	}
+}
+
+// TestItemList_Validate verifies item lists need a column and a delimiter
+func TestItemList_Validate(t *testing.T) {
+	if err := models.NewItemList("Synonyms", ";").Validate(); err != nil {
+		t.Errorf("Expected valid item list, got error: %v", err)
+	}
+	if err := models.NewItemList("Synonyms", " ").Validate(); err == nil {
+		t.Error("Expected error for whitespace delimiter")
+	}
+	if err := models.NewItemList("", ";").Validate(); err == nil {
+		t.Error("Expected error for empty column name")
+	}
+}
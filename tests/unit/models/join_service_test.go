@@ -0,0 +1,73 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestParseJoinSpec(t *testing.T) {
+	path, key, err := models.ParseJoinSpec("my words on lookup.csv on Word ")
+	if err != nil {
+		t.Fatalf("ParseJoinSpec failed: %v", err)
+	}
+	if path != "my words on lookup.csv" || key != "Word" {
+		t.Errorf("Unexpected path %q and key %q", path, key)
+	}
+
+	for _, spec := range []string{"lookup.csv", "lookup.csv on ", " on Word"} {
+		if _, _, err := models.ParseJoinSpec(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestJoinService_Join(t *testing.T) {
+	lookup := models.NewInputFile("freq.csv")
+	lookup.Headers = []string{"Rank", "Word", "IPA"}
+	lookup.Records = [][]string{{"1", "chat", "ʃa"}, {"2", "chien "}, {"3", "chat", "ʃat"}}
+
+	join, err := models.NewJoinService(lookup, "Word")
+	if err != nil {
+		t.Fatalf("NewJoinService failed: %v", err)
+	}
+	if !reflect.DeepEqual(join.Columns, []string{"Rank", "IPA"}) {
+		t.Errorf("Unexpected columns %q", join.Columns)
+	}
+	headers := join.Headers([]string{"Word", "English", "Rank"})
+	if !reflect.DeepEqual(headers, []string{"Word", "English", "Rank", "IPA"}) {
+		t.Errorf("Unexpected headers %q", headers)
+	}
+
+	header := models.NewDataEntry(map[string]string{"Word": "Word", "English": "English"}, "in.csv", 0)
+	chat := models.NewDataEntry(map[string]string{"Word": "chat", "English": "cat"}, "in.csv", 2)
+	chien := models.NewDataEntry(map[string]string{"Word": "chien", "English": "dog", "Rank": "5"}, "in.csv", 3)
+	oiseau := models.NewDataEntry(map[string]string{"Word": "oiseau", "English": "bird"}, "in.csv", 4)
+	again := models.NewDataEntry(map[string]string{"Word": "oiseau", "English": "bird"}, "in.csv", 5)
+	join.Join([]*models.DataEntry{header, chat, chien, oiseau, again})
+
+	if chat.GetValue("Rank") != "1" || chat.GetValue("IPA") != "ʃa" {
+		t.Errorf("Expected the first lookup row for chat, got %q", chat.Values)
+	}
+	if chien.GetValue("Rank") != "5" || chien.GetValue("IPA") != "" {
+		t.Errorf("Expected existing Rank to be kept, got %q", chien.Values)
+	}
+	if oiseau.GetValue("Rank") != "" {
+		t.Errorf("Expected unmatched entry unchanged, got %q", oiseau.Values)
+	}
+	if header.GetValue("IPA") != "IPA" {
+		t.Errorf("Expected header row to name joined columns, got %q", header.Values)
+	}
+	if join.Matched != 2 || !reflect.DeepEqual(join.Unmatched, []string{"oiseau"}) {
+		t.Errorf("Expected 2 matched and [oiseau] unmatched, got %d and %q", join.Matched, join.Unmatched)
+	}
+}
+
+func TestNewJoinService_MissingKey(t *testing.T) {
+	lookup := models.NewInputFile("freq.csv")
+	lookup.Headers = []string{"Rank", "Lemma"}
+	if _, err := models.NewJoinService(lookup, "Word"); err == nil {
+		t.Error("Expected error for missing key column")
+	}
+}
@@ -0,0 +1,65 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestDetectLanguage verifies the heuristic detector on flashcard-sized text
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "le chat noir", want: models.LanguageFrench},
+		{text: "Il pleut aujourd'hui.", want: models.LanguageFrench},
+		{text: "l'homme", want: models.LanguageFrench},
+		{text: "<b>{{c1::la}}</b> maison", want: models.LanguageFrench},
+		{text: "the black cat", want: models.LanguageEnglish},
+		{text: "It's raining today.", want: models.LanguageEnglish},
+		{text: "ねこ", want: models.LanguageJapanese},
+		{text: "猫です", want: models.LanguageJapanese},
+		{text: "кошка", want: models.LanguageRussian},
+		{text: "قطة", want: models.LanguageArabic},
+		{text: "table", want: models.LanguageUnknown},
+		{text: "", want: models.LanguageUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := models.DetectLanguage(tt.text); got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDetectSwappedColumns verifies flipped rows are reported per source file
+func TestDetectSwappedColumns(t *testing.T) {
+	rows := []struct {
+		source, front, back string
+	}{
+		{"a.csv", "le chat noir", "the black cat"},
+		{"a.csv", "the house", "la maison"},
+		{"a.csv", "il pleut", "it is raining"},
+		{"a.csv", "maison", "house"},
+		// Second file has the opposite layout, so its rows are not suspect
+		{"b.csv", "the dog", "le chien"},
+		{"b.csv", "the tree", "l'arbre"},
+	}
+
+	var entries []*models.DataEntry
+	for i, row := range rows {
+		entries = append(entries, models.NewDataEntry(
+			map[string]string{"Front": row.front, "Back": row.back}, row.source, i+2))
+	}
+
+	suspects := models.DetectSwappedColumns(entries, "Front", "Back")
+	if len(suspects) != 1 {
+		t.Fatalf("Expected 1 suspect row, got %d", len(suspects))
+	}
+	if suspects[0].GetValue("Front") != "the house" {
+		t.Errorf("Expected swapped row %q, got %q", "the house", suspects[0].GetValue("Front"))
+	}
+}
@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestCheckNoteTypeFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		fields  []string
+		want    []string
+	}{
+		{"match", []string{"Front", "Back", "Tags"}, []string{"Front", "Back"}, nil},
+		{"metadata", []string{"Front", "Deck", "Back", "GUID"}, []string{"Front", "Back"}, nil},
+		{"case-insensitive", []string{"front", "back"}, []string{"Front", "Back"}, nil},
+		{"extra", []string{"Front", "Back", "Notes"}, []string{"Front", "Back"},
+			[]string{`3 column(s) but note type "Basic" has 2 field(s); Anki drops Notes`}},
+		{"missing", []string{"Front"}, []string{"Front", "Back"},
+			[]string{`1 column(s) but note type "Basic" has 2 field(s); Back stay empty`}},
+		{"renamed", []string{"Front", "English"}, []string{"Front", "Back"},
+			[]string{`column 2 is "English" but field 2 of "Basic" is "Back"`}},
+	}
+
+	for _, tt := range tests {
+		got := models.CheckNoteTypeFields("Basic", tt.columns, tt.fields)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestLoadNoteTypes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note-types.json")
+	if err := os.WriteFile(path, []byte(`{"Cloze": ["Text", "Back Extra"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	noteTypes, err := models.LoadNoteTypes(path)
+	if err != nil {
+		t.Fatalf("LoadNoteTypes failed: %v", err)
+	}
+	if fields := noteTypes["Cloze"]; len(fields) != 2 || fields[1] != "Back Extra" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+
+	if err := os.WriteFile(path, []byte(`["Text"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := models.LoadNoteTypes(path); err == nil {
+		t.Error("Expected an error for an invalid file")
+	}
+}
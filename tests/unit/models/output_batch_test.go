@@ -0,0 +1,128 @@
+package models_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+// countingWriter records the size of every write call
+type countingWriter struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestBatchWriter_AnkiRecords(t *testing.T) {
+	var entries []*models.DataEntry
+	for i := 1; i <= 100; i++ {
+		entries = append(entries, models.NewDataEntry(map[string]string{"Front": fmt.Sprintf("word%03d", i), "Back": "meaning"}, "a.csv", i+1))
+	}
+
+	var direct bytes.Buffer
+	if err := models.WriteAnki(&direct, []string{"Front", "Back"}, entries, models.SeparatorComma); err != nil {
+		t.Fatalf("WriteAnki() error = %v", err)
+	}
+
+	out := &countingWriter{}
+	batch := models.NewBatchWriter(out, 500)
+	var stats []models.BatchStats
+	batch.OnFlush = func(s models.BatchStats) { stats = append(stats, s) }
+	if err := models.WriteAnki(batch, []string{"Front", "Back"}, entries, models.SeparatorComma); err != nil {
+		t.Fatalf("WriteAnki() error = %v", err)
+	}
+	if err := batch.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if out.String() != direct.String() {
+		t.Errorf("batched output differs from direct output:\n%s", out.String())
+	}
+	if len(out.writes) != len(stats) || len(stats) < 2 {
+		t.Fatalf("got %d writes and %d batches, want one write per batch and several batches", len(out.writes), len(stats))
+	}
+
+	rows := 0
+	for i, s := range stats {
+		if s.Number != i+1 || s.Bytes != out.writes[i] {
+			t.Errorf("batch %d = %+v, want number %d and %d bytes", i, s, i+1, out.writes[i])
+		}
+		if i < len(stats)-1 && s.Bytes < 500 {
+			t.Errorf("batch %d has %d bytes, want at least 500", s.Number, s.Bytes)
+		}
+		rows += s.Rows
+	}
+	if rows != len(entries) {
+		t.Errorf("batches hold %d rows, want %d", rows, len(entries))
+	}
+
+	// Batches end between records
+	written := 0
+	for _, size := range out.writes[:len(out.writes)-1] {
+		written += size
+		if out.String()[written-1] != '\n' {
+			t.Errorf("batch ending at byte %d splits a record", written)
+		}
+	}
+}
+
+func TestBatchWriter_WithoutRecords(t *testing.T) {
+	out := &countingWriter{}
+	batch := models.NewBatchWriter(out, 10)
+	for i := 0; i < 5; i++ {
+		if _, err := batch.Write([]byte("abcd")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := batch.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if out.String() != strings.Repeat("abcd", 5) {
+		t.Errorf("output = %q", out.String())
+	}
+	if len(out.writes) != 2 || out.writes[0] != 12 || out.writes[1] != 8 {
+		t.Errorf("writes = %v, want [12 8]", out.writes)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestBatchWriter_Error(t *testing.T) {
+	batch := models.NewBatchWriter(failingWriter{}, 4)
+	if _, err := batch.Write([]byte("abcdef")); err == nil {
+		t.Error("Write() filling the batch should fail")
+	}
+	if err := batch.Flush(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Flush() error = %v, want disk full", err)
+	}
+	if _, err := batch.Write([]byte("x")); err == nil {
+		t.Error("Write() after a failed write should fail")
+	}
+}
+
+func TestBatchStats_Rates(t *testing.T) {
+	stats := models.BatchStats{Rows: 500, Bytes: 2_000_000, Duration: 500 * time.Millisecond}
+	if got := stats.RowsPerSecond(); got != 1000 {
+		t.Errorf("RowsPerSecond() = %v, want 1000", got)
+	}
+	if got := stats.MBPerSecond(); got != 4 {
+		t.Errorf("MBPerSecond() = %v, want 4", got)
+	}
+	if got := (models.BatchStats{Rows: 1}).RowsPerSecond(); got != 0 {
+		t.Errorf("RowsPerSecond() without a duration = %v, want 0", got)
+	}
+}
@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestVerifyAnkiOutput(t *testing.T) {
+	headers := []string{"Front", "Back", "Deck", "Tags"}
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat", "Back": "le \"chat\",\nnoir", "Deck": "French", "Tags": "animal"}, "vocab.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien", "Back": "le chien", "Deck": "French"}, "vocab.csv", 3),
+	}
+
+	for _, separator := range []string{models.SeparatorComma, models.SeparatorTab} {
+		var buf bytes.Buffer
+		if err := models.WriteAnki(&buf, headers, entries, separator); err != nil {
+			t.Fatalf("WriteAnki failed: %v", err)
+		}
+		if problems := models.VerifyAnkiOutput(&buf, "out.csv", headers, separator, len(entries)); len(problems) > 0 {
+			t.Errorf("Expected %s output to verify, got %v", separator, problems)
+		}
+	}
+}
+
+func TestVerifyAnkiOutput_Problems(t *testing.T) {
+	headers := []string{"Front", "Back"}
+	tests := []struct {
+		name    string
+		content string
+		rows    int
+		want    string
+	}{
+		{"missing directives", "chat,le chat\n", 1, "#html:true is missing"},
+		{"wrong separator", "#separator:tab\n#html:true\n#columns:Front\tBack\nchat\tle chat\n", 1, "#separator declares"},
+		{"wrong columns", "#separator:comma\n#html:true\n#columns:Front,Verso\nchat,le chat\n", 1, "#columns lists Front, Verso"},
+		{"missing row", "#separator:comma\n#html:true\n#columns:Front,Back\nchat,le chat\n", 2, "1 record(s) read back, 2 written"},
+		{"unquoted separator", "#separator:comma\n#html:true\n#columns:Front,Back\nchat,le chat, noir\n", 1, "line 4: 3 field(s), expected 2"},
+		{"bare quote", "#separator:comma\n#html:true\n#columns:Front,Back\nchat,le \"chat\"\n", 1, "cannot be read back"},
+		{"invalid UTF-8", "#separator:comma\n#html:true\n#columns:Front,Back\nchat,le ch\xe9at\n", 1, "column Back is not valid UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := models.VerifyAnkiOutput(strings.NewReader(tt.content), "out.csv", headers, models.SeparatorComma, tt.rows)
+			if !strings.Contains(strings.Join(problems, "\n"), tt.want) {
+				t.Errorf("Expected a problem containing %q, got %v", tt.want, problems)
+			}
+		})
+	}
+}
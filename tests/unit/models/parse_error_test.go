@@ -0,0 +1,115 @@
+package models_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// strictParse parses content with strict quoting and returns the error
+func strictParse(t *testing.T, path, content string) error {
+	t.Helper()
+	inputFile := models.NewInputFile(path)
+	parser := models.NewCSVParser()
+	parser.LazyQuotes = false
+	return parser.Parse(strings.NewReader(content), inputFile, func([]string, int) error { return nil })
+}
+
+func TestParseError(t *testing.T) {
+	err := strictParse(t, "vocab.csv", "Front,Back\nchat,cat\nétoilé,le \"star\"\n")
+
+	var parseErr *models.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got %v", err)
+	}
+	if !errors.Is(err, csv.ErrBareQuote) {
+		t.Errorf("Expected the error to wrap csv.ErrBareQuote")
+	}
+	// The column counts characters, not bytes
+	if got := err.Error(); got != "vocab.csv:3:11: bare quote in non-quoted field" {
+		t.Errorf("Unexpected message %q", got)
+	}
+	want := "3 | étoilé,le \"star\"\n  |           ^"
+	if got := parseErr.Snippet(); got != want {
+		t.Errorf("Snippet() = %q, want %q", got, want)
+	}
+}
+
+func TestParseError_Snippet(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *models.ParseError
+		want string
+	}{
+		{"tabs line up", &models.ParseError{Line: 12, Column: 6, Text: "a\tb\tc\"d"}, "12 | a\tb\tc\"d\n   |  \t \t ^"},
+		{"past the end", &models.ParseError{Line: 2, Column: 9, Text: "x,y"}, "2 | x,y\n  |    ^"},
+		{"long line", &models.ParseError{Line: 1, Column: 151, Text: strings.Repeat("a", 150) + "\"" + strings.Repeat("b", 150)},
+			"1 | …" + strings.Repeat("a", 49) + "\"" + strings.Repeat("b", 50) + "…\n  |  " + strings.Repeat(" ", 49) + "^"},
+		{"line not kept", &models.ParseError{Line: 2, Column: 9}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Snippet(); got != tt.want {
+				t.Errorf("Snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseError_UnclosedQuote(t *testing.T) {
+	err := strictParse(t, "vocab.csv", "Front,Back\nchat,\"le chat\nnoir\n")
+	if got := err.Error(); got != "vocab.csv:3:5: extraneous or missing quote in quoted field (the record starts on line 2)" {
+		t.Errorf("Unexpected message %q", got)
+	}
+}
+
+func TestParseError_AnkiExport(t *testing.T) {
+	content := "#separator:comma\n#html:true\nchat,cat\nchien,le \"dog\"\n"
+	_, err := models.ParseAnkiExport(strings.NewReader(content), models.NewInputFile("export.txt"), false)
+	if err == nil || err.Error() != "export.txt:4:10: bare quote in non-quoted field" {
+		t.Errorf("Expected the error on line 4 of the file, got %v", err)
+	}
+}
+
+func TestParseFailure(t *testing.T) {
+	parseErr := &models.ParseError{File: "a.csv", Line: 2, Err: csv.ErrQuote}
+	if got := models.ParseFailure("a.csv", parseErr); got != parseErr {
+		t.Errorf("Expected the ParseError unchanged, got %v", got)
+	}
+	if got := models.ParseFailure("a.csv", errors.New("file contains no data")).Error(); got != "cannot parse a.csv: file contains no data" {
+		t.Errorf("Unexpected message %q", got)
+	}
+}
+
+func TestSkippedInputIssue(t *testing.T) {
+	issue := models.SkippedInputIssue(&models.ParseError{File: "a.csv", Line: 2, Column: 5, StartLine: 1, Err: csv.ErrQuote})
+	if issue.Code != models.IssueInputSkipped || issue.File != "a.csv" || issue.Line != 2 || issue.Column != 5 {
+		t.Errorf("Unexpected issue %+v", issue)
+	}
+	if got := issue.String(); got != "a.csv:2:5: extraneous or missing quote in quoted field (the record starts on line 1); skipping it" {
+		t.Errorf("Unexpected message %q", got)
+	}
+
+	issue = models.SkippedInputIssue(errors.New("cannot parse b.csv: file contains no data"))
+	if issue.File != "" || issue.String() != "cannot parse b.csv: file contains no data; skipping it" {
+		t.Errorf("Unexpected issue %+v", issue)
+	}
+}
+
+func TestParseError_LargeFile(t *testing.T) {
+	// The error comes long after the first lines are no longer kept
+	content := "Front,Back\n" + strings.Repeat("chat,the cat sat on the mat\n", 10000) + "chien,le \"dog\"\n"
+	err := strictParse(t, "big.csv", content)
+
+	var parseErr *models.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got %v", err)
+	}
+	if parseErr.Line != 10002 || parseErr.Text != "chien,le \"dog\"" {
+		t.Errorf("Expected line 10002 with its text, got %d %q", parseErr.Line, parseErr.Text)
+	}
+}
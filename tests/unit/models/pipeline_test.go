@@ -0,0 +1,129 @@
+package models_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+func TestSortEntries(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "Front"}, "a.csv", 0),
+		models.NewDataEntry(map[string]string{"Front": "b", "Back": "1"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "a"}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "b", "Back": "2"}, "a.csv", 4),
+	}
+
+	models.SortEntries(entries, "Front")
+
+	var order []int
+	for _, entry := range entries {
+		order = append(order, entry.LineNumber)
+	}
+	expected := []int{0, 3, 2, 4}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("Expected line order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestSortEntriesCollated(t *testing.T) {
+	collator, err := models.NewCollator("fr")
+	if err != nil {
+		t.Fatalf("NewCollator failed: %v", err)
+	}
+
+	var entries []*models.DataEntry
+	for i, value := range []string{"zèbre", "école", "Ecole", "abeille", "ecole", "Été"} {
+		entries = append(entries, models.NewDataEntry(map[string]string{"Front": value}, "a.csv", i+2))
+	}
+	entries = append(entries, models.NewDataEntry(map[string]string{"Front": "Front"}, "a.csv", 0))
+
+	models.SortEntriesCollated(entries, "Front", collator)
+
+	var order []string
+	for _, entry := range entries {
+		order = append(order, entry.GetValue("Front"))
+	}
+	expected := []string{"Front", "abeille", "ecole", "Ecole", "école", "Été", "zèbre"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+
+	for _, tag := range []string{"f r", "xx"} {
+		if _, err := models.NewCollator(tag); err == nil {
+			t.Errorf("Expected error for %q", tag)
+		}
+	}
+}
+
+func TestAnkiWriter_NoEntries(t *testing.T) {
+	var buf strings.Builder
+	writer := models.NewAnkiWriter(&buf, []string{"Front", "Back"}, models.SeparatorTab)
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	expected := "#separator:tab\n#html:true\n#columns:Front\tBack\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPlainFormat(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "Front", "Back": "Back"}, "a.csv", 0),
+		models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat, feline"}, "a.csv", 2),
+	}
+
+	var buf strings.Builder
+	if err := models.PlainFormat(models.SeparatorComma)(&buf, entries, []string{"Front", "Back"}); err != nil {
+		t.Fatalf("PlainFormat failed: %v", err)
+	}
+	expected := "Front,Back\nchat,\"cat, feline\"\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestApplyTypographyLimit(t *testing.T) {
+	pathological := strings.Repeat("{{c1::", 200000)
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "chat :", "Back": pathological}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien !", "Back": "dog"}, "a.csv", 3),
+	}
+
+	slow, err := models.ApplyTypographyLimit(context.Background(), entries, nil, true, false, false, false, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("ApplyTypographyLimit failed: %v", err)
+	}
+	if len(slow) != 1 || slow[0].Entry != entries[0] || slow[0].Column != "Back" || slow[0].Size != len(pathological) {
+		t.Fatalf("Expected only line 2 column Back to be too slow, got %+v", slow)
+	}
+	if got := entries[0].GetValue("Back"); got != pathological {
+		t.Errorf("Expected the slow cell to be left unchanged")
+	}
+	if got := entries[0].GetValue("Front"); got != "chat\u202f:" {
+		t.Errorf("Expected the other cells to be formatted, got %q", got)
+	}
+	if got := entries[1].GetValue("Front"); got != "chien\u202f!" {
+		t.Errorf("Expected the other entries to be formatted, got %q", got)
+	}
+}
+
+func TestApplyTypographyLimit_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	entries := []*models.DataEntry{models.NewDataEntry(map[string]string{"Front": "chat :"}, "a.csv", 2)}
+	if _, err := models.ApplyTypographyLimit(ctx, entries, nil, true, false, false, false, time.Second); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if got := entries[0].GetValue("Front"); got != "chat :" {
+		t.Errorf("Expected a cancelled run to leave cells unchanged, got %q", got)
+	}
+}
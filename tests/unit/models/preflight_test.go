@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"fmt"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestEstimatePreflight(t *testing.T) {
+	columns := []string{"Front", "Back", "Notes"}
+
+	t.Run("small input is counted exactly", func(t *testing.T) {
+		entries := []*models.DataEntry{
+			models.NewDataEntry(map[string]string{"Front": "Front", "Back": "Back"}, "a.csv", 0),
+			models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat"}, "a.csv", 2),
+			models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat"}, "b.csv", 2),
+			models.NewDataEntry(map[string]string{"Front": "chien", "Back": " ", "Notes": "x"}, "b.csv", 3),
+		}
+
+		preflight := models.EstimatePreflight(entries, columns, models.ExactHasher{}, 10)
+		if preflight.Entries != 3 || preflight.Sampled != 3 || preflight.Duplicates != 1 {
+			t.Errorf("Expected 3 entries, all sampled, 1 duplicate, got %+v", preflight)
+		}
+		if got := preflight.EmptyRates["Back"]; got < 0.33 || got > 0.34 {
+			t.Errorf("Expected Back to be 1/3 empty, got %v", got)
+		}
+		if got := preflight.EmptyRates["Notes"]; got < 0.66 || got > 0.67 {
+			t.Errorf("Expected Notes to be 2/3 empty, got %v", got)
+		}
+	})
+
+	t.Run("large input is estimated from a sample", func(t *testing.T) {
+		// The same 20000 notes merged twice: half the entries are duplicates
+		var entries []*models.DataEntry
+		for copy := 0; copy < 2; copy++ {
+			for i := 0; i < 20000; i++ {
+				values := map[string]string{"Front": fmt.Sprintf("word%d", i), "Back": "meaning"}
+				entries = append(entries, models.NewDataEntry(values, "a.csv", i+2))
+			}
+		}
+
+		preflight := models.EstimatePreflight(entries, columns, models.ExactHasher{}, 4000)
+		if preflight.Entries != 40000 || preflight.Sampled != 4000 {
+			t.Fatalf("Expected a sample of 4000 of 40000 entries, got %+v", preflight)
+		}
+		if rate := preflight.DuplicateRate(); rate < 0.3 || rate > 0.7 {
+			t.Errorf("Expected a duplicate rate near 50%%, got %.0f%%", 100*rate)
+		}
+		if got := preflight.EmptyRates["Notes"]; got != 1 {
+			t.Errorf("Expected Notes to be empty, got %v", got)
+		}
+
+		again := models.EstimatePreflight(entries, columns, models.ExactHasher{}, 4000)
+		if again.Duplicates != preflight.Duplicates {
+			t.Errorf("Expected the same estimate for the same input, got %d and %d", preflight.Duplicates, again.Duplicates)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestBuiltinProfiles(t *testing.T) {
+	profiles, err := models.BuiltinProfiles()
+	if err != nil {
+		t.Fatalf("BuiltinProfiles() error = %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, profile := range profiles {
+		names[profile.Name] = true
+		if profile.Description == "" {
+			t.Errorf("profile %s has no description", profile.Name)
+		}
+		if len(profile.Flags) == 0 && profile.Schema == nil {
+			t.Errorf("profile %s sets nothing", profile.Name)
+		}
+	}
+	for _, name := range []string{"french-vocab", "cloze-grammar"} {
+		if !names[name] {
+			t.Errorf("Expected a built-in %s profile, got %v", name, names)
+		}
+	}
+}
+
+func TestLookupProfile(t *testing.T) {
+	profile, err := models.LookupProfile("french-vocab")
+	if err != nil {
+		t.Fatalf("LookupProfile() error = %v", err)
+	}
+	if profile.Flags["skip-duplicates"] != "true" || strings.Join(profile.FlagNames(), ",") != "dedupe-strategy,skip-duplicates" {
+		t.Errorf("LookupProfile() flags = %v", profile.Flags)
+	}
+
+	if _, err := models.LookupProfile("german-vocab"); err == nil || !strings.Contains(err.Error(), "available: ") {
+		t.Errorf("LookupProfile() error = %v, want the available profiles listed", err)
+	}
+}
@@ -0,0 +1,68 @@
+package models_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+func TestProgressReporter_Printf(t *testing.T) {
+	var out bytes.Buffer
+	progress := models.NewProgressReporter(&out)
+	progress.Printf("Processing %d input file(s)...", 2)
+	progress.Printf("Writing output to %s\n", "out.csv")
+
+	expected := "Processing 2 input file(s)...\nWriting output to out.csv\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestProgressReporter_Concurrent(t *testing.T) {
+	var out bytes.Buffer
+	progress := models.NewProgressReporter(&out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				progress.Printf("worker line %s", strings.Repeat("x", 40))
+				progress.Add("typography", 2, time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 1000 {
+		t.Fatalf("Expected 1000 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if line != "worker line "+strings.Repeat("x", 40) {
+			t.Fatalf("Interleaved line: %q", line)
+		}
+	}
+
+	stages := progress.Stages()
+	if len(stages) != 1 || stages[0].Items != 2000 || stages[0].Duration != time.Second {
+		t.Errorf("Expected typography stage with 2000 items in 1s, got %+v", stages)
+	}
+}
+
+func TestProgressReporter_Stages(t *testing.T) {
+	progress := models.NewProgressReporter(nil)
+	progress.Add("parsing", 10, time.Second)
+	progress.Add("typography", 8, time.Second)
+	progress.Add("parsing", 5, time.Second)
+
+	stages := progress.Stages()
+	if len(stages) != 2 || stages[0].Name != "parsing" || stages[0].Items != 15 || stages[1].Name != "typography" {
+		t.Errorf("Unexpected stages: %+v", stages)
+	}
+}
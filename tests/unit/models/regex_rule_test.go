@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestRegexRule_Apply verifies sed-style rules change values like sed would
+func TestRegexRule_Apply(t *testing.T) {
+	tests := []struct {
+		rule  string
+		value string
+		want  string
+	}{
+		{`Back:s/\s+$//`, "cat  \t", "cat"},
+		{`Back:s/a/o/`, "banana", "bonana"},
+		{`Back:s/a/o/g`, "banana", "bonono"},
+		{`Back:s/CAT/dog/i`, "Cat food", "dog food"},
+		{`Back:s/(\w+), (\w+)/\2 \1/`, "run, to", "to run"},
+		{`Back:s/(\w+)-(\w+)/$2$1/g`, "a-b c-d", "ba dc"},
+		{`Back:s|/|, |g`, "cat/feline", "cat, feline"},
+		{`Back:s/\//-/`, "and/or", "and-or"},
+		{`Back:s/^(to )?//`, "to run", "run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := models.ParseRegexRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRegexRule failed: %v", err)
+			}
+			if got := rule.Apply(tt.value); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseRegexRule_Errors verifies malformed rules are rejected
+func TestParseRegexRule_Errors(t *testing.T) {
+	for _, rule := range []string{
+		`s/a/b/`,        // no column
+		`Back:/a/b/`,    // no s command
+		`Back:s/a/b`,    // missing final delimiter
+		`Back:s/a/b/c/`, // too many parts
+		`Back:s/(/b/`,   // does not compile
+		`Back:s/a/b/x`,  // unknown flag
+		`Back:s\a\b\`,   // backslash delimiter
+	} {
+		if _, err := models.ParseRegexRule(rule); err == nil {
+			t.Errorf("Expected error for %q", rule)
+		}
+	}
+}
+
+// TestApplyRegexRules verifies rules apply in order to their columns only
+func TestApplyRegexRules(t *testing.T) {
+	var rules []*models.RegexRule
+	for _, rule := range []string{`*:s/^\s+//`, `Back:s/^to //`, `Back:s/run/walk/`} {
+		parsed, err := models.ParseRegexRule(rule)
+		if err != nil {
+			t.Fatalf("ParseRegexRule failed: %v", err)
+		}
+		rules = append(rules, parsed)
+	}
+
+	header := models.NewDataEntry(map[string]string{"Back": " to Back"}, "in.csv", 0)
+	entry := models.NewDataEntry(map[string]string{"Front": " courir", "Back": " to run", "Notes": "to run"}, "in.csv", 2)
+	changed := models.ApplyRegexRules([]*models.DataEntry{header, entry}, rules)
+
+	if changed != 2 {
+		t.Errorf("Expected 2 cells changed, got %d", changed)
+	}
+	if entry.GetValue("Front") != "courir" || entry.GetValue("Back") != "walk" || entry.GetValue("Notes") != "to run" {
+		t.Errorf("Unexpected values %q", entry.Values)
+	}
+	if header.GetValue("Back") != " to Back" {
+		t.Errorf("Header row changed: %q", header.GetValue("Back"))
+	}
+}
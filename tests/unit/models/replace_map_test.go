@@ -0,0 +1,60 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestLoadReplaceMap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pos.csv")
+	content := "Column,From,To\nPOS,n.,noun\nPOS,v.,verb\n,qqch,quelque chose\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create replace map: %v", err)
+	}
+
+	replaceMap, err := models.LoadReplaceMap(path)
+	if err != nil {
+		t.Fatalf("LoadReplaceMap failed: %v", err)
+	}
+	if replaceMap.Size() != 3 {
+		t.Errorf("Expected 3 substitutions, got %d", replaceMap.Size())
+	}
+
+	header := models.NewDataEntry(map[string]string{"POS": "n."}, "in.csv", 0)
+	entry := models.NewDataEntry(map[string]string{"POS": "n.", "Back": "qqch", "Notes": "n."}, "in.csv", 2)
+	partial := models.NewDataEntry(map[string]string{"POS": "n. pl.", "Back": "faire qqch"}, "in.csv", 3)
+
+	replaced := replaceMap.Apply([]*models.DataEntry{header, entry, partial})
+	if replaced != 2 {
+		t.Errorf("Expected 2 replacements, got %d", replaced)
+	}
+	if entry.GetValue("POS") != "noun" || entry.GetValue("Back") != "quelque chose" || entry.GetValue("Notes") != "n." {
+		t.Errorf("Unexpected values %q", entry.Values)
+	}
+	if partial.GetValue("POS") != "n. pl." || partial.GetValue("Back") != "faire qqch" || header.GetValue("POS") != "n." {
+		t.Error("Expected only exact matches in data rows to be replaced")
+	}
+}
+
+func TestLoadReplaceMap_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"missing columns": "Column,Value\nPOS,n.\n",
+		"repeated value":  "Column,From,To\nPOS,n.,noun\nPOS,n.,name\n",
+		"empty value":     "Column,From,To\nPOS,,noun\n",
+	}
+
+	for name, content := range tests {
+		path := filepath.Join(dir, "map.csv")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create replace map: %v", err)
+		}
+		if _, err := models.LoadReplaceMap(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+func TestRetryPolicy_Do(t *testing.T) {
+	var waits []time.Duration
+	policy := models.NewRetryPolicy(3, time.Second)
+	policy.Jitter = 0
+	policy.Sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	t.Run("retries until success", func(t *testing.T) {
+		waits = nil
+		calls := 0
+		err := policy.Do(func() error {
+			calls++
+			if calls < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("Expected success after 3 calls, got %v after %d", err, calls)
+		}
+		if len(waits) != 2 || waits[0] != time.Second || waits[1] != 2*time.Second {
+			t.Errorf("Expected waits [1s 2s], got %v", waits)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(func() error {
+			calls++
+			return errors.New("timeout")
+		})
+		if err == nil || err.Error() != "timeout" || calls != 3 {
+			t.Errorf("Expected last error after 3 calls, got %v after %d", err, calls)
+		}
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		calls := 0
+		rejected := errors.New("400 Bad Request")
+		err := policy.Do(func() error {
+			calls++
+			return models.Permanent(rejected)
+		})
+		if err != rejected || calls != 1 {
+			t.Errorf("Expected the unwrapped error after 1 call, got %v after %d", err, calls)
+		}
+	})
+
+	t.Run("nil policy tries once", func(t *testing.T) {
+		var none *models.RetryPolicy
+		calls := 0
+		none.Do(func() error {
+			calls++
+			return errors.New("timeout")
+		})
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+	})
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := &models.RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.Delay(i + 1); got != want {
+			t.Errorf("Delay(%d) = %v, expected %v", i+1, got, want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Delay(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("Delay with 50%% jitter out of range: %v", got)
+		}
+	}
+}
+
+func TestNetworkRetryPolicy(t *testing.T) {
+	network, standard := models.NetworkRetryPolicy(), models.DefaultRetryPolicy()
+	if network.MaxAttempts <= standard.MaxAttempts || network.Backoff <= standard.Backoff || network.MaxBackoff < standard.MaxBackoff {
+		t.Errorf("NetworkRetryPolicy() = %+v, want more attempts and longer waits than %+v", network, standard)
+	}
+}
@@ -0,0 +1,77 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestParseRowExplode(t *testing.T) {
+	explode, err := models.ParseRowExplode("Synonyms|;")
+	if err != nil || explode.Column != "Synonyms" || explode.Delimiter != ";" {
+		t.Errorf("ParseRowExplode() = %+v, %v", explode, err)
+	}
+	if explode, err := models.ParseRowExplode("Synonyms||"); err != nil || explode.Delimiter != "|" {
+		t.Errorf("ParseRowExplode() with a | delimiter = %+v, %v", explode, err)
+	}
+
+	for _, spec := range []string{"Synonyms", "|;", "Synonyms|", "Synonyms| "} {
+		if _, err := models.ParseRowExplode(spec); err == nil {
+			t.Errorf("ParseRowExplode(%q) expected an error", spec)
+		}
+	}
+}
+
+func TestRowExplode_Apply(t *testing.T) {
+	explode := &models.RowExplode{Column: "Synonyms", Delimiter: ";"}
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Word": "Word", "Synonyms": "Synonyms"}, "vocab.csv", 0),
+		models.NewDataEntry(map[string]string{"Word": "chat", "Synonyms": "matou; minou;;"}, "vocab.csv", 2),
+		models.NewDataEntry(map[string]string{"Word": "chien", "Synonyms": ""}, "vocab.csv", 3),
+	}
+
+	exploded := explode.Apply(entries)
+	want := []struct {
+		word, synonym string
+		line          int
+	}{
+		{"Word", "Synonyms", 0},
+		{"chat", "matou", 2},
+		{"chat", "minou", 2},
+		{"chien", "", 3},
+	}
+	if len(exploded) != len(want) {
+		t.Fatalf("Apply() returned %d entries, want %d", len(exploded), len(want))
+	}
+	for i, w := range want {
+		entry := exploded[i]
+		if entry.GetValue("Word") != w.word || entry.GetValue("Synonyms") != w.synonym || entry.LineNumber != w.line {
+			t.Errorf("entry %d = %v (line %d), want %s/%s (line %d)", i, entry.Values, entry.LineNumber, w.word, w.synonym, w.line)
+		}
+	}
+
+	// Copies must not share their values
+	exploded[1].SetValue("Word", "changed")
+	if exploded[2].GetValue("Word") != "chat" {
+		t.Error("Expected exploded rows to have their own values")
+	}
+}
+
+func TestRowExplode_ApplyTrimsAndSplitsGUIDs(t *testing.T) {
+	explode := &models.RowExplode{Column: "Synonyms", Delimiter: ";"}
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"GUID": "a1", "Synonyms": " cat; "}, "vocab.csv", 2),
+		models.NewDataEntry(map[string]string{"GUID": "b2", "Synonyms": "matou;minou"}, "vocab.csv", 3),
+	}
+
+	exploded := explode.Apply(entries)
+	want := []struct{ guid, synonym string }{{"a1", "cat"}, {"b2-1", "matou"}, {"b2-2", "minou"}}
+	if len(exploded) != len(want) {
+		t.Fatalf("Apply() returned %d entries, want %d", len(exploded), len(want))
+	}
+	for i, w := range want {
+		if got := exploded[i]; got.GetValue("GUID") != w.guid || got.GetValue("Synonyms") != w.synonym {
+			t.Errorf("entry %d = %v, want GUID %s and synonym %s", i, got.Values, w.guid, w.synonym)
+		}
+	}
+}
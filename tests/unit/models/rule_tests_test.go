@@ -0,0 +1,71 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestLoadRuleTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+
+	path := write("rules_test.yaml", `- name: space before a colon
+  flags: [french]
+  input: "Bonjour : monde"
+  expected: "Bonjour\u202F: monde"
+
+- column: English
+  flags: [french]
+  input: "Hello : world"
+  expected: "Hello : world"
+
+- name: wrong expectation
+  flags: [smart-quotes]
+  input: 'a "word"'
+  expected: 'a "word"'
+
+- name: no space before fullwidth punctuation
+  flags: [cjk-spacing]
+  input: "你好 ，世界"
+  expected: "你好，世界"
+`)
+	tests, err := models.LoadRuleTests(path)
+	if err != nil {
+		t.Fatalf("LoadRuleTests() error = %v", err)
+	}
+	if len(tests) != 4 {
+		t.Fatalf("LoadRuleTests() returned %d cases, want 4", len(tests))
+	}
+	if tests[1].Name != "line 6" || tests[2].Line != 11 {
+		t.Errorf("LoadRuleTests() names/lines = %q/%d, want \"line 6\"/11", tests[1].Name, tests[2].Line)
+	}
+
+	for i, want := range []bool{true, true, false, true} {
+		if got, ok := tests[i].Run(); ok != want {
+			t.Errorf("case %q Run() = %q, %v; want ok = %v", tests[i].Name, got, ok, want)
+		}
+	}
+
+	invalid := map[string]string{
+		"has no expected value": "- input: x\n",
+		"unknown flag":          "- flags: [italian]\n  input: x\n  expected: x\n",
+		"expected a list":       "input: x\n",
+		"field flag not found":  "- flag: [french]\n  input: x\n  expected: x\n",
+	}
+	for want, content := range invalid {
+		_, err := models.LoadRuleTests(write("invalid.yaml", content))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadRuleTests(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
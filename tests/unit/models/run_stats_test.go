@@ -0,0 +1,69 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ankiprep/internal/models"
+)
+
+func TestRunStats_Metrics(t *testing.T) {
+	report := models.NewProcessingReport()
+	report.AddInputFile("vocab.csv")
+	report.SetCounts(200, 50, 150)
+	report.SetProcessingTime(2 * time.Second)
+
+	stats := models.NewRunStats(report, time.Now())
+	if got := stats.RowsPerSecond(); got != 100 {
+		t.Errorf("RowsPerSecond() = %v, want 100", got)
+	}
+	if got := stats.DedupeRate(); got != 25 {
+		t.Errorf("DedupeRate() = %v, want 25", got)
+	}
+
+	empty := &models.RunStats{}
+	if empty.RowsPerSecond() != 0 || empty.DedupeRate() != 0 {
+		t.Error("empty run should report zero throughput and dedupe rate")
+	}
+}
+
+func TestRunStats_AppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "stats.jsonl")
+
+	runs, err := models.LoadRunStats(path)
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("LoadRunStats() on missing file = %v, %v; want no runs", runs, err)
+	}
+
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := &models.RunStats{Time: base.Add(time.Hour), InputFiles: []string{"b.csv"}, InputRecords: 10, Duration: time.Second}
+	earlier := &models.RunStats{Time: base, InputFiles: []string{"a.csv"}, InputRecords: 30, Duration: time.Second}
+	for _, run := range []*models.RunStats{later, earlier} {
+		if err := models.AppendRunStats(path, run); err != nil {
+			t.Fatalf("AppendRunStats() error = %v", err)
+		}
+	}
+
+	runs, err = models.LoadRunStats(path)
+	if err != nil {
+		t.Fatalf("LoadRunStats() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].InputFiles[0] != "a.csv" || runs[1].InputFiles[0] != "b.csv" {
+		t.Fatalf("LoadRunStats() = %+v, want runs sorted oldest first", runs)
+	}
+	if !runs[0].Time.Equal(base) || runs[0].Duration != time.Second {
+		t.Errorf("round trip lost data: %+v", runs[0])
+	}
+	if median := models.MedianRowsPerSecond(runs); median != 20 {
+		t.Errorf("MedianRowsPerSecond() = %v, want 20", median)
+	}
+
+	if err := os.WriteFile(path, []byte("not json\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := models.LoadRunStats(path); err == nil {
+		t.Error("LoadRunStats() should reject a corrupt file")
+	}
+}
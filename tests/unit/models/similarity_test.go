@@ -0,0 +1,64 @@
+package models_test
+
+import (
+	"math"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+// TestSimilarityFuncs verifies the jaccard and levenshtein measures
+func TestSimilarityFuncs(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		a, b   string
+		want   float64
+	}{
+		{name: "jaccard reordered", method: "jaccard", a: "to run", b: "run (to)", want: 1.0},
+		{name: "jaccard partial", method: "jaccard", a: "big red house", b: "red house", want: 2.0 / 3.0},
+		{name: "jaccard disjoint", method: "jaccard", a: "cat", b: "dog", want: 0.0},
+		{name: "levenshtein identical ignoring case", method: "levenshtein", a: "Maison", b: "maison", want: 1.0},
+		{name: "levenshtein one edit", method: "levenshtein", a: "house", b: "houses", want: 1.0 - 1.0/6.0},
+		{name: "levenshtein unicode", method: "levenshtein", a: "école", b: "ecole", want: 0.8},
+		{name: "levenshtein empty", method: "levenshtein", a: "", b: "", want: 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			similarity, err := models.GetSimilarityFunc(tt.method)
+			if err != nil {
+				t.Fatalf("GetSimilarityFunc(%q) failed: %v", tt.method, err)
+			}
+			if got := similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("%s(%q, %q) = %f, want %f", tt.method, tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := models.GetSimilarityFunc("soundex"); err == nil {
+		t.Error("Expected error for unknown similarity method")
+	}
+}
+
+// TestClusterSimilar verifies transitive grouping in input order
+func TestClusterSimilar(t *testing.T) {
+	values := []string{"to run", "cat", "run (to)", "", "dog", "run"}
+	var entries []*models.DataEntry
+	for i, v := range values {
+		entries = append(entries, models.NewDataEntry(map[string]string{"English": v}, "test.csv", i+2))
+	}
+
+	clusters := models.ClusterSimilar(entries, "English", 0.5, models.JaccardSimilarity)
+	if len(clusters) != 1 {
+		t.Fatalf("Expected 1 cluster, got %d", len(clusters))
+	}
+
+	var lines []int
+	for _, entry := range clusters[0] {
+		lines = append(lines, entry.LineNumber)
+	}
+	if len(lines) != 3 || lines[0] != 2 || lines[1] != 4 || lines[2] != 7 {
+		t.Errorf("Expected cluster lines [2 4 7], got %v", lines)
+	}
+}
@@ -0,0 +1,71 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func srsEntries() []*models.DataEntry {
+	return []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Tags": "Tags", "Front": "Front", "Back": "Back", "Example": "Example"}, "a.csv", 0),
+		models.NewDataEntry(map[string]string{"Tags": "animals", "Front": "<b>chat</b>", "Back": "cat", "Example": "Le chat dort.<br>The cat sleeps."}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Tags": "", "Front": "chien", "Back": "dog &amp; hound", "Example": ""}, "a.csv", 3),
+	}
+}
+
+var srsHeaders = []string{"Tags", "Front", "Back", "Example"}
+
+func TestWriteMochi(t *testing.T) {
+	var buf strings.Builder
+	if err := models.WriteMochi(&buf, srsEntries(), srsHeaders); err != nil {
+		t.Fatalf("WriteMochi failed: %v", err)
+	}
+
+	expected := "<b>chat</b>\n---\ncat\n\nLe chat dort.\nThe cat sleeps.\n" +
+		"***\nchien\n---\ndog &amp; hound\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+func TestWriteRemNote(t *testing.T) {
+	var buf strings.Builder
+	if err := models.WriteRemNote(&buf, srsEntries(), srsHeaders); err != nil {
+		t.Fatalf("WriteRemNote failed: %v", err)
+	}
+
+	expected := "chat,\"cat\nLe chat dort.\nThe cat sleeps.\"\n" +
+		"chien,dog & hound\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+func TestWriteQuizlet(t *testing.T) {
+	var buf strings.Builder
+	if err := models.WriteQuizlet(&buf, srsEntries(), srsHeaders); err != nil {
+		t.Fatalf("WriteQuizlet failed: %v", err)
+	}
+
+	expected := "chat\tcat / Le chat dort. / The cat sleeps.\n" +
+		"chien\tdog & hound\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+func TestNewOutputFormat(t *testing.T) {
+	for _, format := range []string{"", "csv", "tsv", "mochi", "remnote", "quizlet"} {
+		if _, ok := models.NewOutputFormat(format, models.SeparatorComma); !ok {
+			t.Errorf("Expected format %q to be known", format)
+		}
+	}
+	if _, ok := models.NewOutputFormat("apkg", models.SeparatorComma); ok {
+		t.Error("Expected apkg to be unknown")
+	}
+	if ext := models.FormatExtension("mochi", models.SeparatorComma); ext != ".md" {
+		t.Errorf("Expected .md for mochi, got %q", ext)
+	}
+}
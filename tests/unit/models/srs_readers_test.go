@@ -0,0 +1,71 @@
+package models_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestParseQuizlet(t *testing.T) {
+	inputFile := models.NewInputFile("set.txt")
+	content := "chat\tcat\n\nchien\tdog\tfaithful\r\n"
+	if err := models.ParseQuizlet(strings.NewReader(content), inputFile, "\t", "\n"); err != nil {
+		t.Fatalf("ParseQuizlet failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(inputFile.Headers, []string{"Front", "Back"}) {
+		t.Errorf("Unexpected headers %v", inputFile.Headers)
+	}
+	expected := [][]string{{"chat", "cat"}, {"chien", "dog\tfaithful"}}
+	if !reflect.DeepEqual(inputFile.Records, expected) {
+		t.Errorf("Expected %q, got %q", expected, inputFile.Records)
+	}
+	if inputFile.LineNumber(1) != 3 {
+		t.Errorf("Expected second card on line 3, got %d", inputFile.LineNumber(1))
+	}
+}
+
+func TestParseQuizlet_CustomSeparators(t *testing.T) {
+	inputFile := models.NewInputFile("set.txt")
+	if err := models.ParseQuizlet(strings.NewReader("chat - cat;chien - dog;"), inputFile, " - ", ";"); err != nil {
+		t.Fatalf("ParseQuizlet failed: %v", err)
+	}
+	expected := [][]string{{"chat", "cat"}, {"chien", "dog"}}
+	if !reflect.DeepEqual(inputFile.Records, expected) {
+		t.Errorf("Expected %q, got %q", expected, inputFile.Records)
+	}
+
+	if err := models.ParseQuizlet(strings.NewReader("chat cat\n"), models.NewInputFile("bad.txt"), "\t", "\n"); err == nil {
+		t.Error("Expected an error for a row without a term separator")
+	}
+}
+
+func TestParseSeparatorName(t *testing.T) {
+	tests := map[string]string{"tab": "\t", "Comma": ",", "semicolon": ";", "newline": "\n", " - ": " - "}
+	for name, want := range tests {
+		if got, err := models.ParseSeparatorName(name); err != nil || got != want {
+			t.Errorf("ParseSeparatorName(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := models.ParseSeparatorName(""); err == nil {
+		t.Error("Expected an error for an empty separator")
+	}
+}
+
+func TestNormalizeMemrise(t *testing.T) {
+	inputFile := models.NewInputFile("course.csv")
+	inputFile.Headers = []string{"French", "English", "Level"}
+	if err := models.NormalizeMemrise(inputFile); err != nil {
+		t.Fatalf("NormalizeMemrise failed: %v", err)
+	}
+	if !reflect.DeepEqual(inputFile.Headers, []string{"Front", "Back", "Level"}) {
+		t.Errorf("Unexpected headers %v", inputFile.Headers)
+	}
+
+	inputFile.Headers = []string{"French"}
+	if err := models.NormalizeMemrise(inputFile); err == nil {
+		t.Error("Expected an error for a single column")
+	}
+}
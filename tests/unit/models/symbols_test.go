@@ -0,0 +1,75 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestNormalizeSymbols(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		mode  string
+		want  string
+	}{
+		{"fullwidth punctuation", "本当？！ 時間：", models.SymbolsASCII, "本当?! 時間:"},
+		{"fullwidth letters and space", "ＡＢＣ\u3000１２", models.SymbolsASCII, "ABC 12"},
+		{"ascii punctuation", "本当?! (ほんとう)", models.SymbolsFullwidth, "本当？！ （ほんとう）"},
+		{"markup is kept", "<b>本当</b>?&amp; {{c1::時間::hint}}:", models.SymbolsFullwidth, "<b>本当</b>？&amp; {{c1::時間::hint}}："},
+		{"media reference is kept", "你好! [sound:ni_hao.mp3]", models.SymbolsFullwidth, "你好！ [sound:ni_hao.mp3]"},
+		{"url is kept", "見て: https://example.com/a,b (例)", models.SymbolsFullwidth, "見て： https://example.com/a,b （例）"},
+		{"digit groups are kept", "1,000円, 12:30:", models.SymbolsFullwidth, "1,000円， 12:30："},
+		{"emoji only", "❤\uFE0F！", models.SymbolsEmoji, "❤！"},
+		{"text variation", "❤\uFE0E", models.SymbolsASCII, "❤"},
+		{"keycap keeps its selector", "1\uFE0F\u20E3", models.SymbolsEmoji, "1\uFE0F\u20E3"},
+		{"joined emoji keep their selector", "\U0001F3F3\uFE0F\u200D\U0001F308", models.SymbolsEmoji, "\U0001F3F3\uFE0F\u200D\U0001F308"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.NormalizeSymbols(tt.value, tt.mode); got != tt.want {
+				t.Errorf("NormalizeSymbols(%q, %s) = %q, want %q", tt.value, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSymbolRule(t *testing.T) {
+	rule, err := models.ParseSymbolRule(" Front : ascii ")
+	if err != nil {
+		t.Fatalf("ParseSymbolRule failed: %v", err)
+	}
+	if rule.Column != "Front" || rule.Mode != models.SymbolsASCII {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+
+	for _, bad := range []string{"Front", ":ascii", "Front:latin"} {
+		if _, err := models.ParseSymbolRule(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestApplySymbolRules(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "なに？", "Back": "what？", "Deck": "日本語::語彙"}, "vocab.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "はい", "Back": "yes ❤\uFE0F"}, "vocab.csv", 3),
+	}
+	rules := []*models.SymbolRule{
+		{Column: models.AllColumns, Mode: models.SymbolsEmoji},
+		{Column: "Back", Mode: models.SymbolsASCII},
+	}
+	if changed := models.ApplySymbolRules(entries, rules); changed != 2 {
+		t.Errorf("Expected 2 changed cells, got %d", changed)
+	}
+	if got := entries[0].GetValue("Front"); got != "なに？" {
+		t.Errorf("Expected Front to keep its fullwidth mark, got %q", got)
+	}
+	if got := entries[0].GetValue("Back"); got != "what?" {
+		t.Errorf("Expected what?, got %q", got)
+	}
+	if got := entries[1].GetValue("Back"); got != "yes ❤" {
+		t.Errorf("Expected the variation selector to go, got %q", got)
+	}
+}
@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"bytes"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestEscapeTSVField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		want  string
+	}{
+		{"plain", "bonjour", "bonjour"},
+		{"newline", "line one\nline two", "line one<br>line two"},
+		{"crlf", "line one\r\nline two", "line one<br>line two"},
+		{"tab", "a\tb", "a&#9;b"},
+		{"quotes", `say "hi"`, `"say ""hi"""`},
+		{"comma untouched", "a, b", "a, b"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.EscapeTSVField(tt.field); got != tt.want {
+				t.Errorf("EscapeTSVField(%q) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTSVWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	writer := models.NewTSVWriter(&buf)
+
+	records := [][]string{
+		{"chat", "cat\nfeline", "animal\tpet"},
+		{"", "dog", ""},
+	}
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		t.Fatalf("Error() = %v", err)
+	}
+
+	want := "chat\tcat<br>feline\tanimal&#9;pet\n\tdog\t\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
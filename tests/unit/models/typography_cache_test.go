@@ -0,0 +1,109 @@
+package models_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func cacheInput(path string, records ...[]string) *models.InputFile {
+	inputFile := models.NewInputFile(path)
+	inputFile.Headers = []string{"Front", "Back"}
+	inputFile.Records = records
+	return inputFile
+}
+
+func cacheEntries(inputFiles ...*models.InputFile) []*models.DataEntry {
+	entries, _ := models.BuildEntries(inputFiles, []string{"Front", "Back"}, false)
+	return entries
+}
+
+// formatWithCache runs typography on entries through a cache in dir, as the
+// command does, and returns how many entries were formatted
+func formatWithCache(t *testing.T, dir string, entries []*models.DataEntry, inputFiles []*models.InputFile, options ...string) int {
+	t.Helper()
+	cache, err := models.NewTypographyCache(dir, inputFiles, options...)
+	if err != nil {
+		t.Fatalf("NewTypographyCache failed: %v", err)
+	}
+	pending := cache.Apply(entries)
+	slow, err := models.ApplyTypographyLimit(context.Background(), pending, nil, true, false, false, false, 0)
+	if err != nil {
+		t.Fatalf("ApplyTypographyLimit failed: %v", err)
+	}
+	cache.Store(pending, slow)
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return len(pending)
+}
+
+func TestTypographyCache(t *testing.T) {
+	dir := t.TempDir()
+	a := cacheInput("a.csv", []string{"Quoi ?", "what"}, []string{"chat", "cat"})
+	b := cacheInput("b.csv", []string{"Oui !", "yes"})
+
+	if formatted := formatWithCache(t, dir, cacheEntries(a, b), []*models.InputFile{a, b}, "french"); formatted != 3 {
+		t.Errorf("Expected every entry formatted on the first run, got %d", formatted)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("Expected one segment per input file, got %d", len(files))
+	}
+
+	// Unchanged inputs come from the cache with the same values
+	entries := cacheEntries(a, b)
+	if formatted := formatWithCache(t, dir, entries, []*models.InputFile{a, b}, "french"); formatted != 0 {
+		t.Errorf("Expected no entry formatted on the second run, got %d", formatted)
+	}
+	if got := entries[0].GetValue("Front"); got != "Quoi\u202F?" {
+		t.Errorf("Expected cached French spacing, got %q", got)
+	}
+
+	// A changed file is formatted again, the other one is not
+	b = cacheInput("b.csv", []string{"Oui !", "yes"}, []string{"Non !", "no"})
+	if formatted := formatWithCache(t, dir, cacheEntries(a, b), []*models.InputFile{a, b}, "french"); formatted != 2 {
+		t.Errorf("Expected the changed file's 2 entries formatted, got %d", formatted)
+	}
+
+	// Other options find no segment
+	if formatted := formatWithCache(t, dir, cacheEntries(a, b), []*models.InputFile{a, b}, "french", "smart-quotes"); formatted != 4 {
+		t.Errorf("Expected every entry formatted with other options, got %d", formatted)
+	}
+}
+
+func TestTypographyCache_CorruptSegment(t *testing.T) {
+	dir := t.TempDir()
+	a := cacheInput("a.csv", []string{"Quoi ?", "what"})
+	formatWithCache(t, dir, cacheEntries(a), []*models.InputFile{a})
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("Expected one segment, got %d", len(files))
+	}
+	if err := os.WriteFile(dir+"/"+files[0].Name(), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := cacheEntries(a)
+	if formatted := formatWithCache(t, dir, entries, []*models.InputFile{a}); formatted != 1 {
+		t.Errorf("Expected an unreadable segment to be a cache miss, got %d formatted", formatted)
+	}
+	if got := entries[0].GetValue("Front"); got != "Quoi\u202F?" {
+		t.Errorf("Expected French spacing, got %q", got)
+	}
+}
+
+func TestTypographyCache_Nil(t *testing.T) {
+	var cache *models.TypographyCache
+	entries := cacheEntries(cacheInput("a.csv", []string{"chat", "cat"}))
+	if pending := cache.Apply(entries); len(pending) != 1 {
+		t.Errorf("Expected a nil cache to return every entry, got %d", len(pending))
+	}
+	cache.Store(entries, nil)
+	if err := cache.Save(); err != nil {
+		t.Errorf("Expected no error from a nil cache, got %v", err)
+	}
+}
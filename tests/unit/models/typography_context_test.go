@@ -127,7 +127,7 @@ func TestTypographyResult_Contract(t *testing.T) {
 			ProcessedText: "Question\u00A0: The capital is {{c1::Paris}}",
 			ClozeCount:    1,
 			WarningCount:  0,
-			Warnings:      []string{},
+			Warnings:      []*models.Issue{},
 		}
 
 		// Test contract guarantees
@@ -146,9 +146,9 @@ func TestTypographyResult_Contract(t *testing.T) {
 	})
 
 	t.Run("warning consistency", func(t *testing.T) {
-		warnings := []string{
-			"Malformed cloze block at position 10",
-			"Invalid cloze number: 0",
+		warnings := []*models.Issue{
+			{Severity: models.SeverityWarning, Code: "malformed-cloze", Message: "Malformed cloze block at position 10"},
+			{Severity: models.SeverityWarning, Code: "malformed-cloze", Message: "Invalid cloze number: 0"},
 		}
 
 		result := models.TypographyResult{
@@ -1,5 +1,4 @@
 package models
-package models
 
 import (
 	"strings"
@@ -147,4 +146,195 @@ func TestApplyGuillemetSpacing_Enhanced(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestProcessText_ProtectsCode verifies code samples keep their quotes and spacing
+func TestProcessText_ProtectsCode(t *testing.T) {
+	processor := models.NewTypographyProcessor(true, true)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "inline code",
+			input: `Use "print" like this: ` + "`print('a: b')`",
+			want:  "Use \u201cprint\u201d like this\u202f: `print('a: b')`",
+		},
+		{
+			name:  "fenced block",
+			input: "Exemple :\n```\nx = {\"a\": 1}\n```",
+			want:  "Exemple\u202f:\n```\nx = {\"a\": 1}\n```",
+		},
+		{
+			name:  "html code element",
+			input: `Voir <code class="py">d["k"]?</code> !`,
+			want:  "Voir <code class=\"py\">d[\"k\"]?</code>\u202f!",
+		},
+		{
+			name:  "pre element",
+			input: "<PRE>if x: print('y')</PRE>",
+			want:  "<PRE>if x: print('y')</PRE>",
+		},
+		{
+			name:  "unterminated backtick is text",
+			input: `Say "hi" ` + "`open",
+			want:  "Say \u201chi\u201d `open",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := processor.ProcessText(tt.input); got != tt.want {
+				t.Errorf("ProcessText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProcessText_ClozeHintQuotes tests that smart quotes convert the answers
+// of cloze deletions but leave the quotes of their hints straight
+func TestProcessText_ClozeHintQuotes(t *testing.T) {
+	processor := models.NewTypographyProcessor(false, true)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "hint quotes kept",
+			input: `{{c1::"oui"::say "yes"}}`,
+			want:  "{{c1::\u201coui\u201d::say \"yes\"}}",
+		},
+		{
+			name:  "quotes around a cloze",
+			input: `He said "{{c1::bonjour::"hello"}}" twice`,
+			want:  "He said \u201c{{c1::bonjour::\"hello\"}}\u201d twice",
+		},
+		{
+			name:  "no hint",
+			input: `{{c1::"oui"}} and "non"`,
+			want:  "{{c1::\u201coui\u201d}} and \u201cnon\u201d",
+		},
+		{
+			name:  "unclosed deletion",
+			input: `{{c1::"oui" :: "non"`,
+			want:  "{{c1::\u201coui\u201d :: \u201cnon\u201d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := processor.ProcessText(tt.input); got != tt.want {
+				t.Errorf("ProcessText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProcessText_ClozeScanner tests that French spacing leaves alone cloze
+// deletions with braces or line breaks in them
+func TestProcessText_ClozeScanner(t *testing.T) {
+	processor := models.NewTypographyProcessor(true, true)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "MathJax braces",
+			input: `Formule : {{c1::\(x^{2}: y\)::"carré"}} !`,
+			want:  "Formule\u202f: {{c1::\\(x^{2}: y\\)::\"carré\"}}\u202f!",
+		},
+		{
+			name:  "line break in deletion",
+			input: "Villes : {{c1::Paris;\nRome!}} ?",
+			want:  "Villes\u202f: {{c1::Paris;\nRome!}}\u202f?",
+		},
+		{
+			name:  "nested deletion",
+			input: `{{c1::le {{c2::"chat"::animal : "félin"}} noir}} : fin`,
+			want:  "{{c1::le {{c2::\u201cchat\u201d::animal : \"félin\"}} noir}}\u202f: fin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := processor.ProcessText(tt.input); got != tt.want {
+				t.Errorf("ProcessText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProcessText_ManyProtectedRegions tests that cells with thousands of
+// cloze deletions or code spans are processed intact and in linear time
+func TestProcessText_ManyProtectedRegions(t *testing.T) {
+	processor := models.NewTypographyProcessor(true, true)
+
+	tests := []struct {
+		name, input string
+	}{
+		{"cloze deletions", strings.Repeat("{{c1::a : b}} ", 20000)},
+		{"code spans", strings.Repeat("`a: 'b'` ", 20000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := processor.ProcessText(tt.input); got != tt.input {
+				t.Errorf("Expected the protected regions to be unchanged, got %q...", got[:40])
+			}
+		})
+	}
+}
+
+// TestProcessText_Chunks tests that a large cell processed in chunks gets
+// the same typography as when processed whole
+func TestProcessText_Chunks(t *testing.T) {
+	paragraph := "<p>Il a dit : \"oui\" ; l'homme 'seul' ! {{c1::Paris}} « ville »</p>\n" +
+		"<p>Quote across\n\"lines\", a cloze {{c2::across\nlines}} and `code: \"x\"`</p>"
+	text := strings.Repeat(paragraph, 5000)
+
+	whole := models.NewTypographyProcessor(true, true)
+	whole.ChunkBytes = 0
+	expected := whole.ProcessText(text)
+
+	chunked := models.NewTypographyProcessor(true, true)
+	if len(text) <= chunked.ChunkBytes {
+		t.Fatalf("Expected a text over %d bytes, got %d", chunked.ChunkBytes, len(text))
+	}
+	if got := chunked.ProcessText(text); got != expected {
+		t.Errorf("Expected chunked processing to match whole processing")
+	}
+}
+
+// TestProcessText_ChunksClozeQuotes tests that quotes inside a cloze
+// deletion, which are converted on their own, do not make a chunk boundary
+// look balanced
+func TestProcessText_ChunksClozeQuotes(t *testing.T) {
+	text := "\"a {{c1::b\"}}\nfiller text here\nc\" d"
+
+	whole := models.NewTypographyProcessor(false, true)
+	whole.ChunkBytes = 0
+	expected := whole.ProcessText(text)
+
+	chunked := models.NewTypographyProcessor(false, true)
+	chunked.ChunkBytes = 4
+	if got := chunked.ProcessText(text); got != expected {
+		t.Errorf("Expected chunked processing to give %q, got %q", expected, got)
+	}
+}
+
+// TestProcessText_PlaceholderText tests that text looking like an internal
+// placeholder is kept as it is
+func TestProcessText_PlaceholderText(t *testing.T) {
+	processor := models.NewTypographyProcessor(true, false)
+
+	input := "__CLOZE_PLACEHOLDER_0__ {{c1::a}} `b` __CODE_PLACEHOLDER_0__"
+	if got := processor.ProcessText(input); got != input {
+		t.Errorf("ProcessText(%q) = %q, want it unchanged", input, got)
+	}
+}
@@ -0,0 +1,101 @@
+package models_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected string
+	}{
+		{
+			name:     "equal",
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "changed line",
+			from: "a\nb\nc\n",
+			to:   "a\nB\nc\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "added to empty",
+			from: "",
+			to:   "a\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "missing final newline",
+			from: "a\n",
+			to:   "a",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.UnifiedDiff("old", "new", tt.from, tt.to); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_Hunks(t *testing.T) {
+	var from, to []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		from = append(from, line)
+		to = append(to, line)
+	}
+	to[1] = "X"
+	to[17] = "Y"
+
+	diff := models.UnifiedDiff("old", "new", strings.Join(from, "\n")+"\n", strings.Join(to, "\n")+"\n")
+	if got := strings.Count(diff, "@@ -"); got != 2 {
+		t.Fatalf("Expected 2 hunks for distant changes, got %d:\n%s", got, diff)
+	}
+	for _, want := range []string{"@@ -1,5 +1,5 @@\n", "@@ -15,6 +15,6 @@\n"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Expected hunk header %q in:\n%s", want, diff)
+		}
+	}
+}
+
+// TestUnifiedDiff_Large tests that long texts are diffed without a table of
+// every pair of lines: lines they share at both ends are skipped, and a
+// middle too large to compare is shown as removed then added
+func TestUnifiedDiff_Large(t *testing.T) {
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d\n", i)
+	}
+	from := strings.Join(lines, "")
+	lines[10000] = "changed\n"
+	to := strings.Join(lines, "")
+
+	diff := models.UnifiedDiff("old", "new", from, to)
+	if want := "@@ -9998,7 +9998,7 @@\n line 9997\n line 9998\n line 9999\n-line 10000\n+changed\n line 10001\n"; !strings.Contains(diff, want) {
+		t.Errorf("Expected hunk %q, got:\n%s", want, diff)
+	}
+
+	var other strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&other, "other %d\n", i)
+	}
+	diff = models.UnifiedDiff("old", "new", strings.Join(lines[:10000], ""), other.String())
+	if removed, added := strings.Count(diff, "\n-line "), strings.Count(diff, "\n+other "); removed != 10000 || added != 5000 {
+		t.Errorf("Expected 10000 removed and 5000 added lines, got %d and %d", removed, added)
+	}
+}
@@ -0,0 +1,35 @@
+package models_test
+
+import (
+	"testing"
+
+	"ankiprep/internal/models"
+)
+
+func TestFindCollisions(t *testing.T) {
+	entries := []*models.DataEntry{
+		models.NewDataEntry(map[string]string{"Front": "Front", "Back": "Back"}, "a.csv", 0),
+		models.NewDataEntry(map[string]string{"Front": "chat", "Back": "cat"}, "a.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "chien", "Back": ""}, "a.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "chat ", "Back": "tomcat"}, "b.csv", 2),
+		models.NewDataEntry(map[string]string{"Front": "Chat", "Back": ""}, "b.csv", 3),
+		models.NewDataEntry(map[string]string{"Front": "Front", "Back": "cat"}, "b.csv", 4),
+	}
+
+	collisions := models.FindCollisions(entries, []string{"Front", "Back"})
+	if len(collisions) != 2 {
+		t.Fatalf("Expected 2 collisions, got %d", len(collisions))
+	}
+
+	// Surrounding spaces are ignored, case and header rows are not
+	front := collisions[0]
+	if front.Column != "Front" || front.Value != "chat" || len(front.Locations) != 2 ||
+		front.Locations[0].String() != "a.csv:2" || front.Locations[1].String() != "b.csv:2" {
+		t.Errorf("Unexpected collision %+v", front)
+	}
+	// Empty values are not collisions
+	back := collisions[1]
+	if back.Column != "Back" || back.Value != "cat" || len(back.Locations) != 2 || back.Locations[1].String() != "b.csv:4" {
+		t.Errorf("Unexpected collision %+v", back)
+	}
+}
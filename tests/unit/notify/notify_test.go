@@ -0,0 +1,134 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/models"
+	"ankiprep/internal/notify"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	var received notify.Event
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode webhook body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	report := models.NewProcessingReport()
+	report.SetCounts(3, 1, 2)
+	event := &notify.Event{Status: notify.StatusCompleted, Output: "vocab_anki.csv", Report: report}
+	if err := notify.NewWebhook(server.URL).Notify(event); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected application/json, got %q", contentType)
+	}
+	if received.Status != notify.StatusCompleted || received.Output != "vocab_anki.csv" {
+		t.Errorf("Unexpected event: %+v", received)
+	}
+	if received.Report == nil || received.Report.OutputRecords != 2 {
+		t.Errorf("Expected the report with 2 output records, got %+v", received.Report)
+	}
+}
+
+func TestWebhook_NotifyRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		requests int
+	}{
+		{"server errors are retried", http.StatusInternalServerError, 3},
+		{"client errors are final", http.StatusNotFound, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				http.Error(w, "nope", tt.status)
+			}))
+			defer server.Close()
+
+			webhook := notify.NewWebhook(server.URL)
+			webhook.Retry = models.NewRetryPolicy(3, 0)
+			err := webhook.Notify(&notify.Event{Status: notify.StatusFailed})
+			if err == nil || !strings.Contains(err.Error(), http.StatusText(tt.status)) {
+				t.Errorf("Expected %d error, got %v", tt.status, err)
+			}
+			if requests != tt.requests {
+				t.Errorf("Expected %d request(s), got %d", tt.requests, requests)
+			}
+		})
+	}
+}
+
+func TestEmail_Message(t *testing.T) {
+	email := &notify.Email{Server: "localhost:25", From: "ankiprep@localhost", To: []string{"a@example.com", "b@example.com"}}
+	report := models.NewProcessingReport()
+
+	t.Run("completed", func(t *testing.T) {
+		message, err := email.Message(&notify.Event{Status: notify.StatusCompleted, Output: "out.csv", Report: report})
+		if err != nil {
+			t.Fatalf("Message failed: %v", err)
+		}
+		for _, want := range []string{
+			"From: ankiprep@localhost\r\n",
+			"To: a@example.com, b@example.com\r\n",
+			"Subject: ankiprep run completed: out.csv\r\n",
+			"The notes were written to out.csv\r\n",
+			"\"output_records\": 0",
+		} {
+			if !strings.Contains(string(message), want) {
+				t.Errorf("Expected %q in message:\n%s", want, message)
+			}
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		message, err := email.Message(&notify.Event{Status: notify.StatusFailed, Error: "Error: file not found", Report: report})
+		if err != nil {
+			t.Fatalf("Message failed: %v", err)
+		}
+		for _, want := range []string{"Subject: ankiprep run failed\r\n", "The run failed: Error: file not found\r\n"} {
+			if !strings.Contains(string(message), want) {
+				t.Errorf("Expected %q in message:\n%s", want, message)
+			}
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		message, err := email.Message(&notify.Event{Status: notify.StatusCancelled, Error: "Cancelled by interrupt after 2.00 seconds", Report: report})
+		if err != nil {
+			t.Fatalf("Message failed: %v", err)
+		}
+		for _, want := range []string{"Subject: ankiprep run cancelled\r\n", "Cancelled by interrupt after 2.00 seconds\r\n"} {
+			if !strings.Contains(string(message), want) {
+				t.Errorf("Expected %q in message:\n%s", want, message)
+			}
+		}
+	})
+
+	t.Run("line breaks in headers", func(t *testing.T) {
+		message, err := email.Message(&notify.Event{Status: notify.StatusCompleted, Output: "out.csv\r\nBcc: x@example.com", Report: report})
+		if err != nil {
+			t.Fatalf("Message failed: %v", err)
+		}
+		if !strings.Contains(string(message), "Subject: ankiprep run completed: out.csv Bcc: x@example.com\r\n") {
+			t.Errorf("Expected the line break in the subject replaced, got:\n%s", message)
+		}
+		headers, _, _ := strings.Cut(string(message), "\r\n\r\n")
+		if strings.Contains(headers, "\r\nBcc:") {
+			t.Errorf("Expected no injected header, got:\n%s", message)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ankiprep/internal/server"
+)
+
+// upload builds a multipart /convert request with the given files and fields
+func upload(t *testing.T, files map[string]string, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(part, content)
+	}
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/convert", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestConvert(t *testing.T) {
+	request := upload(t,
+		map[string]string{"vocab.csv": "Front,Back\nQuoi?,What?\nQuoi?,What?\n"},
+		map[string]string{"french": "true", "skip_duplicates": "on"})
+	recorder := httptest.NewRecorder()
+	server.NewHandler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	expected := "#separator:comma\n#html:true\n#columns:Front,Back\nQuoi\u202f?,What\u202f?\n"
+	if recorder.Body.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, recorder.Body.String())
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); !strings.Contains(disposition, "vocab_processed.csv") {
+		t.Errorf("Unexpected Content-Disposition %q", disposition)
+	}
+	if recorder.Header().Get("X-Ankiprep-Duplicates-Removed") != "1" {
+		t.Errorf("Expected 1 duplicate removed, got %q", recorder.Header().Get("X-Ankiprep-Duplicates-Removed"))
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		fields map[string]string
+		status int
+	}{
+		{"no files", nil, nil, http.StatusBadRequest},
+		{"unsupported file", map[string]string{"vocab.xlsx": "x"}, nil, http.StatusBadRequest},
+		{"bad format", map[string]string{"vocab.csv": "a\n1\n"}, map[string]string{"format": "apkg"}, http.StatusBadRequest},
+		{"unknown column", map[string]string{"vocab.csv": "a\n1\n"}, map[string]string{"columns": "b"}, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		server.NewHandler().ServeHTTP(recorder, upload(t, tt.files, tt.fields))
+		if recorder.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.status, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestTypography(t *testing.T) {
+	body, _ := json.Marshal(server.TypographyRequest{Text: `Il dit "oui" !`, French: true, SmartQuotes: true})
+	recorder := httptest.NewRecorder()
+	server.NewHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/typography", bytes.NewReader(body)))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response server.TypographyResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid JSON reply: %v", err)
+	}
+	if response.Text == `Il dit "oui" !` || !strings.Contains(response.Text, "\u202f!") {
+		t.Errorf("Expected processed text, got %q", response.Text)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.NewHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/typography", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", recorder.Code)
+	}
+}
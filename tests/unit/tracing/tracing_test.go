@@ -0,0 +1,228 @@
+package tracing_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ankiprep/internal/tracing"
+)
+
+// exportedSpan is the part of an OTLP/JSON span the tests look at
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Attributes   []struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	} `json:"attributes"`
+	Status *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+// attribute returns the value of the span attribute key, or nil
+func (s exportedSpan) attribute(key string) interface{} {
+	for _, attr := range s.Attributes {
+		if attr.Key == key {
+			for _, value := range attr.Value {
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+// collector starts an OTLP collector recording the spans it receives
+func collector(t *testing.T) (*httptest.Server, chan []exportedSpan) {
+	received := make(chan []exportedSpan, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tracing.TracesPath {
+			t.Errorf("Expected a post to %s, got %s", tracing.TracesPath, r.URL.Path)
+		}
+		var body struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []exportedSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode OTLP body: %v", err)
+		}
+		var spans []exportedSpan
+		for _, resource := range body.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				spans = append(spans, scope.Spans...)
+			}
+		}
+		received <- spans
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestTracer_Flush(t *testing.T) {
+	server, received := collector(t)
+	tracer, err := tracing.New(server.URL, "ankiprep")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	root := tracer.Start("ankiprep", nil)
+	stage := root.Child("deduplication")
+	stage.SetAttribute("records", 3)
+	stage.SetAttribute("file.path", "vocab.csv")
+	stage.End()
+	root.Fail("boom")
+	root.End()
+	if err := tracer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	spans := <-received
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	child, parent := spans[0], spans[1]
+	if child.Name != "deduplication" || parent.Name != "ankiprep" {
+		t.Errorf("Unexpected span names %q and %q", child.Name, parent.Name)
+	}
+	if len(parent.TraceID) != 32 || len(parent.SpanID) != 16 {
+		t.Errorf("Expected hex IDs, got trace %q span %q", parent.TraceID, parent.SpanID)
+	}
+	if child.TraceID != parent.TraceID || child.ParentSpanID != parent.SpanID {
+		t.Errorf("Expected the stage to be a child of the run, got %+v and %+v", child, parent)
+	}
+	if parent.ParentSpanID != "" {
+		t.Errorf("Expected the run span to be a root, got parent %q", parent.ParentSpanID)
+	}
+	if child.attribute("records") != "3" || child.attribute("file.path") != "vocab.csv" {
+		t.Errorf("Unexpected attributes %+v", child.Attributes)
+	}
+	if parent.Status == nil || parent.Status.Message != "boom" || child.Status != nil {
+		t.Errorf("Expected only the run span to fail, got %+v and %+v", parent.Status, child.Status)
+	}
+
+	// Spans are exported once
+	if err := tracer.Flush(); err != nil {
+		t.Fatalf("Second Flush failed: %v", err)
+	}
+	select {
+	case spans := <-received:
+		t.Errorf("Expected nothing to export, got %d spans", len(spans))
+	default:
+	}
+}
+
+func TestTracer_FlushClientError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	tracer, err := tracing.New(server.URL, "ankiprep")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	tracer.Start("ankiprep", nil).End()
+	if err := tracer.Flush(); err == nil {
+		t.Error("Expected Flush to fail")
+	}
+	if requests != 1 {
+		t.Errorf("Expected client errors not to be retried, got %d requests", requests)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces", false},
+		{"https://collector.example.com/", "https://collector.example.com/v1/traces", false},
+		{"http://localhost:4318/custom/traces", "http://localhost:4318/custom/traces", false},
+		{"localhost:4318", "", true},
+		{"ftp://localhost", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			tracer, err := tracing.New(tt.endpoint, "ankiprep")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tracer.Endpoint != tt.want {
+				t.Errorf("Expected endpoint %q, got %q", tt.want, tracer.Endpoint)
+			}
+		})
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *tracing.Tracer
+	span := tracer.Start("ankiprep", nil)
+	if span != nil {
+		t.Fatalf("Expected a nil span from a nil tracer, got %+v", span)
+	}
+	child := span.Child("parsing")
+	child.SetAttribute("records", 1)
+	child.Fail("boom")
+	child.End()
+	if err := tracer.Flush(); err != nil {
+		t.Errorf("Expected Flush of a nil tracer to do nothing, got %v", err)
+	}
+
+	next := http.NotFoundHandler()
+	if handler := tracer.Handler(next, nil); handler == nil {
+		t.Error("Expected a nil tracer to return the handler unchanged")
+	}
+}
+
+func TestTracer_Handler(t *testing.T) {
+	server, received := collector(t)
+	tracer, err := tracing.New(server.URL, "ankiprep")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	handler := tracer.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := tracing.SpanFromContext(r.Context()).Child("convert")
+		span.End()
+		w.WriteHeader(http.StatusBadGateway)
+	}), func(err error) {
+		t.Errorf("Export failed: %v", err)
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/convert", nil))
+	if recorder.Code != http.StatusBadGateway {
+		t.Errorf("Expected the handler's status, got %d", recorder.Code)
+	}
+
+	spans := <-received
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	child, request := spans[0], spans[1]
+	if request.Name != "POST /convert" || request.Kind != 2 {
+		t.Errorf("Expected a server span for the request, got %+v", request)
+	}
+	if child.ParentSpanID != request.SpanID {
+		t.Errorf("Expected convert to be a child of the request span")
+	}
+	if request.attribute("http.response.status_code") != "502" {
+		t.Errorf("Expected status code 502, got %v", request.attribute("http.response.status_code"))
+	}
+	if request.Status == nil {
+		t.Error("Expected a server error to fail the span")
+	}
+}
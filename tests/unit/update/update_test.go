@@ -0,0 +1,117 @@
+package update_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ankiprep/internal/update"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.1.0", "1.0.0", true},
+		{"1.0.10", "1.0.9", true},
+		{"v2.0", "1.9.9", true},
+		{"v1.0.0", "1.0.0", false},
+		{"v1.0.0", "1.0.1", false},
+		{"v1.2.0-rc1", "1.1.0", true},
+		{"1.0.0", "1.0.0-dev", false},
+	}
+
+	for _, tt := range tests {
+		if got := update.IsNewer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("IsNewer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	if got := update.AssetName("darwin", "arm64"); got != "ankiprep_darwin_arm64" {
+		t.Errorf("AssetName(darwin, arm64) = %q", got)
+	}
+	if got := update.AssetName("windows", "amd64"); got != "ankiprep_windows_amd64.exe" {
+		t.Errorf("AssetName(windows, amd64) = %q", got)
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	binary := []byte("binary content")
+	sum := sha256.Sum256(binary)
+	digest := hex.EncodeToString(sum[:])
+
+	sums := update.ParseChecksums([]byte(digest + "  ankiprep_linux_amd64\n" +
+		"ABCDEF *ankiprep_windows_amd64.exe\n" +
+		"malformed line with too many fields\n"))
+
+	if sums["ankiprep_linux_amd64"] != digest {
+		t.Errorf("linux checksum = %q, want %q", sums["ankiprep_linux_amd64"], digest)
+	}
+	if sums["ankiprep_windows_amd64.exe"] != "abcdef" {
+		t.Errorf("windows checksum = %q, want binary-mode entry lower-cased", sums["ankiprep_windows_amd64.exe"])
+	}
+	if len(sums) != 2 {
+		t.Errorf("ParseChecksums() returned %d entries, want 2", len(sums))
+	}
+
+	if err := update.VerifyChecksum(binary, digest); err != nil {
+		t.Errorf("VerifyChecksum() error = %v", err)
+	}
+	if err := update.VerifyChecksum([]byte("tampered"), digest); err == nil {
+		t.Error("VerifyChecksum() should reject tampered content")
+	}
+}
+
+func TestPackageManager(t *testing.T) {
+	tests := map[string]string{
+		"/opt/homebrew/Cellar/ankiprep/1.0.0/bin/ankiprep":     "Homebrew",
+		"/usr/local/Cellar/ankiprep/1.0.0/bin/ankiprep":        "Homebrew",
+		`C:\Users\me\scoop\apps\ankiprep\current\ankiprep.exe`: "Scoop",
+		"/usr/local/bin/ankiprep":                              "",
+		`C:\Tools\ankiprep.exe`:                                "",
+	}
+	for path, want := range tests {
+		if got := update.PackageManager(path); got != want {
+			t.Errorf("PackageManager(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestClient_LatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/ankiprep/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v1.2.0","assets":[{"name":"checksums.txt","browser_download_url":"https://example.com/checksums.txt"}]}`))
+	}))
+	defer server.Close()
+
+	client := update.NewClient()
+	client.BaseURL = server.URL
+	client.Repository = "owner/ankiprep"
+
+	release, err := client.LatestRelease()
+	if err != nil {
+		t.Fatalf("LatestRelease() error = %v", err)
+	}
+	if release.TagName != "v1.2.0" {
+		t.Errorf("TagName = %q, want v1.2.0", release.TagName)
+	}
+	if asset, ok := release.Asset(update.ChecksumsAsset); !ok || asset.URL != "https://example.com/checksums.txt" {
+		t.Errorf("Asset(checksums.txt) = %v, %v", asset, ok)
+	}
+	if _, ok := release.Asset("missing"); ok {
+		t.Error("Asset(missing) should not be found")
+	}
+
+	client.Repository = "owner/unknown"
+	if _, err := client.LatestRelease(); err == nil {
+		t.Error("LatestRelease() should fail for a missing repository")
+	}
+}